@@ -302,6 +302,8 @@ func (b *Boot) createApplication() (*Application, error) {
 	app.AddConfigurer(&LoggerConfigurer{})
 	app.AddConfigurer(&DBStoreConfigurer{})
 	app.AddConfigurer(&CacheConfigurer{})
+	app.AddConfigurer(&QueueConfigurer{})
+	app.AddConfigurer(&LeaderElectionConfigurer{})
 	app.AddConfigurer(&WebConfigurer{})
 
 	// 添加自定义配置器
@@ -433,7 +435,7 @@ type ApplicationMetrics struct {
 //  2. 加载属性源
 //  3. 加载环境变量
 //  4. 创建组件注册表
-//  5. 创建事件总线
+//  5. 按eventbus.*配置创建事件总线
 //  6. 创建自动配置引擎
 //  7. 创建健康检查器
 //  8. 初始化应用指标
@@ -459,7 +461,12 @@ func NewApplication(configPath string) (*Application, error) {
 	registry := NewComponentRegistry(ctx, propSource)
 
 	// 创建事件总线
-	eventBus := NewEventBus()
+	eventBusOptions, err := eventBusOptionsFromProperties(propSource)
+	if err != nil {
+		cancel()
+		return nil, NewConfigError("EventBus", "无效的事件总线配置", err)
+	}
+	eventBus := NewEventBus(eventBusOptions)
 
 	// 创建自动配置引擎
 	autoConfig := NewAutoConfig()
@@ -550,6 +557,9 @@ func (a *Application) Initialize() error {
 		return NewConfigError("Application", "自动配置失败", err)
 	}
 
+	// 输出组件激活报告
+	a.logActivationReport()
+
 	// 解析组件依赖
 	if err := a.registry.ResolveDependencies(); err != nil {
 		a.setState(AppStateFailed)
@@ -571,11 +581,29 @@ func (a *Application) Initialize() error {
 	return nil
 }
 
+// logActivationReport 输出自动配置期间各组件的激活情况及原因
+func (a *Application) logActivationReport() {
+	report := a.registry.GetActivationReport()
+	if len(report) == 0 {
+		return
+	}
+
+	log.Printf("组件激活报告:")
+	for _, decision := range report {
+		log.Printf("  %s", decision)
+	}
+}
+
 // initializeComponents 初始化组件
 func (a *Application) initializeComponents() error {
 	components := a.registry.GetAllComponentsSorted()
 
 	for _, component := range components {
+		// 为需要事件总线的组件注入事件总线
+		if aware, ok := component.(EventBusAware); ok {
+			aware.SetEventBus(a.eventBus)
+		}
+
 		if err := component.Initialize(a.ctx); err != nil {
 			return NewComponentError(
 				component.Name(),
@@ -763,6 +791,11 @@ func (a *Application) Shutdown(ctx context.Context) error {
 	// 发布应用已停止事件
 	a.eventBus.PublishSync("application.stopped", a)
 
+	// 关闭事件总线，排空尚未分发的异步事件
+	if err := a.eventBus.Close(ctx); err != nil {
+		log.Printf("关闭事件总线时发生错误: %v", err)
+	}
+
 	log.Printf("应用 %s 已停止", a.name)
 
 	return nil
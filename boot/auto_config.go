@@ -1,9 +1,37 @@
 package boot
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 )
 
+const (
+	// PropertyComponentsInclude 声明式启用的组件列表，配置后只有列表中的组件会被激活
+	PropertyComponentsInclude = "app.components.include"
+	// PropertyComponentsExclude 声明式禁用的组件列表，优先级高于include和激活器
+	PropertyComponentsExclude = "app.components.exclude"
+)
+
+// ActivationDecision 组件激活决策，记录组件是否被激活以及原因
+type ActivationDecision struct {
+	// Name 组件名称
+	Name string
+	// Activated 是否被激活
+	Activated bool
+	// Reason 激活或未激活的原因
+	Reason string
+}
+
+// String 返回激活决策的可读描述
+func (d ActivationDecision) String() string {
+	state := "activated"
+	if !d.Activated {
+		state = "skipped"
+	}
+	return fmt.Sprintf("%s: %s (%s)", d.Name, state, d.Reason)
+}
+
 // AutoConfig 自动配置引擎
 type AutoConfig struct {
 	configurers []AutoConfigurer
@@ -34,10 +62,17 @@ func (a *AutoConfig) AddActivator(activator ComponentActivator) {
 }
 
 // Configure 执行自动配置
+// 配置期间注册的组件工厂都会经过激活规则过滤：
+// app.components.exclude中的组件不会被注册；配置了app.components.include时只注册列表中的组件；
+// 任意一个对应组件的激活器返回false都会否决注册
 func (a *AutoConfig) Configure(registry *ComponentRegistry, props PropertySource) error {
 	// 设置默认属性
 	a.setDefaultProperties(props)
 
+	// 安装激活过滤器，配置完成后移除
+	registry.setActivationFilter(a.activationFilter(props))
+	defer registry.setActivationFilter(nil)
+
 	// 执行所有配置器
 	for _, configurer := range a.configurers {
 		if err := configurer.Configure(registry, props); err != nil {
@@ -48,6 +83,110 @@ func (a *AutoConfig) Configure(registry *ComponentRegistry, props PropertySource
 	return nil
 }
 
+// activationFilter 根据声明式列表和激活器创建组件激活过滤器
+func (a *AutoConfig) activationFilter(props PropertySource) activationFilter {
+	include := toSet(getStringList(props, PropertyComponentsInclude))
+	exclude := toSet(getStringList(props, PropertyComponentsExclude))
+
+	return func(name string) (bool, string) {
+		if _, excluded := exclude[name]; excluded {
+			return false, "excluded by " + PropertyComponentsExclude
+		}
+
+		if len(include) > 0 {
+			if _, included := include[name]; !included {
+				return false, "not listed in " + PropertyComponentsInclude
+			}
+		}
+
+		var approvedBy []string
+		for _, activator := range a.activators {
+			if activator.ComponentType() != name {
+				continue
+			}
+			if !activator.ShouldActivate(props) {
+				return false, fmt.Sprintf("vetoed by activator %T", activator)
+			}
+			approvedBy = append(approvedBy, fmt.Sprintf("%T", activator))
+		}
+
+		if len(approvedBy) > 0 {
+			return true, "approved by activator " + strings.Join(approvedBy, ", ")
+		}
+		return true, ""
+	}
+}
+
+// registerComponentFactory 按组件的启用开关注册工厂，并记录激活原因
+// enabledKey为空表示组件始终启用；未显式设置开关时，出现在app.components.include中的组件视为启用
+func registerComponentFactory(registry *ComponentRegistry, props PropertySource, name, enabledKey string, defaultEnabled bool, factory ComponentFactory) error {
+	enabled, reason := isComponentEnabled(props, name, enabledKey, defaultEnabled)
+	if !enabled {
+		registry.recordActivation(ActivationDecision{Name: name, Activated: false, Reason: reason})
+		return nil
+	}
+	return registry.registerFactory(name, factory, reason)
+}
+
+// isComponentEnabled 判断组件的启用开关，返回是否启用以及原因
+func isComponentEnabled(props PropertySource, name, enabledKey string, defaultEnabled bool) (bool, string) {
+	if enabledKey == "" {
+		return true, "always enabled"
+	}
+
+	if props.HasProperty(enabledKey) {
+		enabled := props.GetBool(enabledKey, defaultEnabled)
+		return enabled, fmt.Sprintf("%s=%t", enabledKey, enabled)
+	}
+
+	for _, included := range getStringList(props, PropertyComponentsInclude) {
+		if included == name {
+			return true, "listed in " + PropertyComponentsInclude
+		}
+	}
+
+	return defaultEnabled, fmt.Sprintf("%s defaults to %t", enabledKey, defaultEnabled)
+}
+
+// getStringList 读取列表类型的属性，支持逗号分隔的字符串和切片
+func getStringList(props PropertySource, key string) []string {
+	value, exists := props.GetProperty(key)
+	if !exists || value == nil {
+		return nil
+	}
+
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, item := range v {
+			raw = append(raw, fmt.Sprintf("%v", item))
+		}
+	default:
+		return nil
+	}
+
+	result := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// toSet 将字符串切片转换为集合
+func toSet(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
+
 // setDefaultProperties 设置默认属性
 func (a *AutoConfig) setDefaultProperties(props PropertySource) {
 	// 应用默认配置
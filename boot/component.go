@@ -133,7 +133,7 @@ type PropertySchema struct {
 type ComponentFactory interface {
 	// Create 创建组件实例
 	// 参数：
-	//   ctx: 上下文，可用于传递取消信号或超时
+	//   ctx: 上下文，可用于传递取消信号或超时，已创建的依赖组件可通过DependencyFromContext获取
 	//   props: 属性源，提供组件配置属性
 	// 返回：
 	//   创建的组件实例和可能的错误
@@ -190,6 +190,8 @@ type AutoConfigurer interface {
 }
 
 // ComponentActivator 组件激活器接口，决定组件是否应该被激活
+// 自动配置期间，名称与ComponentType()相同的组件工厂在注册前会询问激活器，
+// 任意一个激活器返回false都会否决该组件的注册
 type ComponentActivator interface {
 	// ShouldActivate 判断组件是否应该激活
 	// 参数：
@@ -200,10 +202,18 @@ type ComponentActivator interface {
 
 	// ComponentType 获取组件类型
 	// 返回：
-	//   组件类型的字符串表示
+	//   激活器作用的组件名称，例如"cache"、"web"
 	ComponentType() string
 }
 
+// EventBusAware 需要事件总线的组件实现此接口，应用在初始化组件前注入事件总线
+type EventBusAware interface {
+	// SetEventBus 设置事件总线
+	// 参数：
+	//   bus: 应用的事件总线
+	SetEventBus(bus *EventBus)
+}
+
 // Condition 条件接口，用于条件化配置
 type Condition interface {
 	// Matches 判断条件是否匹配
@@ -3,10 +3,15 @@ package boot
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/guanzhenxing/go-snap/cache"
+	"github.com/guanzhenxing/go-snap/queue"
 )
 
 // NewMemoryPropertySource 创建内存属性源，用于测试
@@ -1247,3 +1252,1073 @@ func TestSimpleDependencyResolution(t *testing.T) {
 		t.Errorf("应该至少有3个组件，但实际有 %d 个", len(components))
 	}
 }
+
+// 测试缓存组件指标
+func TestCacheComponentMetrics(t *testing.T) {
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+
+	// 默认不启用统计，GetCache返回底层缓存
+	factory := &CacheComponentFactory{}
+	component, err := factory.Create(ctx, props)
+	if err != nil {
+		t.Fatalf("CacheComponentFactory.Create failed: %v", err)
+	}
+	if _, ok := component.(*CacheComponent).GetCache().(*cache.MemoryCache); !ok {
+		t.Errorf("Expected *cache.MemoryCache by default, got %T", component.(*CacheComponent).GetCache())
+	}
+	if _, exists := component.GetMetrics()["hits"]; exists {
+		t.Error("cache metrics should not be reported by default")
+	}
+
+	props.SetProperty("cache.metrics.enabled", true)
+	component, err = factory.Create(ctx, props)
+	if err != nil {
+		t.Fatalf("CacheComponentFactory.Create failed: %v", err)
+	}
+	cacheComponent := component.(*CacheComponent)
+	if err := cacheComponent.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	c := cacheComponent.GetCache()
+	if err := c.Set(ctx, "user:1", "alice", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	c.Get(ctx, "user:1")
+	c.Get(ctx, "user:2")
+
+	metrics := cacheComponent.GetMetrics()
+	if metrics["hits"] != uint64(1) || metrics["misses"] != uint64(1) || metrics["sets"] != uint64(1) {
+		t.Errorf("Unexpected cache counters: hits=%v misses=%v sets=%v", metrics["hits"], metrics["misses"], metrics["sets"])
+	}
+	if metrics["hit_rate"] != 0.5 {
+		t.Errorf("Expected hit rate 0.5, got %v", metrics["hit_rate"])
+	}
+	latencies, ok := metrics["latencies"].(map[string]interface{})
+	if !ok || latencies["get"] == nil {
+		t.Errorf("Expected get latency in metrics, got %v", metrics["latencies"])
+	}
+	prefixes, ok := metrics["prefixes"].(map[string]interface{})
+	if !ok || prefixes["user"] == nil {
+		t.Errorf("Expected user prefix in metrics, got %v", metrics["prefixes"])
+	}
+
+}
+
+// 测试缓存组件的快照和预热
+func TestCacheComponentSnapshotAndWarmup(t *testing.T) {
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+	props.SetProperty("cache.memory.snapshot_path", t.TempDir()+"/cache.snapshot")
+
+	factory := &CacheComponentFactory{}
+	create := func() *CacheComponent {
+		component, err := factory.Create(ctx, props)
+		if err != nil {
+			t.Fatalf("CacheComponentFactory.Create failed: %v", err)
+		}
+		cacheComponent := component.(*CacheComponent)
+		if err := cacheComponent.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		return cacheComponent
+	}
+
+	first := create()
+	var healthyDuringWarmup error
+	first.SetWarmup(func(ctx context.Context, c cache.Cache) error {
+		healthyDuringWarmup = first.HealthCheck()
+		return c.Set(ctx, "config:site", "go-snap", time.Hour)
+	})
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if healthyDuringWarmup == nil {
+		t.Error("component should not report healthy before warm-up completes")
+	}
+	if err := first.HealthCheck(); err != nil {
+		t.Errorf("component should be healthy after warm-up: %v", err)
+	}
+	if err := first.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// 新实例从快照恢复
+	second := create()
+	defer second.Stop(ctx)
+	if value, found := second.GetCache().Get(ctx, "config:site"); !found || value != "go-snap" {
+		t.Errorf("Expected value restored from snapshot, got %v (found=%v)", value, found)
+	}
+
+	// 预热失败时启动失败
+	second.SetWarmup(func(ctx context.Context, c cache.Cache) error {
+		return fmt.Errorf("database unavailable")
+	})
+	if err := second.Start(ctx); err == nil {
+		t.Error("Start should fail when warm-up fails")
+	}
+	if second.GetStatus() != ComponentStatusFailed {
+		t.Errorf("Expected failed status, got %v", second.GetStatus())
+	}
+}
+
+// 测试缓存组件报告多级缓存的熔断器状态
+func TestCacheComponentCircuitBreaker(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+	props.SetProperty("cache.type", "multi_level")
+	props.SetProperty("cache.redis.addr", mr.Addr())
+	props.SetProperty("cache.circuit_breaker.enabled", true)
+	props.SetProperty("cache.circuit_breaker.failure_threshold", 1)
+	props.SetProperty("cache.circuit_breaker.open_timeout_ms", 3600000)
+	props.SetProperty("cache.circuit_breaker.operation_timeout_ms", 200)
+
+	factory := &CacheComponentFactory{}
+	if err := factory.ValidateConfig(props); err != nil {
+		t.Fatalf("ValidateConfig failed: %v", err)
+	}
+	created, err := factory.Create(ctx, props)
+	if err != nil {
+		t.Fatalf("CacheComponentFactory.Create failed: %v", err)
+	}
+	component := created.(*CacheComponent)
+	if _, ok := component.GetCache().(*cache.MultiLevelCache); !ok {
+		t.Fatalf("Expected *cache.MultiLevelCache, got %T", component.GetCache())
+	}
+	if err := component.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := component.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer component.Stop(ctx)
+
+	if err := component.HealthCheck(); err != nil {
+		t.Errorf("component should be healthy while redis is up: %v", err)
+	}
+	if state := component.GetMetrics()["circuit_state"]; state != "closed" {
+		t.Errorf("Expected closed circuit, got %v", state)
+	}
+
+	// Redis不可用时熔断，降级期间健康检查通过，在指标中报告降级状态
+	mr.Close()
+	_ = component.HealthCheck()
+	if err := component.HealthCheck(); err != nil {
+		t.Errorf("degraded cache should stay healthy by default: %v", err)
+	}
+	metrics := component.GetMetrics()
+	if metrics["circuit_state"] != "open" || metrics["circuit_trips"] != uint64(1) || metrics["degraded"] != true {
+		t.Errorf("Unexpected circuit metrics: state=%v trips=%v degraded=%v", metrics["circuit_state"], metrics["circuit_trips"], metrics["degraded"])
+	}
+
+	// 配置后降级期间健康检查失败
+	component.failWhenDegraded = true
+	if err := component.HealthCheck(); err == nil || !strings.Contains(err.Error(), "open") {
+		t.Errorf("Expected degraded health check error, got %v", err)
+	}
+}
+
+// 测试任务队列组件
+func TestQueueComponent(t *testing.T) {
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+	registry := NewComponentRegistry(ctx, props)
+
+	configurer := &QueueConfigurer{}
+	if configurer.Order() != 350 {
+		t.Errorf("Expected order 350, got %d", configurer.Order())
+	}
+
+	// 未启用时不注册工厂
+	if err := configurer.Configure(registry, props); err != nil {
+		t.Fatalf("QueueConfigurer.Configure failed: %v", err)
+	}
+	if _, exists := registry.GetComponent("queue"); exists {
+		t.Error("queue component should not be registered when disabled")
+	}
+
+	// 无效的队列配置
+	props.SetProperty("queue.enabled", true)
+	props.SetProperty("queue.queues", "reports:abc")
+	if err := configurer.Configure(registry, props); err == nil {
+		t.Error("Expected error for invalid queue.queues")
+	}
+
+	props.SetProperty("queue.queues", "reports:2, emails")
+	props.SetProperty("queue.poll_interval_ms", 5)
+	if err := configurer.Configure(registry, props); err != nil {
+		t.Fatalf("QueueConfigurer.Configure failed: %v", err)
+	}
+
+	component, exists := registry.GetComponent("queue")
+	if !exists {
+		t.Fatal("queue component should be created")
+	}
+	queueComponent := component.(*QueueComponent)
+
+	if err := queueComponent.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := queueComponent.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	manager := queueComponent.GetManager()
+	manager.Register("test", func(ctx context.Context, job *queue.Job) error {
+		close(done)
+		return nil
+	})
+	if _, err := manager.Enqueue(ctx, "test", nil, queue.WithQueue("emails")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job was not processed")
+	}
+
+	if err := queueComponent.HealthCheck(); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+	if err := queueComponent.Stop(ctx); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if queueComponent.GetMetrics()["jobs_processed"] != int64(1) {
+		t.Errorf("Expected 1 processed job, got %v", queueComponent.GetMetrics()["jobs_processed"])
+	}
+}
+
+// 测试Redis任务队列复用缓存组件的Redis客户端并报告后端错误
+func TestQueueComponentSharesCacheRedis(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+	props.SetProperty("cache.type", "redis")
+	props.SetProperty("cache.redis.addr", mr.Addr())
+	props.SetProperty("queue.enabled", true)
+	props.SetProperty("queue.backend", "redis")
+	props.SetProperty("queue.poll_interval_ms", 5)
+
+	registry := NewComponentRegistry(ctx, props)
+	if err := (&CacheConfigurer{}).Configure(registry, props); err != nil {
+		t.Fatalf("CacheConfigurer.Configure failed: %v", err)
+	}
+	if err := (&QueueConfigurer{}).Configure(registry, props); err != nil {
+		t.Fatalf("QueueConfigurer.Configure failed: %v", err)
+	}
+
+	// 按需创建队列组件时先创建它依赖的缓存组件
+	component, exists := registry.GetComponent("queue")
+	if !exists {
+		t.Fatal("queue component should be created")
+	}
+	queueComponent := component.(*QueueComponent)
+	cacheComponent, exists := registry.GetComponent("cache")
+	if !exists {
+		t.Fatal("cache component should be created")
+	}
+	redisCache := cacheComponent.(*CacheComponent).GetRedisCache()
+	if redisCache == nil {
+		t.Fatal("cache component should expose its redis cache")
+	}
+
+	backend, ok := queueComponent.GetManager().Backend().(*queue.RedisBackend)
+	if !ok {
+		t.Fatalf("Expected *queue.RedisBackend, got %T", queueComponent.GetManager().Backend())
+	}
+	if backend.GetClient() != redisCache.GetClient() {
+		t.Error("queue should reuse the cache component's redis client")
+	}
+
+	if err := queueComponent.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := queueComponent.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	mr.SetError("connection refused")
+	deadline := time.Now().Add(time.Second)
+	for queueComponent.GetMetrics()["backend_error"] == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if queueComponent.GetMetrics()["backend_error"] == nil {
+		t.Error("dequeue errors should be reported")
+	}
+	mr.SetError("")
+
+	if err := queueComponent.Stop(ctx); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if err := redisCache.GetClient().Ping(ctx).Err(); err != nil {
+		t.Errorf("stopping the queue must not close the shared client: %v", err)
+	}
+}
+
+// 测试缓存组件创建失败时复用其Redis客户端的队列组件立即失败
+func TestQueueComponentCacheUnavailable(t *testing.T) {
+	ctx := context.Background()
+	props := NewMemoryPropertySource()
+	props.SetProperty("cache.type", "redis")
+	props.SetProperty("queue.enabled", true)
+	props.SetProperty("queue.backend", "redis")
+
+	registry := NewComponentRegistry(ctx, props)
+	if err := registry.RegisterFactory("cache", &MockComponentFactory{createError: fmt.Errorf("redis unreachable")}); err != nil {
+		t.Fatalf("RegisterFactory failed: %v", err)
+	}
+	if err := (&QueueConfigurer{}).Configure(registry, props); err != nil {
+		t.Fatalf("QueueConfigurer.Configure failed: %v", err)
+	}
+
+	done := make(chan bool, 1)
+	go func() {
+		_, exists := registry.GetComponent("queue")
+		done <- exists
+	}()
+	select {
+	case exists := <-done:
+		if exists {
+			t.Error("queue component should not be created without the cache component")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetComponent deadlocked")
+	}
+
+	if err := registry.ResolveDependencies(); err == nil {
+		t.Error("ResolveDependencies should report the missing cache component")
+	}
+}
+
+// 可配置结果的激活器
+type staticActivator struct {
+	name     string
+	activate bool
+}
+
+func (a *staticActivator) ShouldActivate(props PropertySource) bool {
+	return a.activate
+}
+
+func (a *staticActivator) ComponentType() string {
+	return a.name
+}
+
+// 根据注册表中已有的激活决策判断是否激活的激活器
+type registryActivator struct {
+	name      string
+	registry  *ComponentRegistry
+	sawLogger bool
+}
+
+func (a *registryActivator) ShouldActivate(props PropertySource) bool {
+	for _, decision := range a.registry.GetActivationReport() {
+		if decision.Name == "logger" && decision.Activated {
+			a.sawLogger = true
+		}
+	}
+	return true
+}
+
+func (a *registryActivator) ComponentType() string {
+	return a.name
+}
+
+// newActivationTestConfig 创建包含全部内置配置器的自动配置引擎
+func newActivationTestConfig(activators ...ComponentActivator) *AutoConfig {
+	autoConfig := NewAutoConfig()
+	autoConfig.AddConfigurer(&ConfigConfigurer{})
+	autoConfig.AddConfigurer(&LoggerConfigurer{})
+	autoConfig.AddConfigurer(&DBStoreConfigurer{})
+	autoConfig.AddConfigurer(&CacheConfigurer{})
+	autoConfig.AddConfigurer(&QueueConfigurer{})
+	autoConfig.AddConfigurer(&WebConfigurer{})
+	for _, activator := range activators {
+		autoConfig.AddActivator(activator)
+	}
+	return autoConfig
+}
+
+// activationByName 将激活报告转换为以组件名为键的映射
+func activationByName(registry *ComponentRegistry) map[string]ActivationDecision {
+	result := make(map[string]ActivationDecision)
+	for _, decision := range registry.GetActivationReport() {
+		result[decision.Name] = decision
+	}
+	return result
+}
+
+// 测试组件激活规则
+func TestComponentActivation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("默认开关", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		registry := NewComponentRegistry(ctx, props)
+		if err := newActivationTestConfig().Configure(registry, props); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+
+		report := activationByName(registry)
+		for name, want := range map[string]bool{
+			"config": true, "logger": true, "cache": true,
+			"dbstore": false, "queue": false, "web": false,
+		} {
+			decision, ok := report[name]
+			if !ok {
+				t.Errorf("missing activation decision for %s", name)
+				continue
+			}
+			if decision.Activated != want {
+				t.Errorf("%s activated = %v, want %v (%s)", name, decision.Activated, want, decision.Reason)
+			}
+			if decision.Reason == "" {
+				t.Errorf("%s should have an activation reason", name)
+			}
+		}
+		if report["web"].Reason != "web.enabled defaults to false" {
+			t.Errorf("unexpected reason for web: %s", report["web"].Reason)
+		}
+	})
+
+	t.Run("include列表", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		props.SetProperty(PropertyComponentsInclude, "config, logger, queue")
+		registry := NewComponentRegistry(ctx, props)
+		if err := newActivationTestConfig().Configure(registry, props); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+
+		report := activationByName(registry)
+		if !report["queue"].Activated {
+			t.Errorf("queue should be activated by include list: %s", report["queue"].Reason)
+		}
+		if report["cache"].Activated {
+			t.Error("cache should not be activated when not in include list")
+		}
+		if report["cache"].Reason != "not listed in "+PropertyComponentsInclude {
+			t.Errorf("unexpected reason for cache: %s", report["cache"].Reason)
+		}
+		if _, exists := registry.GetComponent("queue"); !exists {
+			t.Error("queue component should be registered")
+		}
+		if _, exists := registry.GetComponent("cache"); exists {
+			t.Error("cache component should not be registered")
+		}
+	})
+
+	t.Run("exclude列表", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		props.SetProperty(PropertyComponentsInclude, []interface{}{"cache", "web"})
+		props.SetProperty(PropertyComponentsExclude, []interface{}{"cache"})
+		registry := NewComponentRegistry(ctx, props)
+		if err := newActivationTestConfig().Configure(registry, props); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+
+		report := activationByName(registry)
+		if report["cache"].Activated {
+			t.Error("excluded component should not be activated")
+		}
+		if !report["web"].Activated {
+			t.Errorf("web should be activated: %s", report["web"].Reason)
+		}
+	})
+
+	t.Run("激活器否决", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		registry := NewComponentRegistry(ctx, props)
+		autoConfig := newActivationTestConfig(
+			&staticActivator{name: "cache", activate: false},
+			&staticActivator{name: "logger", activate: true},
+		)
+		if err := autoConfig.Configure(registry, props); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+
+		report := activationByName(registry)
+		if report["cache"].Activated {
+			t.Error("cache should be vetoed by activator")
+		}
+		if _, exists := registry.GetComponent("cache"); exists {
+			t.Error("vetoed component should not be registered")
+		}
+		if !report["logger"].Activated {
+			t.Error("logger should be activated")
+		}
+		if report["logger"].Reason != "logger.enabled defaults to true; approved by activator *boot.staticActivator" {
+			t.Errorf("unexpected reason for logger: %s", report["logger"].Reason)
+		}
+	})
+
+	t.Run("激活器读取注册表", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		registry := NewComponentRegistry(ctx, props)
+		activator := &registryActivator{name: "cache", registry: registry}
+		autoConfig := newActivationTestConfig(activator)
+
+		done := make(chan error, 1)
+		go func() { done <- autoConfig.Configure(registry, props) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Configure failed: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("activator calling back into the registry must not deadlock")
+		}
+		if !activator.sawLogger {
+			t.Error("activator should see components registered before it")
+		}
+		if !activationByName(registry)["cache"].Activated {
+			t.Error("cache should be activated")
+		}
+	})
+
+	t.Run("配置结束后移除过滤器", func(t *testing.T) {
+		props := NewMemoryPropertySource()
+		props.SetProperty(PropertyComponentsExclude, "custom")
+		registry := NewComponentRegistry(ctx, props)
+		if err := newActivationTestConfig().Configure(registry, props); err != nil {
+			t.Fatalf("Configure failed: %v", err)
+		}
+
+		if err := registry.RegisterFactory("custom", &MockComponentFactory{}); err != nil {
+			t.Fatalf("RegisterFactory failed: %v", err)
+		}
+		if _, exists := registry.GetComponent("custom"); !exists {
+			t.Error("factories registered after auto configuration should not be filtered")
+		}
+	})
+}
+
+// 测试通过订阅句柄和监听器取消订阅
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+
+	var count int32
+	listener := EventListener(func(name string, data interface{}) {
+		atomic.AddInt32(&count, 1)
+	})
+
+	sub := bus.Subscribe("test.event", listener)
+	bus.PublishSync("test.event", nil)
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	bus.PublishSync("test.event", nil)
+
+	if atomic.LoadInt32(&count) != 1 {
+		t.Errorf("Expected 1 call, got %d", count)
+	}
+	if bus.HasListeners("test.event") {
+		t.Error("Subscription should have been removed")
+	}
+
+	// 基于监听器的取消订阅
+	other := EventListener(func(name string, data interface{}) {})
+	bus.Subscribe("test.event", listener)
+	bus.Subscribe("test.event", other)
+	bus.Unsubscribe("test.event", listener)
+	bus.PublishSync("test.event", nil)
+
+	if atomic.LoadInt32(&count) != 1 {
+		t.Errorf("Listener should have been unsubscribed, got %d calls", count)
+	}
+	if !bus.HasListeners("test.event") {
+		t.Error("Other listener should remain subscribed")
+	}
+}
+
+// 测试通配符主题
+func TestEventBusWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"application.started", "application.started", true},
+		{"application.*", "application.started", true},
+		{"application.*", "application.health_check.failed", false},
+		{"application.#", "application.health_check.failed", true},
+		{"application.#", "application", true},
+		{"component.#", "component.stop.error", true},
+		{"*.stop.*", "component.stop.error", true},
+		{"#.failed", "application.health_check.failed", true},
+		{"#", "anything.at.all", true},
+		{"application.*", "component.started", false},
+	}
+
+	for _, tt := range tests {
+		got := matchTopic(strings.Split(tt.pattern, "."), strings.Split(tt.topic, "."))
+		if got != tt.match {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.match)
+		}
+	}
+
+	bus := NewEventBus()
+	var received []string
+	bus.Subscribe("component.#", func(name string, data interface{}) {
+		received = append(received, name)
+	})
+
+	bus.PublishSync("component.stop.error", nil)
+	bus.PublishSync("application.started", nil)
+
+	if len(received) != 1 || received[0] != "component.stop.error" {
+		t.Errorf("Unexpected events received: %v", received)
+	}
+	if !bus.HasListeners("component.start") {
+		t.Error("HasListeners should consider wildcard subscriptions")
+	}
+}
+
+// 测试监听器优先级和一次性订阅
+func TestEventBusPriorityAndOnce(t *testing.T) {
+	bus := NewEventBus()
+
+	var order []string
+	bus.Subscribe("evt", func(name string, data interface{}) { order = append(order, "low") }, WithPriority(-1))
+	bus.Subscribe("evt", func(name string, data interface{}) { order = append(order, "default-1") })
+	bus.Subscribe("evt", func(name string, data interface{}) { order = append(order, "high") }, WithPriority(10))
+	bus.Subscribe("evt", func(name string, data interface{}) { order = append(order, "default-2") })
+	bus.SubscribeOnce("evt", func(name string, data interface{}) { order = append(order, "once") }, WithPriority(5))
+
+	bus.PublishSync("evt", nil)
+	bus.PublishSync("evt", nil)
+
+	want := []string{"high", "once", "default-1", "default-2", "low", "high", "default-1", "default-2", "low"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected call order: %v, want %v", order, want)
+	}
+
+	// 并发发布时一次性订阅者也只会被调用一次
+	var onceCount int32
+	bus.SubscribeOnce("concurrent", func(name string, data interface{}) {
+		atomic.AddInt32(&onceCount, 1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bus.PublishSync("concurrent", nil)
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&onceCount) != 1 {
+		t.Errorf("Once listener called %d times", onceCount)
+	}
+}
+
+// 测试类型化订阅和发布
+func TestEventBusTyped(t *testing.T) {
+	type orderCreated struct {
+		ID string
+	}
+
+	bus := NewEventBus()
+
+	var received []string
+	sub := Subscribe(bus, "order.*", func(name string, event orderCreated) {
+		received = append(received, name+":"+event.ID)
+	})
+
+	PublishSync(bus, "order.created", orderCreated{ID: "1"})
+	// 负载类型不匹配的事件会被忽略
+	PublishSync(bus, "order.created", "not an order")
+
+	done := make(chan struct{})
+	Subscribe(bus, "payment.completed", func(name string, event orderCreated) {
+		close(done)
+	}, WithOnce())
+	Publish(bus, "payment.completed", orderCreated{ID: "2"})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Async typed listener should have been called")
+	}
+
+	sub.Unsubscribe()
+	PublishSync(bus, "order.created", orderCreated{ID: "3"})
+
+	if len(received) != 1 || received[0] != "order.created:1" {
+		t.Errorf("Unexpected typed events: %v", received)
+	}
+}
+
+func TestEventBusPanicRecoveryAndDeadLetters(t *testing.T) {
+	bus := NewEventBus()
+
+	var called atomic.Int32
+	bus.Subscribe("job.failed", func(eventName string, eventData interface{}) {
+		panic("boom")
+	}, WithPriority(10))
+	bus.Subscribe("job.failed", func(eventName string, eventData interface{}) {
+		called.Add(1)
+	})
+
+	// 同步发布时panic不会中断后续监听器
+	bus.PublishSync("job.failed", "sync")
+	if called.Load() != 1 {
+		t.Errorf("Listener after panicking one should be called, got %d calls", called.Load())
+	}
+
+	bus.Publish("job.failed", "async")
+
+	for _, want := range []string{"sync", "async"} {
+		select {
+		case letter := <-bus.DeadLetters():
+			if letter.EventName != "job.failed" || letter.EventData != want {
+				t.Errorf("Unexpected dead letter: %+v", letter)
+			}
+			if letter.Err == nil || !strings.Contains(letter.Err.Error(), "boom") {
+				t.Errorf("Dead letter should carry the panic value, got %v", letter.Err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Panicking listener should produce a dead letter")
+		}
+	}
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if called.Load() != 2 {
+		t.Errorf("Expected 2 calls after async publish, got %d", called.Load())
+	}
+}
+
+func TestEventBusOrderedDelivery(t *testing.T) {
+	options := DefaultEventBusOptions()
+	options.Workers = 4
+	options.OrderedTopics = []string{"account.#"}
+	bus := NewEventBus(options)
+
+	var mu sync.Mutex
+	var received []int
+	bus.Subscribe("account.balance", func(eventName string, eventData interface{}) {
+		// 让出调度，若投递无序更容易暴露
+		time.Sleep(time.Microsecond)
+		mu.Lock()
+		received = append(received, eventData.(int))
+		mu.Unlock()
+	})
+
+	for i := 0; i < 100; i++ {
+		bus.Publish("account.balance", i)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(received) != 100 {
+		t.Fatalf("Expected 100 events, got %d", len(received))
+	}
+	for i, v := range received {
+		if v != i {
+			t.Fatalf("Ordered events delivered out of order at %d: %v", i, received)
+		}
+	}
+}
+
+func TestEventBusBackpressure(t *testing.T) {
+	options := DefaultEventBusOptions()
+	options.Workers = 1
+	options.QueueSize = 1
+	options.Backpressure = BackpressureDrop
+	bus := NewEventBus(options)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.Subscribe("slow", func(eventName string, eventData interface{}) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+
+	// 第一个事件占住工作协程，第二个填满队列，第三个被丢弃
+	bus.Publish("slow", 1)
+	<-started
+	bus.Publish("slow", 2)
+	bus.Publish("slow", 3)
+
+	select {
+	case letter := <-bus.DeadLetters():
+		if letter.EventData != 3 || letter.Err != ErrEventQueueFull {
+			t.Errorf("Unexpected dead letter: %+v", letter)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Dropped event should produce a dead letter")
+	}
+	close(release)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// 调用者执行策略在队列已满时直接在发布者goroutine中调用监听器
+	options.Backpressure = BackpressureCallerRuns
+	bus = NewEventBus(options)
+	block := make(chan struct{})
+	taken := make(chan struct{})
+	bus.Subscribe("busy", func(eventName string, eventData interface{}) {
+		if eventData.(int) == 1 {
+			close(taken)
+			<-block
+		}
+	})
+	var ran atomic.Bool
+	bus.Subscribe("inline", func(eventName string, eventData interface{}) {
+		ran.Store(true)
+	})
+	bus.Publish("busy", 1)
+	<-taken
+	bus.Publish("busy", 2)
+	bus.Publish("inline", nil)
+	if !ran.Load() {
+		t.Error("Listener should run in the caller goroutine when the queue is full")
+	}
+	close(block)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestEventBusClose(t *testing.T) {
+	bus := NewEventBus()
+
+	var handled atomic.Int32
+	bus.Subscribe("work", func(eventName string, eventData interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		handled.Add(1)
+	})
+	for i := 0; i < 20; i++ {
+		bus.Publish("work", i)
+	}
+
+	// Close会等待队列中的事件处理完成
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if handled.Load() != 20 {
+		t.Errorf("Close should drain pending events, handled %d", handled.Load())
+	}
+
+	// 关闭后发布的异步事件进入死信通道
+	bus.Publish("work", "late")
+	if letter := <-bus.DeadLetters(); letter.Err != ErrEventBusClosed {
+		t.Errorf("Expected ErrEventBusClosed, got %v", letter.Err)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Errorf("Repeated Close should succeed, got %v", err)
+	}
+
+	// 超时时返回上下文错误
+	bus = NewEventBus()
+	release := make(chan struct{})
+	bus.Subscribe("stuck", func(eventName string, eventData interface{}) {
+		<-release
+	})
+	bus.Publish("stuck", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	close(release)
+}
+
+func TestEventBusCloseWakesBlockedPublisher(t *testing.T) {
+	options := DefaultEventBusOptions()
+	options.Workers = 1
+	options.QueueSize = 1
+	bus := NewEventBus(options)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	bus.Subscribe("slow", func(eventName string, eventData interface{}) {
+		if eventData == 1 {
+			close(started)
+		}
+		<-release
+	})
+
+	// 第一个事件占住工作协程，第二个填满队列，第三个阻塞发布者
+	bus.Publish("slow", 1)
+	<-started
+	bus.Publish("slow", 2)
+	published := make(chan struct{})
+	go func() {
+		bus.Publish("slow", 3)
+		close(published)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// 阻塞的发布者不能使Close无法获得写锁
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Blocked publisher should return once the bus is closing")
+	}
+	if letter := <-bus.DeadLetters(); letter.EventData != 3 || letter.Err != ErrEventBusClosed {
+		t.Errorf("Unexpected dead letter: %+v", letter)
+	}
+	close(release)
+}
+
+func TestEventBusOptionsFromProperties(t *testing.T) {
+	props := NewMemoryPropertySource()
+	props.SetProperty("eventbus.workers", 2)
+	props.SetProperty("eventbus.queue_size", 16)
+	props.SetProperty("eventbus.backpressure", "drop")
+	props.SetProperty("eventbus.ordered_topics", "order.#, payment.*")
+
+	options, err := eventBusOptionsFromProperties(props)
+	if err != nil {
+		t.Fatalf("eventBusOptionsFromProperties failed: %v", err)
+	}
+	if options.Workers != 2 || options.QueueSize != 16 || options.Backpressure != BackpressureDrop {
+		t.Errorf("Unexpected options: %+v", options)
+	}
+	if options.DeadLetterSize != DefaultEventBusOptions().DeadLetterSize {
+		t.Errorf("Expected default dead letter size, got %d", options.DeadLetterSize)
+	}
+	if len(options.OrderedTopics) != 2 || options.OrderedTopics[1] != "payment.*" {
+		t.Errorf("Unexpected ordered topics: %v", options.OrderedTopics)
+	}
+
+	props.SetProperty("eventbus.backpressure", "unknown")
+	if _, err := eventBusOptionsFromProperties(props); err == nil {
+		t.Error("Expected error for unknown backpressure policy")
+	}
+}
+
+func TestLeaderElectionComponent(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	ctx := context.Background()
+	bus := NewEventBus()
+	events := make(chan string, 10)
+	bus.Subscribe("leader.*", func(eventName string, eventData interface{}) {
+		data := eventData.(map[string]interface{})
+		events <- eventName + ":" + data["identity"].(string)
+	})
+
+	newComponent := func(identity string) *LeaderElectionComponent {
+		props := NewMemoryPropertySource()
+		props.SetProperty("leader.enabled", true)
+		props.SetProperty("leader.identity", identity)
+		props.SetProperty("leader.key", "test:leader")
+		props.SetProperty("leader.lease_ms", 1000)
+		props.SetProperty("leader.renew_interval_ms", 20)
+		props.SetProperty("leader.retry_interval_ms", 20)
+		props.SetProperty("leader.redis.addr", mr.Addr())
+
+		registry := NewComponentRegistry(ctx, props)
+		if err := (&LeaderElectionConfigurer{}).Configure(registry, props); err != nil {
+			t.Fatalf("LeaderElectionConfigurer.Configure failed: %v", err)
+		}
+		component, exists := registry.GetComponent("leader")
+		if !exists {
+			t.Fatal("leader component should be created")
+		}
+		leader := component.(*LeaderElectionComponent)
+		leader.SetEventBus(bus)
+		if err := leader.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		if err := leader.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		return leader
+	}
+
+	expectEvent := func(want string) {
+		t.Helper()
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("Expected event %s, got %s", want, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for event %s", want)
+		}
+	}
+
+	first := newComponent("node-1")
+	expectEvent("leader.elected:node-1")
+	if !first.IsLeader() {
+		t.Error("node-1 should be leader")
+	}
+
+	second := newComponent("node-2")
+	time.Sleep(100 * time.Millisecond)
+	if second.IsLeader() {
+		t.Error("node-2 should not be leader while node-1 holds the lease")
+	}
+	if err := second.HealthCheck(); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+
+	// 模拟租约被其他副本接管后node-1失去领导权
+	mr.Set("test:leader", "node-3")
+	expectEvent("leader.lost:node-1")
+	mr.Del("test:leader")
+
+	// 任意一个副本接任
+	var elected string
+	select {
+	case elected = <-events:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for re-election")
+	}
+	if elected != "leader.elected:node-1" && elected != "leader.elected:node-2" {
+		t.Fatalf("Unexpected event %s", elected)
+	}
+	if first.IsLeader() == second.IsLeader() {
+		t.Error("Exactly one component should be leader")
+	}
+
+	// 领导者关闭时主动卸任
+	current, other := first, second
+	if second.IsLeader() {
+		current, other = second, first
+	}
+	if err := current.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	expectEvent("leader.lost:" + current.GetElector().Identity())
+	expectEvent("leader.elected:" + other.GetElector().Identity())
+	if err := other.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if metrics := other.GetMetrics(); metrics["is_leader"] != false {
+		t.Errorf("Expected is_leader=false after stop, got %v", metrics["is_leader"])
+	}
+}
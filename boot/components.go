@@ -3,12 +3,16 @@ package boot
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/guanzhenxing/go-snap/cache"
 	"github.com/guanzhenxing/go-snap/config"
+	"github.com/guanzhenxing/go-snap/lock"
 	"github.com/guanzhenxing/go-snap/logger"
+	"github.com/guanzhenxing/go-snap/queue"
 )
 
 // BaseComponent 基础组件实现
@@ -126,14 +130,8 @@ type LoggerConfigurer struct{}
 
 // Configure 配置日志组件
 func (c *LoggerConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
-	// 检查是否启用日志
-	enabled := props.GetBool("logger.enabled", true)
-	if !enabled {
-		return nil
-	}
-
-	// 创建日志组件工厂
-	return registry.RegisterFactory("logger", &LoggerComponentFactory{})
+	// 根据logger.enabled和app.components.include判断是否启用日志，并创建日志组件工厂
+	return registerComponentFactory(registry, props, "logger", "logger.enabled", true, &LoggerComponentFactory{})
 }
 
 // Order 配置顺序
@@ -152,7 +150,7 @@ type ConfigConfigurer struct{}
 // Configure 配置配置组件
 func (c *ConfigConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
 	// 配置组件总是启用
-	return registry.RegisterFactory("config", &ConfigComponentFactory{})
+	return registerComponentFactory(registry, props, "config", "", true, &ConfigComponentFactory{})
 }
 
 // Order 配置顺序
@@ -170,14 +168,8 @@ type DBStoreConfigurer struct{}
 
 // Configure 配置数据库组件
 func (c *DBStoreConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
-	// 检查是否启用数据库
-	enabled := props.GetBool("database.enabled", false)
-	if !enabled {
-		return nil
-	}
-
-	// 创建数据库组件工厂
-	return registry.RegisterFactory("dbstore", &DBStoreComponentFactory{})
+	// 根据database.enabled和app.components.include判断是否启用数据库，并创建数据库组件工厂
+	return registerComponentFactory(registry, props, "dbstore", "database.enabled", false, &DBStoreComponentFactory{})
 }
 
 // Order 配置顺序
@@ -195,14 +187,8 @@ type CacheConfigurer struct{}
 
 // Configure 配置缓存组件
 func (c *CacheConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
-	// 检查是否启用缓存
-	enabled := props.GetBool("cache.enabled", true)
-	if !enabled {
-		return nil
-	}
-
-	// 创建缓存组件工厂
-	return registry.RegisterFactory("cache", &CacheComponentFactory{})
+	// 根据cache.enabled和app.components.include判断是否启用缓存，并创建缓存组件工厂
+	return registerComponentFactory(registry, props, "cache", "cache.enabled", true, &CacheComponentFactory{})
 }
 
 // Order 配置顺序
@@ -215,19 +201,53 @@ func (c *CacheConfigurer) GetName() string {
 	return "CacheConfigurer"
 }
 
+// QueueConfigurer 任务队列配置器
+type QueueConfigurer struct{}
+
+// Configure 配置任务队列组件
+func (c *QueueConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
+	// 根据queue.enabled和app.components.include判断是否启用任务队列，并创建任务队列组件工厂
+	return registerComponentFactory(registry, props, "queue", "queue.enabled", false, &QueueComponentFactory{
+		sharesCache: sharesCacheRedis(props),
+	})
+}
+
+// Order 配置顺序
+func (c *QueueConfigurer) Order() int {
+	return 350
+}
+
+// GetName 获取配置器名称
+func (c *QueueConfigurer) GetName() string {
+	return "QueueConfigurer"
+}
+
+// LeaderElectionConfigurer 领导者选举配置器
+type LeaderElectionConfigurer struct{}
+
+// Configure 配置领导者选举组件
+func (c *LeaderElectionConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
+	// 根据leader.enabled和app.components.include判断是否启用领导者选举，并创建领导者选举组件工厂
+	return registerComponentFactory(registry, props, "leader", "leader.enabled", false, &LeaderElectionComponentFactory{})
+}
+
+// Order 配置顺序
+func (c *LeaderElectionConfigurer) Order() int {
+	return 360
+}
+
+// GetName 获取配置器名称
+func (c *LeaderElectionConfigurer) GetName() string {
+	return "LeaderElectionConfigurer"
+}
+
 // WebConfigurer Web配置器
 type WebConfigurer struct{}
 
 // Configure 配置Web组件
 func (c *WebConfigurer) Configure(registry *ComponentRegistry, props PropertySource) error {
-	// 检查是否启用Web
-	enabled := props.GetBool("web.enabled", false)
-	if !enabled {
-		return nil
-	}
-
-	// 创建Web组件工厂
-	return registry.RegisterFactory("web", &WebComponentFactory{})
+	// 根据web.enabled和app.components.include判断是否启用Web，并创建Web组件工厂
+	return registerComponentFactory(registry, props, "web", "web.enabled", false, &WebComponentFactory{})
 }
 
 // Order 配置顺序
@@ -408,7 +428,7 @@ func (f *DBStoreComponentFactory) Dependencies() []string {
 
 // ValidateConfig 验证配置
 func (f *DBStoreComponentFactory) ValidateConfig(props PropertySource) error {
-	if !props.GetBool("database.enabled", false) {
+	if enabled, _ := isComponentEnabled(props, "dbstore", "database.enabled", false); !enabled {
 		return nil
 	}
 
@@ -470,26 +490,85 @@ func (f *CacheComponentFactory) Create(ctx context.Context, props PropertySource
 	var cacheInstance cache.Cache
 	var err error
 
+	// 构建组件
+	component := &CacheComponent{
+		BaseComponent: NewBaseComponent("cache", ComponentTypeInfrastructure),
+		cacheType:     cacheType,
+		warmupTimeout: time.Duration(props.GetInt("cache.warmup.timeout_ms", 30000)) * time.Millisecond,
+	}
+
+	memoryOptions := func() cache.Options {
+		opts := cache.DefaultOptions()
+		opts.Snapshot = cache.SnapshotOptions{
+			Path:     props.GetString("cache.memory.snapshot_path", ""),
+			Interval: time.Duration(props.GetInt("cache.memory.snapshot_interval_ms", 0)) * time.Millisecond,
+			OnError:  component.snapshotError,
+		}
+		return opts
+	}
+
 	switch cacheType {
 	case "memory":
-		cacheInstance = cache.NewMemoryCache()
+		cacheInstance = cache.NewMemoryCache(memoryOptions())
 	case "redis":
-		// 这里需要根据实际的redis配置创建redis缓存
-		cacheInstance = cache.NewMemoryCache() // 临时使用内存缓存
+		component.redisCache, err = cache.NewRedisCache(cacheRedisOptions(props), nil)
+		if err != nil {
+			return nil, NewConfigError("cache", "连接Redis失败", err)
+		}
+		cacheInstance = component.redisCache
+	case "multi_level":
+		component.redisCache, err = cache.NewRedisCache(cacheRedisOptions(props), nil)
+		if err != nil {
+			return nil, NewConfigError("cache", "连接Redis失败", err)
+		}
+		cacheInstance, err = cache.NewMultiLevelCache(cache.NewMemoryCache(memoryOptions()), component.redisCache, multiLevelOptions(props))
+		if err != nil {
+			_ = component.redisCache.Close()
+			return nil, NewConfigError("cache", "创建多级缓存失败", err)
+		}
+		component.failWhenDegraded = props.GetBool("cache.circuit_breaker.fail_health_check", false)
 	default:
 		return nil, NewConfigError("cache", fmt.Sprintf("不支持的缓存类型: %s", cacheType), nil)
 	}
-
-	// 构建组件
-	component := &CacheComponent{
-		BaseComponent: NewBaseComponent("cache", ComponentTypeInfrastructure),
-		cache:         cacheInstance,
-		cacheType:     cacheType,
+	component.cache = cacheInstance
+
+	// 启用统计时包装缓存，由GetMetrics报告命中率和延迟等指标
+	// 默认不启用，GetCache返回的仍是具体的缓存类型
+	if props.GetBool("cache.metrics.enabled", false) {
+		component.instrumented = cache.NewInstrumentedCache(cacheInstance)
+		component.cache = component.instrumented
+		component.slowThreshold = time.Duration(props.GetInt("cache.metrics.slow_threshold_ms", 100)) * time.Millisecond
 	}
 
 	return component, err
 }
 
+// cacheRedisOptions 从cache.redis.*属性读取Redis缓存选项
+func cacheRedisOptions(props PropertySource) cache.RedisOptions {
+	opts := cache.DefaultRedisOptions()
+	opts.Addr = props.GetString("cache.redis.addr", opts.Addr)
+	opts.Password = props.GetString("cache.redis.password", opts.Password)
+	opts.DB = props.GetInt("cache.redis.db", opts.DB)
+	opts.KeyPrefix = props.GetString("cache.redis.key_prefix", opts.KeyPrefix)
+	return opts
+}
+
+// multiLevelOptions 从cache.multi_level.*和cache.circuit_breaker.*属性读取多级缓存选项
+func multiLevelOptions(props PropertySource) cache.MultiLevelOptions {
+	opts := cache.DefaultMultiLevelOptions()
+	opts.LocalTTL = time.Duration(props.GetInt("cache.multi_level.local_ttl_ms", int(opts.LocalTTL/time.Millisecond))) * time.Millisecond
+
+	if props.GetBool("cache.circuit_breaker.enabled", false) {
+		breaker := cache.DefaultCircuitBreakerOptions()
+		breaker.FailureThreshold = props.GetInt("cache.circuit_breaker.failure_threshold", breaker.FailureThreshold)
+		breaker.OpenTimeout = time.Duration(props.GetInt("cache.circuit_breaker.open_timeout_ms", int(breaker.OpenTimeout/time.Millisecond))) * time.Millisecond
+		breaker.OperationTimeout = time.Duration(props.GetInt("cache.circuit_breaker.operation_timeout_ms", 0)) * time.Millisecond
+		breaker.MaxPendingKeys = props.GetInt("cache.circuit_breaker.max_pending_keys", breaker.MaxPendingKeys)
+		opts.CircuitBreaker = &breaker
+	}
+	return opts
+}
+
 // Dependencies 依赖
 func (f *CacheComponentFactory) Dependencies() []string {
 	return []string{"logger", "config"}
@@ -497,12 +576,12 @@ func (f *CacheComponentFactory) Dependencies() []string {
 
 // ValidateConfig 验证配置
 func (f *CacheComponentFactory) ValidateConfig(props PropertySource) error {
-	if !props.GetBool("cache.enabled", true) {
+	if enabled, _ := isComponentEnabled(props, "cache", "cache.enabled", true); !enabled {
 		return nil
 	}
 
 	cacheType := props.GetString("cache.type", "memory")
-	validTypes := []string{"memory", "redis"}
+	validTypes := []string{"memory", "redis", "multi_level"}
 	isValid := false
 	for _, validType := range validTypes {
 		if cacheType == validType {
@@ -531,7 +610,438 @@ func (f *CacheComponentFactory) GetConfigSchema() ConfigSchema {
 			"cache.type": {
 				Type:         "string",
 				DefaultValue: "memory",
-				Description:  "缓存类型",
+				Description:  "缓存类型，支持memory、redis和multi_level（本地内存缓存加Redis）",
+				Required:     false,
+			},
+			"cache.redis.addr": {
+				Type:         "string",
+				DefaultValue: "localhost:6379",
+				Description:  "redis和multi_level缓存使用的Redis地址",
+				Required:     false,
+			},
+			"cache.redis.key_prefix": {
+				Type:         "string",
+				DefaultValue: "",
+				Description:  "Redis缓存键前缀",
+				Required:     false,
+			},
+			"cache.multi_level.local_ttl_ms": {
+				Type:         "int",
+				DefaultValue: 300000,
+				Description:  "多级缓存本地层的生存时间（毫秒）",
+				Required:     false,
+			},
+			"cache.circuit_breaker.enabled": {
+				Type:         "bool",
+				DefaultValue: false,
+				Description:  "多级缓存是否启用远程缓存熔断器，熔断期间降级为只使用本地缓存",
+				Required:     false,
+			},
+			"cache.circuit_breaker.failure_threshold": {
+				Type:         "int",
+				DefaultValue: 5,
+				Description:  "触发熔断的连续失败次数",
+				Required:     false,
+			},
+			"cache.circuit_breaker.open_timeout_ms": {
+				Type:         "int",
+				DefaultValue: 30000,
+				Description:  "熔断后进入半开状态前等待的时间（毫秒）",
+				Required:     false,
+			},
+			"cache.circuit_breaker.operation_timeout_ms": {
+				Type:         "int",
+				DefaultValue: 0,
+				Description:  "单次远程缓存操作的超时时间（毫秒），0表示不限制",
+				Required:     false,
+			},
+			"cache.circuit_breaker.max_pending_keys": {
+				Type:         "int",
+				DefaultValue: 10000,
+				Description:  "熔断期间记录的待同步键的最大数量",
+				Required:     false,
+			},
+			"cache.circuit_breaker.fail_health_check": {
+				Type:         "bool",
+				DefaultValue: false,
+				Description:  "熔断期间健康检查是否失败，默认只在degraded指标中报告降级状态",
+				Required:     false,
+			},
+			"cache.metrics.enabled": {
+				Type:         "bool",
+				DefaultValue: false,
+				Description:  "是否统计缓存命中率、延迟等指标，启用后GetCache返回*cache.InstrumentedCache",
+				Required:     false,
+			},
+			"cache.metrics.slow_threshold_ms": {
+				Type:         "int",
+				DefaultValue: 100,
+				Description:  "缓存慢操作日志阈值（毫秒），0表示不记录",
+				Required:     false,
+			},
+			"cache.memory.snapshot_path": {
+				Type:         "string",
+				DefaultValue: "",
+				Description:  "内存缓存快照文件路径，启动时恢复，停止时写入，为空表示不启用",
+				Required:     false,
+			},
+			"cache.memory.snapshot_interval_ms": {
+				Type:         "int",
+				DefaultValue: 0,
+				Description:  "内存缓存定期写入快照的间隔（毫秒），0表示只在停止时写入",
+				Required:     false,
+			},
+			"cache.warmup.timeout_ms": {
+				Type:         "int",
+				DefaultValue: 30000,
+				Description:  "缓存预热超时时间（毫秒）",
+				Required:     false,
+			},
+		},
+		Dependencies: []string{"logger", "config"},
+	}
+}
+
+// QueueComponentFactory 任务队列组件工厂
+// Redis后端在缓存组件使用Redis且没有单独配置queue.redis.addr时复用缓存组件的Redis客户端
+type QueueComponentFactory struct {
+	sharesCache bool
+}
+
+// sharesCacheRedis 判断Redis队列后端是否复用缓存组件的Redis客户端
+func sharesCacheRedis(props PropertySource) bool {
+	if props.GetString("queue.backend", "memory") != "redis" || props.HasProperty("queue.redis.addr") {
+		return false
+	}
+	if enabled, _ := isComponentEnabled(props, "cache", "cache.enabled", true); !enabled {
+		return false
+	}
+	cacheType := props.GetString("cache.type", "memory")
+	return cacheType == "redis" || cacheType == "multi_level"
+}
+
+// Create 创建任务队列组件
+func (f *QueueComponentFactory) Create(ctx context.Context, props PropertySource) (Component, error) {
+	backendType := props.GetString("queue.backend", "memory")
+
+	opts := queue.DefaultOptions()
+	opts.Concurrency = props.GetInt("queue.concurrency", opts.Concurrency)
+	opts.MaxRetries = props.GetInt("queue.max_retries", opts.MaxRetries)
+	opts.PollInterval = time.Duration(props.GetInt("queue.poll_interval_ms", int(opts.PollInterval/time.Millisecond))) * time.Millisecond
+	opts.RetryBackoff = time.Duration(props.GetInt("queue.retry_backoff_ms", int(opts.RetryBackoff/time.Millisecond))) * time.Millisecond
+	opts.MaxRetryBackoff = time.Duration(props.GetInt("queue.max_retry_backoff_ms", int(opts.MaxRetryBackoff/time.Millisecond))) * time.Millisecond
+
+	queues, err := parseQueueConcurrency(props.GetString("queue.queues", ""))
+	if err != nil {
+		return nil, NewConfigError("queue", "无效的队列配置", err)
+	}
+	opts.Queues = queues
+
+	component := &QueueComponent{
+		BaseComponent: NewBaseComponent("queue", ComponentTypeCore),
+		backendType:   backendType,
+	}
+
+	switch backendType {
+	case "memory":
+		component.backend = queue.NewMemoryBackend()
+	case "redis":
+		redisCache, err := f.redisCache(ctx, props)
+		if err != nil {
+			return nil, err
+		}
+
+		backend, err := queue.FromRedisCache(redisCache, queue.RedisBackendOptions{
+			KeyPrefix:         props.GetString("queue.redis.key_prefix", queue.DefaultRedisBackendOptions().KeyPrefix),
+			VisibilityTimeout: time.Duration(props.GetInt("queue.redis.visibility_timeout_ms", int(queue.DefaultRedisBackendOptions().VisibilityTimeout/time.Millisecond))) * time.Millisecond,
+		})
+		if err != nil {
+			if !f.sharesCache {
+				_ = redisCache.Close()
+			}
+			return nil, NewConfigError("queue", "创建Redis队列后端失败", err)
+		}
+		component.backend = backend
+		// 只关闭自己创建的Redis连接，共享的连接由缓存组件关闭
+		if !f.sharesCache {
+			component.redisCache = redisCache
+		}
+	default:
+		return nil, NewConfigError("queue", fmt.Sprintf("不支持的队列后端: %s", backendType), nil)
+	}
+
+	opts.OnError = component.backendError
+	component.manager = queue.NewManager(component.backend, opts)
+	return component, nil
+}
+
+// redisCache 返回Redis队列后端使用的Redis缓存，复用缓存组件的客户端或按queue.redis.*创建新的连接
+// 复用时缓存组件是声明的依赖，由注册表在调用Create前创建，缓存组件创建失败时直接返回错误
+func (f *QueueComponentFactory) redisCache(ctx context.Context, props PropertySource) (*cache.RedisCache, error) {
+	if f.sharesCache {
+		if component, ok := DependencyFromContext(ctx, "cache"); ok {
+			if cacheComponent, ok := component.(*CacheComponent); ok && cacheComponent.GetRedisCache() != nil {
+				return cacheComponent.GetRedisCache(), nil
+			}
+		}
+		return nil, NewConfigError("queue", "缓存组件不可用或没有可复用的Redis客户端", nil)
+	}
+
+	redisOpts := cache.DefaultRedisOptions()
+	redisOpts.Addr = props.GetString("queue.redis.addr", redisOpts.Addr)
+	redisOpts.Password = props.GetString("queue.redis.password", redisOpts.Password)
+	redisOpts.DB = props.GetInt("queue.redis.db", redisOpts.DB)
+
+	redisCache, err := cache.NewRedisCache(redisOpts, nil)
+	if err != nil {
+		return nil, NewConfigError("queue", "连接Redis失败", err)
+	}
+	return redisCache, nil
+}
+
+// Dependencies 依赖，复用缓存组件的Redis客户端时依赖缓存组件
+func (f *QueueComponentFactory) Dependencies() []string {
+	if f.sharesCache {
+		return []string{"logger", "config", "cache"}
+	}
+	return []string{"logger", "config"}
+}
+
+// ValidateConfig 验证配置
+func (f *QueueComponentFactory) ValidateConfig(props PropertySource) error {
+	if enabled, _ := isComponentEnabled(props, "queue", "queue.enabled", false); !enabled {
+		return nil
+	}
+
+	backendType := props.GetString("queue.backend", "memory")
+	if backendType != "memory" && backendType != "redis" {
+		return NewConfigError("queue", fmt.Sprintf("不支持的队列后端: %s", backendType), nil)
+	}
+
+	if concurrency := props.GetInt("queue.concurrency", 1); concurrency <= 0 {
+		return NewConfigError("queue", fmt.Sprintf("无效的并发数: %d", concurrency), nil)
+	}
+
+	if _, err := parseQueueConcurrency(props.GetString("queue.queues", "")); err != nil {
+		return NewConfigError("queue", "无效的队列配置", err)
+	}
+
+	return nil
+}
+
+// GetConfigSchema 获取配置模式
+func (f *QueueComponentFactory) GetConfigSchema() ConfigSchema {
+	return ConfigSchema{
+		RequiredProperties: []string{},
+		Properties: map[string]PropertySchema{
+			"queue.enabled": {
+				Type:         "bool",
+				DefaultValue: false,
+				Description:  "是否启用任务队列",
+				Required:     false,
+			},
+			"queue.backend": {
+				Type:         "string",
+				DefaultValue: "memory",
+				Description:  "队列后端，支持memory和redis",
+				Required:     false,
+			},
+			"queue.concurrency": {
+				Type:         "int",
+				DefaultValue: 4,
+				Description:  "默认队列的工作协程数量",
+				Required:     false,
+			},
+			"queue.queues": {
+				Type:         "string",
+				DefaultValue: "",
+				Description:  "其他队列及其并发数，格式为name:concurrency，多个队列用逗号分隔",
+				Required:     false,
+			},
+			"queue.max_retries": {
+				Type:         "int",
+				DefaultValue: 3,
+				Description:  "任务默认最大重试次数",
+				Required:     false,
+			},
+			"queue.poll_interval_ms": {
+				Type:         "int",
+				DefaultValue: 200,
+				Description:  "队列为空时的轮询间隔（毫秒）",
+				Required:     false,
+			},
+			"queue.retry_backoff_ms": {
+				Type:         "int",
+				DefaultValue: 1000,
+				Description:  "首次重试等待时间（毫秒），之后按指数增长",
+				Required:     false,
+			},
+			"queue.max_retry_backoff_ms": {
+				Type:         "int",
+				DefaultValue: 300000,
+				Description:  "重试等待时间上限（毫秒）",
+				Required:     false,
+			},
+			"queue.redis.addr": {
+				Type:         "string",
+				DefaultValue: "localhost:6379",
+				Description:  "Redis后端地址，未配置且缓存组件使用Redis时复用缓存组件的Redis客户端",
+				Required:     false,
+			},
+			"queue.redis.visibility_timeout_ms": {
+				Type:         "int",
+				DefaultValue: 300000,
+				Description:  "任务出队后等待确认的时间（毫秒），超时未确认的任务重新投递",
+				Required:     false,
+			},
+			"queue.redis.key_prefix": {
+				Type:         "string",
+				DefaultValue: "queue",
+				Description:  "Redis后端键前缀",
+				Required:     false,
+			},
+		},
+		Dependencies: []string{"logger", "config"},
+	}
+}
+
+// parseQueueConcurrency 解析name:concurrency格式的队列配置
+func parseQueueConcurrency(value string) (map[string]int, error) {
+	result := make(map[string]int)
+	if strings.TrimSpace(value) == "" {
+		return result, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, count, found := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("队列名称不能为空: %q", part)
+		}
+
+		concurrency := 1
+		if found {
+			n, err := strconv.Atoi(strings.TrimSpace(count))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("无效的队列并发数: %q", part)
+			}
+			concurrency = n
+		}
+		result[name] = concurrency
+	}
+
+	return result, nil
+}
+
+// LeaderElectionComponentFactory 领导者选举组件工厂
+type LeaderElectionComponentFactory struct{}
+
+// Create 创建领导者选举组件
+func (f *LeaderElectionComponentFactory) Create(ctx context.Context, props PropertySource) (Component, error) {
+	redisOpts := cache.DefaultRedisOptions()
+	redisOpts.Addr = props.GetString("leader.redis.addr", redisOpts.Addr)
+	redisOpts.Password = props.GetString("leader.redis.password", redisOpts.Password)
+	redisOpts.DB = props.GetInt("leader.redis.db", redisOpts.DB)
+
+	redisCache, err := cache.NewRedisCache(redisOpts, nil)
+	if err != nil {
+		return nil, NewConfigError("leader", "连接Redis失败", err)
+	}
+
+	component := &LeaderElectionComponent{
+		BaseComponent: NewBaseComponent("leader", ComponentTypeCore),
+		redisCache:    redisCache,
+	}
+
+	defaults := lock.DefaultElectionOptions()
+	opts := lock.ElectionOptions{
+		LeaseDuration: time.Duration(props.GetInt("leader.lease_ms", int(defaults.LeaseDuration/time.Millisecond))) * time.Millisecond,
+		RenewInterval: time.Duration(props.GetInt("leader.renew_interval_ms", int(defaults.RenewInterval/time.Millisecond))) * time.Millisecond,
+		RetryInterval: time.Duration(props.GetInt("leader.retry_interval_ms", int(defaults.RetryInterval/time.Millisecond))) * time.Millisecond,
+		SafetyMargin:  time.Duration(props.GetInt("leader.safety_margin_ms", 0)) * time.Millisecond,
+		Identity:      props.GetString("leader.identity", ""),
+		OnElected:     component.onElected,
+		OnLost:        component.onLost,
+	}
+	key := props.GetString("leader.key", "leader:"+props.GetString("app.name", "go-snap"))
+	component.elector = lock.NewElector(redisCache.GetClient(), key, opts)
+
+	return component, nil
+}
+
+// Dependencies 依赖
+func (f *LeaderElectionComponentFactory) Dependencies() []string {
+	return []string{"logger", "config"}
+}
+
+// ValidateConfig 验证配置
+func (f *LeaderElectionComponentFactory) ValidateConfig(props PropertySource) error {
+	if enabled, _ := isComponentEnabled(props, "leader", "leader.enabled", false); !enabled {
+		return nil
+	}
+
+	lease := props.GetInt("leader.lease_ms", int(lock.DefaultElectionOptions().LeaseDuration/time.Millisecond))
+	if lease <= 0 {
+		return NewConfigError("leader", fmt.Sprintf("无效的租约时长: %d", lease), nil)
+	}
+
+	if renew := props.GetInt("leader.renew_interval_ms", lease/3); renew <= 0 || renew >= lease {
+		return NewConfigError("leader", fmt.Sprintf("续约间隔必须大于0且小于租约时长: %d", renew), nil)
+	}
+
+	return nil
+}
+
+// GetConfigSchema 获取配置模式
+func (f *LeaderElectionComponentFactory) GetConfigSchema() ConfigSchema {
+	return ConfigSchema{
+		RequiredProperties: []string{},
+		Properties: map[string]PropertySchema{
+			"leader.enabled": {
+				Type:         "bool",
+				DefaultValue: false,
+				Description:  "是否启用领导者选举",
+				Required:     false,
+			},
+			"leader.key": {
+				Type:         "string",
+				DefaultValue: "leader:<app.name>",
+				Description:  "选举使用的Redis键",
+				Required:     false,
+			},
+			"leader.identity": {
+				Type:         "string",
+				DefaultValue: "",
+				Description:  "参选者标识，默认由主机名和进程号生成",
+				Required:     false,
+			},
+			"leader.lease_ms": {
+				Type:         "int",
+				DefaultValue: 15000,
+				Description:  "租约时长（毫秒）",
+				Required:     false,
+			},
+			"leader.renew_interval_ms": {
+				Type:         "int",
+				DefaultValue: 5000,
+				Description:  "续约间隔（毫秒）",
+				Required:     false,
+			},
+			"leader.retry_interval_ms": {
+				Type:         "int",
+				DefaultValue: 2000,
+				Description:  "竞选重试间隔（毫秒）",
+				Required:     false,
+			},
+			"leader.redis.addr": {
+				Type:         "string",
+				DefaultValue: "localhost:6379",
+				Description:  "Redis地址",
 				Required:     false,
 			},
 		},
@@ -564,7 +1074,7 @@ func (f *WebComponentFactory) Dependencies() []string {
 
 // ValidateConfig 验证配置
 func (f *WebComponentFactory) ValidateConfig(props PropertySource) error {
-	if !props.GetBool("web.enabled", false) {
+	if enabled, _ := isComponentEnabled(props, "web", "web.enabled", false); !enabled {
 		return nil
 	}
 
@@ -741,10 +1251,17 @@ func (c *DBStoreComponent) SetConfig(config config.Provider) {
 // CacheComponent 缓存组件
 type CacheComponent struct {
 	*BaseComponent
-	cache     cache.Cache
-	cacheType string
-	logger    logger.Logger
-	config    config.Provider
+	cache         cache.Cache
+	redisCache    *cache.RedisCache
+	instrumented  *cache.InstrumentedCache
+	slowThreshold time.Duration
+	warmup        cache.WarmupFunc
+	warmupTimeout time.Duration
+	cacheType     string
+	// failWhenDegraded 熔断器未关闭时健康检查是否失败
+	failWhenDegraded bool
+	logger           logger.Logger
+	config           config.Provider
 }
 
 // Initialize 初始化组件
@@ -753,11 +1270,30 @@ func (c *CacheComponent) Initialize(ctx context.Context) error {
 		return err
 	}
 	c.SetMetric("cache_type", c.cacheType)
+	if c.instrumented != nil && c.logger != nil {
+		c.instrumented.Use(cache.NewLoggingHook(c.logger, cache.LoggingHookOptions{SlowThreshold: c.slowThreshold}))
+	}
 	return nil
 }
 
-// Start 启动组件
+// Start 启动组件，设置了预热函数时先完成预热，预热完成之前健康检查不通过
 func (c *CacheComponent) Start(ctx context.Context) error {
+	if c.warmup != nil {
+		warmupCtx := ctx
+		if c.warmupTimeout > 0 {
+			var cancel context.CancelFunc
+			warmupCtx, cancel = context.WithTimeout(ctx, c.warmupTimeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		if err := c.warmup(warmupCtx, c.cache); err != nil {
+			c.SetStatus(ComponentStatusFailed)
+			return NewComponentError("cache", "start", "缓存预热失败", err)
+		}
+		c.SetMetric("warmup_duration_ms", time.Since(start).Milliseconds())
+	}
+
 	if err := c.BaseComponent.Start(ctx); err != nil {
 		return err
 	}
@@ -767,14 +1303,31 @@ func (c *CacheComponent) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop 停止组件
+// Stop 停止组件，关闭缓存，内存缓存启用快照时写入快照
 func (c *CacheComponent) Stop(ctx context.Context) error {
 	if c.logger != nil {
 		c.logger.Info("缓存组件正在停止")
 	}
+	if err := c.cache.Close(); err != nil {
+		c.snapshotError(err)
+	}
 	return c.BaseComponent.Stop(ctx)
 }
 
+// SetWarmup 设置预热函数，在Start中执行
+// 预热超时由cache.warmup.timeout_ms配置，预热失败时组件启动失败
+func (c *CacheComponent) SetWarmup(fn cache.WarmupFunc) {
+	c.warmup = fn
+}
+
+// snapshotError 记录快照读写错误，快照失败不影响缓存使用
+func (c *CacheComponent) snapshotError(err error) {
+	c.SetMetric("snapshot_error", err.Error())
+	if c.logger != nil {
+		c.logger.Warn("缓存快照失败", logger.Err(err))
+	}
+}
+
 // HealthCheck 健康检查
 func (c *CacheComponent) HealthCheck() error {
 	if err := c.BaseComponent.HealthCheck(); err != nil {
@@ -789,9 +1342,69 @@ func (c *CacheComponent) HealthCheck() error {
 	if _, found := c.cache.Get(ctx, testKey); !found {
 		return fmt.Errorf("缓存读取测试失败: 键未找到")
 	}
+	// 多级缓存的远程层熔断时仍可读写本地缓存，默认只在指标中报告降级状态，
+	// 设置cache.circuit_breaker.fail_health_check时健康检查失败
+	if stats, ok := cache.CircuitBreakerStatsOf(c.cache); ok {
+		degraded := stats.State != cache.CircuitClosed
+		c.SetMetric("degraded", degraded)
+		if degraded && c.failWhenDegraded {
+			return fmt.Errorf("远程缓存熔断器处于%s状态，已降级为本地缓存: %v", stats.State, stats.LastError)
+		}
+	}
 	return nil
 }
 
+// GetMetrics 获取组件指标，启用统计时包含缓存命中率、延迟和按键前缀分组的统计，
+// 多级缓存启用熔断器时包含熔断器状态
+func (c *CacheComponent) GetMetrics() map[string]interface{} {
+	metrics := c.BaseComponent.GetMetrics()
+	if stats, ok := cache.CircuitBreakerStatsOf(c.cache); ok {
+		metrics["circuit_state"] = stats.State.String()
+		metrics["circuit_trips"] = stats.Trips
+		metrics["circuit_rejected"] = stats.Rejected
+		metrics["circuit_last_state_change"] = stats.LastStateChange
+		metrics["circuit_pending_keys"] = stats.PendingKeys
+		metrics["circuit_reconciled"] = stats.Reconciled
+		metrics["circuit_reconcile_dropped"] = stats.ReconcileDropped
+	}
+	if c.instrumented == nil {
+		return metrics
+	}
+
+	stats := c.instrumented.Metrics()
+	metrics["hits"] = stats.Hits
+	metrics["misses"] = stats.Misses
+	metrics["hit_rate"] = stats.HitRate()
+	metrics["sets"] = stats.Sets
+	metrics["deletes"] = stats.Deletes
+	metrics["evictions"] = stats.Evictions
+	metrics["errors"] = stats.Errors
+
+	latencies := make(map[string]interface{}, len(stats.Latencies))
+	for op, h := range stats.Latencies {
+		latencies[string(op)] = map[string]interface{}{
+			"count":   h.Count,
+			"mean_ms": float64(h.Mean()) / float64(time.Millisecond),
+			"max_ms":  float64(h.Max) / float64(time.Millisecond),
+		}
+	}
+	metrics["latencies"] = latencies
+
+	prefixes := make(map[string]interface{}, len(stats.Prefixes))
+	for prefix, p := range stats.Prefixes {
+		prefixes[prefix] = map[string]interface{}{
+			"hits":     p.Hits,
+			"misses":   p.Misses,
+			"hit_rate": p.HitRate(),
+			"sets":     p.Sets,
+			"deletes":  p.Deletes,
+			"errors":   p.Errors,
+		}
+	}
+	metrics["prefixes"] = prefixes
+	return metrics
+}
+
 // SetLogger 设置日志器
 func (c *CacheComponent) SetLogger(logger logger.Logger) {
 	c.logger = logger
@@ -802,11 +1415,243 @@ func (c *CacheComponent) SetConfig(config config.Provider) {
 	c.config = config
 }
 
+// GetRedisCache 获取缓存使用的Redis缓存，cache.type为memory时返回nil
+// 其他组件可以复用它的Redis客户端，客户端由缓存组件在停止时关闭
+func (c *CacheComponent) GetRedisCache() *cache.RedisCache {
+	return c.redisCache
+}
+
 // GetCache 获取缓存
+// 启用cache.metrics.enabled时返回包装后的*cache.InstrumentedCache，通过它的操作计入统计，
+// 可用Unwrap获取底层缓存
 func (c *CacheComponent) GetCache() cache.Cache {
 	return c.cache
 }
 
+// QueueComponent 任务队列组件
+type QueueComponent struct {
+	*BaseComponent
+	manager     *queue.Manager
+	backend     queue.Backend
+	backendType string
+	redisCache  *cache.RedisCache
+	logger      logger.Logger
+	config      config.Provider
+}
+
+// Initialize 初始化组件
+func (c *QueueComponent) Initialize(ctx context.Context) error {
+	if err := c.BaseComponent.Initialize(ctx); err != nil {
+		return err
+	}
+	c.SetMetric("backend", c.backendType)
+	return nil
+}
+
+// Start 启动组件
+func (c *QueueComponent) Start(ctx context.Context) error {
+	if err := c.manager.Start(ctx); err != nil {
+		c.SetStatus(ComponentStatusFailed)
+		return err
+	}
+	if err := c.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if c.logger != nil {
+		c.logger.Info("任务队列组件已启动", logger.String("backend", c.backendType))
+	}
+	return nil
+}
+
+// Stop 停止组件，等待正在执行的任务完成
+func (c *QueueComponent) Stop(ctx context.Context) error {
+	if c.logger != nil {
+		c.logger.Info("任务队列组件正在停止")
+	}
+
+	err := c.manager.Stop(ctx)
+	_ = c.backend.Close()
+	if c.redisCache != nil {
+		_ = c.redisCache.Close()
+	}
+
+	if stopErr := c.BaseComponent.Stop(ctx); stopErr != nil {
+		return stopErr
+	}
+	return err
+}
+
+// HealthCheck 健康检查
+func (c *QueueComponent) HealthCheck() error {
+	if err := c.BaseComponent.HealthCheck(); err != nil {
+		return err
+	}
+	if _, err := c.backend.Size(context.Background(), queue.DefaultQueue); err != nil {
+		return fmt.Errorf("队列后端不可用: %v", err)
+	}
+	return nil
+}
+
+// GetMetrics 获取组件指标
+func (c *QueueComponent) GetMetrics() map[string]interface{} {
+	metrics := c.BaseComponent.GetMetrics()
+	stats := c.manager.Stats()
+	metrics["jobs_enqueued"] = stats.Enqueued
+	metrics["jobs_processed"] = stats.Processed
+	metrics["jobs_failed"] = stats.Failed
+	metrics["jobs_retried"] = stats.Retried
+	metrics["jobs_dead_lettered"] = stats.DeadLettered
+	return metrics
+}
+
+// SetLogger 设置日志器
+func (c *QueueComponent) SetLogger(logger logger.Logger) {
+	c.logger = logger
+}
+
+// SetConfig 设置配置
+func (c *QueueComponent) SetConfig(config config.Provider) {
+	c.config = config
+}
+
+// backendError 记录队列后端错误，例如出队或确认任务失败
+func (c *QueueComponent) backendError(err error) {
+	c.SetMetric("backend_error", err.Error())
+	if c.logger != nil {
+		c.logger.Error("任务队列后端出错", logger.Err(err))
+	}
+}
+
+// GetManager 获取任务管理器
+func (c *QueueComponent) GetManager() *queue.Manager {
+	return c.manager
+}
+
+// LeaderElectionComponent 领导者选举组件
+// 当选和失去领导权时分别在事件总线上发布"leader.elected"和"leader.lost"事件，
+// 事件数据包含选举键key和参选者标识identity
+type LeaderElectionComponent struct {
+	*BaseComponent
+	elector    *lock.Elector
+	redisCache *cache.RedisCache
+	eventBus   *EventBus
+	logger     logger.Logger
+	config     config.Provider
+}
+
+// Initialize 初始化组件
+func (c *LeaderElectionComponent) Initialize(ctx context.Context) error {
+	if err := c.BaseComponent.Initialize(ctx); err != nil {
+		return err
+	}
+	c.SetMetric("key", c.elector.Key())
+	c.SetMetric("identity", c.elector.Identity())
+	return nil
+}
+
+// Start 启动组件，开始竞选
+func (c *LeaderElectionComponent) Start(ctx context.Context) error {
+	if err := c.elector.Start(ctx); err != nil {
+		c.SetStatus(ComponentStatusFailed)
+		return err
+	}
+	if err := c.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if c.logger != nil {
+		c.logger.Info("领导者选举组件已启动",
+			logger.String("key", c.elector.Key()),
+			logger.String("identity", c.elector.Identity()),
+		)
+	}
+	return nil
+}
+
+// Stop 停止组件，如果当前是领导者则主动卸任
+func (c *LeaderElectionComponent) Stop(ctx context.Context) error {
+	if c.logger != nil {
+		c.logger.Info("领导者选举组件正在停止")
+	}
+
+	err := c.elector.Stop(ctx)
+	_ = c.redisCache.Close()
+
+	if stopErr := c.BaseComponent.Stop(ctx); stopErr != nil {
+		return stopErr
+	}
+	return err
+}
+
+// HealthCheck 健康检查
+func (c *LeaderElectionComponent) HealthCheck() error {
+	if err := c.BaseComponent.HealthCheck(); err != nil {
+		return err
+	}
+	if err := c.redisCache.GetClient().Ping(context.Background()).Err(); err != nil {
+		return fmt.Errorf("选举Redis不可用: %v", err)
+	}
+	return nil
+}
+
+// GetMetrics 获取组件指标
+func (c *LeaderElectionComponent) GetMetrics() map[string]interface{} {
+	metrics := c.BaseComponent.GetMetrics()
+	metrics["is_leader"] = c.elector.IsLeader()
+	return metrics
+}
+
+// SetLogger 设置日志器
+func (c *LeaderElectionComponent) SetLogger(logger logger.Logger) {
+	c.logger = logger
+}
+
+// SetConfig 设置配置
+func (c *LeaderElectionComponent) SetConfig(config config.Provider) {
+	c.config = config
+}
+
+// SetEventBus 设置事件总线
+func (c *LeaderElectionComponent) SetEventBus(bus *EventBus) {
+	c.eventBus = bus
+}
+
+// IsLeader 当前实例是否为领导者
+func (c *LeaderElectionComponent) IsLeader() bool {
+	return c.elector.IsLeader()
+}
+
+// GetElector 获取领导者选举器
+func (c *LeaderElectionComponent) GetElector() *lock.Elector {
+	return c.elector
+}
+
+// onElected 当选领导者
+func (c *LeaderElectionComponent) onElected() {
+	if c.logger != nil {
+		c.logger.Info("当选领导者", logger.String("identity", c.elector.Identity()))
+	}
+	c.publish("leader.elected")
+}
+
+// onLost 失去领导权
+func (c *LeaderElectionComponent) onLost() {
+	if c.logger != nil {
+		c.logger.Warn("失去领导权", logger.String("identity", c.elector.Identity()))
+	}
+	c.publish("leader.lost")
+}
+
+// publish 发布选举事件
+func (c *LeaderElectionComponent) publish(eventName string) {
+	if c.eventBus == nil {
+		return
+	}
+	c.eventBus.Publish(eventName, map[string]interface{}{
+		"key":      c.elector.Key(),
+		"identity": c.elector.Identity(),
+	})
+}
+
 // WebComponent Web组件
 type WebComponent struct {
 	*BaseComponent
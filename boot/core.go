@@ -225,4 +225,8 @@ var (
 	ErrComponentStopError = &ConfigError{Message: "组件停止失败", Component: "Component", Timestamp: time.Now()}
 	// ErrHealthCheckFailed 健康检查失败错误
 	ErrHealthCheckFailed = &ConfigError{Message: "健康检查失败", Component: "HealthChecker", Timestamp: time.Now()}
+	// ErrEventBusClosed 事件总线已关闭错误
+	ErrEventBusClosed = &ConfigError{Message: "事件总线已关闭", Component: "EventBus", Timestamp: time.Now()}
+	// ErrEventQueueFull 事件分发队列已满错误
+	ErrEventQueueFull = &ConfigError{Message: "事件分发队列已满", Component: "EventBus", Timestamp: time.Now()}
 )
@@ -1,100 +1,416 @@
 package boot
 
 import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/guanzhenxing/go-snap/logger"
 )
 
 // EventBus 事件总线，实现发布-订阅模式，用于组件间的解耦通信
 // 允许组件发布事件并让其他组件订阅这些事件，无需直接依赖
+//
+// 事件名称是以点号分隔的层级主题，例如"application.health_check.failed"。
+// 订阅时可以使用通配符：
+//   - "*" 匹配恰好一个层级，例如"application.*"匹配"application.started"
+//   - "#" 匹配零个或多个层级，例如"component.#"匹配"component.stop.error"
+//
+// 异步事件由固定数量的工作协程从有界队列中取出分发，监听器panic会被捕获并记录，
+// 投递失败的事件进入死信通道。应用关闭时通过Close排空队列中尚未处理的事件
 type EventBus struct {
-	// listeners 存储事件名称到监听器列表的映射
-	listeners map[string][]EventListener
+	// listeners 存储订阅模式到订阅者列表的映射
+	listeners map[string][]*subscriber
 	// mutex 用于保护并发访问listeners映射
 	mutex sync.RWMutex
+	// nextID 下一个订阅的序号，用于保证同优先级订阅者按订阅顺序调用
+	nextID uint64
+
+	// options 事件总线配置
+	options EventBusOptions
+	// orderedSegments 需要有序投递的事件模式（已按层级拆分）
+	orderedSegments [][]string
+	// queue 无序事件的共享分发队列
+	queue chan dispatchTask
+	// ordered 有序事件的分片队列，每个工作协程独占一个
+	ordered []chan dispatchTask
+	// deadLetters 死信通道
+	deadLetters chan DeadLetter
+	// dispatchMu 保护队列的关闭，发布时持有读锁，关闭时持有写锁
+	dispatchMu sync.RWMutex
+	// closed 事件总线是否已关闭
+	closed bool
+	// closing 开始关闭时关闭的通道，唤醒因队列已满而阻塞的发布者，使其释放dispatchMu读锁
+	closing chan struct{}
+	// closingOnce 确保closing只关闭一次
+	closingOnce sync.Once
+	// startOnce 确保工作协程只启动一次
+	startOnce sync.Once
+	// workers 等待工作协程退出
+	workers sync.WaitGroup
+	// loggerOnce 确保默认日志实例只创建一次
+	loggerOnce sync.Once
+	// log 用于记录监听器panic等异常的日志实例
+	log logger.Logger
+}
+
+// BackpressurePolicy 分发队列已满时的背压策略
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock 阻塞发布者直到队列有空位
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDrop 丢弃事件并投递到死信通道
+	BackpressureDrop
+	// BackpressureCallerRuns 在发布者的goroutine中直接调用监听器
+	// 对有序事件该策略退化为BackpressureBlock，以保证投递顺序
+	BackpressureCallerRuns
+)
+
+// String 返回背压策略的名称
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case BackpressureBlock:
+		return "block"
+	case BackpressureDrop:
+		return "drop"
+	case BackpressureCallerRuns:
+		return "caller_runs"
+	default:
+		return "unknown"
+	}
+}
+
+// parseBackpressurePolicy 解析背压策略名称，名称与String的返回值相同
+func parseBackpressurePolicy(name string) (BackpressurePolicy, error) {
+	switch name {
+	case "block":
+		return BackpressureBlock, nil
+	case "drop":
+		return BackpressureDrop, nil
+	case "caller_runs":
+		return BackpressureCallerRuns, nil
+	default:
+		return BackpressureBlock, fmt.Errorf("不支持的背压策略: %s", name)
+	}
+}
+
+// EventBusOptions 事件总线配置
+type EventBusOptions struct {
+	// Workers 异步分发的工作协程数量
+	Workers int
+	// QueueSize 共享分发队列的容量，每个有序分片队列的容量为QueueSize/Workers
+	QueueSize int
+	// Backpressure 队列已满时的背压策略
+	Backpressure BackpressurePolicy
+	// OrderedTopics 需要按发布顺序投递的事件模式，支持"*"和"#"通配符
+	// 同名事件总是由同一个工作协程依次处理，监听器按优先级顺序调用
+	OrderedTopics []string
+	// DeadLetterSize 死信通道的容量，通道已满时新的死信会被记录后丢弃
+	DeadLetterSize int
+	// Logger 日志实例，为nil时使用logger.New()创建的默认实例
+	Logger logger.Logger
+}
+
+// DefaultEventBusOptions 返回默认的事件总线配置
+func DefaultEventBusOptions() EventBusOptions {
+	return EventBusOptions{
+		Workers:        4,
+		QueueSize:      1024,
+		Backpressure:   BackpressureBlock,
+		DeadLetterSize: 128,
+	}
+}
+
+// eventBusOptionsFromProperties 从eventbus.*属性读取事件总线配置，未设置的属性使用默认值
+func eventBusOptionsFromProperties(props PropertySource) (EventBusOptions, error) {
+	options := DefaultEventBusOptions()
+	options.Workers = props.GetInt("eventbus.workers", options.Workers)
+	options.QueueSize = props.GetInt("eventbus.queue_size", options.QueueSize)
+	options.DeadLetterSize = props.GetInt("eventbus.dead_letter_size", options.DeadLetterSize)
+
+	policy, err := parseBackpressurePolicy(props.GetString("eventbus.backpressure", options.Backpressure.String()))
+	if err != nil {
+		return options, err
+	}
+	options.Backpressure = policy
+
+	for _, topic := range strings.Split(props.GetString("eventbus.ordered_topics", ""), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			options.OrderedTopics = append(options.OrderedTopics, topic)
+		}
+	}
+	return options, nil
+}
+
+// DeadLetter 投递失败的事件
+type DeadLetter struct {
+	// EventName 事件名称
+	EventName string
+	// EventData 事件数据
+	EventData interface{}
+	// Pattern 未能收到事件的订阅模式
+	Pattern string
+	// Err 失败原因，如监听器panic、队列已满或事件总线已关闭
+	Err error
+}
+
+// dispatchTask 分发任务
+type dispatchTask struct {
+	eventName string
+	eventData interface{}
+	subs      []*subscriber
+}
+
+// subscriber 事件订阅者
+type subscriber struct {
+	id       uint64
+	pattern  string
+	segments []string
+	listener EventListener
+	priority int
+	once     bool
+	fired    atomic.Bool
+}
+
+// Subscription 订阅句柄，用于取消订阅
+type Subscription struct {
+	bus  *EventBus
+	sub  *subscriber
+	done atomic.Bool
+}
+
+// Unsubscribe 取消订阅，重复调用是安全的
+func (s *Subscription) Unsubscribe() {
+	if s == nil || !s.done.CompareAndSwap(false, true) {
+		return
+	}
+	s.bus.remove(s.sub)
+}
+
+// Pattern 返回订阅的事件模式
+func (s *Subscription) Pattern() string {
+	return s.sub.pattern
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(sub *subscriber)
+
+// WithPriority 设置监听器优先级，数值越大越先被调用，默认为0
+func WithPriority(priority int) SubscribeOption {
+	return func(sub *subscriber) {
+		sub.priority = priority
+	}
+}
+
+// WithOnce 设置监听器只被调用一次，调用后自动取消订阅
+func WithOnce() SubscribeOption {
+	return func(sub *subscriber) {
+		sub.once = true
+	}
 }
 
 // NewEventBus 创建并初始化一个新的事件总线实例
+// 参数：
+//
+//	opts: 可选的事件总线配置，未提供时使用DefaultEventBusOptions
+//
 // 返回：
 //
-//	初始化的EventBus实例
-func NewEventBus() *EventBus {
-	return &EventBus{
-		listeners: make(map[string][]EventListener),
+//	初始化的EventBus实例，工作协程在第一次异步发布时启动
+func NewEventBus(opts ...EventBusOptions) *EventBus {
+	options := DefaultEventBusOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	defaults := DefaultEventBusOptions()
+	if options.Workers <= 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaults.QueueSize
+	}
+	if options.DeadLetterSize <= 0 {
+		options.DeadLetterSize = defaults.DeadLetterSize
+	}
+
+	shardSize := options.QueueSize / options.Workers
+	if shardSize < 1 {
+		shardSize = 1
+	}
+
+	b := &EventBus{
+		listeners:   make(map[string][]*subscriber),
+		options:     options,
+		queue:       make(chan dispatchTask, options.QueueSize),
+		ordered:     make([]chan dispatchTask, options.Workers),
+		deadLetters: make(chan DeadLetter, options.DeadLetterSize),
+		closing:     make(chan struct{}),
+		log:         options.Logger,
+	}
+	for i := range b.ordered {
+		b.ordered[i] = make(chan dispatchTask, shardSize)
 	}
+	for _, topic := range options.OrderedTopics {
+		b.orderedSegments = append(b.orderedSegments, strings.Split(topic, "."))
+	}
+	return b
 }
 
-// Subscribe 订阅指定名称的事件
+// Subscribe 订阅指定名称或模式的事件
 // 参数：
 //
-//	eventName: 要订阅的事件名称
+//	eventName: 要订阅的事件名称，可以包含"*"和"#"通配符
 //	listener: 当事件发生时调用的监听器函数
-func (b *EventBus) Subscribe(eventName string, listener EventListener) {
+//	opts: 订阅选项，如优先级和一次性订阅
+//
+// 返回：
+//
+//	订阅句柄，调用其Unsubscribe方法取消订阅
+func (b *EventBus) Subscribe(eventName string, listener EventListener, opts ...SubscribeOption) *Subscription {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	if _, exists := b.listeners[eventName]; !exists {
-		b.listeners[eventName] = []EventListener{}
+	b.nextID++
+	sub := &subscriber{
+		id:       b.nextID,
+		pattern:  eventName,
+		segments: strings.Split(eventName, "."),
+		listener: listener,
+	}
+	for _, opt := range opts {
+		opt(sub)
 	}
 
-	b.listeners[eventName] = append(b.listeners[eventName], listener)
+	b.listeners[eventName] = append(b.listeners[eventName], sub)
+
+	return &Subscription{bus: b, sub: sub}
+}
+
+// SubscribeOnce 订阅事件，监听器只会被调用一次
+func (b *EventBus) SubscribeOnce(eventName string, listener EventListener, opts ...SubscribeOption) *Subscription {
+	return b.Subscribe(eventName, listener, append(opts, WithOnce())...)
 }
 
 // Unsubscribe 取消订阅指定名称的事件
 // 参数：
 //
-//	eventName: 要取消订阅的事件名称
+//	eventName: 订阅时使用的事件名称或模式
 //	listener: 要移除的监听器函数
 //
 // 注意：
 //
-//	由于Go语言的函数比较限制，此方法可能无法正确移除所有情况下的监听器
+//	监听器按函数地址识别，由同一个函数字面量创建的多个闭包无法区分，
+//	此时只会移除最早订阅的一个。推荐使用Subscribe返回的Subscription取消订阅
 func (b *EventBus) Unsubscribe(eventName string, listener EventListener) {
+	target := reflect.ValueOf(listener).Pointer()
+
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	if listeners, exists := b.listeners[eventName]; exists {
-		for i, l := range listeners {
-			if &l == &listener {
-				b.listeners[eventName] = append(listeners[:i], listeners[i+1:]...)
-				break
-			}
+	for i, sub := range b.listeners[eventName] {
+		if reflect.ValueOf(sub.listener).Pointer() == target {
+			b.removeAtUnsafe(eventName, i)
+			return
 		}
 	}
 }
 
-// Publish 异步发布事件，不阻塞调用者
-// 对每个监听器在独立的goroutine中调用，适合长时间运行的处理
+// Publish 异步发布事件
+// 事件放入有界队列后由工作协程分发，队列已满时按配置的背压策略处理。
+// 无序事件的各监听器可能被并发调用；匹配OrderedTopics的事件按发布顺序、
+// 监听器按优先级顺序依次调用
 // 参数：
 //
 //	eventName: 要发布的事件名称
 //	eventData: 与事件一起传递的数据
 func (b *EventBus) Publish(eventName string, eventData interface{}) {
-	b.mutex.RLock()
-	listeners, exists := b.listeners[eventName]
-	b.mutex.RUnlock()
+	subs := b.match(eventName)
+	if len(subs) == 0 {
+		return
+	}
+
+	b.dispatchMu.RLock()
+	defer b.dispatchMu.RUnlock()
 
-	if exists {
-		for _, listener := range listeners {
-			go listener(eventName, eventData)
+	if b.closed {
+		for _, sub := range subs {
+			b.deadLetter(eventName, eventData, sub, ErrEventBusClosed)
 		}
+		return
+	}
+	b.startOnce.Do(b.startWorkers)
+
+	if b.isOrdered(eventName) {
+		b.enqueue(b.ordered[b.shard(eventName)], dispatchTask{eventName: eventName, eventData: eventData, subs: subs}, true)
+		return
+	}
+	for _, sub := range subs {
+		b.enqueue(b.queue, dispatchTask{eventName: eventName, eventData: eventData, subs: []*subscriber{sub}}, false)
 	}
 }
 
 // PublishSync 同步发布事件，阻塞直到所有监听器处理完成
-// 在当前goroutine中顺序调用所有监听器，适合简短的处理
+// 在当前goroutine中按优先级顺序调用所有监听器，适合简短的处理。
+// 监听器panic会被捕获，不影响后续监听器的调用
 // 参数：
 //
 //	eventName: 要发布的事件名称
 //	eventData: 与事件一起传递的数据
 func (b *EventBus) PublishSync(eventName string, eventData interface{}) {
-	b.mutex.RLock()
-	listeners, exists := b.listeners[eventName]
-	b.mutex.RUnlock()
+	for _, sub := range b.match(eventName) {
+		b.invoke(sub, eventName, eventData)
+	}
+}
 
-	if exists {
-		for _, listener := range listeners {
-			listener(eventName, eventData)
-		}
+// DeadLetters 返回死信通道
+// 监听器panic、队列已满被丢弃或关闭后发布的事件会投递到该通道。
+// 通道不会被关闭，消费者应在应用停止时自行退出
+func (b *EventBus) DeadLetters() <-chan DeadLetter {
+	return b.deadLetters
+}
+
+// Close 关闭事件总线并排空队列中尚未处理的事件
+// 关闭后异步发布的事件直接进入死信通道，同步发布不受影响
+// 参数：
+//
+//	ctx: 上下文，用于控制等待排空的超时
+//
+// 返回：
+//
+//	如果在所有事件处理完成前上下文结束则返回上下文错误
+func (b *EventBus) Close(ctx context.Context) error {
+	// 先唤醒阻塞在已满队列上的发布者，否则它们持有的读锁会使下面的写锁永远无法获得
+	b.closingOnce.Do(func() { close(b.closing) })
+
+	b.dispatchMu.Lock()
+	if b.closed {
+		b.dispatchMu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	for _, queue := range b.ordered {
+		close(queue)
+	}
+	b.dispatchMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -105,13 +421,20 @@ func (b *EventBus) PublishSync(eventName string, eventData interface{}) {
 //
 // 返回：
 //
-//	如果事件有至少一个监听器则返回true，否则返回false
+//	如果有至少一个订阅模式匹配该事件则返回true，否则返回false
 func (b *EventBus) HasListeners(eventName string) bool {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
 
-	listeners, exists := b.listeners[eventName]
-	return exists && len(listeners) > 0
+	segments := strings.Split(eventName, ".")
+	for _, subs := range b.listeners {
+		for _, sub := range subs {
+			if matchTopic(sub.segments, segments) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Clear 清除所有事件监听器
@@ -120,5 +443,270 @@ func (b *EventBus) Clear() {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	b.listeners = make(map[string][]EventListener)
+	b.listeners = make(map[string][]*subscriber)
+}
+
+// match 返回匹配事件的订阅者，按优先级从高到低、同优先级按订阅顺序排列
+// 一次性订阅者在这里被取出并移除，保证只会被调用一次
+func (b *EventBus) match(eventName string) []*subscriber {
+	segments := strings.Split(eventName, ".")
+
+	b.mutex.RLock()
+	var matched []*subscriber
+	hasOnce := false
+	for _, subs := range b.listeners {
+		for _, sub := range subs {
+			if matchTopic(sub.segments, segments) {
+				matched = append(matched, sub)
+				hasOnce = hasOnce || sub.once
+			}
+		}
+	}
+	b.mutex.RUnlock()
+
+	if hasOnce {
+		result := matched[:0]
+		for _, sub := range matched {
+			if sub.once {
+				if !sub.fired.CompareAndSwap(false, true) {
+					continue
+				}
+				b.remove(sub)
+			}
+			result = append(result, sub)
+		}
+		matched = result
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].priority != matched[j].priority {
+			return matched[i].priority > matched[j].priority
+		}
+		return matched[i].id < matched[j].id
+	})
+
+	return matched
+}
+
+// remove 移除订阅者
+func (b *EventBus) remove(target *subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for i, sub := range b.listeners[target.pattern] {
+		if sub == target {
+			b.removeAtUnsafe(target.pattern, i)
+			return
+		}
+	}
+}
+
+// removeAtUnsafe 移除指定模式下第i个订阅者（调用方需持有写锁）
+func (b *EventBus) removeAtUnsafe(pattern string, i int) {
+	subs := b.listeners[pattern]
+	remaining := make([]*subscriber, 0, len(subs)-1)
+	remaining = append(remaining, subs[:i]...)
+	remaining = append(remaining, subs[i+1:]...)
+
+	if len(remaining) == 0 {
+		delete(b.listeners, pattern)
+		return
+	}
+	b.listeners[pattern] = remaining
+}
+
+// startWorkers 启动工作协程
+func (b *EventBus) startWorkers() {
+	for _, ordered := range b.ordered {
+		b.workers.Add(1)
+		go b.work(ordered)
+	}
+}
+
+// work 工作协程主循环，同时处理共享队列和独占的有序分片队列，两者都关闭且排空后退出
+func (b *EventBus) work(ordered chan dispatchTask) {
+	defer b.workers.Done()
+
+	shared := b.queue
+	for shared != nil || ordered != nil {
+		select {
+		case task, ok := <-shared:
+			if !ok {
+				shared = nil
+				continue
+			}
+			b.run(task)
+		case task, ok := <-ordered:
+			if !ok {
+				ordered = nil
+				continue
+			}
+			b.run(task)
+		}
+	}
+}
+
+// enqueue 按背压策略将任务放入队列（调用方需持有dispatchMu读锁）
+// 阻塞等待队列空位期间事件总线开始关闭时，事件投递到死信通道
+func (b *EventBus) enqueue(queue chan dispatchTask, task dispatchTask, ordered bool) {
+	switch b.options.Backpressure {
+	case BackpressureDrop:
+		select {
+		case queue <- task:
+		default:
+			for _, sub := range task.subs {
+				b.deadLetter(task.eventName, task.eventData, sub, ErrEventQueueFull)
+			}
+		}
+	case BackpressureCallerRuns:
+		if ordered {
+			b.send(queue, task)
+			return
+		}
+		select {
+		case queue <- task:
+		default:
+			b.run(task)
+		}
+	default:
+		b.send(queue, task)
+	}
+}
+
+// send 阻塞直到任务放入队列，事件总线开始关闭时放弃并投递死信（调用方需持有dispatchMu读锁）
+func (b *EventBus) send(queue chan dispatchTask, task dispatchTask) {
+	select {
+	case queue <- task:
+		return
+	default:
+	}
+
+	select {
+	case queue <- task:
+	case <-b.closing:
+		for _, sub := range task.subs {
+			b.deadLetter(task.eventName, task.eventData, sub, ErrEventBusClosed)
+		}
+	}
+}
+
+// run 依次调用任务中的监听器
+func (b *EventBus) run(task dispatchTask) {
+	for _, sub := range task.subs {
+		b.invoke(sub, task.eventName, task.eventData)
+	}
+}
+
+// invoke 调用监听器并捕获panic，panic会被记录并投递到死信通道
+func (b *EventBus) invoke(sub *subscriber, eventName string, eventData interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("事件监听器panic: %v", r)
+			b.getLogger().Error("事件监听器panic",
+				logger.String("event", eventName),
+				logger.String("pattern", sub.pattern),
+				logger.Any("panic", r),
+				logger.Stack("stack"),
+			)
+			b.deadLetter(eventName, eventData, sub, err)
+		}
+	}()
+	sub.listener(eventName, eventData)
+}
+
+// deadLetter 投递死信，死信通道已满时记录日志后丢弃
+func (b *EventBus) deadLetter(eventName string, eventData interface{}, sub *subscriber, err error) {
+	select {
+	case b.deadLetters <- DeadLetter{EventName: eventName, EventData: eventData, Pattern: sub.pattern, Err: err}:
+	default:
+		b.getLogger().Warn("死信通道已满，丢弃事件",
+			logger.String("event", eventName),
+			logger.String("pattern", sub.pattern),
+			logger.Err(err),
+		)
+	}
+}
+
+// getLogger 返回日志实例，未配置时延迟创建默认实例
+func (b *EventBus) getLogger() logger.Logger {
+	b.loggerOnce.Do(func() {
+		if b.log == nil {
+			b.log = logger.New()
+		}
+	})
+	return b.log
+}
+
+// isOrdered 判断事件是否需要有序投递
+func (b *EventBus) isOrdered(eventName string) bool {
+	if len(b.orderedSegments) == 0 {
+		return false
+	}
+	segments := strings.Split(eventName, ".")
+	for _, pattern := range b.orderedSegments {
+		if matchTopic(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// shard 返回事件所属的有序分片
+func (b *EventBus) shard(eventName string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(eventName))
+	return int(h.Sum32() % uint32(len(b.ordered)))
+}
+
+// matchTopic 判断主题层级是否匹配订阅模式
+func matchTopic(pattern, topic []string) bool {
+	if len(pattern) == 0 {
+		return len(topic) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		// "#"匹配零个或多个层级
+		for i := 0; i <= len(topic); i++ {
+			if matchTopic(pattern[1:], topic[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(topic) > 0 && matchTopic(pattern[1:], topic[1:])
+	default:
+		return len(topic) > 0 && pattern[0] == topic[0] && matchTopic(pattern[1:], topic[1:])
+	}
+}
+
+// TypedListener 类型化事件监听器
+type TypedListener[T any] func(eventName string, payload T)
+
+// Subscribe 以类型安全的方式订阅事件
+// 只有负载类型为T的事件才会传递给监听器，负载为nil时传递T的零值
+//
+// 示例：
+//
+//	boot.Subscribe(bus, "order.created", func(name string, order *Order) {
+//	    // 处理订单
+//	})
+func Subscribe[T any](bus *EventBus, eventName string, listener TypedListener[T], opts ...SubscribeOption) *Subscription {
+	return bus.Subscribe(eventName, func(name string, data interface{}) {
+		payload, ok := data.(T)
+		if !ok && data != nil {
+			return
+		}
+		listener(name, payload)
+	}, opts...)
+}
+
+// Publish 以类型安全的方式异步发布事件
+func Publish[T any](bus *EventBus, eventName string, payload T) {
+	bus.Publish(eventName, payload)
+}
+
+// PublishSync 以类型安全的方式同步发布事件
+func PublishSync[T any](bus *EventBus, eventName string, payload T) {
+	bus.PublishSync(eventName, payload)
 }
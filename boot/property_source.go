@@ -235,9 +235,22 @@ func (p *FilePropertySource) loadProperties() error {
 	// 加载一些常见键的配置
 	commonKeys := []string{
 		"app.name", "app.version", "app.env", "app.debug",
+		"app.components.include", "app.components.exclude",
 		"logger.enabled", "logger.level", "logger.json", "logger.file.path",
 		"database.enabled", "database.driver", "database.dsn",
-		"cache.enabled", "cache.type",
+		"cache.enabled", "cache.type", "cache.metrics.enabled", "cache.metrics.slow_threshold_ms",
+		"cache.memory.snapshot_path", "cache.memory.snapshot_interval_ms", "cache.warmup.timeout_ms",
+		"cache.redis.addr", "cache.redis.password", "cache.redis.db", "cache.redis.key_prefix",
+		"cache.multi_level.local_ttl_ms", "cache.circuit_breaker.enabled", "cache.circuit_breaker.failure_threshold",
+		"cache.circuit_breaker.open_timeout_ms", "cache.circuit_breaker.operation_timeout_ms", "cache.circuit_breaker.max_pending_keys",
+		"cache.circuit_breaker.fail_health_check",
+		"queue.enabled", "queue.backend", "queue.concurrency", "queue.queues",
+		"queue.max_retries", "queue.poll_interval_ms", "queue.retry_backoff_ms", "queue.max_retry_backoff_ms",
+		"queue.redis.addr", "queue.redis.password", "queue.redis.db", "queue.redis.key_prefix", "queue.redis.visibility_timeout_ms",
+		"leader.enabled", "leader.key", "leader.identity",
+		"leader.lease_ms", "leader.renew_interval_ms", "leader.retry_interval_ms", "leader.safety_margin_ms",
+		"leader.redis.addr", "leader.redis.password", "leader.redis.db",
+		"eventbus.workers", "eventbus.queue_size", "eventbus.backpressure", "eventbus.ordered_topics", "eventbus.dead_letter_size",
 		"web.enabled", "web.port", "web.host",
 	}
 
@@ -43,6 +43,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -61,6 +62,8 @@ type ComponentRegistry struct {
 	dependencyGraph map[string][]string
 	// mutex 保护并发访问的互斥锁
 	mutex sync.RWMutex
+	// creating 串行化按需创建组件，创建时不持有mutex，工厂可以在Create中获取依赖的组件
+	creating sync.Mutex
 	// factoryContext 用于创建组件的上下文
 	factoryContext context.Context
 	// propertySource 配置属性源
@@ -69,8 +72,15 @@ type ComponentRegistry struct {
 	healthChecker ComponentHealthChecker
 	// metrics 注册表性能和状态指标
 	metrics *RegistryMetrics
+	// activationFilter 自动配置期间使用的组件激活过滤器
+	activationFilter activationFilter
+	// activations 组件激活决策记录
+	activations []ActivationDecision
 }
 
+// activationFilter 组件激活过滤器，返回组件是否应被激活以及原因
+type activationFilter func(name string) (bool, string)
+
 // RegistryMetrics 注册表指标，收集组件注册表的性能和状态数据
 type RegistryMetrics struct {
 	// ComponentCount 已注册组件数量
@@ -160,14 +170,44 @@ func (r *ComponentRegistry) RegisterComponent(component Component) error {
 //
 //	err := registry.RegisterFactory("database", &DatabaseFactory{})
 func (r *ComponentRegistry) RegisterFactory(name string, factory ComponentFactory) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	return r.registerFactory(name, factory, "")
+}
+
+// registerFactory 注册组件工厂，如果设置了激活过滤器，会先判断组件是否应被激活
+// reason为组件被启用的原因，会和过滤器给出的原因一起记录到激活决策中。
+// 过滤器和配置验证可能读取属性或调用其他注册表方法，因此在持有锁之外执行
+func (r *ComponentRegistry) registerFactory(name string, factory ComponentFactory, reason string) error {
+	r.mutex.RLock()
+	filter := r.activationFilter
+	r.mutex.RUnlock()
+
+	if filter != nil {
+		activated, filterReason := filter(name)
+		if !activated {
+			r.recordActivation(ActivationDecision{Name: name, Activated: false, Reason: filterReason})
+			return nil
+		}
+
+		reasons := make([]string, 0, 2)
+		for _, s := range []string{reason, filterReason} {
+			if s != "" {
+				reasons = append(reasons, s)
+			}
+		}
+		if len(reasons) == 0 {
+			reasons = append(reasons, "registered by configurer")
+		}
+		r.recordActivation(ActivationDecision{Name: name, Activated: true, Reason: strings.Join(reasons, "; ")})
+	}
 
 	// 验证配置
 	if err := factory.ValidateConfig(r.propertySource); err != nil {
 		return NewComponentError(name, "register_factory", "配置验证失败", err)
 	}
 
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	r.factories[name] = factory
 	r.dependencies[name] = factory.Dependencies()
 	r.buildDependencyGraph()
@@ -175,8 +215,32 @@ func (r *ComponentRegistry) RegisterFactory(name string, factory ComponentFactor
 	return nil
 }
 
-// GetComponent 获取组件，如果组件不存在但有对应的工厂，则会创建
-// 使用双重检查锁定模式以提高并发性能
+// GetActivationReport 获取自动配置期间的组件激活决策
+// 返回：
+//
+//	[]ActivationDecision: 按注册顺序排列的激活决策副本
+func (r *ComponentRegistry) GetActivationReport() []ActivationDecision {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]ActivationDecision{}, r.activations...)
+}
+
+// setActivationFilter 设置组件激活过滤器，传入nil表示移除
+func (r *ComponentRegistry) setActivationFilter(filter activationFilter) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.activationFilter = filter
+}
+
+// recordActivation 记录组件激活决策
+func (r *ComponentRegistry) recordActivation(decision ActivationDecision) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.activations = append(r.activations, decision)
+}
+
+// GetComponent 获取组件，如果组件不存在但有对应的工厂，则会先创建其依赖，再创建该组件
 // 参数：
 //
 //	name: 组件名称
@@ -188,7 +252,7 @@ func (r *ComponentRegistry) RegisterFactory(name string, factory ComponentFactor
 //
 // 性能：
 //   - 对于已存在的组件，仅需要读锁，性能较高
-//   - 对于需要创建的组件，会获取写锁，可能影响并发性能
+//   - 需要创建的组件按顺序逐个创建，工厂的Create在锁外执行，通过DependencyFromContext获取已创建的依赖
 //
 // 示例：
 //
@@ -196,43 +260,78 @@ func (r *ComponentRegistry) RegisterFactory(name string, factory ComponentFactor
 //	    // 使用数据库组件
 //	}
 func (r *ComponentRegistry) GetComponent(name string) (Component, bool) {
+	return r.getComponent(name, map[string]bool{})
+}
+
+// getComponent 获取或创建组件，visiting记录正在创建依赖的组件，避免循环依赖导致无限递归
+func (r *ComponentRegistry) getComponent(name string, visiting map[string]bool) (Component, bool) {
 	// 第一次检查（读锁）
 	r.mutex.RLock()
 	component, exists := r.components[name]
-	if exists {
-		r.mutex.RUnlock()
-		return component, true
-	}
-
-	// 检查是否有对应的工厂
 	factory, hasFactory := r.factories[name]
 	r.mutex.RUnlock()
 
+	if exists {
+		return component, true
+	}
 	if !hasFactory {
 		return nil, false
 	}
 
-	// 第二次检查（写锁）
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	// 先创建依赖，循环依赖由ResolveDependencies报告
+	// 已创建的依赖通过上下文传给工厂，工厂不需要在Create中回调注册表
+	visiting[name] = true
+	deps := make(map[string]Component)
+	for _, depName := range factory.Dependencies() {
+		if visiting[depName] {
+			continue
+		}
+		if dep, ok := r.getComponent(depName, visiting); ok {
+			deps[depName] = dep
+		}
+	}
 
-	// 再次检查组件是否已被其他goroutine创建
-	if component, exists := r.components[name]; exists {
+	r.creating.Lock()
+	defer r.creating.Unlock()
+
+	// 第二次检查，组件可能已被其他goroutine创建
+	r.mutex.RLock()
+	component, exists = r.components[name]
+	r.mutex.RUnlock()
+	if exists {
 		return component, true
 	}
 
 	// 创建组件实例
-	component, err := factory.Create(r.factoryContext, r.propertySource)
+	component, err := factory.Create(withDependencies(r.factoryContext, deps), r.propertySource)
 	if err != nil {
 		r.recordFailedComponent(name, err)
 		return nil, false
 	}
 
+	r.mutex.Lock()
 	r.components[name] = component
 	r.updateMetrics()
+	r.mutex.Unlock()
 	return component, true
 }
 
+// dependenciesKey 上下文中保存已创建依赖组件的键
+type dependenciesKey struct{}
+
+// withDependencies 将已创建的依赖组件放入创建组件的上下文
+func withDependencies(ctx context.Context, deps map[string]Component) context.Context {
+	return context.WithValue(ctx, dependenciesKey{}, deps)
+}
+
+// DependencyFromContext 在ComponentFactory.Create中获取Dependencies声明的依赖组件
+// 注册表在调用Create前创建依赖，依赖创建失败或没有注册时返回false
+func DependencyFromContext(ctx context.Context, name string) (Component, bool) {
+	deps, _ := ctx.Value(dependenciesKey{}).(map[string]Component)
+	component, ok := deps[name]
+	return component, ok
+}
+
 // GetComponentByType 获取指定类型的第一个组件
 // 参数：
 //
@@ -367,18 +466,21 @@ func (r *ComponentRegistry) ResolveDependencies() error {
 		}
 
 		// 确保所有依赖都已创建
+		deps := make(map[string]Component)
 		for _, depName := range factory.Dependencies() {
-			if _, exists := r.components[depName]; !exists {
+			dep, exists := r.components[depName]
+			if !exists {
 				return NewDependencyError(
 					fmt.Sprintf("组件 %s 的依赖 %s 未找到", name, depName),
 					[]string{name, depName},
 					nil,
 				)
 			}
+			deps[depName] = dep
 		}
 
 		// 创建组件
-		component, err := factory.Create(r.factoryContext, r.propertySource)
+		component, err := factory.Create(withDependencies(r.factoryContext, deps), r.propertySource)
 		if err != nil {
 			r.recordFailedComponent(name, err)
 			return NewComponentError(name, "create", "创建组件失败", err)
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchCache 支持批量操作的缓存
+// 所有内置缓存实现都实现了此接口，批量操作比逐个调用减少了加锁次数或网络往返
+type BatchCache interface {
+	// GetMany 批量获取缓存值
+	// 参数：
+	//   ctx: 上下文
+	//   keys: 缓存键名列表
+	// 返回：
+	//   map[string]interface{}: 找到的键值，未找到的键不包含在结果中
+	//   error: 读取过程中遇到的错误
+	GetMany(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+	// SetMany 批量设置缓存项，每一项可以有各自的过期时间和标签
+	// 参数：
+	//   ctx: 上下文
+	//   items: 键到缓存项的映射，缓存项不能为nil
+	// 返回：
+	//   error: 设置过程中遇到的错误
+	SetMany(ctx context.Context, items map[string]*Item) error
+
+	// DeleteMany 批量删除缓存
+	// 参数：
+	//   ctx: 上下文
+	//   keys: 要删除的缓存键名列表，不存在的键会被忽略
+	// 返回：
+	//   error: 删除过程中遇到的错误
+	DeleteMany(ctx context.Context, keys []string) error
+}
+
+// GetMany 在任意缓存上批量获取缓存值
+// 缓存实现了BatchCache接口时使用其实现，否则逐个调用Get
+func GetMany(ctx context.Context, c Cache, keys []string) (map[string]interface{}, error) {
+	if b, ok := c.(BatchCache); ok {
+		return b.GetMany(ctx, keys)
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, found := c.Get(ctx, key); found {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// SetMany 在任意缓存上批量设置缓存项
+// 缓存实现了BatchCache接口时使用其实现，否则逐个调用SetItem
+func SetMany(ctx context.Context, c Cache, items map[string]*Item) error {
+	if b, ok := c.(BatchCache); ok {
+		return b.SetMany(ctx, items)
+	}
+
+	if err := validateItems(items); err != nil {
+		return err
+	}
+	for key, item := range items {
+		if err := c.SetItem(ctx, key, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMany 在任意缓存上批量删除缓存
+// 缓存实现了BatchCache接口时使用其实现，否则逐个调用Delete
+func DeleteMany(ctx context.Context, c Cache, keys []string) error {
+	if b, ok := c.(BatchCache); ok {
+		return b.DeleteMany(ctx, keys)
+	}
+
+	for _, key := range keys {
+		if err := c.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateItems 检查批量设置的缓存项，任意一项为nil时整批不写入
+func validateItems(items map[string]*Item) error {
+	for key, item := range items {
+		if item == nil {
+			return fmt.Errorf("cache item cannot be nil: key %q", key)
+		}
+	}
+	return nil
+}
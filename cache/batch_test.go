@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchOperations(t *testing.T) {
+	for name, c := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			require.Implements(t, (*BatchCache)(nil), c)
+
+			items := map[string]*Item{
+				"batch:1": {Value: "one", Expiration: time.Minute, Tags: []string{"odd"}},
+				"batch:2": {Value: "two", Expiration: time.Minute, Tags: []string{"even"}},
+				"batch:3": {Value: "three", Expiration: time.Minute, Tags: []string{"odd"}},
+			}
+			require.NoError(t, SetMany(ctx, c, items))
+
+			values, err := GetMany(ctx, c, []string{"batch:1", "batch:2", "batch:3", "batch:missing"})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"batch:1": "one",
+				"batch:2": "two",
+				"batch:3": "three",
+			}, values)
+
+			// 每一项的标签都被记录
+			require.NoError(t, c.DeleteByTag(ctx, "odd"))
+			values, err = GetMany(ctx, c, []string{"batch:1", "batch:2", "batch:3"})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"batch:2": "two"}, values)
+
+			require.NoError(t, DeleteMany(ctx, c, []string{"batch:2", "batch:missing"}))
+			values, err = GetMany(ctx, c, []string{"batch:2"})
+			require.NoError(t, err)
+			assert.Empty(t, values)
+		})
+	}
+}
+
+func TestBatchOperations_PerItemTTL(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	ctx := context.Background()
+	require.NoError(t, c.SetMany(ctx, map[string]*Item{
+		"short": {Value: 1, Expiration: 10 * time.Millisecond},
+		"long":  {Value: 2, Expiration: time.Minute},
+	}))
+
+	time.Sleep(20 * time.Millisecond)
+	values, err := c.GetMany(ctx, []string{"short", "long"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"long": 2}, values)
+}
+
+func TestBatchOperations_NilItemRejected(t *testing.T) {
+	for name, c := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			err := SetMany(ctx, c, map[string]*Item{
+				"valid": {Value: "v"},
+				"nil":   nil,
+			})
+			assert.Error(t, err)
+
+			exists, err := c.Exists(ctx, "valid")
+			require.NoError(t, err)
+			assert.False(t, exists, "no item should be written when the batch is invalid")
+		})
+	}
+}
+
+func TestMultiLevelCache_GetManyBackfillsLocal(t *testing.T) {
+	local := NewMemoryCache()
+	defer local.Close()
+	remote := newMiniRedisCache(t)
+
+	multi, err := NewMultiLevelCache(local, remote)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, local.Set(ctx, "a", "local-a", time.Minute))
+	require.NoError(t, remote.SetMany(ctx, map[string]*Item{
+		"a": {Value: "remote-a"},
+		"b": {Value: "remote-b"},
+	}))
+
+	values, err := multi.GetMany(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "local-a", "b": "remote-b"}, values)
+
+	// 远程命中的值回填到本地缓存
+	value, found := local.Get(ctx, "b")
+	assert.True(t, found)
+	assert.Equal(t, "remote-b", value)
+	_, ttl, _ := local.GetWithTTL(ctx, "b")
+	assert.LessOrEqual(t, ttl, DefaultMultiLevelOptions().LocalTTL)
+}
+
+func TestShardedMemoryCache_BatchAcrossShards(t *testing.T) {
+	c := NewShardedMemoryCache(8)
+	defer c.Close()
+
+	ctx := context.Background()
+	items := make(map[string]*Item)
+	keys := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key:%d", i)
+		keys = append(keys, key)
+		items[key] = &Item{Value: i}
+	}
+
+	require.NoError(t, c.SetMany(ctx, items))
+	values, err := c.GetMany(ctx, keys)
+	require.NoError(t, err)
+	assert.Len(t, values, 100)
+	assert.Equal(t, 42, values["key:42"])
+
+	require.NoError(t, c.DeleteMany(ctx, keys[:50]))
+	assert.Equal(t, int64(50), c.Stats().Entries)
+}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BloomFilter 布隆过滤器
+// 用于快速判断键一定不存在，判断为可能存在的键仍可能不存在（误判），但不会漏判已添加的键
+type BloomFilter interface {
+	// Add 添加键
+	Add(ctx context.Context, keys ...string) error
+
+	// MightContain 判断键是否可能存在，返回false时键一定没有被添加过
+	MightContain(ctx context.Context, key string) (bool, error)
+
+	// Rebuild 用source提供的全部键重建过滤器，用于清除已删除的键或调整容量
+	// 重建期间过滤器继续使用旧数据，期间通过Add添加的键同时写入新数据，重建完成后原子地切换
+	// source通过调用add添加键，返回错误时放弃重建，旧数据保持不变
+	Rebuild(ctx context.Context, source func(add func(keys ...string) error) error) error
+}
+
+// maxBloomBits 布隆过滤器的最大位数，与Redis字符串的最大长度512MB一致
+const maxBloomBits = uint64(1) << 32
+
+// bloomParams 布隆过滤器的位数和哈希函数个数
+type bloomParams struct {
+	bits   uint64
+	hashes int
+}
+
+// newBloomParams 根据预期元素数量和误判率计算布隆过滤器参数
+func newBloomParams(expectedItems uint64, falsePositiveRate float64) bloomParams {
+	if expectedItems == 0 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	bits := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	bits = min(max(bits, 64), maxBloomBits)
+	hashes := int(math.Round(float64(bits) / n * math.Ln2))
+	hashes = max(hashes, 1)
+
+	return bloomParams{bits: bits, hashes: hashes}
+}
+
+// locations 返回键对应的位下标
+// 使用FNV-1a哈希的高低32位做双重哈希，结果与进程无关，多个实例共享Redis位图时位置一致
+func (p bloomParams) locations(key string) []uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, (sum>>32)|1
+
+	locations := make([]uint64, p.hashes)
+	for i := range locations {
+		locations[i] = (h1 + uint64(i)*h2) % p.bits
+	}
+	return locations
+}
+
+// MemoryBloomFilter 进程内的布隆过滤器
+type MemoryBloomFilter struct {
+	params bloomParams
+
+	mu   sync.RWMutex
+	bits []uint64
+	// next 重建中的新数据，不在重建时为nil
+	next []uint64
+
+	rebuildMu sync.Mutex
+}
+
+// NewMemoryBloomFilter 创建进程内的布隆过滤器
+// 参数：
+//
+//	expectedItems: 预期元素数量，实际数量超过后误判率会上升
+//	falsePositiveRate: 期望的误判率，例如0.01，无效值时使用0.01
+func NewMemoryBloomFilter(expectedItems uint64, falsePositiveRate float64) *MemoryBloomFilter {
+	params := newBloomParams(expectedItems, falsePositiveRate)
+	return &MemoryBloomFilter{
+		params: params,
+		bits:   params.newBitset(),
+	}
+}
+
+func (p bloomParams) newBitset() []uint64 {
+	return make([]uint64, (p.bits+63)/64)
+}
+
+// Add 添加键
+func (f *MemoryBloomFilter) Add(_ context.Context, keys ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		for _, loc := range f.params.locations(key) {
+			f.bits[loc/64] |= 1 << (loc % 64)
+			if f.next != nil {
+				f.next[loc/64] |= 1 << (loc % 64)
+			}
+		}
+	}
+	return nil
+}
+
+// MightContain 判断键是否可能存在
+func (f *MemoryBloomFilter) MightContain(_ context.Context, key string) (bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, loc := range f.params.locations(key) {
+		if f.bits[loc/64]&(1<<(loc%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Rebuild 用source提供的全部键重建过滤器
+func (f *MemoryBloomFilter) Rebuild(_ context.Context, source func(add func(keys ...string) error) error) error {
+	f.rebuildMu.Lock()
+	defer f.rebuildMu.Unlock()
+
+	f.mu.Lock()
+	f.next = f.params.newBitset()
+	f.mu.Unlock()
+
+	err := source(func(keys ...string) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, key := range keys {
+			for _, loc := range f.params.locations(key) {
+				f.next[loc/64] |= 1 << (loc % 64)
+			}
+		}
+		return nil
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		f.bits = f.next
+	}
+	f.next = nil
+	return err
+}
+
+// RedisBloomFilter 以Redis位图存储的布隆过滤器，可在多个实例之间共享
+// 所有共享同一个位图的实例必须使用相同的expectedItems和falsePositiveRate
+//
+// 重建时先写入"<key>:rebuilding"，完成后通过RENAME替换，
+// 在Redis集群中使用时key需要包含哈希标签（例如"{bloom:users}"），保证两个键位于同一个槽
+type RedisBloomFilter struct {
+	client redis.UniversalClient
+	key    string
+	params bloomParams
+}
+
+// NewRedisBloomFilter 创建以Redis位图存储的布隆过滤器
+// 参数：
+//
+//	client: Redis客户端
+//	key: 位图的键名
+//	expectedItems: 预期元素数量，实际数量超过后误判率会上升
+//	falsePositiveRate: 期望的误判率，例如0.01，无效值时使用0.01
+func NewRedisBloomFilter(client redis.UniversalClient, key string, expectedItems uint64, falsePositiveRate float64) *RedisBloomFilter {
+	return &RedisBloomFilter{
+		client: client,
+		key:    key,
+		params: newBloomParams(expectedItems, falsePositiveRate),
+	}
+}
+
+// rebuildKey 重建中的位图的键名
+func (f *RedisBloomFilter) rebuildKey() string {
+	return f.key + ":rebuilding"
+}
+
+// bloomAddScript 设置位图中的位，正在重建时同时写入重建中的位图
+var bloomAddScript = redis.NewScript(`
+	local rebuilding = redis.call("EXISTS", KEYS[2]) == 1
+	for i = 1, #ARGV do
+		redis.call("SETBIT", KEYS[1], ARGV[i], 1)
+		if rebuilding then
+			redis.call("SETBIT", KEYS[2], ARGV[i], 1)
+		end
+	end
+	return 1
+`)
+
+// Add 添加键
+func (f *RedisBloomFilter) Add(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(keys)*f.params.hashes)
+	for _, key := range keys {
+		for _, loc := range f.params.locations(key) {
+			args = append(args, loc)
+		}
+	}
+	if err := bloomAddScript.Run(ctx, f.client, []string{f.key, f.rebuildKey()}, args...).Err(); err != nil {
+		return fmt.Errorf("failed to add keys to bloom filter: %w", err)
+	}
+	return nil
+}
+
+// MightContain 判断键是否可能存在
+func (f *RedisBloomFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	locations := f.params.locations(key)
+	cmds := make([]*redis.IntCmd, len(locations))
+	_, err := f.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, loc := range locations {
+			cmds[i] = pipe.GetBit(ctx, f.key, int64(loc))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query bloom filter: %w", err)
+	}
+
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Rebuild 用source提供的全部键重建过滤器
+func (f *RedisBloomFilter) Rebuild(ctx context.Context, source func(add func(keys ...string) error) error) error {
+	rebuildKey := f.rebuildKey()
+
+	// 先创建重建中的位图，此后通过Add添加的键会同时写入
+	_, err := f.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, rebuildKey)
+		pipe.SetBit(ctx, rebuildKey, 0, 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start bloom filter rebuild: %w", err)
+	}
+
+	err = source(func(keys ...string) error {
+		_, err := f.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				for _, loc := range f.params.locations(key) {
+					pipe.SetBit(ctx, rebuildKey, int64(loc), 1)
+				}
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		_ = f.client.Del(context.WithoutCancel(ctx), rebuildKey).Err()
+		return err
+	}
+
+	if err := f.client.Rename(ctx, rebuildKey, f.key).Err(); err != nil {
+		return fmt.Errorf("failed to finish bloom filter rebuild: %w", err)
+	}
+	return nil
+}
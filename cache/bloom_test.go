@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomParams(t *testing.T) {
+	p := newBloomParams(1000, 0.01)
+	// m = -n*ln(p)/ln(2)^2 ≈ 9586, k = m/n*ln(2) ≈ 7
+	assert.InDelta(t, 9586, p.bits, 2)
+	assert.Equal(t, 7, p.hashes)
+
+	locations := p.locations("user:1")
+	assert.Len(t, locations, 7)
+	assert.Equal(t, locations, p.locations("user:1"), "locations must be deterministic")
+	for _, loc := range locations {
+		assert.Less(t, loc, p.bits)
+	}
+
+	// 无效参数使用默认值
+	assert.Equal(t, newBloomParams(1, 0.01), newBloomParams(0, 2))
+}
+
+func TestBloomFilter_FalsePositiveRate(t *testing.T) {
+	ctx := context.Background()
+	filters := map[string]BloomFilter{
+		"memory": NewMemoryBloomFilter(1000, 0.01),
+		"redis":  NewRedisBloomFilter(newMiniRedisCache(t).GetClient(), "bloom", 1000, 0.01),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			keys := make([]string, 1000)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("member:%d", i)
+			}
+			require.NoError(t, filter.Add(ctx, keys...))
+
+			for _, key := range keys {
+				ok, err := filter.MightContain(ctx, key)
+				require.NoError(t, err)
+				require.True(t, ok, "added key %q must never be rejected", key)
+			}
+
+			falsePositives := 0
+			for i := 0; i < 1000; i++ {
+				ok, err := filter.MightContain(ctx, fmt.Sprintf("other:%d", i))
+				require.NoError(t, err)
+				if ok {
+					falsePositives++
+				}
+			}
+			assert.Less(t, falsePositives, 30)
+		})
+	}
+}
+
+func TestBloomFilter_RebuildKeepsConcurrentAdds(t *testing.T) {
+	ctx := context.Background()
+	filters := map[string]BloomFilter{
+		"memory": NewMemoryBloomFilter(100, 0.01),
+		"redis":  NewRedisBloomFilter(newMiniRedisCache(t).GetClient(), "bloom", 100, 0.01),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, filter.Add(ctx, "old"))
+
+			err := filter.Rebuild(ctx, func(add func(keys ...string) error) error {
+				// 重建期间旧数据仍然可用，新添加的键不会丢失
+				ok, err := filter.MightContain(ctx, "old")
+				require.NoError(t, err)
+				assert.True(t, ok)
+				require.NoError(t, filter.Add(ctx, "concurrent"))
+				return add("rebuilt")
+			})
+			require.NoError(t, err)
+
+			for key, want := range map[string]bool{"old": false, "concurrent": true, "rebuilt": true} {
+				ok, err := filter.MightContain(ctx, key)
+				require.NoError(t, err)
+				assert.Equal(t, want, ok, key)
+			}
+		})
+	}
+}
+
+func TestBloomFilter_FailedRebuildKeepsData(t *testing.T) {
+	ctx := context.Background()
+	filters := map[string]BloomFilter{
+		"memory": NewMemoryBloomFilter(100, 0.01),
+		"redis":  NewRedisBloomFilter(newMiniRedisCache(t).GetClient(), "bloom", 100, 0.01),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, filter.Add(ctx, "old"))
+
+			failing := errors.New("source failed")
+			err := filter.Rebuild(ctx, func(add func(keys ...string) error) error {
+				_ = add("partial")
+				return failing
+			})
+			assert.ErrorIs(t, err, failing)
+
+			ok, err := filter.MightContain(ctx, "old")
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+}
@@ -74,6 +74,57 @@
 //	// 使用多级缓存
 //	multiCache.Set(context.Background(), "key", value, time.Minute)
 //
+// 4. 类型安全访问：
+//
+//	// 不同缓存实现对同一类型返回相同的结果，解码失败时返回错误
+//	users := cache.NewTypedCache[*User](redisCache)
+//	user, found, err := users.Get(context.Background(), "user:123")
+//
+// 5. 合并加载：
+//
+//	// 热点键过期时，并发的未命中只会触发一次加载
+//	value, err := cache.GetOrLoad(ctx, c, "user:123", time.Hour, func(ctx context.Context) (interface{}, error) {
+//	    return userRepo.FindByID(ctx, 123)
+//	})
+//
+// 6. 命名空间：
+//
+//	// 命名空间的键带有版本号，整体失效只需一次INCR，不需要SCAN整个键空间
+//	products, _ := cache.NewNamespace(redisCache, "products")
+//	products.Set(ctx, "product:1", product, time.Hour)
+//	products.Invalidate(ctx)
+//
+//	// 后台清理旧版本的键和标签索引中已失效的成员
+//	sweeper := cache.NewSweeper(redisCache)
+//	sweeper.Add(products)
+//
+// 7. 远程缓存熔断：
+//
+//	// Redis连续失败时降级为只使用本地缓存，恢复后将降级期间的写入同步到Redis
+//	multiCache, _ := cache.NewMultiLevelCache(l1, l2, cache.MultiLevelOptions{
+//	    LocalTTL:       time.Minute,
+//	    CircuitBreaker: &cache.CircuitBreakerOptions{FailureThreshold: 5, OpenTimeout: 30 * time.Second},
+//	})
+//	stats := multiCache.CircuitBreakerStats()
+//
+// 8. 原子条件写入：
+//
+//	// 只有一个实例能获得锁，替代先Exists再Set
+//	cc, _ := cache.Conditional(redisCache)
+//	acquired, _ := cc.SetNX(ctx, "job:lock", instanceID, time.Minute)
+//	// 基于版本的乐观并发控制
+//	version, _, _ := cc.GetVersioned(ctx, "config", &config)
+//	swapped, _ := cc.CompareAndSwap(ctx, "config", version, newConfig, 0)
+//
+// 9. 故障注入：
+//
+//	// 集成测试中为远程缓存注入延迟、错误和分区，验证降级路径
+//	faulty := cache.NewFaultCache(redisCache, cache.FaultOptions{Seed: 1, Rules: []cache.FaultRule{
+//	    {Ops: []cache.Operation{cache.OpGetInto}, Kind: cache.FaultLatency, Latency: 50 * time.Millisecond, Rate: 0.1},
+//	}})
+//	faulty.Partition("user:*")
+//	faulty.Heal()
+//
 // # 性能考虑
 //
 // - MemoryCache适合高频读取操作，但会占用应用内存
@@ -194,11 +245,9 @@ type Cache interface {
 	// DeleteByPattern 通过模式匹配删除缓存
 	// 参数：
 	//   ctx: 上下文，可用于传递超时和取消信号
-	//   pattern: 匹配模式，格式取决于具体实现（如Redis使用glob风格模式）
+	//   pattern: 与Redis相同的glob模式，必须匹配整个键，所有实现的语义一致，见MatchPattern
 	// 返回：
 	//   error: 删除过程中遇到的错误，如果删除成功则为nil
-	// 注意：
-	//   某些实现可能不支持此功能，会返回不支持的错误
 	// 性能：
 	//   - 此操作可能会扫描大量键，在大型缓存中可能很慢
 	//   - 建议在非关键路径中使用
@@ -303,6 +352,40 @@ type Options struct {
 	// 如果为0则使用实现特定的默认值
 	// 较小的间隔会更及时清理过期项，但会增加CPU开销
 	CleanupInterval time.Duration
+
+	// MaxEntries 最大缓存项数量，0表示不限制
+	// 主要用于内存缓存实现，超出后按EvictionPolicy淘汰
+	MaxEntries int
+
+	// MaxCost 最大总成本，0表示不限制
+	// 成本由CostFunc计算，通常为字节数，超出后按EvictionPolicy淘汰
+	MaxCost int64
+
+	// CostFunc 计算缓存项成本的函数
+	// 为nil时使用EstimateCost按字节数估算，仅在MaxCost大于0时使用
+	CostFunc CostFunc
+
+	// EvictionPolicy 容量淘汰策略，默认为LRU
+	EvictionPolicy EvictionPolicy
+
+	// OnEvict 缓存项因容量或过期被淘汰时的回调，主动删除不会触发
+	OnEvict EvictionCallback
+
+	// Snapshot 快照选项，仅用于MemoryCache，ShardedMemoryCache会忽略此选项
+	// 设置Path后，创建时从快照文件恢复数据，关闭时和每隔Interval将数据写入快照文件
+	Snapshot SnapshotOptions
+}
+
+// Stats 缓存统计信息
+type Stats struct {
+	// Entries 当前缓存项数量（可能包含尚未清理的过期项）
+	Entries int64
+	// Cost 当前总成本，仅在设置了MaxCost时统计
+	Cost int64
+	// Evictions 因超出容量被淘汰的缓存项数量
+	Evictions uint64
+	// Expirations 过期后被清理的缓存项数量
+	Expirations uint64
 }
 
 // DefaultOptions 返回默认缓存选项
@@ -228,7 +228,7 @@ func testCacheImplementation(t *testing.T, c Cache) {
 		}
 
 		// 按模式删除
-		err = c.DeleteByPattern(ctx, "tag2.*")
+		err = c.DeleteByPattern(ctx, "tag2*")
 		if err != nil {
 			t.Fatalf("DeleteByPattern returned error: %v", err)
 		}
@@ -237,7 +237,7 @@ func testCacheImplementation(t *testing.T, c Cache) {
 		for key := range tagItems {
 			_, found := c.Get(ctx, key)
 			if found {
-				t.Fatalf("Get returned found for key %s after DeleteByPattern(tag2.*)", key)
+				t.Fatalf("Get returned found for key %s after DeleteByPattern(tag2*)", key)
 			}
 		}
 	})
@@ -382,7 +382,7 @@ func TestMultiLevelCache(t *testing.T) {
 		}
 
 		// 按模式删除
-		err := multiCache.DeleteByPattern(ctx, ".*pattern.*")
+		err := multiCache.DeleteByPattern(ctx, "*pattern*")
 		if err != nil {
 			t.Fatalf("multiCache.DeleteByPattern returned error: %v", err)
 		}
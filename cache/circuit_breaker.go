@@ -0,0 +1,507 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int32
+
+const (
+	// CircuitClosed 关闭状态：远程缓存正常，所有请求都会发往远程缓存
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 打开状态：远程缓存不可用，请求不再发往远程缓存，多级缓存降级为只使用本地缓存
+	CircuitOpen
+	// CircuitHalfOpen 半开状态：打开超过OpenTimeout后放行少量探测请求，成功后关闭，失败后重新打开
+	CircuitHalfOpen
+)
+
+// String 返回状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerOptions 多级缓存远程层熔断器选项，零值字段使用默认值
+//
+// 远程缓存连续失败达到FailureThreshold次后熔断器打开，打开期间：
+//   - 读取只查询本地缓存，不再等待远程缓存超时
+//   - 写入和删除只作用于本地缓存并记录下来，本地写入使用DegradedTTL
+//   - Increment和Decrement返回ErrCircuitOpen
+//
+// 远程缓存恢复后，后台协程按原有顺序将记录的写入、删除和清空操作同步到远程缓存，
+// 同步完成之前的写入继续记录，保证远程缓存不会被旧值覆盖
+type CircuitBreakerOptions struct {
+	// FailureThreshold 触发熔断的连续失败次数，默认5
+	FailureThreshold int
+
+	// OpenTimeout 打开后进入半开状态前等待的时间，也是后台同步的重试间隔，默认30秒
+	OpenTimeout time.Duration
+
+	// HalfOpenMaxRequests 半开状态下同时放行的最大探测请求数，默认1
+	HalfOpenMaxRequests int
+
+	// SuccessThreshold 半开状态下关闭熔断器所需的连续成功次数，默认1
+	SuccessThreshold int
+
+	// OperationTimeout 单次远程操作的超时时间，0表示只使用调用方的context
+	// 超时计为一次失败，避免远程缓存无响应时每次读取都等待完整的连接超时
+	OperationTimeout time.Duration
+
+	// DegradedTTL 降级期间写入本地缓存的最长生存时间，默认与LocalTTL相同
+	DegradedTTL time.Duration
+
+	// MaxPendingKeys 降级期间记录的待同步写入的最大数量，默认10000
+	// 超出后新键的写入只保存在本地缓存，同步时改为删除远程缓存中的旧值，计入ReconcileDropped；
+	// 删除不受此限制，总是被记录
+	MaxPendingKeys int
+
+	// OnStateChange 熔断器状态变化时的回调，在触发状态变化的缓存操作所在协程中执行，回调中不应再调用该缓存
+	OnStateChange func(from, to CircuitState)
+}
+
+// DefaultCircuitBreakerOptions 返回默认的熔断器选项
+func DefaultCircuitBreakerOptions() CircuitBreakerOptions {
+	return CircuitBreakerOptions{
+		FailureThreshold:    5,
+		OpenTimeout:         time.Second * 30,
+		HalfOpenMaxRequests: 1,
+		SuccessThreshold:    1,
+		MaxPendingKeys:      10000,
+	}
+}
+
+// CircuitBreakerStats 熔断器统计信息
+type CircuitBreakerStats struct {
+	// State 当前状态
+	State CircuitState
+	// ConsecutiveFailures 关闭状态下的连续失败次数
+	ConsecutiveFailures int
+	// Trips 熔断器打开的次数
+	Trips uint64
+	// Rejected 熔断器打开期间未发往远程缓存的请求数
+	Rejected uint64
+	// LastError 最近一次远程操作失败的错误
+	LastError error
+	// LastStateChange 最近一次状态变化的时间
+	LastStateChange time.Time
+	// PendingKeys 等待同步到远程缓存的键和操作数
+	PendingKeys int
+	// Reconciled 已同步到远程缓存的键和操作数
+	Reconciled uint64
+	// ReconcileDropped 超出MaxPendingKeys后改为同步删除的写入数
+	ReconcileDropped uint64
+}
+
+// circuitBreaker 熔断器
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+	// onStateChange 状态变化后的回调，在释放锁之后调用
+	onStateChange func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	changedAt time.Time
+	lastErr   error
+
+	trips    atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// newCircuitBreaker 创建熔断器，零值选项使用默认值
+func newCircuitBreaker(opts CircuitBreakerOptions, onStateChange func(from, to CircuitState)) *circuitBreaker {
+	defaults := DefaultCircuitBreakerOptions()
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaults.FailureThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = defaults.OpenTimeout
+	}
+	if opts.HalfOpenMaxRequests <= 0 {
+		opts.HalfOpenMaxRequests = defaults.HalfOpenMaxRequests
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = defaults.SuccessThreshold
+	}
+	if opts.MaxPendingKeys <= 0 {
+		opts.MaxPendingKeys = defaults.MaxPendingKeys
+	}
+	return &circuitBreaker{options: opts, onStateChange: onStateChange, changedAt: time.Now()}
+}
+
+// allow 判断是否放行一次远程操作
+// probe表示该操作是半开状态下的探测请求，完成后必须调用done
+func (b *circuitBreaker) allow() (probe bool, ok bool) {
+	b.mu.Lock()
+	from, changed := b.state, false
+
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.options.OpenTimeout {
+			b.mu.Unlock()
+			b.rejected.Add(1)
+			return false, false
+		}
+		b.setState(CircuitHalfOpen)
+		changed = true
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.options.HalfOpenMaxRequests {
+			b.mu.Unlock()
+			b.rejected.Add(1)
+			b.notify(from, CircuitHalfOpen, changed)
+			return false, false
+		}
+		b.probes++
+		probe = true
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to, changed)
+	return probe, true
+}
+
+// done 记录一次已放行的远程操作的结果，err为nil表示成功
+func (b *circuitBreaker) done(probe bool, err error) {
+	b.mu.Lock()
+	from := b.state
+	if probe && b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	if err != nil {
+		b.lastErr = err
+		switch b.state {
+		case CircuitClosed:
+			b.failures++
+			if b.failures >= b.options.FailureThreshold {
+				b.setState(CircuitOpen)
+			}
+		case CircuitHalfOpen:
+			if probe {
+				b.setState(CircuitOpen)
+			}
+		}
+	} else {
+		switch b.state {
+		case CircuitClosed:
+			b.failures = 0
+		case CircuitHalfOpen:
+			if probe {
+				b.successes++
+				if b.successes >= b.options.SuccessThreshold {
+					b.setState(CircuitClosed)
+				}
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to, from != to)
+}
+
+// release 归还未得到结果的探测请求名额
+func (b *circuitBreaker) release(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe && b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// setState 切换状态并重置计数，调用方需持有mu
+func (b *circuitBreaker) setState(state CircuitState) {
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	b.changedAt = time.Now()
+	if state == CircuitOpen {
+		b.openedAt = b.changedAt
+		b.trips.Add(1)
+	}
+}
+
+// notify 在状态发生变化时调用回调
+func (b *circuitBreaker) notify(from, to CircuitState, changed bool) {
+	if !changed {
+		return
+	}
+	if b.onStateChange != nil {
+		b.onStateChange(from, to)
+	}
+	if b.options.OnStateChange != nil {
+		b.options.OnStateChange(from, to)
+	}
+}
+
+// currentState 返回当前状态
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// stats 返回熔断器部分的统计信息
+func (b *circuitBreaker) stats() CircuitBreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return CircuitBreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips.Load(),
+		Rejected:            b.rejected.Load(),
+		LastError:           b.lastErr,
+		LastStateChange:     b.changedAt,
+	}
+}
+
+// guardedCache 经过熔断器访问的远程缓存
+// 熔断器打开时直接返回ErrCircuitOpen（读取返回未命中），不访问远程缓存
+type guardedCache struct {
+	inner   Cache
+	breaker *circuitBreaker
+}
+
+// do 经过熔断器执行一次远程操作
+func (g *guardedCache) do(ctx context.Context, fn func(ctx context.Context) error) error {
+	probe, ok := g.breaker.allow()
+	if !ok {
+		return ErrCircuitOpen
+	}
+
+	opCtx := ctx
+	if timeout := g.breaker.options.OperationTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		opCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := fn(opCtx)
+	if ctx.Err() != nil {
+		// 调用方取消时无法判断远程缓存是否可用
+		g.breaker.release(probe)
+		return err
+	}
+	g.breaker.done(probe, remoteFailure(opCtx, err))
+	return err
+}
+
+// isCircuitOpen 判断错误是否表示操作被熔断器拒绝
+// 熔断器总是直接返回ErrCircuitOpen，按值比较可以避免错误码相同的其他缓存错误被errors.Is匹配
+func isCircuitOpen(err error) bool {
+	return err == ErrCircuitOpen
+}
+
+// remoteFailure 判断错误是否表示远程缓存不可用
+// 解码失败、类型错误等与远程缓存可用性无关的错误不计为失败；
+// 远程缓存的读取方法不返回错误，单次操作超时时同样计为失败
+func remoteFailure(opCtx context.Context, err error) error {
+	if err == nil {
+		return opCtx.Err()
+	}
+	if errors.Is(err, ErrDecode) || errors.Is(err, ErrWrongType) ||
+		errors.Is(err, ErrNotSupported) || errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (g *guardedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	var value interface{}
+	var found bool
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		found, err = GetInto(ctx, g.inner, key, &value)
+		return err
+	})
+	if err != nil || !found {
+		return nil, false
+	}
+	return value, true
+}
+
+func (g *guardedCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	var value interface{}
+	var ttl time.Duration
+	var found bool
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		ttl, found, err = GetIntoWithTTL(ctx, g.inner, key, &value)
+		return err
+	})
+	if err != nil || !found {
+		return nil, 0, false
+	}
+	return value, ttl, true
+}
+
+// GetInto 实现ValueDecoder接口
+func (g *guardedCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	var found bool
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		found, err = GetInto(ctx, g.inner, key, target)
+		return err
+	})
+	return found, err
+}
+
+// GetIntoWithTTL 实现ValueDecoder接口
+func (g *guardedCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	var ttl time.Duration
+	var found bool
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		ttl, found, err = GetIntoWithTTL(ctx, g.inner, key, target)
+		return err
+	})
+	return ttl, found, err
+}
+
+// GetMany 实现BatchCache接口
+func (g *guardedCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		values, err = GetMany(ctx, g.inner, keys)
+		return err
+	})
+	return values, err
+}
+
+func (g *guardedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.Set(ctx, key, value, ttl)
+	})
+}
+
+func (g *guardedCache) SetItem(ctx context.Context, key string, item *Item) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.SetItem(ctx, key, item)
+	})
+}
+
+// SetMany 实现BatchCache接口
+func (g *guardedCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return SetMany(ctx, g.inner, items)
+	})
+}
+
+func (g *guardedCache) Delete(ctx context.Context, key string) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.Delete(ctx, key)
+	})
+}
+
+// DeleteMany 实现BatchCache接口
+func (g *guardedCache) DeleteMany(ctx context.Context, keys []string) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return DeleteMany(ctx, g.inner, keys)
+	})
+}
+
+func (g *guardedCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.DeleteByPattern(ctx, pattern)
+	})
+}
+
+func (g *guardedCache) DeleteByTag(ctx context.Context, tag string) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.DeleteByTag(ctx, tag)
+	})
+}
+
+// Scan 实现KeyScanner接口，遍历不受OperationTimeout限制
+func (g *guardedCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		probe, ok := g.breaker.allow()
+		if !ok {
+			yield("", ErrCircuitOpen)
+			return
+		}
+
+		var failure error
+		defer func() {
+			if ctx.Err() != nil {
+				g.breaker.release(probe)
+				return
+			}
+			g.breaker.done(probe, failure)
+		}()
+		for key, err := range scanCache(ctx, g.inner, pattern) {
+			if err != nil {
+				failure = remoteFailure(ctx, err)
+			}
+			if !yield(key, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (g *guardedCache) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		exists, err = g.inner.Exists(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+func (g *guardedCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	var result int64
+	err := g.do(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = g.inner.Increment(ctx, key, value)
+		return err
+	})
+	return result, err
+}
+
+func (g *guardedCache) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	return g.Increment(ctx, key, -value)
+}
+
+func (g *guardedCache) Flush(ctx context.Context) error {
+	return g.do(ctx, func(ctx context.Context) error {
+		return g.inner.Flush(ctx)
+	})
+}
+
+// Close 关闭远程缓存，不经过熔断器
+func (g *guardedCache) Close() error {
+	return g.inner.Close()
+}
+
+// CircuitBreakerStatsOf 在c及其Unwrap返回的底层缓存中查找启用了熔断器的MultiLevelCache，返回其熔断器统计信息
+// 未找到时返回false
+func CircuitBreakerStatsOf(c Cache) (CircuitBreakerStats, bool) {
+	multi, ok := findCache[*MultiLevelCache](c)
+	if !ok || multi.breaker == nil {
+		return CircuitBreakerStats{}, false
+	}
+	return multi.CircuitBreakerStats(), true
+}
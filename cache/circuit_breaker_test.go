@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchableRemoteCache 可以切换为不可用的远程缓存
+type switchableRemoteCache struct {
+	*MemoryCache
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (c *switchableRemoteCache) check() error {
+	c.calls.Add(1)
+	if c.down.Load() {
+		return errRemoteDown
+	}
+	return nil
+}
+
+func (c *switchableRemoteCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	if err := c.check(); err != nil {
+		return false, err
+	}
+	return c.MemoryCache.GetInto(ctx, key, target)
+}
+
+func (c *switchableRemoteCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.MemoryCache.SetMany(ctx, items)
+}
+
+func (c *switchableRemoteCache) Delete(ctx context.Context, key string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.MemoryCache.Delete(ctx, key)
+}
+
+func (c *switchableRemoteCache) DeleteMany(ctx context.Context, keys []string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.MemoryCache.DeleteMany(ctx, keys)
+}
+
+func (c *switchableRemoteCache) DeleteByTag(ctx context.Context, tag string) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	return c.MemoryCache.DeleteByTag(ctx, tag)
+}
+
+func newBreakerCache(t *testing.T, remote Cache, opts CircuitBreakerOptions) *MultiLevelCache {
+	multi, err := NewMultiLevelCache(NewMemoryCache(), remote, MultiLevelOptions{
+		WriteMode:      WriteModeWriteThrough,
+		LocalTTL:       time.Minute,
+		CircuitBreaker: &opts,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = multi.Close() })
+	return multi
+}
+
+func TestCircuitBreaker_TripsAndShortCircuits(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	var transitions []CircuitState
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Hour,
+		OnStateChange:    func(from, to CircuitState) { transitions = append(transitions, to) },
+	})
+	ctx := context.Background()
+
+	remote.down.Store(true)
+	for i := 0; i < 3; i++ {
+		_, found := c.Get(ctx, "missing")
+		assert.False(t, found)
+	}
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+	assert.Equal(t, []CircuitState{CircuitOpen}, transitions)
+
+	// 打开期间不再访问远程缓存
+	calls := remote.calls.Load()
+	_, found := c.Get(ctx, "missing")
+	assert.False(t, found)
+	assert.Equal(t, calls, remote.calls.Load())
+
+	stats := c.CircuitBreakerStats()
+	assert.Equal(t, uint64(1), stats.Trips)
+	assert.Equal(t, uint64(1), stats.Rejected)
+	assert.ErrorIs(t, stats.LastError, errRemoteDown)
+}
+
+func TestCircuitBreaker_LocalFallbackWhileOpen(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Hour,
+		DegradedTTL:      time.Second * 10,
+	})
+	ctx := context.Background()
+
+	remote.down.Store(true)
+	assert.ErrorIs(t, c.Set(ctx, "trip", 1, time.Minute), errRemoteDown)
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	require.NoError(t, c.Set(ctx, "key", "value", time.Hour))
+	value, ttl, found := c.GetWithTTL(ctx, "key")
+	require.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.LessOrEqual(t, ttl, time.Second*10, "degraded writes use DegradedTTL locally")
+
+	require.NoError(t, c.Delete(ctx, "key"))
+	require.NoError(t, c.DeleteByTag(ctx, "tag"))
+
+	_, err := c.Increment(ctx, "counter", 1)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	assert.Equal(t, 2, c.CircuitBreakerStats().PendingKeys, "one key and one tag operation are pending")
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	remote.down.Store(true)
+	_, _ = c.Get(ctx, "key")
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	// 半开状态下探测失败重新打开
+	time.Sleep(30 * time.Millisecond)
+	_, _ = c.Get(ctx, "key")
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+	assert.Equal(t, uint64(2), c.CircuitBreakerStats().Trips)
+
+	// 探测成功后关闭
+	remote.down.Store(false)
+	require.NoError(t, remote.MemoryCache.Set(ctx, "key", "value", time.Minute))
+	time.Sleep(30 * time.Millisecond)
+	value, found := c.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+}
+
+func TestCircuitBreaker_ReconcilesAfterRecovery(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, remote.MemoryCache.SetItem(ctx, "stale:1", &Item{Value: "old", Expiration: time.Minute, Tags: []string{"stale"}}))
+	require.NoError(t, remote.MemoryCache.Set(ctx, "removed", "old", time.Minute))
+
+	remote.down.Store(true)
+	_ = c.Set(ctx, "trip", 0, time.Minute)
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	require.NoError(t, c.Set(ctx, "written", "new", time.Minute))
+	require.NoError(t, c.Delete(ctx, "removed"))
+	require.NoError(t, c.DeleteByTag(ctx, "stale"))
+
+	remote.down.Store(false)
+	assert.Eventually(t, func() bool {
+		return c.CircuitBreakerStats().PendingKeys == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+
+	value, found := remote.MemoryCache.Get(ctx, "written")
+	assert.True(t, found)
+	assert.Equal(t, "new", value)
+	_, found = remote.MemoryCache.Get(ctx, "removed")
+	assert.False(t, found)
+	_, found = remote.MemoryCache.Get(ctx, "stale:1")
+	assert.False(t, found)
+	assert.Equal(t, uint64(3), c.CircuitBreakerStats().Reconciled)
+}
+
+func TestCircuitBreaker_PendingLimitKeepsDeletes(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Hour,
+		MaxPendingKeys:   1,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, remote.MemoryCache.Set(ctx, "deleted", "old", time.Minute))
+	require.NoError(t, remote.MemoryCache.Set(ctx, "overflow", "old", time.Minute))
+
+	remote.down.Store(true)
+	_ = c.Set(ctx, "trip", 0, time.Minute)
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	// 限额占满后删除仍然记录，超出限额的写入改为删除远程旧值
+	require.NoError(t, c.Set(ctx, "filled", 1, time.Minute))
+	require.NoError(t, c.Delete(ctx, "deleted"))
+	require.NoError(t, c.Set(ctx, "overflow", "new", time.Minute))
+	assert.Equal(t, 3, c.CircuitBreakerStats().PendingKeys)
+	assert.Equal(t, uint64(1), c.CircuitBreakerStats().ReconcileDropped)
+
+	remote.down.Store(false)
+	c.breaker.mu.Lock()
+	c.breaker.setState(CircuitClosed)
+	c.breaker.mu.Unlock()
+	require.NoError(t, c.Reconcile(ctx))
+	_, found := remote.MemoryCache.Get(ctx, "deleted")
+	assert.False(t, found, "deleted data must not come back")
+	_, found = remote.MemoryCache.Get(ctx, "overflow")
+	assert.False(t, found, "stale remote value must be removed")
+	value, found := c.Get(ctx, "overflow")
+	assert.True(t, found)
+	assert.Equal(t, "new", value)
+}
+
+func TestCircuitBreaker_WritesDuringReconcileAreNotOverwritten(t *testing.T) {
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Hour,
+	})
+	ctx := context.Background()
+
+	remote.down.Store(true)
+	_ = c.Set(ctx, "trip", 0, time.Minute)
+	require.NoError(t, c.Set(ctx, "key", "degraded", time.Minute))
+
+	// 熔断器关闭但尚未同步时的写入同样先记录，按顺序同步
+	remote.down.Store(false)
+	c.breaker.mu.Lock()
+	c.breaker.setState(CircuitClosed)
+	c.breaker.mu.Unlock()
+	require.NoError(t, c.Set(ctx, "key", "latest", time.Minute))
+
+	require.NoError(t, c.Reconcile(ctx))
+	value, found := remote.MemoryCache.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "latest", value)
+
+	// 同步完成后直接写入远程缓存
+	require.NoError(t, c.Set(ctx, "direct", 1, time.Minute))
+	_, found = remote.MemoryCache.Get(ctx, "direct")
+	assert.True(t, found)
+}
+
+func TestCircuitBreakerStatsOf(t *testing.T) {
+	plain, err := NewMultiLevelCache(NewMemoryCache(), NewMemoryCache())
+	require.NoError(t, err)
+	defer plain.Close()
+	_, ok := CircuitBreakerStatsOf(plain)
+	assert.False(t, ok)
+
+	guarded := newBreakerCache(t, NewMemoryCache(), CircuitBreakerOptions{})
+	stats, ok := CircuitBreakerStatsOf(NewInstrumentedCache(guarded))
+	assert.True(t, ok)
+	assert.Equal(t, CircuitClosed, stats.State)
+}
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CompressionAlgorithm 压缩算法
+type CompressionAlgorithm byte
+
+// 压缩算法，取值同时作为数据头部的标识字节
+// 标识字节在0xC0-0xCF之间，不会出现在JSON或Gob数据的第一个字节，
+// 因此启用压缩之前写入的无头部数据仍然可以读取
+const (
+	// CompressionNone 未压缩，仅在未压缩数据的第一个字节与标识字节冲突时使用
+	CompressionNone CompressionAlgorithm = 0xC0
+	// CompressionGzip gzip压缩
+	CompressionGzip CompressionAlgorithm = 0xC1
+	// CompressionDeflate deflate压缩，比gzip少了头部和校验和，更适合小数据
+	CompressionDeflate CompressionAlgorithm = 0xC2
+)
+
+// String 返回压缩算法名称
+func (a CompressionAlgorithm) String() string {
+	switch a {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionDeflate:
+		return "deflate"
+	default:
+		return fmt.Sprintf("unknown(%#x)", byte(a))
+	}
+}
+
+// CompressionOptions 压缩序列化器选项
+type CompressionOptions struct {
+	// Algorithm 压缩算法，默认CompressionGzip
+	Algorithm CompressionAlgorithm
+
+	// Threshold 压缩阈值（字节），序列化后小于此值的数据不压缩，默认1024
+	Threshold int
+
+	// Level 压缩级别，取值与compress/flate相同，0表示使用flate.DefaultCompression
+	Level int
+}
+
+// DefaultCompressionOptions 返回默认的压缩序列化器选项
+func DefaultCompressionOptions() CompressionOptions {
+	return CompressionOptions{
+		Algorithm: CompressionGzip,
+		Threshold: 1024,
+		Level:     flate.DefaultCompression,
+	}
+}
+
+// CompressingSerializer 压缩序列化器
+// 包装另一个序列化器，序列化结果达到阈值时压缩，并在数据前加一个字节的头部标识压缩算法。
+// 未压缩的数据原样保存，不加头部，因此小于阈值的数值仍然可以被Redis的INCRBY等命令处理。
+// 反序列化时根据头部选择解压方式，任意算法写入的数据都可以被读取，
+// 没有头部的数据按未压缩处理，因此可以在已有数据的Redis上直接启用
+//
+// 示例：
+//
+//	serializer := cache.NewCompressingSerializer(&cache.JSONSerializer{}, cache.CompressionOptions{
+//	    Algorithm: cache.CompressionGzip,
+//	    Threshold: 512,
+//	})
+//	c, err := cache.NewBuilder().WithType(cache.CacheTypeRedis).WithOptions(redisOpts).WithSerializer(serializer).Build()
+type CompressingSerializer struct {
+	inner   Serializer
+	options CompressionOptions
+	writers sync.Pool
+}
+
+// NewCompressingSerializer 创建压缩序列化器
+// 参数：
+//
+//	inner: 被包装的序列化器，为nil时使用DefaultSerializer
+//	opts: 压缩选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewCompressingSerializer(inner Serializer, opts ...CompressionOptions) *CompressingSerializer {
+	if inner == nil {
+		inner = DefaultSerializer()
+	}
+
+	defaults := DefaultCompressionOptions()
+	options := defaults
+	if len(opts) > 0 {
+		options = opts[0]
+		if options.Algorithm != CompressionGzip && options.Algorithm != CompressionDeflate {
+			options.Algorithm = defaults.Algorithm
+		}
+		if options.Threshold <= 0 {
+			options.Threshold = defaults.Threshold
+		}
+		if options.Level == 0 || options.Level < flate.HuffmanOnly || options.Level > flate.BestCompression {
+			options.Level = defaults.Level
+		}
+	}
+
+	return &CompressingSerializer{inner: inner, options: options}
+}
+
+// Marshal 序列化并在达到阈值时压缩
+func (s *CompressingSerializer) Marshal(v interface{}) ([]byte, error) {
+	data, err := s.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < s.options.Threshold {
+		return uncompressed(data), nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data)/2 + 1)
+	buf.WriteByte(byte(s.options.Algorithm))
+
+	w, err := s.writer(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("compression error: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("compression error: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compression error: %w", err)
+	}
+	s.writers.Put(w)
+
+	// 压缩没有减小体积时保存原始数据，节省读取时的解压开销
+	if buf.Len() >= len(data)+1 {
+		return uncompressed(data), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// uncompressed 返回未压缩数据的存储形式
+// 数据原样保存，只有第一个字节落在标识字节范围内时（例如msgpack的nil和布尔值）才加CompressionNone头部，
+// 避免被误认为压缩数据
+func uncompressed(data []byte) []byte {
+	if len(data) > 0 && data[0]&0xF0 == 0xC0 {
+		return append([]byte{byte(CompressionNone)}, data...)
+	}
+	return data
+}
+
+// Unmarshal 根据头部解压并反序列化
+func (s *CompressingSerializer) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return s.inner.Unmarshal(data, v)
+	}
+
+	var r io.ReadCloser
+	switch CompressionAlgorithm(data[0]) {
+	case CompressionNone:
+		return s.inner.Unmarshal(data[1:], v)
+	case CompressionGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return fmt.Errorf("decompression error: %w", err)
+		}
+		r = gr
+	case CompressionDeflate:
+		r = flate.NewReader(bytes.NewReader(data[1:]))
+	default:
+		// 启用压缩之前写入的数据没有头部
+		return s.inner.Unmarshal(data, v)
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("decompression error: %w", err)
+	}
+	return s.inner.Unmarshal(decompressed, v)
+}
+
+// compressWriter gzip.Writer和flate.Writer的公共接口
+type compressWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// writer 从池中获取或创建压缩写入器
+func (s *CompressingSerializer) writer(dst io.Writer) (compressWriter, error) {
+	if w, ok := s.writers.Get().(compressWriter); ok {
+		w.Reset(dst)
+		return w, nil
+	}
+
+	if s.options.Algorithm == CompressionDeflate {
+		return flate.NewWriter(dst, s.options.Level)
+	}
+	return gzip.NewWriterLevel(dst, s.options.Level)
+}
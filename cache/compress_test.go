@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serializerPayload 用于压缩和加密测试的较大数据
+type serializerPayload struct {
+	ID          int64             `json:"id"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Attributes  map[string]string `json:"attributes"`
+}
+
+func newSerializerPayload(size int) serializerPayload {
+	const text = "cached payload with repetitive content "
+	return serializerPayload{
+		ID:          42,
+		Description: strings.Repeat(text, size/len(text)+1)[:size],
+		Tags:        []string{"alpha", "beta", "gamma"},
+		Attributes:  map[string]string{"region": "cn-north", "tier": "gold"},
+	}
+}
+
+func TestCompressingSerializer_RoundTrip(t *testing.T) {
+	for _, algorithm := range []CompressionAlgorithm{CompressionGzip, CompressionDeflate} {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			s := NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Algorithm: algorithm, Threshold: 256})
+
+			small := newSerializerPayload(10)
+			data, err := s.Marshal(small)
+			require.NoError(t, err)
+			raw, _ := (&JSONSerializer{}).Marshal(small)
+			assert.Equal(t, raw, data, "payloads below the threshold are stored as is")
+
+			large := newSerializerPayload(4096)
+			data, err = s.Marshal(large)
+			require.NoError(t, err)
+			assert.Equal(t, byte(algorithm), data[0])
+			raw, _ = (&JSONSerializer{}).Marshal(large)
+			assert.Less(t, len(data), len(raw)/4)
+
+			var decoded serializerPayload
+			require.NoError(t, s.Unmarshal(data, &decoded))
+			assert.Equal(t, large, decoded)
+		})
+	}
+}
+
+func TestCompressingSerializer_ReadsMixedData(t *testing.T) {
+	gzipped := NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Algorithm: CompressionGzip, Threshold: 1})
+	deflated := NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Algorithm: CompressionDeflate, Threshold: 1})
+	payload := newSerializerPayload(2048)
+
+	plain, err := (&JSONSerializer{}).Marshal(payload)
+	require.NoError(t, err)
+	fromDeflate, err := deflated.Marshal(payload)
+	require.NoError(t, err)
+
+	// 启用压缩之前写入的数据和其他算法写入的数据都可以读取
+	for name, data := range map[string][]byte{"plain": plain, "deflate": fromDeflate} {
+		var decoded serializerPayload
+		require.NoError(t, gzipped.Unmarshal(data, &decoded), name)
+		assert.Equal(t, payload, decoded, name)
+	}
+
+	gob := NewCompressingSerializer(&GobSerializer{}, CompressionOptions{Threshold: 1})
+	plainGob, err := (&GobSerializer{}).Marshal(payload)
+	require.NoError(t, err)
+	var decoded serializerPayload
+	require.NoError(t, gob.Unmarshal(plainGob, &decoded))
+	assert.Equal(t, payload, decoded)
+}
+
+func TestCompressingSerializer_IncompressibleData(t *testing.T) {
+	s := NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Threshold: 1})
+
+	data, err := s.Marshal("x")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`"x"`), data, "compression that does not save space is skipped")
+
+	// 第一个字节与标识字节冲突的数据加上CompressionNone头部
+	conflicting := NewCompressingSerializer(rawSerializer{}, CompressionOptions{Threshold: 1024})
+	data, err = conflicting.Marshal([]byte{byte(CompressionGzip), 'x'})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{byte(CompressionNone), byte(CompressionGzip), 'x'}, data)
+	var decoded []byte
+	require.NoError(t, conflicting.Unmarshal(data, &decoded))
+	assert.Equal(t, []byte{byte(CompressionGzip), 'x'}, decoded)
+}
+
+// rawSerializer 原样保存字节切片的序列化器
+type rawSerializer struct{}
+
+func (rawSerializer) Marshal(v interface{}) ([]byte, error) { return v.([]byte), nil }
+
+func (rawSerializer) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func TestCompressingSerializer_CorruptData(t *testing.T) {
+	s := NewCompressingSerializer(&JSONSerializer{})
+
+	var v interface{}
+	assert.Error(t, s.Unmarshal([]byte{byte(CompressionGzip), 1, 2, 3}, &v))
+}
+
+func TestCompressingSerializer_WithRedisCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	opts := DefaultRedisOptions()
+	opts.Addr = mr.Addr()
+
+	serializer := NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Threshold: 128})
+	c, err := NewBuilder().WithType(CacheTypeRedis).WithOptions(opts).WithSerializer(serializer).Build()
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	payload := newSerializerPayload(4096)
+	require.NoError(t, c.Set(ctx, "payload", payload, time.Minute))
+
+	stored, err := mr.Get(opts.KeyPrefix + "payload")
+	require.NoError(t, err)
+	assert.Less(t, len(stored), 1024)
+
+	var decoded serializerPayload
+	found, err := GetInto(ctx, c, "payload", &decoded)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, payload, decoded)
+
+	// 小于阈值的数值不加头部，Increment可以直接处理
+	require.NoError(t, c.Set(ctx, "counter", 5, time.Minute))
+	value, err := c.Increment(ctx, "counter", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), value)
+	var counter int64
+	found, err = GetInto(ctx, c, "counter", &counter)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(7), counter)
+}
+
+// benchmarkSerializer 测量序列化和反序列化的耗时，并报告序列化后的大小
+func benchmarkSerializer(b *testing.B, s Serializer, size int) {
+	payload := newSerializerPayload(size)
+	data, err := s.Marshal(payload)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := s.Marshal(payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var decoded serializerPayload
+		if err := s.Unmarshal(data, &decoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(data)), "bytes/value")
+}
+
+func benchmarkSerializers(b *testing.B, size int) {
+	key := bytes.Repeat([]byte{1}, 32)
+	encrypting, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": key},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(b, err)
+	compressingEncrypting, err := NewEncryptingSerializer(NewCompressingSerializer(&JSONSerializer{}), EncryptionOptions{
+		Keys:         map[string][]byte{"k1": key},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(b, err)
+
+	b.Run("json", func(b *testing.B) { benchmarkSerializer(b, &JSONSerializer{}, size) })
+	b.Run("gzip", func(b *testing.B) {
+		benchmarkSerializer(b, NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Algorithm: CompressionGzip}), size)
+	})
+	b.Run("deflate", func(b *testing.B) {
+		benchmarkSerializer(b, NewCompressingSerializer(&JSONSerializer{}, CompressionOptions{Algorithm: CompressionDeflate}), size)
+	})
+	b.Run("aes-gcm", func(b *testing.B) { benchmarkSerializer(b, encrypting, size) })
+	b.Run("gzip+aes-gcm", func(b *testing.B) { benchmarkSerializer(b, compressingEncrypting, size) })
+}
+
+func BenchmarkSerializers_1KB(b *testing.B) {
+	benchmarkSerializers(b, 1024)
+}
+
+func BenchmarkSerializers_64KB(b *testing.B) {
+	benchmarkSerializers(b, 64*1024)
+}
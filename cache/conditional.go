@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Version 缓存值的版本，由GetVersioned返回，作为CompareAndSwap和CompareAndDelete的比较依据
+// 版本是不透明的标记，只能与同一缓存实现返回的版本比较，空版本表示键不存在。
+// 键的任何写入和删除都会改变版本，值被改为其他值后又改回原值时版本也不同
+type Version string
+
+// ConditionalCache 支持原子条件写入的缓存
+// RedisCache、MemoryCache、ShardedMemoryCache和MultiLevelCache实现了此接口，
+// 用于替代先Exists再Set这类存在竞态的写法：
+//   - RedisCache通过Lua脚本执行，同时维护键的版本键
+//   - MemoryCache在写锁内完成检查和写入
+//   - MultiLevelCache将操作交给远程缓存执行，成功后删除本地缓存并通知其他实例
+//
+// ttl的含义与各实现的Set方法相同，小于0时表示永不过期。
+// 条件写入的值不带标签，覆盖或删除已有的键时同时移除它的标签；读取旧值的方法中target为nil时只执行操作不解码。
+// 对结构化数据的键执行GetSet、GetAndDelete、GetVersioned、CompareAndSwap或CompareAndDelete时返回ErrWrongType
+//
+// 示例：
+//
+//	cc, ok := cache.Conditional(c)
+//	if !ok {
+//	    return errors.New("cache does not support conditional operations")
+//	}
+//	for {
+//	    var balance int64
+//	    version, _, err := cc.GetVersioned(ctx, "balance", &balance)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    swapped, err := cc.CompareAndSwap(ctx, "balance", version, balance+10, 0)
+//	    if err != nil || swapped {
+//	        return err
+//	    }
+//	}
+type ConditionalCache interface {
+	// SetNX 仅在键不存在时写入，返回是否写入
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	// SetXX 仅在键已存在时写入，返回是否写入
+	SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	// GetSet 写入新值并将旧值解码到old，返回旧值是否存在
+	// 旧值解码失败时新值已经写入
+	GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error)
+	// GetAndDelete 删除键并将删除前的值解码到target，返回键是否存在
+	GetAndDelete(ctx context.Context, key string, target interface{}) (bool, error)
+	// GetVersioned 将值解码到target并返回当前版本，键不存在时返回空版本
+	GetVersioned(ctx context.Context, key string, target interface{}) (Version, bool, error)
+	// CompareAndSwap 仅在当前版本等于version时写入，version为空表示仅在键不存在时写入
+	// 返回是否写入
+	CompareAndSwap(ctx context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error)
+	// CompareAndDelete 仅在当前版本等于version时删除，返回是否删除
+	CompareAndDelete(ctx context.Context, key string, version Version) (bool, error)
+}
+
+// Conditional 返回缓存的条件写入接口
+// c本身未实现ConditionalCache时，依次查找Unwrap返回的底层缓存，
+// 因此InstrumentedCache包装的缓存同样可用
+func Conditional(c Cache) (ConditionalCache, bool) {
+	return findCache[ConditionalCache](c)
+}
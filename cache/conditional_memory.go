@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// liveItemUnsafe 查找未过期的缓存项（调用方需持有读锁或写锁）
+func (c *MemoryCache) liveItemUnsafe(key string) (*memoryItem, bool) {
+	item, found := c.items[key]
+	if !found || (item.Expiration > 0 && time.Now().UnixNano() > item.Expiration) {
+		return nil, false
+	}
+	return item, true
+}
+
+// newConditionalItem 创建条件写入的缓存项，ttl的含义与Set相同
+func (c *MemoryCache) newConditionalItem(key string, value interface{}, ttl time.Duration) *memoryItem {
+	var exp int64
+	if ttl > 0 {
+		exp = time.Now().Add(ttl).UnixNano()
+	}
+	return &memoryItem{
+		Value:      value,
+		Expiration: exp,
+		Tags:       []string{},
+		Cost:       c.itemCost(key, value),
+	}
+}
+
+// memoryVersion 返回缓存项的版本
+func memoryVersion(item *memoryItem) Version {
+	return Version(strconv.FormatUint(item.Version, 10))
+}
+
+// assignOptional 将值解码到target，target为nil时忽略
+func assignOptional(key string, value interface{}, target interface{}) error {
+	if target == nil {
+		return nil
+	}
+	return assignValue(key, value, target)
+}
+
+// SetNX 仅在键不存在时写入
+// 与Redis一致，键保存结构化数据时视为已存在
+func (c *MemoryCache) SetNX(_ context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	item := c.newConditionalItem(key, value, ttl)
+
+	c.mu.Lock()
+	if _, found := c.liveItemUnsafe(key); found {
+		c.mu.Unlock()
+		return false, nil
+	}
+	evicted := c.storeUnsafe(key, item)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
+	return true, nil
+}
+
+// SetXX 仅在键已存在时写入
+// 与Redis一致，键保存结构化数据时同样被覆盖
+func (c *MemoryCache) SetXX(_ context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	item := c.newConditionalItem(key, value, ttl)
+
+	c.mu.Lock()
+	if _, found := c.liveItemUnsafe(key); !found {
+		c.mu.Unlock()
+		return false, nil
+	}
+	evicted := c.storeUnsafe(key, item)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
+	return true, nil
+}
+
+// GetSet 写入新值并返回旧值
+func (c *MemoryCache) GetSet(_ context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error) {
+	item := c.newConditionalItem(key, value, ttl)
+
+	c.mu.Lock()
+	current, found := c.liveItemUnsafe(key)
+	if found && isMemoryStructure(current.Value) {
+		c.mu.Unlock()
+		return false, ErrWrongType
+	}
+	evicted := c.storeUnsafe(key, item)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
+	if !found {
+		return false, nil
+	}
+	return true, assignOptional(key, current.Value, old)
+}
+
+// GetAndDelete 删除键并返回删除前的值
+func (c *MemoryCache) GetAndDelete(_ context.Context, key string, target interface{}) (bool, error) {
+	c.mu.Lock()
+	current, found := c.liveItemUnsafe(key)
+	if !found {
+		c.mu.Unlock()
+		return false, nil
+	}
+	if isMemoryStructure(current.Value) {
+		c.mu.Unlock()
+		return false, ErrWrongType
+	}
+	c.removeUnsafe(key, current)
+	c.mu.Unlock()
+
+	return true, assignOptional(key, current.Value, target)
+}
+
+// GetVersioned 返回值和当前版本
+func (c *MemoryCache) GetVersioned(_ context.Context, key string, target interface{}) (Version, bool, error) {
+	c.mu.RLock()
+	current, found := c.liveItemUnsafe(key)
+	if !found {
+		c.mu.RUnlock()
+		return "", false, nil
+	}
+	if isMemoryStructure(current.Value) {
+		c.mu.RUnlock()
+		return "", false, ErrWrongType
+	}
+	c.recordAccess(key)
+	value, version := current.Value, memoryVersion(current)
+	c.mu.RUnlock()
+
+	if err := assignOptional(key, value, target); err != nil {
+		return "", false, err
+	}
+	return version, true, nil
+}
+
+// CompareAndSwap 仅在当前版本等于version时写入
+func (c *MemoryCache) CompareAndSwap(_ context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error) {
+	item := c.newConditionalItem(key, value, ttl)
+
+	c.mu.Lock()
+	current, found := c.liveItemUnsafe(key)
+	if found && isMemoryStructure(current.Value) {
+		c.mu.Unlock()
+		return false, ErrWrongType
+	}
+	if (version == "" && found) || (version != "" && (!found || memoryVersion(current) != version)) {
+		c.mu.Unlock()
+		return false, nil
+	}
+	evicted := c.storeUnsafe(key, item)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
+	return true, nil
+}
+
+// CompareAndDelete 仅在当前版本等于version时删除
+func (c *MemoryCache) CompareAndDelete(_ context.Context, key string, version Version) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, found := c.liveItemUnsafe(key)
+	if !found {
+		return false, nil
+	}
+	if isMemoryStructure(current.Value) {
+		return false, ErrWrongType
+	}
+	if memoryVersion(current) != version {
+		return false, nil
+	}
+	c.removeUnsafe(key, current)
+	return true, nil
+}
+
+// SetNX 仅在键不存在时写入
+func (c *ShardedMemoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.shard(key).SetNX(ctx, key, value, ttl)
+}
+
+// SetXX 仅在键已存在时写入
+func (c *ShardedMemoryCache) SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.shard(key).SetXX(ctx, key, value, ttl)
+}
+
+// GetSet 写入新值并返回旧值
+func (c *ShardedMemoryCache) GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error) {
+	return c.shard(key).GetSet(ctx, key, value, ttl, old)
+}
+
+// GetAndDelete 删除键并返回删除前的值
+func (c *ShardedMemoryCache) GetAndDelete(ctx context.Context, key string, target interface{}) (bool, error) {
+	return c.shard(key).GetAndDelete(ctx, key, target)
+}
+
+// GetVersioned 返回值和当前版本
+// 各分片独立分配版本号，同一个键总是落在同一个分片，因此版本可以直接比较
+func (c *ShardedMemoryCache) GetVersioned(ctx context.Context, key string, target interface{}) (Version, bool, error) {
+	return c.shard(key).GetVersioned(ctx, key, target)
+}
+
+// CompareAndSwap 仅在当前版本等于version时写入
+func (c *ShardedMemoryCache) CompareAndSwap(ctx context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error) {
+	return c.shard(key).CompareAndSwap(ctx, key, version, value, ttl)
+}
+
+// CompareAndDelete 仅在当前版本等于version时删除
+func (c *ShardedMemoryCache) CompareAndDelete(ctx context.Context, key string, version Version) (bool, error) {
+	return c.shard(key).CompareAndDelete(ctx, key, version)
+}
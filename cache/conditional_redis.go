@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// untagLua 定义Lua函数untag：按标签反向索引KEYS[3]从所有标签集合中移除键并删除反向索引
+// tagPrefix为带KeyPrefix的标签集合前缀，member为标签集合中保存的不带KeyPrefix的键
+const untagLua = `
+local function untag(tagPrefix, member)
+	for _, tag in ipairs(redis.call("SMEMBERS", KEYS[3])) do
+		redis.call("SREM", tagPrefix .. tag, member)
+	end
+	redis.call("DEL", KEYS[3])
+end
+`
+
+// conditionalSetScript 按ARGV[1]指定的条件写入KEYS[1]，并为KEYS[2]版本键写入新的版本标记ARGV[5]
+// 条件：nx键不存在，xx键已存在，get无条件并返回旧值，cas当前版本等于ARGV[2]（为空表示键不存在）
+// ARGV[3]为值，ARGV[4]为过期时间（毫秒），0表示永不过期；条件不满足时返回nil。
+// 与MemoryCache一致，条件写入的值不带标签，写入后通过untag从标签集合中移除，ARGV[6]和ARGV[7]为untag的参数
+var conditionalSetScript = redis.NewScript(untagLua + `
+local mode = ARGV[1]
+local current
+if mode == "nx" or mode == "xx" then
+	local exists = redis.call("EXISTS", KEYS[1]) == 1
+	if (mode == "nx") == exists then
+		return false
+	end
+else
+	current = redis.call("GET", KEYS[1])
+	if mode == "cas" then
+		if ARGV[2] == "" then
+			if current then
+				return false
+			end
+		else
+			local token = redis.call("GET", KEYS[2])
+			if not current or not token or token .. ":" .. redis.sha1hex(current) ~= ARGV[2] then
+				return false
+			end
+		end
+	end
+end
+if tonumber(ARGV[4]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[3], "PX", ARGV[4])
+	redis.call("SET", KEYS[2], ARGV[5], "PX", ARGV[4])
+else
+	redis.call("SET", KEYS[1], ARGV[3])
+	redis.call("SET", KEYS[2], ARGV[5])
+end
+untag(ARGV[6], ARGV[7])
+if mode == "get" then
+	if current then
+		return {1, current}
+	end
+	return {0}
+end
+return 1
+`)
+
+// conditionalDeleteScript 删除KEYS[1]和版本键KEYS[2]并返回删除前的值，同时通过untag从标签集合中移除
+// ARGV[1]不为空时仅在当前版本等于ARGV[1]时删除；键不存在或版本不一致时返回nil；ARGV[2]和ARGV[3]为untag的参数
+var conditionalDeleteScript = redis.NewScript(untagLua + `
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return false
+end
+if ARGV[1] ~= "" then
+	local token = redis.call("GET", KEYS[2])
+	if not token or token .. ":" .. redis.sha1hex(current) ~= ARGV[1] then
+		return false
+	end
+end
+redis.call("DEL", KEYS[1], KEYS[2])
+untag(ARGV[2], ARGV[3])
+return current
+`)
+
+// getVersionedScript 返回KEYS[1]的值和版本，版本键不存在时写入新的版本标记ARGV[1]，过期时间与值相同
+var getVersionedScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return false
+end
+local token = redis.call("GET", KEYS[2])
+if not token then
+	token = ARGV[1]
+	local ttl = redis.call("PTTL", KEYS[1])
+	if ttl > 0 then
+		redis.call("SET", KEYS[2], token, "PX", ttl)
+	else
+		redis.call("SET", KEYS[2], token)
+	end
+end
+return {current, token .. ":" .. redis.sha1hex(current)}
+`)
+
+// versionKey 返回键的版本键
+// 版本键保存随机的版本标记，条件写入时更新，其他写入和删除时删除，
+// 版本由版本标记和值的SHA1组成，值被改为其他值后又改回原值时版本也会变化。
+// 条件操作通过Lua脚本同时访问值、版本键和标签反向索引，在Redis集群中使用时键需要包含哈希标签（例如"{user:1}"），
+// 保证这些键位于同一个槽；脚本还会从键所在的标签集合中移除该键，集群中对带标签的键执行条件操作时标签也需要包含相同的哈希标签
+func (c *RedisCache) versionKey(key string) string {
+	return c.prefixKey(versionKeyPrefix + key)
+}
+
+// conditionalTTL 返回条件写入使用的过期时间，ttl为0时使用默认TTL，小于0时永不过期
+func (c *RedisCache) conditionalTTL(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		ttl = c.options.DefaultTTL
+	}
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// decodeOptional 将数据反序列化到target，target为nil时忽略
+func (c *RedisCache) decodeOptional(key string, data []byte, target interface{}) error {
+	if target == nil {
+		return nil
+	}
+	if err := c.serializer.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%w: key %q: %w", ErrDecode, key, err)
+	}
+	return nil
+}
+
+// conditionalKeys 返回条件操作脚本使用的键：值、版本键和标签反向索引
+func (c *RedisCache) conditionalKeys(key string) []string {
+	return []string{c.prefixKey(key), c.versionKey(key), c.keyTagsKey(key)}
+}
+
+// conditionalSet 执行条件写入脚本
+func (c *RedisCache) conditionalSet(ctx context.Context, mode, key string, version Version, value interface{}, ttl time.Duration) (interface{}, error) {
+	data, err := c.serializer.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize value: %w", err)
+	}
+
+	result, err := conditionalSetScript.Run(ctx, c.client, c.conditionalKeys(key),
+		mode, string(version), data, c.conditionalTTL(ttl).Milliseconds(), uuid.NewString(),
+		c.prefixKey(tagKeyPrefix), key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set cache: %w", structuredError(err))
+	}
+	return result, nil
+}
+
+// conditionalDelete 执行条件删除脚本
+func (c *RedisCache) conditionalDelete(ctx context.Context, key string, version Version) ([]byte, bool, error) {
+	data, err := conditionalDeleteScript.Run(ctx, c.client, c.conditionalKeys(key),
+		string(version), c.prefixKey(tagKeyPrefix), key).Text()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to delete cache: %w", structuredError(err))
+	}
+	return []byte(data), true, nil
+}
+
+// SetNX 仅在键不存在时写入
+func (c *RedisCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	result, err := c.conditionalSet(ctx, "nx", key, "", value, ttl)
+	return result != nil, err
+}
+
+// SetXX 仅在键已存在时写入
+func (c *RedisCache) SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	result, err := c.conditionalSet(ctx, "xx", key, "", value, ttl)
+	return result != nil, err
+}
+
+// GetSet 写入新值并返回旧值
+func (c *RedisCache) GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error) {
+	result, err := c.conditionalSet(ctx, "get", key, "", value, ttl)
+	if err != nil {
+		return false, err
+	}
+	reply, _ := result.([]interface{})
+	if len(reply) < 2 {
+		return false, nil
+	}
+	previous, _ := reply[1].(string)
+	return true, c.decodeOptional(key, []byte(previous), old)
+}
+
+// GetAndDelete 删除键并返回删除前的值
+func (c *RedisCache) GetAndDelete(ctx context.Context, key string, target interface{}) (bool, error) {
+	data, found, err := c.conditionalDelete(ctx, key, "")
+	if err != nil || !found {
+		return false, err
+	}
+	return true, c.decodeOptional(key, data, target)
+}
+
+// GetVersioned 返回值和当前版本
+func (c *RedisCache) GetVersioned(ctx context.Context, key string, target interface{}) (Version, bool, error) {
+	reply, err := getVersionedScript.Run(ctx, c.client, []string{c.prefixKey(key), c.versionKey(key)}, uuid.NewString()).Slice()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get cache: %w", structuredError(err))
+	}
+	if len(reply) != 2 {
+		return "", false, fmt.Errorf("failed to get cache: unexpected reply %v", reply)
+	}
+
+	data, _ := reply[0].(string)
+	version, _ := reply[1].(string)
+	if err := c.decodeOptional(key, []byte(data), target); err != nil {
+		return "", false, err
+	}
+	return Version(version), true, nil
+}
+
+// CompareAndSwap 仅在当前版本等于version时写入，通过Lua脚本原子执行
+func (c *RedisCache) CompareAndSwap(ctx context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error) {
+	result, err := c.conditionalSet(ctx, "cas", key, version, value, ttl)
+	return result != nil, err
+}
+
+// CompareAndDelete 仅在当前版本等于version时删除，通过Lua脚本原子执行
+func (c *RedisCache) CompareAndDelete(ctx context.Context, key string, version Version) (bool, error) {
+	if version == "" {
+		return false, nil
+	}
+	_, deleted, err := c.conditionalDelete(ctx, key, version)
+	return deleted, err
+}
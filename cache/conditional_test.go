@@ -0,0 +1,336 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conditionalTestCaches(t *testing.T) map[string]ConditionalCache {
+	caches := make(map[string]ConditionalCache)
+	for name, c := range loaderTestCaches(t) {
+		cc, ok := Conditional(c)
+		require.True(t, ok, name)
+		caches[name] = cc
+	}
+	return caches
+}
+
+func TestConditional_SetNXAndSetXX(t *testing.T) {
+	ctx := context.Background()
+	for name, cc := range conditionalTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			set, err := cc.SetXX(ctx, "key", "first", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, set, "SetXX must not create a missing key")
+
+			set, err = cc.SetNX(ctx, "key", "first", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, set)
+
+			set, err = cc.SetNX(ctx, "key", "second", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, set, "SetNX must not overwrite an existing key")
+
+			set, err = cc.SetXX(ctx, "key", "third", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, set)
+
+			var value string
+			_, found, err := cc.GetVersioned(ctx, "key", &value)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "third", value)
+		})
+	}
+}
+
+func TestConditional_GetSetAndGetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	for name, cc := range conditionalTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			var old string
+			found, err := cc.GetSet(ctx, "key", "first", time.Minute, &old)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			found, err = cc.GetSet(ctx, "key", "second", time.Minute, &old)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "first", old)
+
+			var value string
+			found, err = cc.GetAndDelete(ctx, "key", &value)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "second", value)
+
+			found, err = cc.GetAndDelete(ctx, "key", nil)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestConditional_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	for name, cc := range conditionalTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			version, found, err := cc.GetVersioned(ctx, "key", nil)
+			require.NoError(t, err)
+			assert.False(t, found)
+			assert.Empty(t, version)
+
+			// 空版本表示仅在键不存在时写入
+			swapped, err := cc.CompareAndSwap(ctx, "key", "", "first", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+			swapped, err = cc.CompareAndSwap(ctx, "key", "", "again", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, swapped)
+
+			version, found, err = cc.GetVersioned(ctx, "key", nil)
+			require.NoError(t, err)
+			require.True(t, found)
+			require.NotEmpty(t, version)
+
+			swapped, err = cc.CompareAndSwap(ctx, "key", version, "second", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+
+			// 旧版本不能再次写入或删除
+			swapped, err = cc.CompareAndSwap(ctx, "key", version, "third", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, swapped)
+			deleted, err := cc.CompareAndDelete(ctx, "key", version)
+			require.NoError(t, err)
+			assert.False(t, deleted)
+
+			var value string
+			version, _, err = cc.GetVersioned(ctx, "key", &value)
+			require.NoError(t, err)
+			assert.Equal(t, "second", value)
+
+			deleted, err = cc.CompareAndDelete(ctx, "key", version)
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			_, found, err = cc.GetVersioned(ctx, "key", nil)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestConditional_ConcurrentCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	for name, cc := range conditionalTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := cc.SetNX(ctx, "counter", int64(0), time.Minute)
+			require.NoError(t, err)
+
+			const workers, increments = 8, 10
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for n := 0; n < increments; {
+						var counter int64
+						version, _, err := cc.GetVersioned(ctx, "counter", &counter)
+						if !assert.NoError(t, err) {
+							return
+						}
+						swapped, err := cc.CompareAndSwap(ctx, "counter", version, counter+1, time.Minute)
+						if !assert.NoError(t, err) {
+							return
+						}
+						if swapped {
+							n++
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			var counter int64
+			_, _, err = cc.GetVersioned(ctx, "counter", &counter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(workers*increments), counter)
+		})
+	}
+}
+
+func TestConditional_WrongType(t *testing.T) {
+	ctx := context.Background()
+	for name, cc := range conditionalTestCaches(t) {
+		sc, ok := cc.(StructuredCache)
+		if !ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := sc.HSet(ctx, "hash", map[string]string{"field": "value"})
+			require.NoError(t, err)
+
+			set, err := cc.SetNX(ctx, "hash", "value", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, set)
+
+			_, _, err = cc.GetVersioned(ctx, "hash", nil)
+			assert.ErrorIs(t, err, ErrWrongType)
+			_, err = cc.GetAndDelete(ctx, "hash", nil)
+			assert.ErrorIs(t, err, ErrWrongType)
+			_, err = cc.CompareAndSwap(ctx, "hash", "", "value", time.Minute)
+			assert.ErrorIs(t, err, ErrWrongType)
+		})
+	}
+}
+
+func TestConditional_MemoryIncrementChangesVersion(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "counter", int64(1), time.Minute))
+	version, _, err := c.GetVersioned(ctx, "counter", nil)
+	require.NoError(t, err)
+
+	_, err = c.Increment(ctx, "counter", 1)
+	require.NoError(t, err)
+	swapped, err := c.CompareAndSwap(ctx, "counter", version, int64(100), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+}
+
+func TestConditional_RedisUntagsInScript(t *testing.T) {
+	ctx := context.Background()
+	c := newMiniRedisCache(t)
+	c.options.KeyPrefix = "app"
+	client := c.GetClient()
+
+	require.NoError(t, c.SetItem(ctx, "a", &Item{Value: "a", Expiration: time.Hour, Tags: []string{"t1", "t2"}}))
+	require.NoError(t, c.SetItem(ctx, "b", &Item{Value: "b", Expiration: time.Hour, Tags: []string{"t1"}}))
+	tags, err := client.SMembers(ctx, c.keyTagsKey("a")).Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"t1", "t2"}, tags)
+
+	// 条件不满足时不写入，标签保持不变
+	set, err := c.SetNX(ctx, "a", "other", time.Hour)
+	require.NoError(t, err)
+	require.False(t, set)
+	members, err := client.SMembers(ctx, c.prefixKey(tagKeyPrefix+"t1")).Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, members)
+
+	version, _, err := c.GetVersioned(ctx, "a", nil)
+	require.NoError(t, err)
+	swapped, err := c.CompareAndSwap(ctx, "a", version, "untagged", time.Hour)
+	require.NoError(t, err)
+	require.True(t, swapped)
+	members, err = client.SMembers(ctx, c.prefixKey(tagKeyPrefix+"t1")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, members)
+	for _, key := range []string{c.prefixKey(tagKeyPrefix + "t2"), c.keyTagsKey("a")} {
+		n, err := client.Exists(ctx, key).Result()
+		require.NoError(t, err)
+		assert.Zero(t, n, key)
+	}
+
+	found, err := c.GetAndDelete(ctx, "b", nil)
+	require.NoError(t, err)
+	require.True(t, found)
+	keys, err := client.Keys(ctx, "*").Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{c.prefixKey("a"), c.versionKey("a")}, keys, "only the untagged value and its version remain")
+}
+
+func TestConditional_MultiLevelInvalidatesLocal(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryCache()
+	remote := NewMemoryCache()
+	c, err := NewMultiLevelCache(local, remote)
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "key", "first", time.Minute))
+	_, found := local.Get(ctx, "key")
+	require.True(t, found)
+
+	// 版本来自远程缓存
+	version, _, err := c.GetVersioned(ctx, "key", nil)
+	require.NoError(t, err)
+	remoteVersion, _, err := remote.GetVersioned(ctx, "key", nil)
+	require.NoError(t, err)
+	assert.Equal(t, remoteVersion, version)
+
+	swapped, err := c.CompareAndSwap(ctx, "key", version, "second", time.Minute)
+	require.NoError(t, err)
+	require.True(t, swapped)
+	_, found = local.Get(ctx, "key")
+	assert.False(t, found, "local copy must be invalidated")
+
+	value, found := c.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "second", value)
+
+	_, err = c.GetAndDelete(ctx, "key", nil)
+	require.NoError(t, err)
+	_, found = c.Get(ctx, "key")
+	assert.False(t, found)
+}
+
+func TestConditional_MultiLevelWriteBackFlushesPending(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache()
+	c, err := NewMultiLevelCache(NewMemoryCache(), remote, MultiLevelOptions{
+		WriteMode: WriteModeWriteBack,
+		LocalTTL:  time.Minute,
+		WriteBack: WriteBackOptions{FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "key", "pending", time.Minute))
+	set, err := c.SetNX(ctx, "key", "other", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, set, "pending write-back value must reach the remote first")
+
+	value, found := remote.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "pending", value)
+}
+
+func TestConditional_MultiLevelCircuitOpen(t *testing.T) {
+	ctx := context.Background()
+	remote := &switchableRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Hour,
+	})
+
+	remote.down.Store(true)
+	_ = c.Set(ctx, "trip", 1, time.Minute)
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	_, err := c.SetNX(ctx, "key", "value", time.Minute)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, _, err = c.GetVersioned(ctx, "key", nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestConditional_NotSupported(t *testing.T) {
+	namespace, err := NewNamespace(NewMemoryCache(), "ns")
+	require.NoError(t, err)
+	c, err := NewMultiLevelCache(NewMemoryCache(), namespace)
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.SetNX(context.Background(), "key", "value", time.Minute)
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	_, ok := Conditional(NewInstrumentedCache(NewMemoryCache()))
+	assert.True(t, ok)
+}
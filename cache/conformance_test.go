@@ -0,0 +1,362 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceCaches 返回需要通过一致性测试的所有Cache实现
+// 新增实现或包装器时需要加入此列表
+func conformanceCaches() map[string]func(t *testing.T) Cache {
+	return map[string]func(t *testing.T) Cache{
+		"memory": func(t *testing.T) Cache {
+			c := NewMemoryCache()
+			t.Cleanup(func() { _ = c.Close() })
+			return c
+		},
+		"sharded_memory": func(t *testing.T) Cache {
+			c := NewShardedMemoryCache(4)
+			t.Cleanup(func() { _ = c.Close() })
+			return c
+		},
+		"redis": func(t *testing.T) Cache {
+			return newMiniRedisCache(t)
+		},
+		"redis_with_prefix": func(t *testing.T) Cache {
+			c := newMiniRedisCache(t)
+			c.options.KeyPrefix = "app[1]"
+			return c
+		},
+		"multi_level": func(t *testing.T) Cache {
+			c, err := NewMultiLevelCache(NewMemoryCache(), newMiniRedisCache(t))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = c.Close() })
+			return c
+		},
+		"instrumented": func(t *testing.T) Cache {
+			c := NewInstrumentedCache(NewMemoryCache())
+			t.Cleanup(func() { _ = c.Close() })
+			return c
+		},
+		"guarded": func(t *testing.T) Cache {
+			c := NewGuardedCache(newMiniRedisCache(t))
+			return c
+		},
+		"namespace": func(t *testing.T) Cache {
+			ns, err := NewNamespace(newMiniRedisCache(t), "conformance")
+			require.NoError(t, err)
+			return ns
+		},
+		"fault": func(t *testing.T) Cache {
+			c := NewFaultCache(newMiniRedisCache(t))
+			t.Cleanup(func() { _ = c.Close() })
+			return c
+		},
+	}
+}
+
+// runConformance 对每个实现运行测试函数，每个实现使用独立的缓存实例
+func runConformance(t *testing.T, test func(t *testing.T, ctx context.Context, c Cache)) {
+	for name, newCache := range conformanceCaches() {
+		t.Run(name, func(t *testing.T) {
+			test(t, context.Background(), newCache(t))
+		})
+	}
+}
+
+// setKeys 写入一组键，值与键相同
+func setKeys(t *testing.T, ctx context.Context, c Cache, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		require.NoError(t, c.Set(ctx, key, key, time.Hour))
+	}
+}
+
+// remainingKeys 返回keys中仍然存在的键
+func remainingKeys(t *testing.T, ctx context.Context, c Cache, keys ...string) []string {
+	t.Helper()
+	remaining := []string{}
+	for _, key := range keys {
+		exists, err := c.Exists(ctx, key)
+		require.NoError(t, err)
+		if exists {
+			remaining = append(remaining, key)
+		}
+	}
+	return remaining
+}
+
+func TestConformance_GetSetDelete(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		_, found := c.Get(ctx, "missing")
+		assert.False(t, found)
+
+		require.NoError(t, c.Set(ctx, "key", "value", time.Hour))
+		value, found := c.Get(ctx, "key")
+		require.True(t, found)
+		assert.Equal(t, "value", value)
+
+		require.NoError(t, c.Set(ctx, "key", "updated", time.Hour))
+		value, _ = c.Get(ctx, "key")
+		assert.Equal(t, "updated", value, "Set overwrites existing values")
+
+		var target string
+		found, err := GetInto(ctx, c, "key", &target)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "updated", target)
+
+		require.NoError(t, c.Delete(ctx, "key"))
+		require.NoError(t, c.Delete(ctx, "key"), "Delete is idempotent")
+		exists, err := c.Exists(ctx, "key")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestConformance_TTL(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		require.NoError(t, c.Set(ctx, "hour", "v", time.Hour))
+		_, ttl, found := c.GetWithTTL(ctx, "hour")
+		require.True(t, found)
+		// MultiLevelCache返回本地缓存中较短的TTL，因此只要求在(0, 1h]内
+		assert.Greater(t, ttl, time.Duration(0))
+		assert.LessOrEqual(t, ttl, time.Hour)
+
+		require.NoError(t, c.Set(ctx, "forever", "v", -1))
+		_, ttl, found = c.GetWithTTL(ctx, "forever")
+		require.True(t, found)
+		assert.Less(t, ttl, time.Duration(0), "a negative TTL means the key never expires")
+
+		_, _, found = c.GetWithTTL(ctx, "missing")
+		assert.False(t, found)
+	})
+}
+
+func TestConformance_Counters(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		n, err := c.Increment(ctx, "counter", 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n, "missing counters start at zero")
+
+		n, err = c.Decrement(ctx, "counter", 7)
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), n)
+
+		n, err = c.Increment(ctx, "counter", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(-2), n)
+	})
+}
+
+func TestConformance_Tags(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		require.NoError(t, c.SetItem(ctx, "user:1", &Item{Value: "a", Expiration: time.Hour, Tags: []string{"users", "admins"}}))
+		require.NoError(t, c.SetItem(ctx, "user:2", &Item{Value: "b", Expiration: time.Hour, Tags: []string{"users"}}))
+		require.NoError(t, c.SetItem(ctx, "post:1", &Item{Value: "c", Expiration: time.Hour, Tags: []string{"posts"}}))
+
+		require.NoError(t, c.DeleteByTag(ctx, "admins"))
+		assert.Equal(t, []string{"user:2", "post:1"}, remainingKeys(t, ctx, c, "user:1", "user:2", "post:1"))
+
+		require.NoError(t, c.DeleteByTag(ctx, "users"))
+		require.NoError(t, c.DeleteByTag(ctx, "missing"))
+		assert.Equal(t, []string{"post:1"}, remainingKeys(t, ctx, c, "user:1", "user:2", "post:1"))
+	})
+}
+
+func TestConformance_DeleteByPattern(t *testing.T) {
+	keys := []string{"user:1", "user:2", "user:10", "user:a", "users", "xuser:1", "lit*", "lit?", "litx", `back\slash`, "[x]", "x"}
+
+	tests := []struct {
+		pattern string
+		deleted []string
+	}{
+		{"user:*", []string{"user:1", "user:2", "user:10", "user:a"}},
+		{"user:?", []string{"user:1", "user:2", "user:a"}},
+		{"user:[0-9]", []string{"user:1", "user:2"}},
+		{"user:[0-9]*", []string{"user:1", "user:2", "user:10"}},
+		{"user:[^0-9]", []string{"user:a"}},
+		{"user:[a-a]", []string{"user:a"}},
+		{"user*", []string{"user:1", "user:2", "user:10", "user:a", "users"}},
+		{"*user:1*", []string{"user:1", "user:10", "xuser:1"}},
+		{"user:1", []string{"user:1"}},
+		{`lit\*`, []string{"lit*"}},
+		{`lit\?`, []string{"lit?"}},
+		{"lit?", []string{"lit*", "lit?", "litx"}},
+		{`back\\slash`, []string{`back\slash`}},
+		{`\[x\]`, []string{"[x]"}},
+		{"[x]", []string{"x"}},
+		{"nomatch*", []string{}},
+	}
+
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		for _, tt := range tests {
+			require.NoError(t, c.Flush(ctx))
+			setKeys(t, ctx, c, keys...)
+
+			require.NoError(t, c.DeleteByPattern(ctx, tt.pattern))
+
+			var expected []string
+			for _, key := range keys {
+				if !contains(tt.deleted, key) {
+					expected = append(expected, key)
+				}
+			}
+			assert.Equal(t, expected, remainingKeys(t, ctx, c, keys...), "pattern %q", tt.pattern)
+		}
+	})
+}
+
+func TestConformance_Keys(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		setKeys(t, ctx, c, "user:1", "user:2", "user:10")
+		require.NoError(t, c.SetItem(ctx, "post:1", &Item{Value: "post:1", Expiration: time.Hour, Tags: []string{"posts"}}))
+		// 带标签和带版本的键在Redis中有对应的内部键，Keys不应返回它们
+		if cc, ok := Conditional(c); ok {
+			version, _, err := cc.GetVersioned(ctx, "user:1", nil)
+			require.NoError(t, err)
+			swapped, err := cc.CompareAndSwap(ctx, "user:1", version, "user:1", time.Hour)
+			require.NoError(t, err)
+			require.True(t, swapped)
+			_, _, err = cc.GetVersioned(ctx, "user:2", nil)
+			require.NoError(t, err)
+		}
+
+		keys, err := Keys(ctx, c, "user:?")
+		require.NoError(t, err)
+		sort.Strings(keys)
+		assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+		keys, err = Keys(ctx, c, "*")
+		require.NoError(t, err)
+		sort.Strings(keys)
+		assert.Equal(t, []string{"post:1", "user:1", "user:10", "user:2"}, keys)
+
+		keys, err = Keys(ctx, c, "missing*")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		scanner, ok := findCache[KeyScanner](c)
+		require.True(t, ok)
+		visited := 0
+		for key, err := range scanner.Scan(ctx, "user:*") {
+			require.NoError(t, err)
+			assert.True(t, MatchPattern("user:*", key))
+			visited++
+			break
+		}
+		assert.Equal(t, 1, visited, "Scan stops when the caller breaks")
+	})
+}
+
+func TestConformance_Flush(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		setKeys(t, ctx, c, "a", "b")
+		require.NoError(t, c.SetItem(ctx, "c", &Item{Value: "c", Expiration: time.Hour, Tags: []string{"tag"}}))
+
+		require.NoError(t, c.Flush(ctx))
+		assert.Empty(t, remainingKeys(t, ctx, c, "a", "b", "c"))
+
+		require.NoError(t, c.Set(ctx, "a", "again", time.Hour))
+		value, found := c.Get(ctx, "a")
+		require.True(t, found, "the cache is usable after Flush")
+		assert.Equal(t, "again", value)
+	})
+}
+
+// runConditionalConformance 对实现了ConditionalCache的实现运行测试函数
+func runConditionalConformance(t *testing.T, test func(t *testing.T, ctx context.Context, c Cache, cc ConditionalCache)) {
+	runConformance(t, func(t *testing.T, ctx context.Context, c Cache) {
+		cc, ok := Conditional(c)
+		if !ok {
+			t.Skip("conditional operations are not supported")
+		}
+		test(t, ctx, c, cc)
+	})
+}
+
+func TestConformance_ConditionalVersionsChangeOnEveryWrite(t *testing.T) {
+	runConditionalConformance(t, func(t *testing.T, ctx context.Context, c Cache, cc ConditionalCache) {
+		require.NoError(t, c.Set(ctx, "key", "A", time.Hour))
+		stale, _, err := cc.GetVersioned(ctx, "key", nil)
+		require.NoError(t, err)
+
+		// 通过条件写入改为B再改回A
+		version, _, err := cc.GetVersioned(ctx, "key", nil)
+		require.NoError(t, err)
+		swapped, err := cc.CompareAndSwap(ctx, "key", version, "B", time.Hour)
+		require.NoError(t, err)
+		require.True(t, swapped)
+		version, _, err = cc.GetVersioned(ctx, "key", nil)
+		require.NoError(t, err)
+		swapped, err = cc.CompareAndSwap(ctx, "key", version, "A", time.Hour)
+		require.NoError(t, err)
+		require.True(t, swapped)
+
+		swapped, err = cc.CompareAndSwap(ctx, "key", stale, "C", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, swapped, "ABA through conditional writes")
+
+		// 普通写入改回原值
+		stale, _, err = cc.GetVersioned(ctx, "key", nil)
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "key", "B", time.Hour))
+		require.NoError(t, c.Set(ctx, "key", "A", time.Hour))
+		swapped, err = cc.CompareAndSwap(ctx, "key", stale, "C", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, swapped, "ABA through plain writes")
+
+		// 删除后重新写入相同的值
+		stale, _, err = cc.GetVersioned(ctx, "key", nil)
+		require.NoError(t, err)
+		require.NoError(t, c.Delete(ctx, "key"))
+		set, err := cc.SetNX(ctx, "key", "A", time.Hour)
+		require.NoError(t, err)
+		require.True(t, set)
+		deleted, err := cc.CompareAndDelete(ctx, "key", stale)
+		require.NoError(t, err)
+		assert.False(t, deleted, "ABA through delete and recreate")
+
+		value, found := c.Get(ctx, "key")
+		assert.True(t, found)
+		assert.Equal(t, "A", value)
+	})
+}
+
+func TestConformance_ConditionalWritesDropTags(t *testing.T) {
+	runConditionalConformance(t, func(t *testing.T, ctx context.Context, c Cache, cc ConditionalCache) {
+		for _, key := range []string{"setxx", "getset", "cas"} {
+			require.NoError(t, c.SetItem(ctx, key, &Item{Value: "tagged", Expiration: time.Hour, Tags: []string{"old"}}))
+		}
+
+		set, err := cc.SetXX(ctx, "setxx", "untagged", time.Hour)
+		require.NoError(t, err)
+		require.True(t, set)
+		found, err := cc.GetSet(ctx, "getset", "untagged", time.Hour, nil)
+		require.NoError(t, err)
+		require.True(t, found)
+		version, _, err := cc.GetVersioned(ctx, "cas", nil)
+		require.NoError(t, err)
+		swapped, err := cc.CompareAndSwap(ctx, "cas", version, "untagged", time.Hour)
+		require.NoError(t, err)
+		require.True(t, swapped)
+
+		// 条件写入的值不带标签，按旧标签删除时不受影响
+		require.NoError(t, c.DeleteByTag(ctx, "old"))
+		assert.Equal(t, []string{"setxx", "getset", "cas"}, remainingKeys(t, ctx, c, "setxx", "getset", "cas"))
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
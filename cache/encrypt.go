@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+)
+
+// encryptionVersion 加密数据头部的版本字节
+// 取值0xE1不会出现在JSON、Gob或压缩序列化器数据的第一个字节
+const encryptionVersion byte = 0xE1
+
+// EncryptionOptions 加密序列化器选项
+type EncryptionOptions struct {
+	// Keys 密钥ID到AES密钥的映射，密钥长度必须为16、24或32字节
+	// 密钥ID写入每条数据的头部，解密时据此选择密钥，长度不能超过255字节
+	Keys map[string][]byte
+
+	// PrimaryKeyID 加密新数据使用的密钥ID，必须存在于Keys中
+	// 轮换密钥时加入新密钥并将其设为PrimaryKeyID，旧密钥保留到用它加密的数据全部过期
+	PrimaryKeyID string
+
+	// AllowPlaintext 是否允许读取未加密的数据，用于在已有数据的Redis上启用加密
+	// 默认不允许，未加密的数据会返回错误；Increment写入的整数计数器例外，见EncryptingSerializer
+	AllowPlaintext bool
+}
+
+// EncryptingSerializer 加密序列化器
+// 包装另一个序列化器，使用AES-GCM加密序列化结果，数据格式为：
+// 版本(1字节) | 密钥ID长度(1字节) | 密钥ID | 随机数(12字节) | 密文和认证标签。
+// 头部作为附加认证数据参与认证，篡改密钥ID或密文都会导致解密失败。
+// 附加认证数据不包含缓存键，序列化器无法得知数据属于哪个键，
+// 因此能写入Redis的攻击者可以把一个键的密文复制到另一个键，读取时仍会解密成功；
+// 需要防止这种替换时，应在值中自行包含键或其他上下文并在读取后校验。
+//
+// Increment和Decrement由Redis直接在原始数据上计算，计数器以明文整数保存，无法加密。
+// 为了让计数器可以正常读取，即使没有设置AllowPlaintext，未加密的十进制整数也会直接交给内层序列化器；
+// 计数器应当只通过Increment创建，Set写入的加密数据不能再被Increment处理。
+//
+// 与压缩序列化器组合时应先压缩后加密，即加密序列化器在外层：
+//
+//	serializer, err := cache.NewEncryptingSerializer(
+//	    cache.NewCompressingSerializer(&cache.JSONSerializer{}),
+//	    cache.EncryptionOptions{
+//	        Keys:         map[string][]byte{"2024-06": oldKey, "2025-01": newKey},
+//	        PrimaryKeyID: "2025-01",
+//	    },
+//	)
+type EncryptingSerializer struct {
+	inner          Serializer
+	primaryKeyID   string
+	aeads          map[string]cipher.AEAD
+	allowPlaintext bool
+}
+
+// NewEncryptingSerializer 创建加密序列化器
+// 参数：
+//
+//	inner: 被包装的序列化器，为nil时使用DefaultSerializer
+//	opts: 加密选项
+//
+// 返回：
+//
+//	*EncryptingSerializer: 加密序列化器
+//	error: 密钥无效或PrimaryKeyID不存在时返回错误
+func NewEncryptingSerializer(inner Serializer, opts EncryptionOptions) (*EncryptingSerializer, error) {
+	if inner == nil {
+		inner = DefaultSerializer()
+	}
+	if _, ok := opts.Keys[opts.PrimaryKeyID]; !ok {
+		return nil, fmt.Errorf("primary encryption key %q not found", opts.PrimaryKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(opts.Keys))
+	for id, key := range opts.Keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("encryption key id %q is too long", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aeads[id] = aead
+	}
+
+	return &EncryptingSerializer{
+		inner:          inner,
+		primaryKeyID:   opts.PrimaryKeyID,
+		aeads:          aeads,
+		allowPlaintext: opts.AllowPlaintext,
+	}, nil
+}
+
+// Marshal 序列化并使用主密钥加密
+func (s *EncryptingSerializer) Marshal(v interface{}) ([]byte, error) {
+	plaintext, err := s.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	aead := s.aeads[s.primaryKeyID]
+	headerLen := 2 + len(s.primaryKeyID)
+	nonceSize := aead.NonceSize()
+
+	out := make([]byte, headerLen+nonceSize, headerLen+nonceSize+len(plaintext)+aead.Overhead())
+	out[0] = encryptionVersion
+	out[1] = byte(len(s.primaryKeyID))
+	copy(out[2:], s.primaryKeyID)
+
+	nonce := out[headerLen : headerLen+nonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption error: %w", err)
+	}
+
+	return aead.Seal(out, nonce, plaintext, out[:headerLen]), nil
+}
+
+// Unmarshal 根据头部中的密钥ID解密并反序列化
+func (s *EncryptingSerializer) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 || data[0] != encryptionVersion {
+		if s.allowPlaintext || isPlainCounter(data) {
+			return s.inner.Unmarshal(data, v)
+		}
+		return fmt.Errorf("decryption error: data is not encrypted")
+	}
+
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return fmt.Errorf("decryption error: truncated header")
+	}
+	headerLen := 2 + int(data[1])
+	keyID := string(data[2:headerLen])
+
+	aead, ok := s.aeads[keyID]
+	if !ok {
+		return fmt.Errorf("decryption error: unknown key id %q", keyID)
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(data) < headerLen+nonceSize+aead.Overhead() {
+		return fmt.Errorf("decryption error: truncated data")
+	}
+	nonce := data[headerLen : headerLen+nonceSize]
+
+	plaintext, err := aead.Open(nil, nonce, data[headerLen+nonceSize:], data[:headerLen])
+	if err != nil {
+		return fmt.Errorf("decryption error: %w", err)
+	}
+	return s.inner.Unmarshal(plaintext, v)
+}
+
+// isPlainCounter 判断数据是否为Increment写入的明文整数计数器
+func isPlainCounter(data []byte) bool {
+	_, err := strconv.ParseInt(string(data), 10, 64)
+	return err == nil
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEncryptionKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestEncryptingSerializer_RoundTrip(t *testing.T) {
+	s, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+
+	payload := newSerializerPayload(512)
+	data, err := s.Marshal(payload)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "cached payload", "plaintext must not appear in the output")
+
+	again, err := s.Marshal(payload)
+	require.NoError(t, err)
+	assert.NotEqual(t, data, again, "each encryption uses a fresh nonce")
+
+	var decoded serializerPayload
+	require.NoError(t, s.Unmarshal(data, &decoded))
+	assert.Equal(t, payload, decoded)
+}
+
+func TestEncryptingSerializer_KeyRotation(t *testing.T) {
+	old, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"2024": testEncryptionKey(1)},
+		PrimaryKeyID: "2024",
+	})
+	require.NoError(t, err)
+	rotated, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"2024": testEncryptionKey(1), "2025": testEncryptionKey(2)},
+		PrimaryKeyID: "2025",
+	})
+	require.NoError(t, err)
+
+	// 轮换后仍可读取旧密钥加密的数据
+	data, err := old.Marshal("secret")
+	require.NoError(t, err)
+	var value string
+	require.NoError(t, rotated.Unmarshal(data, &value))
+	assert.Equal(t, "secret", value)
+
+	// 新数据使用新密钥，只有旧密钥的实例无法读取
+	data, err = rotated.Marshal("new secret")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2025")
+	assert.ErrorContains(t, old.Unmarshal(data, &value), "unknown key id")
+}
+
+func TestEncryptingSerializer_RejectsTampering(t *testing.T) {
+	s, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+
+	data, err := s.Marshal("secret")
+	require.NoError(t, err)
+
+	var value string
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 0xFF
+	assert.Error(t, s.Unmarshal(tampered, &value))
+	assert.Error(t, s.Unmarshal(data[:5], &value))
+	assert.Error(t, s.Unmarshal([]byte{encryptionVersion}, &value))
+}
+
+func TestEncryptingSerializer_Plaintext(t *testing.T) {
+	plain, err := (&JSONSerializer{}).Marshal("legacy")
+	require.NoError(t, err)
+
+	strict, err := NewEncryptingSerializer(nil, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+	var value string
+	assert.Error(t, strict.Unmarshal(plain, &value))
+
+	lenient, err := NewEncryptingSerializer(nil, EncryptionOptions{
+		Keys:           map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID:   "k1",
+		AllowPlaintext: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, lenient.Unmarshal(plain, &value))
+	assert.Equal(t, "legacy", value)
+
+	// Increment写入的明文计数器总是可以读取
+	var counter int64
+	require.NoError(t, strict.Unmarshal([]byte("-42"), &counter))
+	assert.Equal(t, int64(-42), counter)
+}
+
+func TestEncryptingSerializer_RedisCounter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	opts := DefaultRedisOptions()
+	opts.Addr = mr.Addr()
+
+	serializer, err := NewEncryptingSerializer(&JSONSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+	c, err := NewBuilder().WithType(CacheTypeRedis).WithOptions(opts).WithSerializer(serializer).Build()
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	_, err = c.Increment(ctx, "counter", 3)
+	require.NoError(t, err)
+
+	var counter int64
+	found, err := GetInto(ctx, c, "counter", &counter)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(3), counter)
+}
+
+func TestEncryptingSerializer_InvalidOptions(t *testing.T) {
+	_, err := NewEncryptingSerializer(nil, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "missing",
+	})
+	assert.Error(t, err)
+
+	_, err = NewEncryptingSerializer(nil, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": []byte("short")},
+		PrimaryKeyID: "k1",
+	})
+	assert.Error(t, err)
+}
+
+func TestEncryptingSerializer_WithCompression(t *testing.T) {
+	s, err := NewEncryptingSerializer(NewCompressingSerializer(&JSONSerializer{}), EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+
+	payload := newSerializerPayload(8192)
+	data, err := s.Marshal(payload)
+	require.NoError(t, err)
+	assert.Less(t, len(data), 1024)
+
+	var decoded serializerPayload
+	require.NoError(t, s.Unmarshal(data, &decoded))
+	assert.Equal(t, payload, decoded)
+}
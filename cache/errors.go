@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"github.com/guanzhenxing/go-snap/errors"
+)
+
+// 缓存相关错误定义
+var (
+	// ErrDecode 表示缓存值无法解码为目标类型
+	ErrDecode = errors.New("cache: failed to decode value")
+
+	// ErrNotFound 表示数据不存在
+	// GuardedCache的loader返回此错误时写入不存在标记，读取到标记或被布隆过滤器拒绝时返回此错误
+	ErrNotFound = errors.New("cache: not found")
+
+	// ErrWrongType 表示对保存其他类型数据的键执行了结构化数据操作
+	// 例如对普通缓存值执行HSet，或对哈希执行LPush
+	ErrWrongType = errors.New("cache: operation against a key holding the wrong kind of value")
+
+	// ErrNotSupported 表示缓存实现不支持该操作
+	ErrNotSupported = errors.New("cache: operation not supported")
+
+	// ErrCircuitOpen 表示远程缓存的熔断器处于打开状态，操作没有发往远程缓存
+	ErrCircuitOpen = errors.New("cache: circuit breaker is open")
+)
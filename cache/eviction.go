@@ -0,0 +1,439 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"encoding/json"
+	"hash/maphash"
+)
+
+// EvictionPolicy 容量淘汰策略
+// 仅在设置了MaxEntries或MaxCost的内存缓存中生效
+type EvictionPolicy int
+
+const (
+	// EvictionLRU 淘汰最近最少使用的缓存项
+	EvictionLRU EvictionPolicy = iota
+	// EvictionLFU 淘汰访问频率最低的缓存项，频率相同时淘汰最久未访问的
+	EvictionLFU
+	// EvictionTinyLFU W-TinyLFU策略：新项先进入小的LRU窗口，离开窗口时与主区的淘汰候选
+	// 比较估算访问频率，频率更高者保留，能够抵抗突发的一次性访问对热点数据的冲刷
+	EvictionTinyLFU
+)
+
+// String 返回淘汰策略的名称
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictionLRU:
+		return "lru"
+	case EvictionLFU:
+		return "lfu"
+	case EvictionTinyLFU:
+		return "tinylfu"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionReason 缓存项被淘汰的原因
+type EvictionReason int
+
+const (
+	// EvictionReasonCapacity 超出容量限制被淘汰
+	EvictionReasonCapacity EvictionReason = iota
+	// EvictionReasonExpired 过期后被清理
+	EvictionReasonExpired
+)
+
+// String 返回淘汰原因的名称
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionCallback 缓存项被淘汰时的回调
+// 回调在释放缓存锁之后调用，可以安全地访问缓存
+type EvictionCallback func(key string, value interface{}, reason EvictionReason)
+
+// CostFunc 计算缓存项成本的函数，返回值通常为字节数
+type CostFunc func(key string, value interface{}) int64
+
+// EstimateCost 估算缓存项占用的字节数，是MaxCost的默认成本函数
+// 字符串和字节切片按长度计算，基本数值类型按8字节计算，其他类型按JSON编码后的长度估算
+func EstimateCost(key string, value interface{}) int64 {
+	size := int64(len(key))
+	switch v := value.(type) {
+	case nil:
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		size += 8
+	default:
+		if data, err := json.Marshal(v); err == nil {
+			size += int64(len(data))
+		} else {
+			size += 64
+		}
+	}
+	return size
+}
+
+// evictor 淘汰策略的内部实现，调用方负责并发保护
+type evictor interface {
+	// add 记录新加入的键
+	add(key string)
+	// access 记录一次命中
+	access(key string)
+	// remove 移除键
+	remove(key string)
+	// victim 返回下一个应被淘汰的键
+	victim() (string, bool)
+}
+
+// newEvictor 根据策略创建淘汰器
+func newEvictor(policy EvictionPolicy, capacity int) evictor {
+	switch policy {
+	case EvictionLFU:
+		return newLFUEvictor()
+	case EvictionTinyLFU:
+		return newTinyLFUEvictor(capacity)
+	default:
+		return newLRUEvictor()
+	}
+}
+
+// lruEvictor LRU淘汰器
+type lruEvictor struct {
+	ll    *list.List
+	nodes map[string]*list.Element
+}
+
+func newLRUEvictor() *lruEvictor {
+	return &lruEvictor{ll: list.New(), nodes: make(map[string]*list.Element)}
+}
+
+func (e *lruEvictor) add(key string) {
+	if elem, ok := e.nodes[key]; ok {
+		e.ll.MoveToFront(elem)
+		return
+	}
+	e.nodes[key] = e.ll.PushFront(key)
+}
+
+func (e *lruEvictor) access(key string) {
+	if elem, ok := e.nodes[key]; ok {
+		e.ll.MoveToFront(elem)
+	}
+}
+
+func (e *lruEvictor) remove(key string) {
+	if elem, ok := e.nodes[key]; ok {
+		e.ll.Remove(elem)
+		delete(e.nodes, key)
+	}
+}
+
+func (e *lruEvictor) victim() (string, bool) {
+	if elem := e.ll.Back(); elem != nil {
+		return elem.Value.(string), true
+	}
+	return "", false
+}
+
+// lfuEntry LFU堆中的条目
+type lfuEntry struct {
+	key   string
+	freq  uint64
+	tick  uint64
+	index int
+}
+
+// lfuHeap 按访问频率和最近访问时间排序的小顶堆
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *lfuHeap) Push(x interface{}) {
+	entry := x.(*lfuEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// lfuEvictor LFU淘汰器
+type lfuEvictor struct {
+	heap    lfuHeap
+	entries map[string]*lfuEntry
+	tick    uint64
+}
+
+func newLFUEvictor() *lfuEvictor {
+	return &lfuEvictor{entries: make(map[string]*lfuEntry)}
+}
+
+func (e *lfuEvictor) add(key string) {
+	e.tick++
+	if entry, ok := e.entries[key]; ok {
+		entry.freq++
+		entry.tick = e.tick
+		heap.Fix(&e.heap, entry.index)
+		return
+	}
+	entry := &lfuEntry{key: key, freq: 1, tick: e.tick}
+	e.entries[key] = entry
+	heap.Push(&e.heap, entry)
+}
+
+func (e *lfuEvictor) access(key string) {
+	if entry, ok := e.entries[key]; ok {
+		e.tick++
+		entry.freq++
+		entry.tick = e.tick
+		heap.Fix(&e.heap, entry.index)
+	}
+}
+
+func (e *lfuEvictor) remove(key string) {
+	if entry, ok := e.entries[key]; ok {
+		heap.Remove(&e.heap, entry.index)
+		delete(e.entries, key)
+	}
+}
+
+func (e *lfuEvictor) victim() (string, bool) {
+	if len(e.heap) == 0 {
+		return "", false
+	}
+	return e.heap[0].key, true
+}
+
+// tinyLFU分区
+const (
+	segmentWindow = iota
+	segmentProbation
+	segmentProtected
+)
+
+// tinyLFUNode W-TinyLFU中的节点
+type tinyLFUNode struct {
+	key     string
+	segment int
+}
+
+// tinyLFUEvictor W-TinyLFU淘汰器
+// 窗口区约占1%，超出窗口的项进入主区的试用区；主区为分段LRU，保护区约占主区的80%。
+// 淘汰时试用区中最新进入的候选项与最旧的淘汰候选比较估算频率，频率低者被淘汰
+type tinyLFUEvictor struct {
+	window    *list.List
+	probation *list.List
+	protected *list.List
+	nodes     map[string]*list.Element
+	sketch    *countMinSketch
+	capacity  int
+}
+
+func newTinyLFUEvictor(capacity int) *tinyLFUEvictor {
+	return &tinyLFUEvictor{
+		window:    list.New(),
+		probation: list.New(),
+		protected: list.New(),
+		nodes:     make(map[string]*list.Element),
+		sketch:    newCountMinSketch(capacity),
+		capacity:  capacity,
+	}
+}
+
+func (e *tinyLFUEvictor) add(key string) {
+	if _, ok := e.nodes[key]; ok {
+		e.access(key)
+		return
+	}
+	e.sketch.increment(key)
+	e.nodes[key] = e.window.PushFront(&tinyLFUNode{key: key, segment: segmentWindow})
+	e.balanceWindow()
+}
+
+func (e *tinyLFUEvictor) access(key string) {
+	elem, ok := e.nodes[key]
+	if !ok {
+		return
+	}
+	e.sketch.increment(key)
+
+	node := elem.Value.(*tinyLFUNode)
+	switch node.segment {
+	case segmentWindow:
+		e.window.MoveToFront(elem)
+	case segmentProbation:
+		// 试用区中再次命中的项晋升到保护区
+		e.probation.Remove(elem)
+		node.segment = segmentProtected
+		e.nodes[key] = e.protected.PushFront(node)
+		e.balanceProtected()
+	case segmentProtected:
+		e.protected.MoveToFront(elem)
+	}
+}
+
+// balanceWindow 窗口区超出容量的1%时将最旧的项移入试用区
+func (e *tinyLFUEvictor) balanceWindow() {
+	capacity := e.capacity
+	if capacity <= 0 {
+		capacity = len(e.nodes)
+	}
+	limit := capacity / 100
+	if limit < 1 {
+		limit = 1
+	}
+	for e.window.Len() > limit {
+		node := e.window.Remove(e.window.Back()).(*tinyLFUNode)
+		node.segment = segmentProbation
+		e.nodes[node.key] = e.probation.PushFront(node)
+	}
+}
+
+// balanceProtected 保护区超出主区80%时将最久未访问的项降级到试用区
+func (e *tinyLFUEvictor) balanceProtected() {
+	limit := (e.probation.Len() + e.protected.Len()) * 4 / 5
+	if limit < 1 {
+		limit = 1
+	}
+	for e.protected.Len() > limit {
+		node := e.protected.Remove(e.protected.Back()).(*tinyLFUNode)
+		node.segment = segmentProbation
+		e.nodes[node.key] = e.probation.PushFront(node)
+	}
+}
+
+func (e *tinyLFUEvictor) remove(key string) {
+	elem, ok := e.nodes[key]
+	if !ok {
+		return
+	}
+	e.listOf(elem.Value.(*tinyLFUNode).segment).Remove(elem)
+	delete(e.nodes, key)
+}
+
+func (e *tinyLFUEvictor) listOf(segment int) *list.List {
+	switch segment {
+	case segmentWindow:
+		return e.window
+	case segmentProbation:
+		return e.probation
+	default:
+		return e.protected
+	}
+}
+
+func (e *tinyLFUEvictor) victim() (string, bool) {
+	if e.probation.Len() >= 2 {
+		candidate := e.probation.Front().Value.(*tinyLFUNode)
+		victim := e.probation.Back().Value.(*tinyLFUNode)
+		if e.sketch.estimate(candidate.key) <= e.sketch.estimate(victim.key) {
+			return candidate.key, true
+		}
+		return victim.key, true
+	}
+
+	for _, l := range []*list.List{e.probation, e.protected, e.window} {
+		if elem := l.Back(); elem != nil {
+			return elem.Value.(*tinyLFUNode).key, true
+		}
+	}
+	return "", false
+}
+
+// countMinSketch 用于估算访问频率的Count-Min Sketch
+// 使用4行饱和于15的计数器，总增量达到采样上限后所有计数减半，使频率随时间衰减
+type countMinSketch struct {
+	rows    [4][]uint8
+	mask    uint64
+	seed    maphash.Seed
+	added   int
+	samples int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := 1024
+	for width < capacity {
+		width <<= 1
+	}
+
+	s := &countMinSketch{
+		mask:    uint64(width - 1),
+		seed:    maphash.MakeSeed(),
+		samples: width * 10,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch) indexes(key string) [4]uint64 {
+	h := maphash.String(s.seed, key)
+	lo, hi := h, h>>32|h<<32
+	var idx [4]uint64
+	for i := range idx {
+		idx[i] = (lo + uint64(i)*hi) & s.mask
+	}
+	return idx
+}
+
+func (s *countMinSketch) increment(key string) {
+	for i, idx := range s.indexes(key) {
+		if s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+
+	s.added++
+	if s.added >= s.samples {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	count := uint8(15)
+	for i, idx := range s.indexes(key) {
+		if v := s.rows[i][idx]; v < count {
+			count = v
+		}
+	}
+	return count
+}
+
+// reset 所有计数减半
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.added /= 2
+}
@@ -10,6 +10,8 @@ type CacheType string
 const (
 	// CacheTypeMemory 内存缓存
 	CacheTypeMemory CacheType = "memory"
+	// CacheTypeShardedMemory 分片内存缓存，分片数量为GOMAXPROCS的4倍
+	CacheTypeShardedMemory CacheType = "sharded_memory"
 	// CacheTypeRedis Redis缓存
 	CacheTypeRedis CacheType = "redis"
 	// CacheTypeMultiLevel 多级缓存
@@ -33,6 +35,20 @@ func NewCache(cacheType CacheType, options interface{}) (Cache, error) {
 		}
 		return NewMemoryCache(opts), nil
 
+	case CacheTypeShardedMemory:
+		// 创建分片内存缓存
+		var opts Options
+		if options != nil {
+			if o, ok := options.(Options); ok {
+				opts = o
+			} else {
+				return nil, fmt.Errorf("invalid options type for sharded memory cache: %T", options)
+			}
+		} else {
+			opts = DefaultOptions()
+		}
+		return NewShardedMemoryCache(0, opts), nil
+
 	case CacheTypeRedis:
 		// 创建Redis缓存
 		var opts RedisOptions
@@ -126,6 +142,16 @@ func (b *Builder) Build() (Cache, error) {
 		}
 		return NewMemoryCache(opts), nil
 
+	case CacheTypeShardedMemory:
+		if b.options == nil {
+			b.options = DefaultOptions()
+		}
+		opts, ok := b.options.(Options)
+		if !ok {
+			return nil, fmt.Errorf("invalid options type for sharded memory cache: %T", b.options)
+		}
+		return NewShardedMemoryCache(0, opts), nil
+
 	case CacheTypeRedis:
 		if b.options == nil {
 			b.options = DefaultRedisOptions()
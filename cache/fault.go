@@ -0,0 +1,640 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 故障注入规则额外支持的操作，InstrumentedCache不统计这些操作
+const (
+	OpScan             Operation = "scan"
+	OpSetNX            Operation = "set_nx"
+	OpSetXX            Operation = "set_xx"
+	OpGetSet           Operation = "get_set"
+	OpGetAndDelete     Operation = "get_and_delete"
+	OpGetVersioned     Operation = "get_versioned"
+	OpCompareAndSwap   Operation = "compare_and_swap"
+	OpCompareAndDelete Operation = "compare_and_delete"
+)
+
+// defaultFaultTimeout FaultTimeout规则未设置Latency且上下文没有截止时间时的等待时间
+const defaultFaultTimeout = time.Second
+
+// FaultKind 注入的故障类型
+type FaultKind int
+
+const (
+	// FaultLatency 延迟Latency加上[0, Jitter)的随机时间后正常执行操作
+	FaultLatency FaultKind = iota
+	// FaultError 不执行操作，直接返回错误
+	FaultError
+	// FaultTimeout 不执行操作，等待上下文结束或Latency后返回超时错误
+	FaultTimeout
+	// FaultPartition 模拟网络分区，不执行操作，立即返回错误
+	// 与FaultError的区别只在于错误类型和统计，通常与KeyPattern配合，在运行时添加和移除
+	FaultPartition
+)
+
+// String 返回故障类型名称
+func (k FaultKind) String() string {
+	switch k {
+	case FaultLatency:
+		return "latency"
+	case FaultError:
+		return "error"
+	case FaultTimeout:
+		return "timeout"
+	case FaultPartition:
+		return "partition"
+	default:
+		return fmt.Sprintf("FaultKind(%d)", int(k))
+	}
+}
+
+// FaultRule 故障注入规则
+type FaultRule struct {
+	// Name 规则名称，用于RemoveRule，可以为空
+	Name string
+
+	// Ops 规则作用的操作，为空时作用于所有操作
+	Ops []Operation
+
+	// KeyPattern 规则作用的键，语法与Redis的glob模式相同，为空时作用于所有键
+	// 批量操作中任一键匹配即注入故障；DeleteByPattern和Scan匹配模式本身，DeleteByTag匹配标签；
+	// 设置了KeyPattern的规则不作用于Flush
+	KeyPattern string
+
+	// Kind 故障类型
+	Kind FaultKind
+
+	// Rate 注入故障的概率，取值范围(0, 1]，小于等于0或大于1时视为1
+	Rate float64
+
+	// Latency FaultLatency的固定延迟；FaultTimeout的最长等待时间，为0时等待上下文结束，
+	// 上下文没有截止时间时等待1秒
+	Latency time.Duration
+
+	// Jitter FaultLatency在Latency之外增加的最大随机延迟
+	Jitter time.Duration
+
+	// Err FaultError和FaultPartition返回的错误，为空时返回*FaultInjectedError
+	Err error
+}
+
+// matches 判断规则是否作用于操作和键
+func (r *FaultRule) matches(op Operation, keys []string) bool {
+	if len(r.Ops) > 0 && !slices.Contains(r.Ops, op) {
+		return false
+	}
+	if r.KeyPattern == "" {
+		return true
+	}
+	for _, key := range keys {
+		if MatchPattern(r.KeyPattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// FaultInjectedError 注入的故障返回的错误
+// FaultTimeout的错误可以通过errors.Is(err, context.DeadlineExceeded)判断
+type FaultInjectedError struct {
+	Kind FaultKind
+	Op   Operation
+	Key  string
+}
+
+// Error 实现error接口
+func (e *FaultInjectedError) Error() string {
+	return fmt.Sprintf("cache: injected %s fault on %s %q", e.Kind, e.Op, e.Key)
+}
+
+// Unwrap FaultTimeout返回context.DeadlineExceeded
+func (e *FaultInjectedError) Unwrap() error {
+	if e.Kind == FaultTimeout {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// IsFaultInjected 判断错误是否由FaultCache注入
+func IsFaultInjected(err error) bool {
+	var injected *FaultInjectedError
+	return errors.As(err, &injected)
+}
+
+// FaultOptions 故障注入选项
+type FaultOptions struct {
+	// Seed 随机数种子，相同的种子和相同的调用顺序产生相同的故障序列
+	Seed int64
+
+	// Rules 初始规则，按顺序检查，所有匹配的规则都会生效，
+	// 延迟依次累加，遇到第一个返回错误的规则时停止
+	Rules []FaultRule
+
+	// Disabled 创建时不启用故障注入，之后可通过Enable启用
+	Disabled bool
+}
+
+// FaultStats 故障注入统计
+type FaultStats struct {
+	// Latencies 注入延迟的次数
+	Latencies uint64
+	// Errors 注入错误的次数
+	Errors uint64
+	// Timeouts 注入超时的次数
+	Timeouts uint64
+	// Partitions 因分区失败的次数
+	Partitions uint64
+}
+
+// FaultCache 注入故障的缓存包装器，用于在集成测试中验证缓存降级路径
+// 按操作和键模式注入延迟、错误、超时和分区，使用固定种子的随机数保证故障序列可复现
+// （并发调用时序列取决于调用顺序），规则可以在运行时修改，也可以整体启用或停用。
+//
+// 不会读取值的Get和GetWithTTL在注入错误时返回未命中。FaultCache实现了ValueDecoder、
+// BatchCache、KeyScanner、Loader和ConditionalCache，底层缓存不支持条件操作时返回ErrNotSupported。
+// FaultCache刻意不提供Unwrap，避免Structured、Conditional等查找底层缓存时绕过故障注入
+//
+// 示例：
+//
+//	remote := cache.NewFaultCache(redisCache, cache.FaultOptions{Seed: 1})
+//	multi, _ := cache.NewMultiLevelCache(local, remote, cache.MultiLevelOptions{
+//	    CircuitBreaker: &cache.CircuitBreakerOptions{FailureThreshold: 3},
+//	})
+//	remote.AddRule(cache.FaultRule{Ops: []cache.Operation{cache.OpGetInto}, Kind: cache.FaultError, Rate: 0.2})
+//	remote.Partition("user:*")
+//	// ... 验证降级行为 ...
+//	remote.Heal()
+type FaultCache struct {
+	cache Cache
+	loads loadGroup
+
+	enabled atomic.Bool
+
+	rulesMu sync.Mutex
+	rules   atomic.Pointer[[]FaultRule]
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	latencies  atomic.Uint64
+	errors     atomic.Uint64
+	timeouts   atomic.Uint64
+	partitions atomic.Uint64
+}
+
+// NewFaultCache 创建注入故障的缓存
+// 参数：
+//
+//	c: 被包装的缓存
+//	opts: 可选的故障注入选项，默认种子为0、没有规则且已启用
+func NewFaultCache(c Cache, opts ...FaultOptions) *FaultCache {
+	var options FaultOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	fc := &FaultCache{
+		cache: c,
+		rand:  rand.New(rand.NewSource(options.Seed)),
+	}
+	fc.enabled.Store(!options.Disabled)
+	rules := slices.Clone(options.Rules)
+	fc.rules.Store(&rules)
+	return fc
+}
+
+// Enable 启用故障注入
+func (c *FaultCache) Enable() {
+	c.enabled.Store(true)
+}
+
+// Disable 停用故障注入，规则保留，所有操作直接交给底层缓存
+func (c *FaultCache) Disable() {
+	c.enabled.Store(false)
+}
+
+// Enabled 返回是否启用了故障注入
+func (c *FaultCache) Enabled() bool {
+	return c.enabled.Load()
+}
+
+// Rules 返回当前规则的副本
+func (c *FaultCache) Rules() []FaultRule {
+	return slices.Clone(*c.rules.Load())
+}
+
+// SetRules 替换所有规则
+func (c *FaultCache) SetRules(rules ...FaultRule) {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	rules = slices.Clone(rules)
+	c.rules.Store(&rules)
+}
+
+// AddRule 追加规则
+func (c *FaultCache) AddRule(rule FaultRule) {
+	c.updateRules(func(rules []FaultRule) []FaultRule {
+		return append(rules, rule)
+	})
+}
+
+// RemoveRule 删除指定名称的规则，返回删除的规则数
+func (c *FaultCache) RemoveRule(name string) int {
+	var removed int
+	c.updateRules(func(rules []FaultRule) []FaultRule {
+		kept := slices.DeleteFunc(rules, func(rule FaultRule) bool { return rule.Name == name })
+		removed = len(rules) - len(kept)
+		return kept
+	})
+	return removed
+}
+
+// ClearRules 删除所有规则
+func (c *FaultCache) ClearRules() {
+	c.SetRules()
+}
+
+// Partition 添加分区规则，匹配pattern的键全部不可达，pattern为空时整个缓存不可达
+// 规则名称为"partition:"加上pattern，可以通过RemoveRule或Heal移除
+func (c *FaultCache) Partition(pattern string) {
+	c.AddRule(FaultRule{Name: "partition:" + pattern, KeyPattern: pattern, Kind: FaultPartition})
+}
+
+// Heal 移除所有分区规则
+func (c *FaultCache) Heal() {
+	c.updateRules(func(rules []FaultRule) []FaultRule {
+		return slices.DeleteFunc(rules, func(rule FaultRule) bool { return rule.Kind == FaultPartition })
+	})
+}
+
+// Reseed 重置随机数种子，之后的故障序列与使用该种子新建的FaultCache相同
+func (c *FaultCache) Reseed(seed int64) {
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	c.rand = rand.New(rand.NewSource(seed))
+}
+
+// FaultStats 返回故障注入统计
+func (c *FaultCache) FaultStats() FaultStats {
+	return FaultStats{
+		Latencies:  c.latencies.Load(),
+		Errors:     c.errors.Load(),
+		Timeouts:   c.timeouts.Load(),
+		Partitions: c.partitions.Load(),
+	}
+}
+
+// updateRules 以写时复制的方式修改规则
+func (c *FaultCache) updateRules(fn func(rules []FaultRule) []FaultRule) {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	rules := fn(slices.Clone(*c.rules.Load()))
+	c.rules.Store(&rules)
+}
+
+// roll 按概率判断是否注入故障
+func (c *FaultCache) roll(rate float64) bool {
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return c.rand.Float64() < rate
+}
+
+// jitter 返回[0, max)的随机延迟
+func (c *FaultCache) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return time.Duration(c.rand.Int63n(int64(max)))
+}
+
+// inject 按规则为一次操作注入故障，返回非nil错误时不应执行操作
+func (c *FaultCache) inject(ctx context.Context, op Operation, keys ...string) error {
+	if !c.enabled.Load() {
+		return nil
+	}
+
+	rules := *c.rules.Load()
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(op, keys) || !c.roll(rule.Rate) {
+			continue
+		}
+
+		switch rule.Kind {
+		case FaultLatency:
+			c.latencies.Add(1)
+			if err := sleepContext(ctx, rule.Latency+c.jitter(rule.Jitter)); err != nil {
+				return err
+			}
+		case FaultTimeout:
+			c.timeouts.Add(1)
+			return c.timeout(ctx, rule, op, keys)
+		case FaultPartition:
+			c.partitions.Add(1)
+			return rule.failure(op, keys)
+		default:
+			c.errors.Add(1)
+			return rule.failure(op, keys)
+		}
+	}
+	return nil
+}
+
+// timeout 等待上下文结束或规则的等待时间后返回超时错误，调用方主动取消时返回ctx.Err()
+func (c *FaultCache) timeout(ctx context.Context, rule *FaultRule, op Operation, keys []string) error {
+	wait := rule.Latency
+	if _, ok := ctx.Deadline(); !ok && wait <= 0 {
+		wait = defaultFaultTimeout
+	}
+
+	var err error
+	if wait > 0 {
+		err = sleepContext(ctx, wait)
+	} else {
+		<-ctx.Done()
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return &FaultInjectedError{Kind: FaultTimeout, Op: op, Key: firstKey(keys)}
+}
+
+// failure 返回FaultError或FaultPartition规则的错误
+func (r *FaultRule) failure(op Operation, keys []string) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	return &FaultInjectedError{Kind: r.Kind, Op: op, Key: firstKey(keys)}
+}
+
+// firstKey 返回错误信息中使用的键
+func firstKey(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// sleepContext 等待d或上下文结束，d小于等于0时立即返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Get 获取缓存值，注入错误时返回未命中
+func (c *FaultCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	if c.inject(ctx, OpGet, key) != nil {
+		return nil, false
+	}
+	return c.cache.Get(ctx, key)
+}
+
+// GetWithTTL 获取缓存值和剩余生存时间，注入错误时返回未命中
+func (c *FaultCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	if c.inject(ctx, OpGetWithTTL, key) != nil {
+		return nil, 0, false
+	}
+	return c.cache.GetWithTTL(ctx, key)
+}
+
+// GetInto 获取缓存值并解码到target
+func (c *FaultCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	if err := c.inject(ctx, OpGetInto, key); err != nil {
+		return false, err
+	}
+	return GetInto(ctx, c.cache, key, target)
+}
+
+// GetIntoWithTTL 获取缓存值和剩余生存时间并解码到target
+func (c *FaultCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	if err := c.inject(ctx, OpGetIntoWithTTL, key); err != nil {
+		return 0, false, err
+	}
+	return GetIntoWithTTL(ctx, c.cache, key, target)
+}
+
+// GetOrLoad 获取缓存值，未命中时加载并写入缓存
+// 先按OpGetOrLoad注入故障，之后的读取和写入分别按OpGet和OpSet注入故障
+func (c *FaultCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	if err := c.inject(ctx, OpGetOrLoad, key); err != nil {
+		return nil, err
+	}
+	return loadThrough(ctx, c, &c.loads, nil, key, ttl, loader)
+}
+
+// GetMany 批量获取缓存值
+func (c *FaultCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	if err := c.inject(ctx, OpGetMany, keys...); err != nil {
+		return nil, err
+	}
+	return GetMany(ctx, c.cache, keys)
+}
+
+// Set 设置缓存值
+func (c *FaultCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := c.inject(ctx, OpSet, key); err != nil {
+		return err
+	}
+	return c.cache.Set(ctx, key, value, ttl)
+}
+
+// SetItem 设置带完整选项的缓存项
+func (c *FaultCache) SetItem(ctx context.Context, key string, item *Item) error {
+	if err := c.inject(ctx, OpSetItem, key); err != nil {
+		return err
+	}
+	return c.cache.SetItem(ctx, key, item)
+}
+
+// SetMany 批量设置缓存项
+func (c *FaultCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := c.inject(ctx, OpSetMany, itemKeys(items)...); err != nil {
+		return err
+	}
+	return SetMany(ctx, c.cache, items)
+}
+
+// Delete 删除缓存
+func (c *FaultCache) Delete(ctx context.Context, key string) error {
+	if err := c.inject(ctx, OpDelete, key); err != nil {
+		return err
+	}
+	return c.cache.Delete(ctx, key)
+}
+
+// DeleteMany 批量删除缓存
+func (c *FaultCache) DeleteMany(ctx context.Context, keys []string) error {
+	if err := c.inject(ctx, OpDeleteMany, keys...); err != nil {
+		return err
+	}
+	return DeleteMany(ctx, c.cache, keys)
+}
+
+// DeleteByPattern 删除匹配模式的缓存
+func (c *FaultCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	if err := c.inject(ctx, OpDeleteByPattern, pattern); err != nil {
+		return err
+	}
+	return c.cache.DeleteByPattern(ctx, pattern)
+}
+
+// DeleteByTag 删除带有指定标签的缓存
+func (c *FaultCache) DeleteByTag(ctx context.Context, tag string) error {
+	if err := c.inject(ctx, OpDeleteByTag, tag); err != nil {
+		return err
+	}
+	return c.cache.DeleteByTag(ctx, tag)
+}
+
+// Scan 遍历匹配pattern的键，注入错误时产出一次错误后结束
+func (c *FaultCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := c.inject(ctx, OpScan, pattern); err != nil {
+			yield("", err)
+			return
+		}
+		for key, err := range scanCache(ctx, c.cache, pattern) {
+			if !yield(key, err) {
+				return
+			}
+		}
+	}
+}
+
+// Exists 检查键是否存在
+func (c *FaultCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := c.inject(ctx, OpExists, key); err != nil {
+		return false, err
+	}
+	return c.cache.Exists(ctx, key)
+}
+
+// Increment 增加数值
+func (c *FaultCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	if err := c.inject(ctx, OpIncrement, key); err != nil {
+		return 0, err
+	}
+	return c.cache.Increment(ctx, key, value)
+}
+
+// Decrement 减少数值
+func (c *FaultCache) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	if err := c.inject(ctx, OpDecrement, key); err != nil {
+		return 0, err
+	}
+	return c.cache.Decrement(ctx, key, value)
+}
+
+// Flush 清空缓存，只有未设置KeyPattern的规则作用于此操作
+func (c *FaultCache) Flush(ctx context.Context) error {
+	if err := c.inject(ctx, OpFlush); err != nil {
+		return err
+	}
+	return c.cache.Flush(ctx)
+}
+
+// Close 关闭底层缓存，不注入故障
+func (c *FaultCache) Close() error {
+	return c.cache.Close()
+}
+
+// conditional 注入故障后返回底层缓存的条件写入接口
+func (c *FaultCache) conditional(ctx context.Context, op Operation, key string) (ConditionalCache, error) {
+	if err := c.inject(ctx, op, key); err != nil {
+		return nil, err
+	}
+	cc, ok := Conditional(c.cache)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return cc, nil
+}
+
+// SetNX 仅在键不存在时写入
+func (c *FaultCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	cc, err := c.conditional(ctx, OpSetNX, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.SetNX(ctx, key, value, ttl)
+}
+
+// SetXX 仅在键已存在时写入
+func (c *FaultCache) SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	cc, err := c.conditional(ctx, OpSetXX, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.SetXX(ctx, key, value, ttl)
+}
+
+// GetSet 写入新值并返回旧值
+func (c *FaultCache) GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error) {
+	cc, err := c.conditional(ctx, OpGetSet, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.GetSet(ctx, key, value, ttl, old)
+}
+
+// GetAndDelete 删除键并返回删除前的值
+func (c *FaultCache) GetAndDelete(ctx context.Context, key string, target interface{}) (bool, error) {
+	cc, err := c.conditional(ctx, OpGetAndDelete, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.GetAndDelete(ctx, key, target)
+}
+
+// GetVersioned 返回值和当前版本
+func (c *FaultCache) GetVersioned(ctx context.Context, key string, target interface{}) (Version, bool, error) {
+	cc, err := c.conditional(ctx, OpGetVersioned, key)
+	if err != nil {
+		return "", false, err
+	}
+	return cc.GetVersioned(ctx, key, target)
+}
+
+// CompareAndSwap 仅在当前版本等于version时写入
+func (c *FaultCache) CompareAndSwap(ctx context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error) {
+	cc, err := c.conditional(ctx, OpCompareAndSwap, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.CompareAndSwap(ctx, key, version, value, ttl)
+}
+
+// CompareAndDelete 仅在当前版本等于version时删除
+func (c *FaultCache) CompareAndDelete(ctx context.Context, key string, version Version) (bool, error) {
+	cc, err := c.conditional(ctx, OpCompareAndDelete, key)
+	if err != nil {
+		return false, err
+	}
+	return cc.CompareAndDelete(ctx, key, version)
+}
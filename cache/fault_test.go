@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultCache_ErrorsByOperationAndPattern(t *testing.T) {
+	ctx := context.Background()
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Rules: []FaultRule{
+		{Ops: []Operation{OpSet, OpSetMany}, KeyPattern: "user:*", Kind: FaultError},
+	}})
+	defer c.Close()
+
+	err := c.Set(ctx, "user:1", "alice", time.Minute)
+	assert.True(t, IsFaultInjected(err))
+	var injected *FaultInjectedError
+	require.ErrorAs(t, err, &injected)
+	assert.Equal(t, FaultInjectedError{Kind: FaultError, Op: OpSet, Key: "user:1"}, *injected)
+
+	// 其他键和其他操作不受影响
+	require.NoError(t, c.Set(ctx, "order:1", "book", time.Minute))
+	require.NoError(t, c.Delete(ctx, "user:1"))
+
+	// 批量操作中任一键匹配即失败
+	err = c.SetMany(ctx, map[string]*Item{"order:2": {Value: 1}, "user:2": {Value: 2}})
+	assert.True(t, IsFaultInjected(err))
+	assert.Equal(t, uint64(2), c.FaultStats().Errors)
+}
+
+func TestFaultCache_CustomErrorAndReadMiss(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryCache()
+	require.NoError(t, inner.Set(ctx, "key", "value", time.Minute))
+
+	errDown := errors.New("connection refused")
+	c := NewFaultCache(inner, FaultOptions{Rules: []FaultRule{{Kind: FaultError, Err: errDown}}})
+
+	_, found := c.Get(ctx, "key")
+	assert.False(t, found, "Get reports a miss when a fault is injected")
+	var value string
+	_, err := c.GetInto(ctx, "key", &value)
+	assert.ErrorIs(t, err, errDown)
+	_, err = Keys(ctx, c, "*")
+	assert.ErrorIs(t, err, errDown)
+}
+
+func TestFaultCache_Latency(t *testing.T) {
+	ctx := context.Background()
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Rules: []FaultRule{
+		{Ops: []Operation{OpGet}, Kind: FaultLatency, Latency: 20 * time.Millisecond},
+	}})
+
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+	start := time.Now()
+	value, found := c.Get(ctx, "key")
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, uint64(1), c.FaultStats().Latencies)
+
+	// 延迟期间上下文结束时返回上下文错误
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	var target string
+	_, err := c.GetInto(timeoutCtx, "key", &target)
+	assert.NoError(t, err, "rule only applies to get")
+	c.SetRules(FaultRule{Kind: FaultLatency, Latency: time.Second})
+	_, err = c.GetInto(timeoutCtx, "key", &target)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, IsFaultInjected(err))
+}
+
+func TestFaultCache_Timeout(t *testing.T) {
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Rules: []FaultRule{{Kind: FaultTimeout}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := c.Set(ctx, "key", "value", time.Minute)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, IsFaultInjected(err))
+
+	// 没有截止时间时等待Latency
+	c.SetRules(FaultRule{Kind: FaultTimeout, Latency: 5 * time.Millisecond})
+	_, err = c.Exists(context.Background(), "key")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, uint64(2), c.FaultStats().Timeouts)
+}
+
+func TestFaultCache_PartitionAndHeal(t *testing.T) {
+	ctx := context.Background()
+	c := NewFaultCache(NewMemoryCache())
+
+	c.Partition("shard:a:*")
+	err := c.Set(ctx, "shard:a:1", 1, time.Minute)
+	var injected *FaultInjectedError
+	require.ErrorAs(t, err, &injected)
+	assert.Equal(t, FaultPartition, injected.Kind)
+	require.NoError(t, c.Set(ctx, "shard:b:1", 1, time.Minute))
+	assert.NoError(t, c.Flush(ctx), "pattern rules do not apply to flush")
+
+	c.Heal()
+	assert.Empty(t, c.Rules())
+	require.NoError(t, c.Set(ctx, "shard:a:1", 1, time.Minute))
+	assert.Equal(t, uint64(1), c.FaultStats().Partitions)
+}
+
+func TestFaultCache_RuntimeSwitching(t *testing.T) {
+	ctx := context.Background()
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Disabled: true})
+	c.AddRule(FaultRule{Name: "writes", Ops: []Operation{OpSet}, Kind: FaultError})
+
+	require.NoError(t, c.Set(ctx, "key", 1, time.Minute), "disabled cache passes through")
+	c.Enable()
+	assert.True(t, c.Enabled())
+	assert.Error(t, c.Set(ctx, "key", 1, time.Minute))
+	c.Disable()
+	require.NoError(t, c.Set(ctx, "key", 1, time.Minute))
+
+	c.Enable()
+	assert.Equal(t, 1, c.RemoveRule("writes"))
+	require.NoError(t, c.Set(ctx, "key", 1, time.Minute))
+}
+
+func TestFaultCache_DeterministicBySeed(t *testing.T) {
+	ctx := context.Background()
+	rules := []FaultRule{{Kind: FaultError, Rate: 0.3}}
+
+	run := func(c *FaultCache) []bool {
+		failures := make([]bool, 100)
+		for i := range failures {
+			failures[i] = c.Set(ctx, "key", i, time.Minute) != nil
+		}
+		return failures
+	}
+
+	first := run(NewFaultCache(NewMemoryCache(), FaultOptions{Seed: 42, Rules: rules}))
+	second := run(NewFaultCache(NewMemoryCache(), FaultOptions{Seed: 42, Rules: rules}))
+	assert.Equal(t, first, second)
+
+	var failed int
+	for _, f := range first {
+		if f {
+			failed++
+		}
+	}
+	assert.Greater(t, failed, 10)
+	assert.Less(t, failed, 50)
+
+	reseeded := NewFaultCache(NewMemoryCache(), FaultOptions{Seed: 7, Rules: rules})
+	reseeded.Reseed(42)
+	assert.Equal(t, first, run(reseeded))
+}
+
+func TestFaultCache_ConditionalOperations(t *testing.T) {
+	ctx := context.Background()
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Rules: []FaultRule{
+		{Ops: []Operation{OpCompareAndSwap}, Kind: FaultError},
+	}})
+
+	cc, ok := Conditional(c)
+	require.True(t, ok)
+	set, err := cc.SetNX(ctx, "key", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	version, _, err := cc.GetVersioned(ctx, "key", nil)
+	require.NoError(t, err)
+	_, err = cc.CompareAndSwap(ctx, "key", version, 2, time.Minute)
+	assert.True(t, IsFaultInjected(err))
+
+	namespace, err := NewNamespace(NewMemoryCache(), "ns")
+	require.NoError(t, err)
+	_, err = NewFaultCache(namespace).SetNX(ctx, "key", 1, 0)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestFaultCache_TripsMultiLevelCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	remote := NewFaultCache(NewMemoryCache(), FaultOptions{Seed: 1})
+	c := newBreakerCache(t, remote, CircuitBreakerOptions{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+	})
+
+	require.NoError(t, c.Set(ctx, "key", "before", time.Minute))
+
+	remote.Partition("")
+	_ = c.Set(ctx, "a", 1, time.Minute)
+	_ = c.Set(ctx, "b", 1, time.Minute)
+	require.Equal(t, CircuitOpen, c.CircuitState())
+
+	// 降级期间写入本地缓存，分区恢复后同步到远程缓存
+	require.NoError(t, c.Set(ctx, "key", "during", time.Minute))
+	remote.Heal()
+	assert.Eventually(t, func() bool {
+		value, found := remote.Get(ctx, "key")
+		return found && value == "during"
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return c.CircuitState() == CircuitClosed
+	}, time.Second, 5*time.Millisecond)
+}
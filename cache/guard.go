@@ -0,0 +1,291 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// GuardOptions 缓存穿透防护选项
+type GuardOptions struct {
+	// NegativeTTL 不存在标记的生存时间，默认30秒
+	// 通常远小于正常数据的生存时间，数据被创建后最多在此时间内仍被认为不存在，
+	// 创建数据时调用Set或Delete可以立即覆盖标记
+	NegativeTTL time.Duration
+
+	// Filter 可选的布隆过滤器，为nil时不启用
+	// 启用后GetOrLoad会先查询过滤器，过滤器判断一定不存在的键直接返回ErrNotFound，
+	// 因此使用前需要通过RebuildFilter装入所有已存在的键，创建数据时通过AddKeys或Set添加
+	Filter BloomFilter
+
+	// OnError 查询或更新布隆过滤器出错时的回调
+	// 查询出错时视为键可能存在，继续读取缓存和加载
+	OnError func(err error)
+}
+
+// DefaultGuardOptions 返回默认的缓存穿透防护选项
+func DefaultGuardOptions() GuardOptions {
+	return GuardOptions{
+		NegativeTTL: time.Second * 30,
+	}
+}
+
+// absentMarker 不存在标记
+// 使用字符串以便在所有序列化方式下保持原值
+const absentMarker = "\x00cache:absent\x00"
+
+// isAbsent 判断缓存值是否为不存在标记
+func isAbsent(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && s == absentMarker
+}
+
+// GuardedCache 防止缓存穿透的缓存包装器
+// loader返回ErrNotFound时写入带有独立短TTL的不存在标记，之后对该键的GetOrLoad直接返回ErrNotFound，
+// 不再反复查询数据源；配置布隆过滤器后，过滤器判断一定不存在的键连缓存也不会查询。
+//
+// 由GuardedCache管理的键应只通过它读写，直接通过底层缓存读取时会读到不存在标记
+//
+// 示例：
+//
+//	filter := cache.NewRedisBloomFilter(redisCache.GetClient(), "bloom:users", 1_000_000, 0.01)
+//	users := cache.NewGuardedCache(redisCache, cache.GuardOptions{NegativeTTL: 10 * time.Second, Filter: filter})
+//	users.RebuildFilter(ctx, func(add func(keys ...string) error) error {
+//	    return userRepo.EachID(ctx, func(id int64) error { return add(fmt.Sprintf("user:%d", id)) })
+//	})
+//	user, err := users.GetOrLoad(ctx, "user:123", time.Hour, func(ctx context.Context) (interface{}, error) {
+//	    user, err := userRepo.FindByID(ctx, 123)
+//	    if errors.Is(err, gorm.ErrRecordNotFound) {
+//	        return nil, cache.ErrNotFound
+//	    }
+//	    return user, err
+//	})
+type GuardedCache struct {
+	cache   Cache
+	options GuardOptions
+	loads   loadGroup
+}
+
+// NewGuardedCache 创建防止缓存穿透的缓存
+// 参数：
+//
+//	c: 底层缓存，可以是MemoryCache、RedisCache、MultiLevelCache等任意实现
+//	opts: 防护选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewGuardedCache(c Cache, opts ...GuardOptions) *GuardedCache {
+	options := DefaultGuardOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.NegativeTTL <= 0 {
+		options.NegativeTTL = DefaultGuardOptions().NegativeTTL
+	}
+
+	return &GuardedCache{cache: c, options: options}
+}
+
+// Cache 返回底层缓存
+func (c *GuardedCache) Cache() Cache {
+	return c.cache
+}
+
+// Get 获取缓存值，不存在标记视为未命中
+func (c *GuardedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	value, found := c.cache.Get(ctx, key)
+	if !found || isAbsent(value) {
+		return nil, false
+	}
+	return value, true
+}
+
+// GetWithTTL 获取缓存值和剩余生存时间，不存在标记视为未命中
+func (c *GuardedCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	value, ttl, found := c.cache.GetWithTTL(ctx, key)
+	if !found || isAbsent(value) {
+		return nil, 0, false
+	}
+	return value, ttl, true
+}
+
+// GetOrLoad 获取缓存值，未命中时调用loader加载并写入缓存，实现Loader接口
+// 返回ErrNotFound的情况：
+//   - 布隆过滤器判断键一定不存在，此时不读取缓存也不调用loader
+//   - 缓存中有未过期的不存在标记
+//   - loader返回ErrNotFound，此时写入生存时间为NegativeTTL的不存在标记
+//
+// 同一实例内同一键的并发未命中只会调用一次loader
+func (c *GuardedCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	if !c.mightContain(ctx, key) {
+		return nil, ErrNotFound
+	}
+
+	if value, found := c.cache.Get(ctx, key); found {
+		return c.unwrap(value)
+	}
+
+	return c.loads.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		if value, found := c.cache.Get(ctx, key); found {
+			return c.unwrap(value)
+		}
+
+		value, err := loader(ctx)
+		if errors.Is(err, ErrNotFound) {
+			if err := c.SetAbsent(ctx, key); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.cache.Set(ctx, key, value, ttl); err != nil {
+			return value, err
+		}
+		return value, nil
+	})
+}
+
+// unwrap 将缓存值转换为GetOrLoad的返回值
+func (c *GuardedCache) unwrap(value interface{}) (interface{}, error) {
+	if isAbsent(value) {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// mightContain 查询布隆过滤器，未配置或查询出错时返回true
+func (c *GuardedCache) mightContain(ctx context.Context, key string) bool {
+	if c.options.Filter == nil {
+		return true
+	}
+	ok, err := c.options.Filter.MightContain(ctx, key)
+	if err != nil {
+		c.reportError(err)
+		return true
+	}
+	return ok
+}
+
+// SetAbsent 写入不存在标记，生存时间为NegativeTTL
+func (c *GuardedCache) SetAbsent(ctx context.Context, key string) error {
+	return c.cache.Set(ctx, key, absentMarker, c.options.NegativeTTL)
+}
+
+// AddKeys 将已存在的键添加到布隆过滤器，未配置过滤器时不做任何事
+// 数据源中创建新数据时应调用此方法，否则在下次重建之前该键会被过滤器拒绝
+func (c *GuardedCache) AddKeys(ctx context.Context, keys ...string) error {
+	if c.options.Filter == nil || len(keys) == 0 {
+		return nil
+	}
+	return c.options.Filter.Add(ctx, keys...)
+}
+
+// RebuildFilter 用source提供的全部键重建布隆过滤器，未配置过滤器时返回错误
+func (c *GuardedCache) RebuildFilter(ctx context.Context, source func(add func(keys ...string) error) error) error {
+	if c.options.Filter == nil {
+		return fmt.Errorf("guarded cache has no bloom filter")
+	}
+	return c.options.Filter.Rebuild(ctx, source)
+}
+
+// Set 设置缓存值，同时将键添加到布隆过滤器
+// 过滤器更新失败时不写入缓存并返回错误，避免已存在的键被过滤器拒绝
+func (c *GuardedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := c.AddKeys(ctx, key); err != nil {
+		return err
+	}
+	return c.cache.Set(ctx, key, value, ttl)
+}
+
+// SetItem 设置缓存项，同时将键添加到布隆过滤器
+func (c *GuardedCache) SetItem(ctx context.Context, key string, item *Item) error {
+	if err := c.AddKeys(ctx, key); err != nil {
+		return err
+	}
+	return c.cache.SetItem(ctx, key, item)
+}
+
+// GetMany 批量获取缓存值，不存在标记视为未命中
+func (c *GuardedCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	values, err := GetMany(ctx, c.cache, keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if isAbsent(value) {
+			delete(values, key)
+		}
+	}
+	return values, nil
+}
+
+// SetMany 批量设置缓存项，同时将键添加到布隆过滤器
+func (c *GuardedCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
+	if err := c.AddKeys(ctx, itemKeys(items)...); err != nil {
+		return err
+	}
+	return SetMany(ctx, c.cache, items)
+}
+
+// DeleteMany 批量删除缓存项
+func (c *GuardedCache) DeleteMany(ctx context.Context, keys []string) error {
+	return DeleteMany(ctx, c.cache, keys)
+}
+
+// Delete 删除缓存项，包括不存在标记
+func (c *GuardedCache) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// DeleteByPattern 根据模式删除缓存项
+func (c *GuardedCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	return c.cache.DeleteByPattern(ctx, pattern)
+}
+
+// Scan 遍历底层缓存中匹配模式的键，实现KeyScanner接口
+// 不存在标记同样保存为键，因此也会被遍历到
+func (c *GuardedCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return scanCache(ctx, c.cache, pattern)
+}
+
+// DeleteByTag 根据标签删除缓存项
+func (c *GuardedCache) DeleteByTag(ctx context.Context, tag string) error {
+	return c.cache.DeleteByTag(ctx, tag)
+}
+
+// Exists 检查键是否存在，不存在标记视为不存在
+func (c *GuardedCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, found := c.Get(ctx, key)
+	return found, nil
+}
+
+// Increment 增加计数器值
+func (c *GuardedCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	return c.cache.Increment(ctx, key, value)
+}
+
+// Decrement 减少计数器值
+func (c *GuardedCache) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	return c.cache.Decrement(ctx, key, value)
+}
+
+// Flush 清空缓存，不影响布隆过滤器
+func (c *GuardedCache) Flush(ctx context.Context) error {
+	return c.cache.Flush(ctx)
+}
+
+// Close 关闭底层缓存
+func (c *GuardedCache) Close() error {
+	return c.cache.Close()
+}
+
+func (c *GuardedCache) reportError(err error) {
+	if c.options.OnError != nil {
+		c.options.OnError(err)
+	}
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardedCache_NegativeCaching(t *testing.T) {
+	for name, backend := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			c := NewGuardedCache(backend, GuardOptions{NegativeTTL: time.Minute})
+			ctx := context.Background()
+
+			var calls atomic.Int32
+			loader := func(ctx context.Context) (interface{}, error) {
+				calls.Add(1)
+				return nil, fmt.Errorf("user 404: %w", ErrNotFound)
+			}
+
+			for i := 0; i < 3; i++ {
+				_, err := c.GetOrLoad(ctx, "user:404", time.Hour, loader)
+				assert.ErrorIs(t, err, ErrNotFound)
+			}
+			assert.Equal(t, int32(1), calls.Load(), "absent marker should be served from cache")
+
+			// 不存在标记对普通读取不可见
+			_, found := c.Get(ctx, "user:404")
+			assert.False(t, found)
+			exists, err := c.Exists(ctx, "user:404")
+			require.NoError(t, err)
+			assert.False(t, exists)
+			values, err := c.GetMany(ctx, []string{"user:404"})
+			require.NoError(t, err)
+			assert.Empty(t, values)
+			_, found, err = NewTypedCache[string](c).Get(ctx, "user:404")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			// 创建数据后写入缓存覆盖标记
+			require.NoError(t, c.Set(ctx, "user:404", "created", time.Hour))
+			value, err := c.GetOrLoad(ctx, "user:404", time.Hour, loader)
+			require.NoError(t, err)
+			assert.Equal(t, "created", value)
+		})
+	}
+}
+
+func TestGuardedCache_NegativeTTL(t *testing.T) {
+	backend := NewMemoryCache()
+	defer backend.Close()
+	c := NewGuardedCache(backend, GuardOptions{NegativeTTL: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			return nil, ErrNotFound
+		}
+		return "found", nil
+	}
+
+	_, err := c.GetOrLoad(ctx, "key", time.Hour, loader)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, ttl, found := backend.GetWithTTL(ctx, "key")
+	require.True(t, found)
+	assert.LessOrEqual(t, ttl, 20*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+	value, err := c.GetOrLoad(ctx, "key", time.Hour, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "found", value)
+	_, ttl, _ = backend.GetWithTTL(ctx, "key")
+	assert.Greater(t, ttl, time.Minute, "loaded value should use the regular ttl")
+}
+
+func TestGuardedCache_LoaderErrorNotCached(t *testing.T) {
+	c := NewGuardedCache(NewMemoryCache())
+	defer c.Close()
+	ctx := context.Background()
+
+	failing := errors.New("database down")
+	_, err := c.GetOrLoad(ctx, "key", time.Hour, func(ctx context.Context) (interface{}, error) {
+		return nil, failing
+	})
+	assert.ErrorIs(t, err, failing)
+
+	_, found := c.Cache().Get(ctx, "key")
+	assert.False(t, found, "only ErrNotFound should produce an absent marker")
+}
+
+func TestGuardedCache_BloomFilter(t *testing.T) {
+	ctx := context.Background()
+	filters := map[string]BloomFilter{
+		"memory": NewMemoryBloomFilter(1000, 0.01),
+		"redis":  NewRedisBloomFilter(newMiniRedisCache(t).GetClient(), "bloom:users", 1000, 0.01),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			c := NewGuardedCache(NewMemoryCache(), GuardOptions{Filter: filter})
+			defer c.Close()
+
+			require.NoError(t, c.RebuildFilter(ctx, func(add func(keys ...string) error) error {
+				return add("user:1", "user:2")
+			}))
+
+			var calls atomic.Int32
+			loader := func(ctx context.Context) (interface{}, error) {
+				calls.Add(1)
+				return "loaded", nil
+			}
+
+			// 过滤器拒绝的键不读取缓存也不调用loader
+			_, err := c.GetOrLoad(ctx, "user:999", time.Hour, loader)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Equal(t, int32(0), calls.Load())
+
+			value, err := c.GetOrLoad(ctx, "user:1", time.Hour, loader)
+			require.NoError(t, err)
+			assert.Equal(t, "loaded", value)
+			assert.Equal(t, int32(1), calls.Load())
+
+			// 新创建的键通过AddKeys或Set加入过滤器
+			require.NoError(t, c.AddKeys(ctx, "user:3"))
+			_, err = c.GetOrLoad(ctx, "user:3", time.Hour, loader)
+			require.NoError(t, err)
+			require.NoError(t, c.Set(ctx, "user:4", "set", time.Hour))
+			ok, err := filter.MightContain(ctx, "user:4")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			// 重建后只保留source提供的键
+			require.NoError(t, c.RebuildFilter(ctx, func(add func(keys ...string) error) error {
+				return add("user:2")
+			}))
+			_, err = c.GetOrLoad(ctx, "user:3", time.Hour, loader)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestGuardedCache_FilterErrorFailsOpen(t *testing.T) {
+	mr := newMiniRedisCache(t)
+	filter := NewRedisBloomFilter(mr.GetClient(), "bloom", 100, 0.01)
+	require.NoError(t, mr.GetClient().Close())
+
+	var reported atomic.Int32
+	c := NewGuardedCache(NewMemoryCache(), GuardOptions{
+		Filter:  filter,
+		OnError: func(err error) { reported.Add(1) },
+	})
+	defer c.Close()
+
+	value, err := c.GetOrLoad(context.Background(), "key", time.Hour, func(ctx context.Context) (interface{}, error) {
+		return "loaded", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, int32(1), reported.Load())
+}
+
+func TestGuardedCache_NoFilter(t *testing.T) {
+	c := NewGuardedCache(NewMemoryCache())
+	defer c.Close()
+
+	assert.NoError(t, c.AddKeys(context.Background(), "key"))
+	assert.Error(t, c.RebuildFilter(context.Background(), func(add func(keys ...string) error) error { return nil }))
+}
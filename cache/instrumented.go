@@ -0,0 +1,749 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guanzhenxing/go-snap/logger"
+)
+
+// Operation 缓存操作名称
+type Operation string
+
+// 缓存操作
+const (
+	OpGet             Operation = "get"
+	OpGetWithTTL      Operation = "get_with_ttl"
+	OpGetInto         Operation = "get_into"
+	OpGetIntoWithTTL  Operation = "get_into_with_ttl"
+	OpGetOrLoad       Operation = "get_or_load"
+	OpGetMany         Operation = "get_many"
+	OpSet             Operation = "set"
+	OpSetItem         Operation = "set_item"
+	OpSetMany         Operation = "set_many"
+	OpDelete          Operation = "delete"
+	OpDeleteMany      Operation = "delete_many"
+	OpDeleteByPattern Operation = "delete_by_pattern"
+	OpDeleteByTag     Operation = "delete_by_tag"
+	OpExists          Operation = "exists"
+	OpIncrement       Operation = "increment"
+	OpDecrement       Operation = "decrement"
+	OpFlush           Operation = "flush"
+)
+
+// allOperations 所有被统计的操作
+var allOperations = []Operation{
+	OpGet, OpGetWithTTL, OpGetInto, OpGetIntoWithTTL, OpGetOrLoad, OpGetMany,
+	OpSet, OpSetItem, OpSetMany,
+	OpDelete, OpDeleteMany, OpDeleteByPattern, OpDeleteByTag,
+	OpExists, OpIncrement, OpDecrement, OpFlush,
+}
+
+// OperationInfo 传给钩子的操作信息
+type OperationInfo struct {
+	// Op 操作名称
+	Op Operation
+	// Key 单键操作的键；DeleteByPattern时为模式，DeleteByTag时为标签
+	Key string
+	// Keys 批量操作的键
+	Keys []string
+	// Start 操作开始时间
+	Start time.Time
+
+	// 以下字段在操作完成后填充
+
+	// Duration 操作耗时
+	Duration time.Duration
+	// Hits 读取操作命中的键数
+	Hits int
+	// Misses 读取操作未命中的键数
+	Misses int
+	// Err 操作返回的错误
+	Err error
+}
+
+// Hook 缓存操作钩子，用于接入日志、追踪等横切逻辑
+type Hook interface {
+	// BeforeOperation 在操作执行前调用
+	// 返回的上下文会传给底层缓存和AfterOperation，可用于开始追踪span
+	BeforeOperation(ctx context.Context, op *OperationInfo) context.Context
+
+	// AfterOperation 在操作完成后调用，此时op中的结果字段已经填充
+	AfterOperation(ctx context.Context, op *OperationInfo)
+}
+
+// HookFuncs 由函数组成的钩子，未设置的函数会被忽略
+type HookFuncs struct {
+	Before func(ctx context.Context, op *OperationInfo) context.Context
+	After  func(ctx context.Context, op *OperationInfo)
+}
+
+// BeforeOperation 实现Hook接口
+func (h HookFuncs) BeforeOperation(ctx context.Context, op *OperationInfo) context.Context {
+	if h.Before == nil {
+		return ctx
+	}
+	return h.Before(ctx, op)
+}
+
+// AfterOperation 实现Hook接口
+func (h HookFuncs) AfterOperation(ctx context.Context, op *OperationInfo) {
+	if h.After != nil {
+		h.After(ctx, op)
+	}
+}
+
+// InstrumentOptions 缓存统计选项
+type InstrumentOptions struct {
+	// Hooks 缓存操作钩子，按顺序调用BeforeOperation，按相反顺序调用AfterOperation
+	Hooks []Hook
+
+	// LatencyBuckets 延迟直方图的桶上界，需按升序排列
+	LatencyBuckets []time.Duration
+
+	// PrefixFunc 从键中提取前缀，用于按前缀分组统计，默认取第一个冒号之前的部分
+	PrefixFunc func(key string) string
+
+	// MaxPrefixes 单独统计的前缀数量上限，默认100
+	// 超过后新出现的前缀计入OtherPrefix，避免键设计不当时统计数据无限增长
+	MaxPrefixes int
+}
+
+// OtherPrefix 超过MaxPrefixes后新出现的前缀的统计分组
+const OtherPrefix = "_other"
+
+// DefaultInstrumentOptions 返回默认的缓存统计选项
+func DefaultInstrumentOptions() InstrumentOptions {
+	return InstrumentOptions{
+		LatencyBuckets: []time.Duration{
+			time.Microsecond * 100,
+			time.Microsecond * 500,
+			time.Millisecond,
+			time.Millisecond * 5,
+			time.Millisecond * 10,
+			time.Millisecond * 50,
+			time.Millisecond * 100,
+			time.Millisecond * 500,
+			time.Second,
+		},
+		PrefixFunc:  KeyPrefix,
+		MaxPrefixes: 100,
+	}
+}
+
+// KeyPrefix 返回键中第一个冒号之前的部分，没有冒号时返回整个键
+func KeyPrefix(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// Metrics 缓存统计数据
+type Metrics struct {
+	// Hits 读取命中的键数
+	Hits uint64
+	// Misses 读取未命中的键数
+	Misses uint64
+	// Sets 写入的键数
+	Sets uint64
+	// Deletes 按键删除的键数，不包括按模式、标签删除和清空
+	Deletes uint64
+	// Errors 返回错误的操作次数
+	Errors uint64
+	// Evictions 底层缓存因容量淘汰的项数，底层缓存不提供Stats时为0
+	Evictions uint64
+	// Expirations 底层缓存过期清理的项数，底层缓存不提供Stats时为0
+	Expirations uint64
+	// Latencies 各操作的延迟直方图，只包含执行过的操作
+	Latencies map[Operation]LatencyHistogram
+	// Prefixes 按键前缀分组的统计
+	Prefixes map[string]PrefixMetrics
+}
+
+// HitRate 返回命中率，没有读取时返回0
+func (m Metrics) HitRate() float64 {
+	return hitRate(m.Hits, m.Misses)
+}
+
+// PrefixMetrics 单个键前缀的统计数据
+type PrefixMetrics struct {
+	Hits    uint64
+	Misses  uint64
+	Sets    uint64
+	Deletes uint64
+	Errors  uint64
+}
+
+// HitRate 返回命中率，没有读取时返回0
+func (m PrefixMetrics) HitRate() float64 {
+	return hitRate(m.Hits, m.Misses)
+}
+
+func hitRate(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// LatencyHistogram 延迟直方图
+type LatencyHistogram struct {
+	// Count 操作次数
+	Count uint64
+	// Sum 总耗时
+	Sum time.Duration
+	// Max 最大耗时
+	Max time.Duration
+	// Buckets 各桶的累计次数，即耗时不超过UpperBound的操作次数
+	// 超过最大上界的操作只计入Count
+	Buckets []LatencyBucket
+}
+
+// Mean 返回平均耗时
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// LatencyBucket 直方图的桶
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// statsProvider 提供容量和过期统计的缓存
+type statsProvider interface {
+	Stats() Stats
+}
+
+// InstrumentedCache 带统计和钩子的缓存包装器
+// 可以包装任意Cache实现，记录命中、未命中、写入、删除、错误次数，
+// 各操作的延迟直方图和按键前缀分组的统计，并在每次操作前后调用钩子。
+// 底层缓存实现了ValueDecoder、Loader或BatchCache时，包装后仍保留这些能力
+//
+// 示例：
+//
+//	c := cache.NewInstrumentedCache(cache.NewMemoryCache(), cache.InstrumentOptions{
+//	    Hooks: []cache.Hook{cache.NewLoggingHook(log, cache.LoggingHookOptions{SlowThreshold: 10 * time.Millisecond})},
+//	})
+//	c.Set(ctx, "user:1", user, time.Minute)
+//	c.Get(ctx, "user:1")
+//	fmt.Println(c.Metrics().HitRate(), c.Metrics().Prefixes["user"].Hits)
+type InstrumentedCache struct {
+	cache   Cache
+	options InstrumentOptions
+	hooks   []Hook
+
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+	errors  atomic.Uint64
+
+	latencies map[Operation]*latencyRecorder
+
+	prefixMu sync.RWMutex
+	prefixes map[string]*prefixCounters
+}
+
+// NewInstrumentedCache 创建带统计和钩子的缓存
+// 参数：
+//
+//	c: 被包装的缓存
+//	opts: 可选的统计选项，未设置的字段使用默认值
+func NewInstrumentedCache(c Cache, opts ...InstrumentOptions) *InstrumentedCache {
+	options := DefaultInstrumentOptions()
+	if len(opts) > 0 {
+		opt := opts[0]
+		options.Hooks = opt.Hooks
+		if len(opt.LatencyBuckets) > 0 {
+			options.LatencyBuckets = opt.LatencyBuckets
+		}
+		if opt.PrefixFunc != nil {
+			options.PrefixFunc = opt.PrefixFunc
+		}
+		if opt.MaxPrefixes > 0 {
+			options.MaxPrefixes = opt.MaxPrefixes
+		}
+	}
+
+	ic := &InstrumentedCache{
+		cache:     c,
+		options:   options,
+		hooks:     append([]Hook(nil), options.Hooks...),
+		latencies: make(map[Operation]*latencyRecorder, len(allOperations)),
+		prefixes:  make(map[string]*prefixCounters),
+	}
+	for _, op := range allOperations {
+		ic.latencies[op] = newLatencyRecorder(options.LatencyBuckets)
+	}
+	return ic
+}
+
+// Use 追加钩子，应在缓存开始使用之前调用
+func (c *InstrumentedCache) Use(hooks ...Hook) {
+	c.hooks = append(c.hooks, hooks...)
+}
+
+// Unwrap 返回被包装的缓存
+func (c *InstrumentedCache) Unwrap() Cache {
+	return c.cache
+}
+
+// Metrics 返回统计数据快照
+func (c *InstrumentedCache) Metrics() Metrics {
+	m := Metrics{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Sets:      c.sets.Load(),
+		Deletes:   c.deletes.Load(),
+		Errors:    c.errors.Load(),
+		Latencies: make(map[Operation]LatencyHistogram),
+		Prefixes:  make(map[string]PrefixMetrics),
+	}
+	if sp, ok := c.cache.(statsProvider); ok {
+		stats := sp.Stats()
+		m.Evictions = stats.Evictions
+		m.Expirations = stats.Expirations
+	}
+
+	for op, recorder := range c.latencies {
+		if h := recorder.snapshot(); h.Count > 0 {
+			m.Latencies[op] = h
+		}
+	}
+
+	c.prefixMu.RLock()
+	for prefix, counters := range c.prefixes {
+		m.Prefixes[prefix] = counters.snapshot()
+	}
+	c.prefixMu.RUnlock()
+
+	return m
+}
+
+// Get 获取缓存值
+func (c *InstrumentedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	op := &OperationInfo{Op: OpGet, Key: key}
+	ctx = c.before(ctx, op)
+	value, found := c.cache.Get(ctx, key)
+	c.recordRead(op, key, found)
+	c.after(ctx, op)
+	return value, found
+}
+
+// GetWithTTL 获取缓存值和剩余生存时间
+func (c *InstrumentedCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	op := &OperationInfo{Op: OpGetWithTTL, Key: key}
+	ctx = c.before(ctx, op)
+	value, ttl, found := c.cache.GetWithTTL(ctx, key)
+	c.recordRead(op, key, found)
+	c.after(ctx, op)
+	return value, ttl, found
+}
+
+// GetInto 获取缓存值并解码到target
+func (c *InstrumentedCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	op := &OperationInfo{Op: OpGetInto, Key: key}
+	ctx = c.before(ctx, op)
+	found, err := GetInto(ctx, c.cache, key, target)
+	op.Err = err
+	if err == nil {
+		c.recordRead(op, key, found)
+	}
+	c.after(ctx, op)
+	return found, err
+}
+
+// GetIntoWithTTL 获取缓存值和剩余生存时间并解码到target
+func (c *InstrumentedCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	op := &OperationInfo{Op: OpGetIntoWithTTL, Key: key}
+	ctx = c.before(ctx, op)
+	ttl, found, err := GetIntoWithTTL(ctx, c.cache, key, target)
+	op.Err = err
+	if err == nil {
+		c.recordRead(op, key, found)
+	}
+	c.after(ctx, op)
+	return ttl, found, err
+}
+
+// GetOrLoad 获取缓存值，未命中时加载并写入缓存
+// 由本次调用执行加载时计为未命中，其余情况计为命中
+func (c *InstrumentedCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	op := &OperationInfo{Op: OpGetOrLoad, Key: key}
+	ctx = c.before(ctx, op)
+
+	var loaded atomic.Bool
+	value, err := GetOrLoad(ctx, c.cache, key, ttl, func(ctx context.Context) (interface{}, error) {
+		loaded.Store(true)
+		return loader(ctx)
+	})
+	op.Err = err
+	if err == nil {
+		c.recordRead(op, key, !loaded.Load())
+		if loaded.Load() {
+			c.recordSet(key)
+		}
+	}
+	c.after(ctx, op)
+	return value, err
+}
+
+// GetMany 批量获取缓存值
+func (c *InstrumentedCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	op := &OperationInfo{Op: OpGetMany, Keys: keys}
+	ctx = c.before(ctx, op)
+	values, err := GetMany(ctx, c.cache, keys)
+	op.Err = err
+	if err == nil {
+		for _, key := range keys {
+			_, found := values[key]
+			c.recordRead(op, key, found)
+		}
+	}
+	c.after(ctx, op)
+	return values, err
+}
+
+// Set 设置缓存值
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	op := &OperationInfo{Op: OpSet, Key: key}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.Set(ctx, key, value, ttl)
+	if op.Err == nil {
+		c.recordSet(key)
+	}
+	c.after(ctx, op)
+	return op.Err
+}
+
+// SetItem 设置缓存项
+func (c *InstrumentedCache) SetItem(ctx context.Context, key string, item *Item) error {
+	op := &OperationInfo{Op: OpSetItem, Key: key}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.SetItem(ctx, key, item)
+	if op.Err == nil {
+		c.recordSet(key)
+	}
+	c.after(ctx, op)
+	return op.Err
+}
+
+// SetMany 批量设置缓存项
+func (c *InstrumentedCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	op := &OperationInfo{Op: OpSetMany, Keys: itemKeys(items)}
+	ctx = c.before(ctx, op)
+	op.Err = SetMany(ctx, c.cache, items)
+	if op.Err == nil {
+		for _, key := range op.Keys {
+			c.recordSet(key)
+		}
+	}
+	c.after(ctx, op)
+	return op.Err
+}
+
+// Delete 删除缓存项
+func (c *InstrumentedCache) Delete(ctx context.Context, key string) error {
+	op := &OperationInfo{Op: OpDelete, Key: key}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.Delete(ctx, key)
+	if op.Err == nil {
+		c.recordDelete(key)
+	}
+	c.after(ctx, op)
+	return op.Err
+}
+
+// DeleteMany 批量删除缓存项
+func (c *InstrumentedCache) DeleteMany(ctx context.Context, keys []string) error {
+	op := &OperationInfo{Op: OpDeleteMany, Keys: keys}
+	ctx = c.before(ctx, op)
+	op.Err = DeleteMany(ctx, c.cache, keys)
+	if op.Err == nil {
+		for _, key := range keys {
+			c.recordDelete(key)
+		}
+	}
+	c.after(ctx, op)
+	return op.Err
+}
+
+// DeleteByPattern 根据模式删除缓存项
+func (c *InstrumentedCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	op := &OperationInfo{Op: OpDeleteByPattern, Key: pattern}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.DeleteByPattern(ctx, pattern)
+	c.after(ctx, op)
+	return op.Err
+}
+
+// DeleteByTag 根据标签删除缓存项
+func (c *InstrumentedCache) DeleteByTag(ctx context.Context, tag string) error {
+	op := &OperationInfo{Op: OpDeleteByTag, Key: tag}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.DeleteByTag(ctx, tag)
+	c.after(ctx, op)
+	return op.Err
+}
+
+// Exists 检查键是否存在
+func (c *InstrumentedCache) Exists(ctx context.Context, key string) (bool, error) {
+	op := &OperationInfo{Op: OpExists, Key: key}
+	ctx = c.before(ctx, op)
+	exists, err := c.cache.Exists(ctx, key)
+	op.Err = err
+	c.after(ctx, op)
+	return exists, err
+}
+
+// Increment 增加计数器值
+func (c *InstrumentedCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	op := &OperationInfo{Op: OpIncrement, Key: key}
+	ctx = c.before(ctx, op)
+	result, err := c.cache.Increment(ctx, key, value)
+	op.Err = err
+	c.after(ctx, op)
+	return result, err
+}
+
+// Decrement 减少计数器值
+func (c *InstrumentedCache) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	op := &OperationInfo{Op: OpDecrement, Key: key}
+	ctx = c.before(ctx, op)
+	result, err := c.cache.Decrement(ctx, key, value)
+	op.Err = err
+	c.after(ctx, op)
+	return result, err
+}
+
+// Flush 清空缓存
+func (c *InstrumentedCache) Flush(ctx context.Context) error {
+	op := &OperationInfo{Op: OpFlush}
+	ctx = c.before(ctx, op)
+	op.Err = c.cache.Flush(ctx)
+	c.after(ctx, op)
+	return op.Err
+}
+
+// Close 关闭被包装的缓存
+func (c *InstrumentedCache) Close() error {
+	return c.cache.Close()
+}
+
+// before 记录开始时间并调用钩子
+func (c *InstrumentedCache) before(ctx context.Context, op *OperationInfo) context.Context {
+	op.Start = time.Now()
+	for _, hook := range c.hooks {
+		ctx = hook.BeforeOperation(ctx, op)
+	}
+	return ctx
+}
+
+// after 记录耗时和错误并按相反顺序调用钩子
+func (c *InstrumentedCache) after(ctx context.Context, op *OperationInfo) {
+	op.Duration = time.Since(op.Start)
+	c.latencies[op.Op].observe(op.Duration)
+
+	if op.Err != nil {
+		c.errors.Add(1)
+		// 按模式、标签删除和清空不针对具体的键，只计入总数
+		switch {
+		case op.Op == OpDeleteByPattern || op.Op == OpDeleteByTag || op.Op == OpFlush:
+		case len(op.Keys) > 0:
+			c.prefix(op.Keys[0]).errors.Add(1)
+		default:
+			c.prefix(op.Key).errors.Add(1)
+		}
+	}
+
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		c.hooks[i].AfterOperation(ctx, op)
+	}
+}
+
+func (c *InstrumentedCache) recordRead(op *OperationInfo, key string, found bool) {
+	counters := c.prefix(key)
+	if found {
+		op.Hits++
+		c.hits.Add(1)
+		counters.hits.Add(1)
+	} else {
+		op.Misses++
+		c.misses.Add(1)
+		counters.misses.Add(1)
+	}
+}
+
+func (c *InstrumentedCache) recordSet(key string) {
+	c.sets.Add(1)
+	c.prefix(key).sets.Add(1)
+}
+
+func (c *InstrumentedCache) recordDelete(key string) {
+	c.deletes.Add(1)
+	c.prefix(key).deletes.Add(1)
+}
+
+// prefix 返回键前缀的计数器，前缀数量超过上限时返回OtherPrefix的计数器
+func (c *InstrumentedCache) prefix(key string) *prefixCounters {
+	prefix := c.options.PrefixFunc(key)
+
+	c.prefixMu.RLock()
+	counters, ok := c.prefixes[prefix]
+	c.prefixMu.RUnlock()
+	if ok {
+		return counters
+	}
+
+	c.prefixMu.Lock()
+	defer c.prefixMu.Unlock()
+	if counters, ok := c.prefixes[prefix]; ok {
+		return counters
+	}
+	if len(c.prefixes) >= c.options.MaxPrefixes {
+		prefix = OtherPrefix
+		if counters, ok := c.prefixes[prefix]; ok {
+			return counters
+		}
+	}
+	counters = &prefixCounters{}
+	c.prefixes[prefix] = counters
+	return counters
+}
+
+// prefixCounters 单个键前缀的计数器
+type prefixCounters struct {
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+	errors  atomic.Uint64
+}
+
+func (p *prefixCounters) snapshot() PrefixMetrics {
+	return PrefixMetrics{
+		Hits:    p.hits.Load(),
+		Misses:  p.misses.Load(),
+		Sets:    p.sets.Load(),
+		Deletes: p.deletes.Load(),
+		Errors:  p.errors.Load(),
+	}
+}
+
+// latencyRecorder 无锁的延迟直方图
+type latencyRecorder struct {
+	bounds []time.Duration
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+	max    atomic.Int64
+}
+
+func newLatencyRecorder(bounds []time.Duration) *latencyRecorder {
+	return &latencyRecorder{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)),
+	}
+}
+
+func (r *latencyRecorder) observe(d time.Duration) {
+	r.count.Add(1)
+	r.sum.Add(int64(d))
+	for {
+		current := r.max.Load()
+		if int64(d) <= current || r.max.CompareAndSwap(current, int64(d)) {
+			break
+		}
+	}
+	for i, bound := range r.bounds {
+		if d <= bound {
+			r.counts[i].Add(1)
+			return
+		}
+	}
+}
+
+func (r *latencyRecorder) snapshot() LatencyHistogram {
+	h := LatencyHistogram{
+		Count:   r.count.Load(),
+		Sum:     time.Duration(r.sum.Load()),
+		Max:     time.Duration(r.max.Load()),
+		Buckets: make([]LatencyBucket, len(r.bounds)),
+	}
+	var cumulative uint64
+	for i, bound := range r.bounds {
+		cumulative += r.counts[i].Load()
+		h.Buckets[i] = LatencyBucket{UpperBound: bound, Count: cumulative}
+	}
+	return h
+}
+
+// LoggingHookOptions 日志钩子选项
+type LoggingHookOptions struct {
+	// SlowThreshold 耗时超过此值的操作以Warn级别记录，0表示不记录慢操作
+	SlowThreshold time.Duration
+
+	// LogAll 是否以Debug级别记录所有操作
+	LogAll bool
+}
+
+// loggingHook 通过logger记录缓存操作的钩子
+type loggingHook struct {
+	log     logger.Logger
+	options LoggingHookOptions
+}
+
+// NewLoggingHook 创建通过logger记录缓存操作的钩子
+// 出错的操作以Error级别记录，慢操作以Warn级别记录
+func NewLoggingHook(log logger.Logger, opts ...LoggingHookOptions) Hook {
+	h := &loggingHook{log: log}
+	if len(opts) > 0 {
+		h.options = opts[0]
+	}
+	return h
+}
+
+// BeforeOperation 实现Hook接口
+func (h *loggingHook) BeforeOperation(ctx context.Context, op *OperationInfo) context.Context {
+	return ctx
+}
+
+// AfterOperation 实现Hook接口
+func (h *loggingHook) AfterOperation(ctx context.Context, op *OperationInfo) {
+	slow := h.options.SlowThreshold > 0 && op.Duration >= h.options.SlowThreshold
+	if op.Err == nil && !slow && !h.options.LogAll {
+		return
+	}
+
+	fields := []logger.Field{
+		logger.String("op", string(op.Op)),
+		logger.Duration("duration", op.Duration),
+	}
+	if op.Key != "" {
+		fields = append(fields, logger.String("key", op.Key))
+	}
+	if len(op.Keys) > 0 {
+		fields = append(fields, logger.Int("keys", len(op.Keys)))
+	}
+	if op.Hits+op.Misses > 0 {
+		fields = append(fields, logger.Int("hits", op.Hits), logger.Int("misses", op.Misses))
+	}
+
+	log := h.log.WithContext(ctx)
+	switch {
+	case op.Err != nil:
+		log.Error("缓存操作失败", append(fields, logger.Err(op.Err))...)
+	case slow:
+		log.Warn("缓存操作耗时过长", fields...)
+	default:
+		log.Debug("缓存操作", fields...)
+	}
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guanzhenxing/go-snap/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedCache_CountsAcrossBackends(t *testing.T) {
+	for name, backend := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			c := NewInstrumentedCache(backend)
+			ctx := context.Background()
+			require.Implements(t, (*ValueDecoder)(nil), c)
+			require.Implements(t, (*Loader)(nil), c)
+			require.Implements(t, (*BatchCache)(nil), c)
+
+			require.NoError(t, c.Set(ctx, "user:1", "alice", time.Minute))
+			require.NoError(t, c.SetMany(ctx, map[string]*Item{
+				"user:2":  {Value: "bob", Expiration: time.Minute},
+				"order:1": {Value: "book", Expiration: time.Minute},
+			}))
+
+			_, found := c.Get(ctx, "user:1")
+			assert.True(t, found)
+			_, found = c.Get(ctx, "user:missing")
+			assert.False(t, found)
+			var s string
+			found, err := c.GetInto(ctx, "order:1", &s)
+			require.NoError(t, err)
+			assert.True(t, found)
+			_, err = c.GetMany(ctx, []string{"user:2", "order:missing"})
+			require.NoError(t, err)
+
+			require.NoError(t, c.Delete(ctx, "user:1"))
+			require.NoError(t, c.DeleteMany(ctx, []string{"user:2", "order:1"}))
+
+			m := c.Metrics()
+			assert.Equal(t, uint64(3), m.Hits)
+			assert.Equal(t, uint64(2), m.Misses)
+			assert.Equal(t, uint64(3), m.Sets)
+			assert.Equal(t, uint64(3), m.Deletes)
+			assert.Equal(t, uint64(0), m.Errors)
+			assert.InDelta(t, 0.6, m.HitRate(), 0.001)
+
+			assert.Equal(t, PrefixMetrics{Hits: 2, Misses: 1, Sets: 2, Deletes: 2}, m.Prefixes["user"])
+			assert.Equal(t, PrefixMetrics{Hits: 1, Misses: 1, Sets: 1, Deletes: 1}, m.Prefixes["order"])
+
+			assert.Equal(t, uint64(2), m.Latencies[OpGet].Count)
+			assert.Equal(t, uint64(1), m.Latencies[OpSetMany].Count)
+			assert.NotContains(t, m.Latencies, OpFlush)
+		})
+	}
+}
+
+func TestInstrumentedCache_GetOrLoad(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache())
+	defer c.Close()
+	ctx := context.Background()
+
+	loader := func(ctx context.Context) (interface{}, error) { return "loaded", nil }
+	for i := 0; i < 3; i++ {
+		value, err := c.GetOrLoad(ctx, "key", time.Minute, loader)
+		require.NoError(t, err)
+		assert.Equal(t, "loaded", value)
+	}
+
+	failing := errors.New("load failed")
+	_, err := c.GetOrLoad(ctx, "other", time.Minute, func(ctx context.Context) (interface{}, error) {
+		return nil, failing
+	})
+	assert.ErrorIs(t, err, failing)
+
+	m := c.Metrics()
+	assert.Equal(t, uint64(2), m.Hits)
+	assert.Equal(t, uint64(1), m.Misses)
+	assert.Equal(t, uint64(1), m.Sets)
+	assert.Equal(t, uint64(1), m.Errors)
+}
+
+func TestInstrumentedCache_Errors(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache())
+	defer c.Close()
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "user:1", "not a number", time.Minute))
+	_, err := c.Increment(ctx, "user:1", 1)
+	require.Error(t, err)
+
+	var n int
+	_, err = c.GetInto(ctx, "user:1", &n)
+	require.Error(t, err)
+
+	m := c.Metrics()
+	assert.Equal(t, uint64(2), m.Errors)
+	assert.Equal(t, uint64(2), m.Prefixes["user"].Errors)
+	assert.Equal(t, uint64(0), m.Hits+m.Misses, "failed reads are not counted as hits or misses")
+}
+
+func TestInstrumentedCache_Evictions(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache(Options{MaxEntries: 2}))
+	defer c.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("key:%d", i), i, time.Minute))
+	}
+	assert.Equal(t, uint64(3), c.Metrics().Evictions)
+}
+
+func TestInstrumentedCache_LatencyHistogram(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache(), InstrumentOptions{
+		LatencyBuckets: []time.Duration{time.Millisecond, time.Hour},
+	})
+	defer c.Close()
+	ctx := context.Background()
+
+	slow := HookFuncs{Before: func(ctx context.Context, op *OperationInfo) context.Context {
+		if op.Key == "slow" {
+			time.Sleep(5 * time.Millisecond)
+		}
+		return ctx
+	}}
+	c.Use(slow)
+
+	c.Get(ctx, "fast")
+	c.Get(ctx, "slow")
+
+	h := c.Metrics().Latencies[OpGet]
+	assert.Equal(t, uint64(2), h.Count)
+	assert.GreaterOrEqual(t, h.Max, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, h.Sum, h.Max)
+	assert.Equal(t, h.Sum/2, h.Mean())
+	require.Len(t, h.Buckets, 2)
+	assert.Equal(t, uint64(1), h.Buckets[0].Count)
+	assert.Equal(t, uint64(2), h.Buckets[1].Count, "bucket counts are cumulative")
+}
+
+func TestInstrumentedCache_MaxPrefixes(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache(), InstrumentOptions{MaxPrefixes: 2})
+	defer c.Close()
+	ctx := context.Background()
+
+	for _, prefix := range []string{"a", "b", "c", "d"} {
+		c.Get(ctx, prefix+":1")
+	}
+
+	m := c.Metrics()
+	assert.Len(t, m.Prefixes, 3)
+	assert.Equal(t, uint64(2), m.Prefixes[OtherPrefix].Misses)
+}
+
+type contextKey string
+
+func TestInstrumentedCache_HooksOrderAndContext(t *testing.T) {
+	var calls []string
+	hook := func(name string) Hook {
+		return HookFuncs{
+			Before: func(ctx context.Context, op *OperationInfo) context.Context {
+				calls = append(calls, "before:"+name)
+				return context.WithValue(ctx, contextKey(name), true)
+			},
+			After: func(ctx context.Context, op *OperationInfo) {
+				assert.Equal(t, true, ctx.Value(contextKey(name)), "after hook should receive the context from before")
+				calls = append(calls, fmt.Sprintf("after:%s:%s:%d:%d", name, op.Op, op.Hits, op.Misses))
+			},
+		}
+	}
+
+	c := NewInstrumentedCache(NewMemoryCache(), InstrumentOptions{Hooks: []Hook{hook("outer"), hook("inner")}})
+	defer c.Close()
+
+	c.Get(context.Background(), "missing")
+	assert.Equal(t, []string{
+		"before:outer",
+		"before:inner",
+		"after:inner:get:0:1",
+		"after:outer:get:0:1",
+	}, calls)
+}
+
+func TestLoggingHook(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache(), InstrumentOptions{
+		Hooks: []Hook{NewLoggingHook(logger.New(), LoggingHookOptions{SlowThreshold: time.Nanosecond, LogAll: true})},
+	})
+	defer c.Close()
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+	_, err := c.Increment(ctx, "key", 1)
+	assert.Error(t, err)
+	_, err = c.GetMany(ctx, []string{"key", "missing"})
+	assert.NoError(t, err)
+}
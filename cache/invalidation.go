@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidationOptions 多级缓存跨实例失效选项
+// 启用后，每个实例在写入或删除时通过Redis发布订阅广播失效消息，
+// 其他实例收到消息后清除本地缓存中的对应项，避免在LocalTTL内读到旧数据
+type InvalidationOptions struct {
+	// Client 用于发布和订阅的Redis客户端，为nil时使用远程RedisCache的客户端
+	Client redis.UniversalClient
+
+	// Channel 频道前缀，默认"cache:invalidate"
+	// 键的失效消息发布到"<Channel>:<键前缀>"，键前缀为键中第一个冒号之前的部分；
+	// 标签、模式和清空的失效消息发布到"<Channel>"
+	Channel string
+
+	// Prefixes 只订阅这些键前缀的失效消息，为空时订阅全部
+	Prefixes []string
+
+	// InstanceID 实例标识，用于忽略自己发出的消息，默认随机生成
+	InstanceID string
+
+	// SubscribeTimeout 等待订阅确认的超时时间，默认5秒
+	SubscribeTimeout time.Duration
+
+	// ReconnectInterval 订阅连接出错后重试的间隔，默认1秒
+	ReconnectInterval time.Duration
+
+	// OnError 发布或订阅出错时的回调，出错不会影响缓存操作本身的结果
+	OnError func(err error)
+}
+
+// invalidation操作类型
+const (
+	invalidateKeys    = "keys"
+	invalidateTag     = "tag"
+	invalidatePattern = "pattern"
+	invalidateFlush   = "flush"
+)
+
+// invalidationMessage 失效消息
+type invalidationMessage struct {
+	Instance string   `json:"instance"`
+	Op       string   `json:"op"`
+	Keys     []string `json:"keys,omitempty"`
+	Tag      string   `json:"tag,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+}
+
+// invalidator 负责广播和应用失效消息
+type invalidator struct {
+	client  redis.UniversalClient
+	local   Cache
+	options InvalidationOptions
+	pubsub  *redis.PubSub
+	cancel  context.CancelFunc
+	done    chan struct{}
+	closed  sync.Once
+}
+
+// newInvalidator 创建失效广播器并完成订阅
+func newInvalidator(local, remote Cache, opts InvalidationOptions) (*invalidator, error) {
+	if opts.Client == nil {
+		redisCache, ok := remote.(*RedisCache)
+		if !ok {
+			return nil, fmt.Errorf("invalidation requires a redis client or a RedisCache remote layer")
+		}
+		opts.Client = redisCache.GetClient()
+	}
+	if opts.Channel == "" {
+		opts.Channel = "cache:invalidate"
+	}
+	if opts.InstanceID == "" {
+		opts.InstanceID = uuid.NewString()
+	}
+	if opts.SubscribeTimeout <= 0 {
+		opts.SubscribeTimeout = time.Second * 5
+	}
+	if opts.ReconnectInterval <= 0 {
+		opts.ReconnectInterval = time.Second
+	}
+
+	inv := &invalidator{
+		client:  opts.Client,
+		local:   local,
+		options: opts,
+		done:    make(chan struct{}),
+	}
+
+	patterns := inv.patterns()
+	ctx, cancel := context.WithTimeout(context.Background(), opts.SubscribeTimeout)
+	defer cancel()
+
+	inv.pubsub = opts.Client.PSubscribe(ctx, patterns...)
+	for confirmed := 0; confirmed < len(patterns); {
+		msg, err := inv.pubsub.Receive(ctx)
+		if err != nil {
+			_ = inv.pubsub.Close()
+			return nil, fmt.Errorf("failed to subscribe invalidation channel: %w", err)
+		}
+		if _, ok := msg.(*redis.Subscription); ok {
+			confirmed++
+		}
+	}
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+	inv.cancel = runCancel
+	go inv.run(runCtx)
+
+	return inv, nil
+}
+
+// patterns 返回需要订阅的频道模式
+// 频道名和键前缀中的glob特殊字符需要转义，否则"user*"之类的前缀会订阅到其他前缀的消息
+func (inv *invalidator) patterns() []string {
+	channel := EscapePattern(inv.options.Channel)
+	if len(inv.options.Prefixes) == 0 {
+		return []string{channel, channel + ":*"}
+	}
+
+	patterns := []string{channel}
+	for _, prefix := range inv.options.Prefixes {
+		patterns = append(patterns, channel+":"+EscapePattern(prefix))
+	}
+	return patterns
+}
+
+// run 接收并应用失效消息
+// 连接中断期间的消息会丢失，因此重新订阅成功后清空本地缓存
+func (inv *invalidator) run(ctx context.Context) {
+	defer close(inv.done)
+
+	resync := false
+	for {
+		msg, err := inv.pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			inv.reportError(fmt.Errorf("invalidation subscription interrupted: %w", err))
+			resync = true
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(inv.options.ReconnectInterval):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if resync {
+				resync = false
+				_ = inv.local.Flush(ctx)
+			}
+		case *redis.Message:
+			inv.apply(ctx, m.Payload)
+		}
+	}
+}
+
+// apply 将失效消息应用到本地缓存
+func (inv *invalidator) apply(ctx context.Context, payload string) {
+	var msg invalidationMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		inv.reportError(fmt.Errorf("invalid invalidation message: %w", err))
+		return
+	}
+	if msg.Instance == inv.options.InstanceID {
+		return
+	}
+
+	switch msg.Op {
+	case invalidateKeys:
+		_ = DeleteMany(ctx, inv.local, msg.Keys)
+	case invalidateTag:
+		_ = inv.local.DeleteByTag(ctx, msg.Tag)
+	case invalidatePattern:
+		_ = inv.local.DeleteByPattern(ctx, msg.Pattern)
+	case invalidateFlush:
+		_ = inv.local.Flush(ctx)
+	}
+}
+
+// publishKeys 广播键的失效消息，按键前缀发布到不同频道
+func (inv *invalidator) publishKeys(ctx context.Context, keys ...string) {
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		prefix := key
+		if i := strings.Index(key, ":"); i >= 0 {
+			prefix = key[:i]
+		}
+		groups[prefix] = append(groups[prefix], key)
+	}
+
+	for prefix, prefixKeys := range groups {
+		inv.publish(ctx, inv.options.Channel+":"+prefix, invalidationMessage{Op: invalidateKeys, Keys: prefixKeys})
+	}
+}
+
+// publishTag 广播标签的失效消息
+func (inv *invalidator) publishTag(ctx context.Context, tag string) {
+	inv.publish(ctx, inv.options.Channel, invalidationMessage{Op: invalidateTag, Tag: tag})
+}
+
+// publishPattern 广播模式的失效消息
+func (inv *invalidator) publishPattern(ctx context.Context, pattern string) {
+	inv.publish(ctx, inv.options.Channel, invalidationMessage{Op: invalidatePattern, Pattern: pattern})
+}
+
+// publishFlush 广播清空消息
+func (inv *invalidator) publishFlush(ctx context.Context) {
+	inv.publish(ctx, inv.options.Channel, invalidationMessage{Op: invalidateFlush})
+}
+
+func (inv *invalidator) publish(ctx context.Context, channel string, msg invalidationMessage) {
+	msg.Instance = inv.options.InstanceID
+	data, err := json.Marshal(msg)
+	if err != nil {
+		inv.reportError(fmt.Errorf("failed to encode invalidation message: %w", err))
+		return
+	}
+	if err := inv.client.Publish(ctx, channel, data).Err(); err != nil {
+		inv.reportError(fmt.Errorf("failed to publish invalidation message: %w", err))
+	}
+}
+
+func (inv *invalidator) reportError(err error) {
+	if inv.options.OnError != nil {
+		inv.options.OnError(err)
+	}
+}
+
+// close 停止接收失效消息，重复调用是安全的
+func (inv *invalidator) close() {
+	inv.closed.Do(func() {
+		inv.cancel()
+		_ = inv.pubsub.Close()
+		<-inv.done
+	})
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invalidationTestInstance 模拟一个实例上的多级缓存
+type invalidationTestInstance struct {
+	local *MemoryCache
+	cache *MultiLevelCache
+}
+
+func newInvalidationTestInstance(t *testing.T, mr *miniredis.Miniredis, opts InvalidationOptions) *invalidationTestInstance {
+	redisOpts := DefaultRedisOptions()
+	redisOpts.Addr = mr.Addr()
+	remote, err := NewRedisCache(redisOpts, nil)
+	require.NoError(t, err)
+
+	if opts.ReconnectInterval == 0 {
+		opts.ReconnectInterval = 10 * time.Millisecond
+	}
+
+	local := NewMemoryCache()
+	options := DefaultMultiLevelOptions()
+	options.Invalidation = &opts
+	multi, err := NewMultiLevelCache(local, remote, options)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = multi.Close() })
+
+	return &invalidationTestInstance{local: local, cache: multi}
+}
+
+// localMissing 等待本地缓存中的键被清除
+func localMissing(t *testing.T, c *MemoryCache, key string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		_, found := c.Get(context.Background(), key)
+		return !found
+	}, time.Second, 5*time.Millisecond, "local key %q should be invalidated", key)
+}
+
+func TestMultiLevelCache_InvalidationAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "a"})
+	b := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "b"})
+	ctx := context.Background()
+
+	require.NoError(t, a.cache.Set(ctx, "user:1", "v1", time.Minute))
+	value, found := b.cache.Get(ctx, "user:1")
+	require.True(t, found)
+	assert.Equal(t, "v1", value)
+
+	t.Run("set", func(t *testing.T) {
+		require.NoError(t, a.cache.Set(ctx, "user:1", "v2", time.Minute))
+		localMissing(t, b.local, "user:1")
+
+		value, found := b.cache.Get(ctx, "user:1")
+		require.True(t, found)
+		assert.Equal(t, "v2", value)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, a.cache.Delete(ctx, "user:1"))
+		localMissing(t, b.local, "user:1")
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		require.NoError(t, b.local.SetItem(ctx, "user:2", &Item{Value: "cached", Tags: []string{"users"}}))
+		require.NoError(t, a.cache.DeleteByTag(ctx, "users"))
+		localMissing(t, b.local, "user:2")
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		require.NoError(t, b.local.Set(ctx, "order:1", "cached", time.Minute))
+		require.NoError(t, a.cache.Flush(ctx))
+		localMissing(t, b.local, "order:1")
+	})
+}
+
+func TestMultiLevelCache_InvalidationSkipsSelfEcho(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "a"})
+	ctx := context.Background()
+
+	require.NoError(t, a.cache.Set(ctx, "user:1", "v1", time.Minute))
+
+	// 自己发出的失效消息不应清除刚写入的本地缓存
+	time.Sleep(50 * time.Millisecond)
+	value, found := a.local.Get(ctx, "user:1")
+	assert.True(t, found)
+	assert.Equal(t, "v1", value)
+}
+
+func TestMultiLevelCache_InvalidationPrefixes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "a"})
+	b := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "b", Prefixes: []string{"user"}})
+	ctx := context.Background()
+
+	require.NoError(t, b.local.Set(ctx, "user:1", "cached", time.Minute))
+	require.NoError(t, b.local.Set(ctx, "order:1", "cached", time.Minute))
+
+	require.NoError(t, a.cache.DeleteMany(ctx, []string{"user:1", "order:1"}))
+	localMissing(t, b.local, "user:1")
+
+	// 未订阅的前缀不受影响
+	_, found := b.local.Get(ctx, "order:1")
+	assert.True(t, found)
+}
+
+func TestMultiLevelCache_InvalidationEscapesPatterns(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "a", Channel: "inv[1]"})
+	b := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "b", Channel: "inv[1]", Prefixes: []string{"user*"}})
+	ctx := context.Background()
+
+	require.NoError(t, b.local.Set(ctx, "user*:1", "cached", time.Minute))
+	require.NoError(t, b.local.Set(ctx, "users:1", "cached", time.Minute))
+
+	require.NoError(t, a.cache.DeleteMany(ctx, []string{"user*:1", "users:1"}))
+	localMissing(t, b.local, "user*:1")
+
+	// 前缀中的*按字面匹配，不会订阅到users前缀
+	_, found := b.local.Get(ctx, "users:1")
+	assert.True(t, found)
+}
+
+func TestMultiLevelCache_InvalidationResyncAfterReconnect(t *testing.T) {
+	mr := miniredis.RunT(t)
+	b := newInvalidationTestInstance(t, mr, InvalidationOptions{
+		InstanceID: "b",
+		OnError:    func(err error) {},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, b.local.Set(ctx, "user:1", "cached", time.Minute))
+
+	// 断线期间的失效消息会丢失，重新订阅后清空本地缓存
+	mr.Close()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, mr.Restart())
+	localMissing(t, b.local, "user:1")
+
+	// 重新订阅后继续接收失效消息
+	a := newInvalidationTestInstance(t, mr, InvalidationOptions{InstanceID: "a"})
+	require.NoError(t, b.local.Set(ctx, "user:2", "cached", time.Minute))
+	assert.Eventually(t, func() bool {
+		_ = a.cache.Delete(ctx, "user:2")
+		_, found := b.local.Get(ctx, "user:2")
+		return !found
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestMultiLevelCache_InvalidationRequiresRedis(t *testing.T) {
+	_, err := NewMultiLevelCache(NewMemoryCache(), NewMemoryCache(), MultiLevelOptions{
+		LocalTTL:     time.Minute,
+		Invalidation: &InvalidationOptions{},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoadFunc 缓存未命中时加载数据的函数
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// Loader 支持合并加载的缓存
+// 所有内置缓存实现都实现了此接口
+type Loader interface {
+	// GetOrLoad 获取缓存值，未命中时调用loader加载并写入缓存
+	// 参数：
+	//   ctx: 上下文，取消时当前调用立即返回ctx.Err()，不影响正在进行的加载和其他等待者
+	//   key: 缓存键名
+	//   ttl: 写入缓存时使用的生存时间
+	//   loader: 未命中时加载数据的函数
+	// 返回：
+	//   interface{}: 缓存值或加载的值
+	//   error: 加载失败或写入缓存失败时返回错误，加载失败的结果不会被缓存
+	// 注意：
+	//   - 同一实例内同一键的并发未命中只会调用一次loader，其他调用等待并共享结果
+	//   - loader收到的上下文保留调用方的值，但不随调用方取消，以免一个调用方取消导致所有等待者失败
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error)
+}
+
+// GetOrLoad 在任意缓存上执行合并加载
+// 缓存实现了Loader接口时使用其实现，否则只做简单的未命中加载，不合并并发调用
+func GetOrLoad(ctx context.Context, c Cache, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	if l, ok := c.(Loader); ok {
+		return l.GetOrLoad(ctx, key, ttl, loader)
+	}
+
+	if value, found := c.Get(ctx, key); found {
+		return value, nil
+	}
+	return loadAndStore(ctx, c, key, ttl, loader)
+}
+
+// loadLocker 跨实例协调加载的锁
+type loadLocker interface {
+	// tryLoadLock 尝试获取键的加载锁，获取成功时返回释放函数
+	tryLoadLock(ctx context.Context, key string) (release func(), acquired bool, err error)
+	// loadLockRetryInterval 未获得锁时等待其他实例写入缓存的轮询间隔
+	loadLockRetryInterval() time.Duration
+}
+
+// loadCall 一次正在进行的加载
+type loadCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// loadGroup 按键合并并发加载，零值可以直接使用
+type loadGroup struct {
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}
+
+// do 执行或等待键的加载
+// 加载在独立的协程中运行，调用方取消时只有自己返回，加载继续为其他等待者进行
+func (g *loadGroup) do(ctx context.Context, key string, fn LoadFunc) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*loadCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &loadCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(context.WithoutCancel(ctx), key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *loadGroup) run(ctx context.Context, key string, call *loadCall, fn LoadFunc) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.value, call.err = nil, fmt.Errorf("cache: loader panic for key %q: %v", key, r)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn(ctx)
+}
+
+// loadThrough GetOrLoad的通用实现
+// locker不为nil时，本实例的加载者还需获得跨实例的加载锁才会调用loader
+func loadThrough(ctx context.Context, c Cache, group *loadGroup, locker loadLocker, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	if value, found := c.Get(ctx, key); found {
+		return value, nil
+	}
+
+	return group.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		// 上一轮加载可能在本次未命中之后刚刚写入
+		if value, found := c.Get(ctx, key); found {
+			return value, nil
+		}
+		if locker == nil {
+			return loadAndStore(ctx, c, key, ttl, loader)
+		}
+		return loadWithLock(ctx, c, locker, key, ttl, loader)
+	})
+}
+
+// loadWithLock 获得加载锁后加载，未获得锁时等待持锁实例写入缓存
+// 锁带有过期时间，持锁实例异常退出时其他实例会在锁过期后接手加载
+func loadWithLock(ctx context.Context, c Cache, locker loadLocker, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	for {
+		release, acquired, err := locker.tryLoadLock(ctx, key)
+		if err != nil {
+			// 无法协调时退化为进程内合并，保证可用性
+			return loadAndStore(ctx, c, key, ttl, loader)
+		}
+		if acquired {
+			defer release()
+			if value, found := c.Get(ctx, key); found {
+				return value, nil
+			}
+			return loadAndStore(ctx, c, key, ttl, loader)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(locker.loadLockRetryInterval()):
+		}
+
+		if value, found := c.Get(ctx, key); found {
+			return value, nil
+		}
+	}
+}
+
+// loadAndStore 调用loader并将结果写入缓存，加载失败时不写入
+func loadAndStore(ctx context.Context, c Cache, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		return value, err
+	}
+	return value, nil
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loaderTestCaches 返回用于合并加载测试的各种缓存实现
+func loaderTestCaches(t *testing.T) map[string]Cache {
+	caches := typedTestCaches(t)
+
+	sharded := NewShardedMemoryCache(4)
+	t.Cleanup(func() { _ = sharded.Close() })
+	caches["sharded_memory"] = sharded
+
+	return caches
+}
+
+func TestGetOrLoad_CoalescesConcurrentMisses(t *testing.T) {
+	for name, c := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			release := make(chan struct{})
+			var calls atomic.Int32
+
+			loader := func(ctx context.Context) (interface{}, error) {
+				calls.Add(1)
+				<-release
+				return "loaded", nil
+			}
+
+			const callers = 50
+			var wg sync.WaitGroup
+			results := make(chan interface{}, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					value, err := GetOrLoad(ctx, c, "hot", time.Minute, loader)
+					assert.NoError(t, err)
+					results <- value
+				}()
+			}
+
+			// 等待所有调用都进入等待后再放行加载
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(results)
+
+			assert.Equal(t, int32(1), calls.Load())
+			for value := range results {
+				assert.Equal(t, "loaded", value)
+			}
+
+			value, found := c.Get(ctx, "hot")
+			assert.True(t, found)
+			assert.Equal(t, "loaded", value)
+		})
+	}
+}
+
+func TestGetOrLoad_ErrorNotCached(t *testing.T) {
+	for name, c := range loaderTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			loadErr := errors.New("db down")
+
+			_, err := GetOrLoad(ctx, c, "key", time.Minute, func(ctx context.Context) (interface{}, error) {
+				return nil, loadErr
+			})
+			assert.ErrorIs(t, err, loadErr)
+
+			exists, err := c.Exists(ctx, "key")
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			value, err := GetOrLoad(ctx, c, "key", time.Minute, func(ctx context.Context) (interface{}, error) {
+				return "recovered", nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "recovered", value)
+		})
+	}
+}
+
+func TestGetOrLoad_WaiterCancellation(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return "value", nil
+	}
+
+	leaderDone := make(chan interface{})
+	go func() {
+		value, err := c.GetOrLoad(context.Background(), "key", time.Minute, loader)
+		assert.NoError(t, err)
+		leaderDone <- value
+	}()
+	<-started
+
+	// 等待者取消后立即返回，不影响正在进行的加载
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.GetOrLoad(ctx, "key", time.Minute, loader)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.Equal(t, "value", <-leaderDone)
+}
+
+func TestGetOrLoad_LoaderPanic(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	_, err := c.GetOrLoad(context.Background(), "key", time.Minute, func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestGetOrLoad_AcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	newInstance := func() *RedisCache {
+		opts := DefaultRedisOptions()
+		opts.Addr = mr.Addr()
+		opts.LoadLockTTL = time.Second
+		opts.LoadLockRetryInterval = 5 * time.Millisecond
+		c, err := NewRedisCache(opts, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+
+	ctx := context.Background()
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return "shared", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		instance := newInstance()
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := instance.GetOrLoad(ctx, "hot", time.Minute, loader)
+				assert.NoError(t, err)
+				assert.Equal(t, "shared", value)
+			}()
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.False(t, mr.Exists("lock:load:hot"), "load lock should be released")
+}
+
+func TestGetOrLoad_LoadLockUsesReservedKey(t *testing.T) {
+	mr := miniredis.RunT(t)
+	opts := DefaultRedisOptions()
+	opts.Addr = mr.Addr()
+	opts.LoadLockTTL = time.Second
+	c, err := NewRedisCache(opts, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	value, err := c.GetOrLoad(ctx, "user:1", time.Minute, func(ctx context.Context) (interface{}, error) {
+		assert.True(t, mr.Exists("lock:load:user:1"), "the lock lives under the reserved prefix")
+		keys, err := Keys(ctx, c, "user:*")
+		assert.NoError(t, err)
+		assert.Empty(t, keys, "pattern scans must not see the load lock")
+		return "alice", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", value)
+	assert.False(t, mr.Exists("lock:load:user:1"))
+}
@@ -12,16 +12,17 @@
 // - 过期清理由后台goroutine定期执行，不影响正常操作
 //
 // 限制：
-// - 所有数据存储在内存中，重启后数据会丢失
-// - 不适合存储大量数据，可能导致内存压力
+// - 所有数据存储在内存中，未设置Options.Snapshot时重启后数据会丢失
+// - 默认不限制容量，存储大量数据时应设置MaxEntries或MaxCost并选择淘汰策略
 // - 不支持跨实例的数据共享
 package cache
 
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"iter"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -42,6 +43,34 @@ type MemoryCache struct {
 	tagIndex map[string]map[string]struct{}
 	// tagMu 保护标签索引的读写锁
 	tagMu sync.RWMutex
+	// evictor 容量淘汰器，未设置MaxEntries和MaxCost时为nil
+	evictor evictor
+	// evictMu 保护淘汰器状态，读操作持有读锁时通过它记录访问
+	evictMu sync.Mutex
+	// cost 当前总成本，受mu保护
+	cost int64
+	// version 最近一次写入分配的版本号，受mu保护
+	version uint64
+	// evictions 因超出容量被淘汰的缓存项数量
+	evictions atomic.Uint64
+	// expirations 过期后被清理的缓存项数量
+	expirations atomic.Uint64
+	// loads 合并同一键的并发加载
+	loads loadGroup
+	// snapshotStop 通知定期快照goroutine退出，未启用定期快照时为nil
+	snapshotStop chan struct{}
+	// snapshotDone 定期快照goroutine退出后关闭
+	snapshotDone chan struct{}
+	// closeOnce 保证Close只执行一次
+	closeOnce sync.Once
+	// closeErr 第一次Close的结果
+	closeErr error
+}
+
+// evictedEntry 被淘汰的缓存项，用于在释放锁后调用淘汰回调
+type evictedEntry struct {
+	key   string
+	value interface{}
 }
 
 // memoryItem 内存缓存项，存储值和元数据
@@ -52,6 +81,10 @@ type memoryItem struct {
 	Expiration int64
 	// Tags 该缓存项关联的标签列表
 	Tags []string
+	// Cost 缓存项的成本，仅在设置了MaxCost时计算
+	Cost int64
+	// Version 写入时分配的版本号，值每次改变都会更新，用于比较并交换
+	Version uint64
 }
 
 // NewMemoryCache 创建新的内存缓存实例
@@ -77,6 +110,12 @@ type memoryItem struct {
 //	    DefaultTTL: time.Minute * 5,
 //	    CleanupInterval: time.Minute,
 //	})
+//
+//	// 创建最多保存10000项、使用W-TinyLFU淘汰的有界缓存
+//	cache := cache.NewMemoryCache(cache.Options{
+//	    MaxEntries:     10000,
+//	    EvictionPolicy: cache.EvictionTinyLFU,
+//	})
 func NewMemoryCache(opts ...Options) *MemoryCache {
 	var options Options
 	if len(opts) > 0 {
@@ -91,12 +130,20 @@ func NewMemoryCache(opts ...Options) *MemoryCache {
 		tagIndex: make(map[string]map[string]struct{}),
 	}
 
+	// 设置了容量限制时创建淘汰器
+	if options.MaxEntries > 0 || options.MaxCost > 0 {
+		c.evictor = newEvictor(options.EvictionPolicy, options.MaxEntries)
+	}
+
 	// 启动过期项清理器
 	if options.CleanupInterval > 0 {
 		c.janitor = newJanitor(options.CleanupInterval)
-		c.janitor.run(c)
+		c.janitor.run(c.deleteExpired)
 	}
 
+	// 设置了快照路径时恢复上次关闭前的数据
+	c.startSnapshots()
+
 	return c
 }
 
@@ -127,7 +174,7 @@ func (c *MemoryCache) Get(_ context.Context, key string) (interface{}, bool) {
 	defer c.mu.RUnlock()
 
 	item, found := c.items[key]
-	if !found {
+	if !found || isMemoryStructure(item.Value) {
 		return nil, false
 	}
 
@@ -136,6 +183,7 @@ func (c *MemoryCache) Get(_ context.Context, key string) (interface{}, bool) {
 		return nil, false
 	}
 
+	c.recordAccess(key)
 	return item.Value, true
 }
 
@@ -162,12 +210,13 @@ func (c *MemoryCache) GetWithTTL(_ context.Context, key string) (interface{}, ti
 	defer c.mu.RUnlock()
 
 	item, found := c.items[key]
-	if !found {
+	if !found || isMemoryStructure(item.Value) {
 		return nil, 0, false
 	}
 
 	// 如果没有过期时间，则返回-1表示永不过期
 	if item.Expiration == 0 {
+		c.recordAccess(key)
 		return item.Value, -1, true
 	}
 
@@ -177,11 +226,43 @@ func (c *MemoryCache) GetWithTTL(_ context.Context, key string) (interface{}, ti
 		return nil, 0, false
 	}
 
+	c.recordAccess(key)
+
 	// 计算剩余TTL
 	ttl := time.Duration(item.Expiration - now)
 	return item.Value, ttl, true
 }
 
+// GetInto 获取缓存值并赋给target，实现ValueDecoder接口
+// 类型不一致时按JSON规则转换，与RedisCache的行为保持一致
+func (c *MemoryCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	value, found := c.Get(ctx, key)
+	if !found {
+		return false, nil
+	}
+	if err := assignValue(key, value, target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetIntoWithTTL 获取缓存值和剩余TTL并赋给target，实现ValueDecoder接口
+func (c *MemoryCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	value, ttl, found := c.GetWithTTL(ctx, key)
+	if !found {
+		return 0, false, nil
+	}
+	if err := assignValue(key, value, target); err != nil {
+		return 0, false, err
+	}
+	return ttl, true, nil
+}
+
+// GetOrLoad 获取缓存值，未命中时合并并发调用只加载一次，实现Loader接口
+func (c *MemoryCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	return loadThrough(ctx, c, &c.loads, nil, key, ttl, loader)
+}
+
 // Set 设置缓存值
 // 参数：
 //
@@ -215,21 +296,18 @@ func (c *MemoryCache) Set(_ context.Context, key string, value interface{}, ttl
 		exp = time.Now().Add(ttl).UnixNano()
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// 检查是否已存在，如果存在，需要更新标签索引
-	oldItem, found := c.items[key]
-	if found {
-		c.removeItemFromTagIndex(key, oldItem.Tags)
-	}
-
-	c.items[key] = &memoryItem{
+	mItem := &memoryItem{
 		Value:      value,
 		Expiration: exp,
 		Tags:       []string{},
+		Cost:       c.itemCost(key, value),
 	}
 
+	c.mu.Lock()
+	evicted := c.storeUnsafe(key, mItem)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
 	return nil
 }
 
@@ -265,26 +343,18 @@ func (c *MemoryCache) SetItem(_ context.Context, key string, item *Item) error {
 		exp = time.Now().Add(item.Expiration).UnixNano()
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// 检查是否已存在，如果存在，需要更新标签索引
-	oldItem, found := c.items[key]
-	if found {
-		c.removeItemFromTagIndex(key, oldItem.Tags)
-	}
-
 	mItem := &memoryItem{
 		Value:      item.Value,
 		Expiration: exp,
 		Tags:       item.Tags,
+		Cost:       c.itemCost(key, item.Value),
 	}
 
-	c.items[key] = mItem
-
-	// 更新标签索引
-	c.updateTagIndex(key, item.Tags)
+	c.mu.Lock()
+	evicted := c.storeUnsafe(key, mItem)
+	c.mu.Unlock()
 
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
 	return nil
 }
 
@@ -311,54 +381,146 @@ func (c *MemoryCache) Delete(_ context.Context, key string) error {
 
 	// 检查是否存在，并更新标签索引
 	if item, found := c.items[key]; found {
-		c.removeItemFromTagIndex(key, item.Tags)
-		delete(c.items, key)
+		c.removeUnsafe(key, item)
+	}
+
+	return nil
+}
+
+// GetMany 在一次加锁中批量获取缓存值，实现BatchCache接口
+func (c *MemoryCache) GetMany(_ context.Context, keys []string) (map[string]interface{}, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		item, found := c.items[key]
+		if !found || (item.Expiration > 0 && now > item.Expiration) || isMemoryStructure(item.Value) {
+			continue
+		}
+		c.recordAccess(key)
+		values[key] = item.Value
+	}
+	return values, nil
+}
+
+// SetMany 在一次加锁中批量设置缓存项，实现BatchCache接口
+// 任意一项为nil时整批不写入
+func (c *MemoryCache) SetMany(_ context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	mItems := make(map[string]*memoryItem, len(items))
+	for key, item := range items {
+		var exp int64
+		if item.Expiration > 0 {
+			exp = now.Add(item.Expiration).UnixNano()
+		}
+		mItems[key] = &memoryItem{
+			Value:      item.Value,
+			Expiration: exp,
+			Tags:       item.Tags,
+			Cost:       c.itemCost(key, item.Value),
+		}
+	}
+
+	var evicted []evictedEntry
+	c.mu.Lock()
+	for key, mItem := range mItems {
+		evicted = append(evicted, c.storeUnsafe(key, mItem)...)
 	}
+	c.mu.Unlock()
 
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
 	return nil
 }
 
-// DeleteByPattern 根据正则表达式模式删除缓存
+// DeleteMany 在一次加锁中批量删除缓存，实现BatchCache接口
+func (c *MemoryCache) DeleteMany(_ context.Context, keys []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if item, found := c.items[key]; found {
+			c.removeUnsafe(key, item)
+		}
+	}
+	return nil
+}
+
+// DeleteByPattern 删除键匹配glob模式的所有缓存
 // 参数：
 //
 //	ctx: 上下文（本实现中未使用，但保留以兼容接口）
-//	pattern: 正则表达式模式字符串
+//	pattern: glob模式，语法与RedisCache相同，见MatchPattern
 //
 // 返回：
 //
-//	error: 如果正则表达式无效则返回错误，否则返回nil
+//	error: 本实现始终返回nil
 //
 // 性能：
 //   - 时间复杂度：O(n)，其中n为缓存项数量
 //   - 对于大量缓存项，此操作可能较慢且占用写锁时间较长
 //
-// 安全：
-//   - 复杂的正则表达式可能导致性能问题，请谨慎使用
-//
 // 示例：
 //
 //	// 删除所有用户缓存
-//	cache.DeleteByPattern(context.Background(), "^user:")
+//	cache.DeleteByPattern(context.Background(), "user:*")
 //
 //	// 删除特定ID范围的项
-//	cache.DeleteByPattern(context.Background(), "item:[1-9][0-9]$")
+//	cache.DeleteByPattern(context.Background(), "item:[1-9][0-9]")
 func (c *MemoryCache) DeleteByPattern(_ context.Context, pattern string) error {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return fmt.Errorf("invalid pattern: %w", err)
-	}
+	c.deleteMatching(pattern)
+	return nil
+}
 
+// deleteMatching 删除键匹配模式的所有项
+func (c *MemoryCache) deleteMatching(pattern string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	for key, item := range c.items {
-		if re.MatchString(key) {
-			c.removeItemFromTagIndex(key, item.Tags)
-			delete(c.items, key)
+		if MatchPattern(pattern, key) {
+			c.removeUnsafe(key, item)
 		}
 	}
+}
 
-	return nil
+// Scan 遍历匹配模式的未过期键，实现KeyScanner接口
+// 开始遍历时复制匹配的键，遍历期间不持有锁；结构化数据的键同样会被遍历到
+func (c *MemoryCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, key := range c.matchingKeys(pattern) {
+			if err := ctx.Err(); err != nil {
+				yield("", err)
+				return
+			}
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+// matchingKeys 返回匹配模式的未过期键
+func (c *MemoryCache) matchingKeys(pattern string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	var keys []string
+	for key, item := range c.items {
+		if item.Expiration > 0 && now > item.Expiration {
+			continue
+		}
+		if MatchPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // DeleteByTag 删除特定标签的所有缓存
@@ -400,8 +562,7 @@ func (c *MemoryCache) DeleteByTag(_ context.Context, tag string) error {
 	// 删除所有标记的键
 	for _, key := range keysToDelete {
 		if item, found := c.items[key]; found {
-			c.removeItemFromTagIndex(key, item.Tags)
-			delete(c.items, key)
+			c.removeUnsafe(key, item)
 		}
 	}
 
@@ -429,64 +590,83 @@ func (c *MemoryCache) Exists(_ context.Context, key string) (bool, error) {
 // Increment 增加数值
 func (c *MemoryCache) Increment(_ context.Context, key string, value int64) (int64, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
 	item, found := c.items[key]
-	if !found {
-		// 如果不存在，创建新项
-		c.items[key] = &memoryItem{
+	// 如果不存在或已过期，创建新项
+	if !found || (item.Expiration > 0 && time.Now().UnixNano() > item.Expiration) {
+		evicted := c.storeUnsafe(key, &memoryItem{
 			Value:      value,
 			Expiration: 0,
 			Tags:       []string{},
-		}
-		return value, nil
-	}
+			Cost:       c.itemCost(key, value),
+		})
+		c.mu.Unlock()
 
-	// 检查是否过期
-	if item.Expiration > 0 && time.Now().UnixNano() > item.Expiration {
-		// 如果过期，创建新项
-		c.items[key] = &memoryItem{
-			Value:      value,
-			Expiration: 0,
-			Tags:       []string{},
-		}
+		c.notifyEvicted(evicted, EvictionReasonCapacity)
 		return value, nil
 	}
+	defer c.mu.Unlock()
 
 	// 尝试转换为整数类型并增加
-	var newValue int64
-	switch v := item.Value.(type) {
+	current, ok := counterValue(item.Value)
+	if !ok {
+		return 0, fmt.Errorf("value is not a number: %v", item.Value)
+	}
+	newValue := current + value
+
+	// 更新值
+	item.Value = newValue
+	c.version++
+	item.Version = c.version
+	return newValue, nil
+}
+
+// counterValue 将数值类型的缓存值转换为int64
+func counterValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
 	case int:
-		newValue = int64(v) + value
+		return int64(v), true
 	case int8:
-		newValue = int64(v) + value
+		return int64(v), true
 	case int16:
-		newValue = int64(v) + value
+		return int64(v), true
 	case int32:
-		newValue = int64(v) + value
+		return int64(v), true
 	case int64:
-		newValue = v + value
+		return v, true
 	case uint:
-		newValue = int64(v) + value
+		return int64(v), true
 	case uint8:
-		newValue = int64(v) + value
+		return int64(v), true
 	case uint16:
-		newValue = int64(v) + value
+		return int64(v), true
 	case uint32:
-		newValue = int64(v) + value
+		return int64(v), true
 	case uint64:
-		newValue = int64(v) + value
+		return int64(v), true
 	case float32:
-		newValue = int64(v) + value
+		return int64(v), true
 	case float64:
-		newValue = int64(v) + value
+		return int64(v), true
 	default:
-		return 0, fmt.Errorf("value is not a number: %v", item.Value)
+		return 0, false
 	}
+}
 
-	// 更新值
-	item.Value = newValue
-	return newValue, nil
+// readCounter 读取Increment计数器，实现counterReader接口
+func (c *MemoryCache) readCounter(_ context.Context, key string) (int64, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, found := c.liveItemUnsafe(key)
+	if !found {
+		return 0, false, nil
+	}
+	value, ok := counterValue(item.Value)
+	if !ok {
+		return 0, false, fmt.Errorf("value is not a number: %v", item.Value)
+	}
+	return value, true, nil
 }
 
 // Decrement 减少数值
@@ -498,6 +678,12 @@ func (c *MemoryCache) Decrement(ctx context.Context, key string, value int64) (i
 func (c *MemoryCache) Flush(_ context.Context) error {
 	c.mu.Lock()
 	c.items = make(map[string]*memoryItem)
+	c.cost = 0
+	if c.evictor != nil {
+		c.evictMu.Lock()
+		c.evictor = newEvictor(c.options.EvictionPolicy, c.options.MaxEntries)
+		c.evictMu.Unlock()
+	}
 	c.mu.Unlock()
 
 	c.tagMu.Lock()
@@ -507,12 +693,16 @@ func (c *MemoryCache) Flush(_ context.Context) error {
 	return nil
 }
 
-// Close 关闭缓存连接
+// Close 关闭缓存，停止后台清理
+// 设置了快照路径时写入快照，返回写入快照的错误
 func (c *MemoryCache) Close() error {
-	if c.janitor != nil {
-		c.janitor.stop()
-	}
-	return nil
+	c.closeOnce.Do(func() {
+		if c.janitor != nil {
+			c.janitor.stop()
+		}
+		c.closeErr = c.stopSnapshots()
+	})
+	return c.closeErr
 }
 
 // 从标签索引中删除项
@@ -548,17 +738,137 @@ func (c *MemoryCache) updateTagIndex(key string, tags []string) {
 func (c *MemoryCache) deleteExpired() {
 	now := time.Now().UnixNano()
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
+	var expired []evictedEntry
 	for key, item := range c.items {
 		if item.Expiration > 0 && now > item.Expiration {
-			c.removeItemFromTagIndex(key, item.Tags)
-			delete(c.items, key)
+			c.removeUnsafe(key, item)
+			expired = append(expired, evictedEntry{key: key, value: item.Value})
+		}
+	}
+	c.mu.Unlock()
+
+	c.expirations.Add(uint64(len(expired)))
+	c.notifyEvicted(expired, EvictionReasonExpired)
+}
+
+// Stats 返回缓存统计信息
+func (c *MemoryCache) Stats() Stats {
+	c.mu.RLock()
+	entries, cost := len(c.items), c.cost
+	c.mu.RUnlock()
+
+	return Stats{
+		Entries:     int64(entries),
+		Cost:        cost,
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+}
+
+// storeUnsafe 写入缓存项并按容量限制淘汰，返回被淘汰的项（调用方需持有写锁）
+func (c *MemoryCache) storeUnsafe(key string, item *memoryItem) []evictedEntry {
+	// 检查是否已存在，如果存在，需要更新标签索引
+	if oldItem, found := c.items[key]; found {
+		c.removeItemFromTagIndex(key, oldItem.Tags)
+		c.cost -= oldItem.Cost
+	}
+
+	c.version++
+	item.Version = c.version
+	c.items[key] = item
+	c.cost += item.Cost
+	c.updateTagIndex(key, item.Tags)
+
+	if c.evictor == nil {
+		return nil
+	}
+
+	// 先在已有的项中淘汰以腾出空间，再将新项交给淘汰器，避免新项因访问频率低被立即淘汰
+	var evicted []evictedEntry
+	for c.overCapacityUnsafe() {
+		c.evictMu.Lock()
+		victim, ok := c.evictor.victim()
+		c.evictMu.Unlock()
+		if !ok {
+			// 只剩新项本身仍超出限制
+			victim = key
+		}
+
+		victimItem, found := c.items[victim]
+		if !found {
+			c.evictMu.Lock()
+			c.evictor.remove(victim)
+			c.evictMu.Unlock()
+			continue
 		}
+
+		c.removeUnsafe(victim, victimItem)
+		c.evictions.Add(1)
+		evicted = append(evicted, evictedEntry{key: victim, value: victimItem.Value})
+	}
+
+	if _, found := c.items[key]; found {
+		c.evictMu.Lock()
+		c.evictor.add(key)
+		c.evictMu.Unlock()
+	}
+	return evicted
+}
+
+// removeUnsafe 移除缓存项（调用方需持有写锁）
+func (c *MemoryCache) removeUnsafe(key string, item *memoryItem) {
+	c.removeItemFromTagIndex(key, item.Tags)
+	delete(c.items, key)
+	c.cost -= item.Cost
+
+	if c.evictor != nil {
+		c.evictMu.Lock()
+		c.evictor.remove(key)
+		c.evictMu.Unlock()
+	}
+}
+
+// overCapacityUnsafe 是否超出容量限制（调用方需持有锁）
+func (c *MemoryCache) overCapacityUnsafe() bool {
+	if c.options.MaxEntries > 0 && len(c.items) > c.options.MaxEntries {
+		return true
+	}
+	return c.options.MaxCost > 0 && c.cost > c.options.MaxCost
+}
+
+// recordAccess 记录一次命中，供淘汰策略使用（调用方需持有读锁或写锁）
+func (c *MemoryCache) recordAccess(key string) {
+	if c.evictor == nil {
+		return
+	}
+	c.evictMu.Lock()
+	c.evictor.access(key)
+	c.evictMu.Unlock()
+}
+
+// itemCost 计算缓存项成本，未设置MaxCost时返回0
+func (c *MemoryCache) itemCost(key string, value interface{}) int64 {
+	if c.options.MaxCost <= 0 {
+		return 0
+	}
+	if c.options.CostFunc != nil {
+		return c.options.CostFunc(key, value)
+	}
+	return EstimateCost(key, value)
+}
+
+// notifyEvicted 调用淘汰回调（调用方不能持有锁）
+func (c *MemoryCache) notifyEvicted(entries []evictedEntry, reason EvictionReason) {
+	if c.options.OnEvict == nil {
+		return
+	}
+	for _, entry := range entries {
+		c.options.OnEvict(entry.key, entry.value, reason)
 	}
 }
 
-// janitor 负责定期清理过期项
+// janitor 负责定期清理过期项，MemoryCache和ShardedMemoryCache共用
 type janitor struct {
 	interval time.Duration
 	stopChan chan bool
@@ -571,13 +881,14 @@ func newJanitor(interval time.Duration) *janitor {
 	}
 }
 
-func (j *janitor) run(c *MemoryCache) {
+// run 启动后台协程，每个间隔调用一次cleanup
+func (j *janitor) run(cleanup func()) {
 	ticker := time.NewTicker(j.interval)
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				c.deleteExpired()
+				cleanup()
 			case <-j.stopChan:
 				ticker.Stop()
 				return
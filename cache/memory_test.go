@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -74,7 +75,7 @@ func TestMemoryCache_DeleteByPattern(t *testing.T) {
 	}
 
 	// 按模式删除
-	err := cache.DeleteByPattern(ctx, "pattern:.*")
+	err := cache.DeleteByPattern(ctx, "pattern:*")
 	if err != nil {
 		t.Fatalf("DeleteByPattern returned error: %v", err)
 	}
@@ -213,3 +214,220 @@ func TestMemoryCache_JanitorCleanup(t *testing.T) {
 	value, found := cache.Get(ctx, "after_stop_key")
 	t.Logf("After janitor stop - Key: after_stop_key, Found: %v, Value: %v", found, value)
 }
+
+func TestMemoryCache_EvictionLRU(t *testing.T) {
+	var evicted []string
+	cache := NewMemoryCache(Options{
+		MaxEntries: 3,
+		OnEvict: func(key string, value interface{}, reason EvictionReason) {
+			if reason != EvictionReasonCapacity {
+				t.Errorf("Expected capacity eviction, got %s", reason)
+			}
+			evicted = append(evicted, key)
+		},
+	})
+	defer cache.Close()
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		_ = cache.Set(ctx, key, key, time.Minute)
+	}
+	// 访问a，使b成为最近最少使用的项
+	cache.Get(ctx, "a")
+	_ = cache.Set(ctx, "d", "d", time.Minute)
+
+	if _, found := cache.Get(ctx, "b"); found {
+		t.Error("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, found := cache.Get(ctx, key); !found {
+			t.Errorf("Expected %s to be retained", key)
+		}
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("Unexpected evicted keys: %v", evicted)
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 3 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// 覆盖已有键不会触发淘汰
+	_ = cache.Set(ctx, "a", "a2", time.Minute)
+	if stats := cache.Stats(); stats.Evictions != 1 {
+		t.Errorf("Overwriting should not evict, stats: %+v", stats)
+	}
+}
+
+func TestMemoryCache_EvictionLFU(t *testing.T) {
+	cache := NewMemoryCache(Options{MaxEntries: 3, EvictionPolicy: EvictionLFU})
+	defer cache.Close()
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		_ = cache.Set(ctx, key, key, time.Minute)
+	}
+	for i := 0; i < 3; i++ {
+		cache.Get(ctx, "a")
+		cache.Get(ctx, "c")
+	}
+	cache.Get(ctx, "b")
+
+	// b访问次数最少，即使最近访问过也会被淘汰
+	_ = cache.Set(ctx, "d", "d", time.Minute)
+	if _, found := cache.Get(ctx, "b"); found {
+		t.Error("Expected b to be evicted")
+	}
+	if _, found := cache.Get(ctx, "a"); !found {
+		t.Error("Expected a to be retained")
+	}
+}
+
+func TestMemoryCache_EvictionTinyLFU(t *testing.T) {
+	cache := NewMemoryCache(Options{MaxEntries: 100, EvictionPolicy: EvictionTinyLFU})
+	defer cache.Close()
+	ctx := context.Background()
+
+	// 热点数据被频繁访问
+	for i := 0; i < 50; i++ {
+		_ = cache.Set(ctx, fmt.Sprintf("hot:%d", i), i, time.Minute)
+	}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 50; i++ {
+			cache.Get(ctx, fmt.Sprintf("hot:%d", i))
+		}
+	}
+
+	// 一次性扫描大量冷数据
+	for i := 0; i < 1000; i++ {
+		_ = cache.Set(ctx, fmt.Sprintf("scan:%d", i), i, time.Minute)
+	}
+
+	hits := 0
+	for i := 0; i < 50; i++ {
+		if _, found := cache.Get(ctx, fmt.Sprintf("hot:%d", i)); found {
+			hits++
+		}
+	}
+	if hits < 45 {
+		t.Errorf("Expected hot keys to survive the scan, only %d/50 retained", hits)
+	}
+	if stats := cache.Stats(); stats.Entries != 100 {
+		t.Errorf("Expected 100 entries, got %d", stats.Entries)
+	}
+}
+
+func TestMemoryCache_MaxCost(t *testing.T) {
+	var evicted []string
+	cache := NewMemoryCache(Options{
+		MaxCost: 10,
+		CostFunc: func(key string, value interface{}) int64 {
+			return int64(len(value.(string)))
+		},
+		OnEvict: func(key string, value interface{}, reason EvictionReason) {
+			evicted = append(evicted, key)
+		},
+	})
+	defer cache.Close()
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "a", "12345", time.Minute)
+	_ = cache.Set(ctx, "b", "1234", time.Minute)
+	if stats := cache.Stats(); stats.Cost != 9 {
+		t.Errorf("Expected cost 9, got %d", stats.Cost)
+	}
+
+	_ = cache.Set(ctx, "c", "123", time.Minute)
+	if _, found := cache.Get(ctx, "a"); found {
+		t.Error("Expected a to be evicted when cost exceeds the limit")
+	}
+	if stats := cache.Stats(); stats.Cost != 7 || stats.Entries != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// 单个超出限制的项会被立即淘汰
+	_ = cache.Set(ctx, "huge", "12345678901", time.Minute)
+	if _, found := cache.Get(ctx, "huge"); found {
+		t.Error("Item larger than MaxCost should not be retained")
+	}
+
+	_ = cache.Delete(ctx, "b")
+	if stats := cache.Stats(); stats.Cost != 0 {
+		t.Errorf("Expected cost 0 after deletes, got %d", stats.Cost)
+	}
+}
+
+func TestMemoryCache_ExpirationCallback(t *testing.T) {
+	expired := make(chan string, 1)
+	cache := NewMemoryCache(Options{
+		CleanupInterval: time.Millisecond * 20,
+		OnEvict: func(key string, value interface{}, reason EvictionReason) {
+			if reason == EvictionReasonExpired {
+				expired <- key
+			}
+		},
+	})
+	defer cache.Close()
+
+	_ = cache.Set(context.Background(), "short", "v", time.Millisecond*10)
+
+	select {
+	case key := <-expired:
+		if key != "short" {
+			t.Errorf("Unexpected expired key %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected expiration callback")
+	}
+	if stats := cache.Stats(); stats.Expirations != 1 {
+		t.Errorf("Expected 1 expiration, got %d", stats.Expirations)
+	}
+}
+
+func benchmarkMemoryCache(b *testing.B, opts Options) {
+	cache := NewMemoryCache(opts)
+	defer cache.Close()
+	ctx := context.Background()
+
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key:%d", i)
+		_ = cache.Set(ctx, keys[i], i, time.Minute)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			// 90%读、10%写
+			if i%10 == 0 {
+				_ = cache.Set(ctx, key, i, time.Minute)
+			} else {
+				cache.Get(ctx, key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkMemoryCache_Unbounded(b *testing.B) {
+	benchmarkMemoryCache(b, Options{})
+}
+
+func BenchmarkMemoryCache_LRU(b *testing.B) {
+	benchmarkMemoryCache(b, Options{MaxEntries: 2048, EvictionPolicy: EvictionLRU})
+}
+
+func BenchmarkMemoryCache_LFU(b *testing.B) {
+	benchmarkMemoryCache(b, Options{MaxEntries: 2048, EvictionPolicy: EvictionLFU})
+}
+
+func BenchmarkMemoryCache_TinyLFU(b *testing.B) {
+	benchmarkMemoryCache(b, Options{MaxEntries: 2048, EvictionPolicy: EvictionTinyLFU})
+}
+
+func BenchmarkMemoryCache_MaxCost(b *testing.B) {
+	benchmarkMemoryCache(b, Options{MaxCost: 64 * 1024})
+}
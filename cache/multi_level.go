@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"fmt"
+	"iter"
+	"reflect"
 	"time"
 
 	"github.com/guanzhenxing/go-snap/errors"
@@ -26,8 +28,11 @@ type WriteMode int
 const (
 	// WriteModeWriteThrough 直写模式：同时写入所有缓存层级
 	WriteModeWriteThrough WriteMode = iota
-	// WriteModeWriteBack 回写模式：只写入最高层级，其他层级按需加载
+	// WriteModeWriteBack 回写模式：立即写入本地缓存，由后台协程按批异步写入远程缓存
+	// 同一键在写入远程缓存之前的多次写入会被合并，关闭时写入剩余的项
 	WriteModeWriteBack
+	// WriteModeWriteAround 绕写模式：只写入远程缓存并删除本地旧值，本地缓存按需加载
+	WriteModeWriteAround
 )
 
 // MultiLevelCache 实现多级缓存
@@ -36,12 +41,32 @@ type MultiLevelCache struct {
 	remote    Cache // 远程缓存，通常为Redis缓存
 	writeMode WriteMode
 	localTTL  time.Duration // 本地缓存的TTL，通常比远程缓存的TTL短
+	loads     loadGroup     // 合并同一键的并发加载
+
+	invalidator *invalidator // 跨实例失效广播，未启用时为nil
+	writeBehind *writeBehind // 回写队列，仅回写模式下不为nil
+
+	origin      Cache           // 未经过熔断器的远程缓存，未启用熔断器时与remote相同
+	breaker     *circuitBreaker // 远程缓存熔断器，未启用时为nil
+	reconciler  *reconciler     // 熔断期间写入的同步器，仅启用熔断器时不为nil
+	degradedTTL time.Duration   // 熔断期间写入本地缓存的TTL
 }
 
 // MultiLevelOptions 多级缓存的选项
 type MultiLevelOptions struct {
 	WriteMode WriteMode
 	LocalTTL  time.Duration
+
+	// WriteBack 回写模式选项，仅在WriteMode为WriteModeWriteBack时生效，零值字段使用默认值
+	WriteBack WriteBackOptions
+
+	// Invalidation 跨实例本地缓存失效选项，为nil时不启用
+	// 启用后写入、删除、按标签或模式删除以及清空操作都会通知其他实例清除本地缓存
+	Invalidation *InvalidationOptions
+
+	// CircuitBreaker 远程缓存熔断器选项，为nil时不启用
+	// 启用后远程缓存连续失败时降级为只使用本地缓存，恢复后将降级期间的写入同步到远程缓存
+	CircuitBreaker *CircuitBreakerOptions
 }
 
 // DefaultMultiLevelOptions 返回默认的多级缓存选项
@@ -65,12 +90,80 @@ func NewMultiLevelCache(local, remote Cache, opts ...MultiLevelOptions) (*MultiL
 		options = DefaultMultiLevelOptions()
 	}
 
-	return &MultiLevelCache{
+	c := &MultiLevelCache{
 		local:     local,
 		remote:    remote,
+		origin:    remote,
 		writeMode: options.WriteMode,
 		localTTL:  options.LocalTTL,
-	}, nil
+	}
+
+	if options.Invalidation != nil {
+		inv, err := newInvalidator(local, remote, *options.Invalidation)
+		if err != nil {
+			return nil, err
+		}
+		c.invalidator = inv
+	}
+
+	if options.CircuitBreaker != nil {
+		c.breaker = newCircuitBreaker(*options.CircuitBreaker, c.circuitStateChanged)
+		c.remote = &guardedCache{inner: remote, breaker: c.breaker}
+		c.reconciler = newReconciler(c.remote, c.breaker, c.breaker.options.OpenTimeout, c.reconciled)
+		c.degradedTTL = options.CircuitBreaker.DegradedTTL
+		if c.degradedTTL <= 0 {
+			c.degradedTTL = c.localTTL
+		}
+	}
+
+	if options.WriteMode == WriteModeWriteBack {
+		// 写入远程缓存后再通知其他实例，避免其他实例重新加载到旧值
+		c.writeBehind = newWriteBehind(c.remote, options.WriteBack, c.invalidateKeys)
+	}
+
+	return c, nil
+}
+
+// circuitStateChanged 熔断器关闭后立即同步降级期间的写入
+func (c *MultiLevelCache) circuitStateChanged(from, to CircuitState) {
+	if to == CircuitClosed && c.reconciler != nil {
+		c.reconciler.trigger()
+	}
+}
+
+// reconciled 降级期间的写入同步到远程缓存后，通知其他实例清除本地缓存
+func (c *MultiLevelCache) reconciled(ctx context.Context, op *reconcileOp, keys []string) {
+	if c.invalidator == nil {
+		return
+	}
+	if op == nil {
+		c.invalidator.publishKeys(ctx, keys...)
+		return
+	}
+	switch op.op {
+	case invalidateTag:
+		c.invalidator.publishTag(ctx, op.arg)
+	case invalidatePattern:
+		c.invalidator.publishPattern(ctx, op.arg)
+	case invalidateFlush:
+		c.invalidator.publishFlush(ctx)
+	}
+}
+
+// writeRemote 执行一次远程写入
+// 启用熔断器且熔断器打开或仍有待同步项时，调用record记录写入并返回degraded为true，由后台协程稍后同步
+func (c *MultiLevelCache) writeRemote(write func() error, record func()) (degraded bool, err error) {
+	if c.reconciler == nil {
+		return false, write()
+	}
+	return c.reconciler.guard(write, record)
+}
+
+// invalidateKeys 通知其他实例清除本地缓存中的键
+func (c *MultiLevelCache) invalidateKeys(ctx context.Context, keys ...string) {
+	if c.invalidator != nil && len(keys) > 0 {
+		c.invalidator.publishKeys(ctx, keys...)
+	}
 }
 
 // Get 从缓存中获取值，优先从本地缓存获取，如果本地缓存没有，再从远程缓存获取
@@ -81,6 +174,11 @@ func (c *MultiLevelCache) Get(ctx context.Context, key string) (interface{}, boo
 		return value, true
 	}
 
+	// 回写模式下尚未写入远程缓存的值
+	if value, _, found := c.pendingValue(key); found {
+		return value, true
+	}
+
 	// 如果本地缓存没有，尝试从远程缓存获取
 	value, found = c.remote.Get(ctx, key)
 	if !found {
@@ -100,6 +198,9 @@ func (c *MultiLevelCache) GetWithTTL(ctx context.Context, key string) (interface
 	if found {
 		return value, ttl, true
 	}
+	if value, ttl, found := c.pendingValue(key); found {
+		return value, ttl, true
+	}
 
 	// 如果本地缓存没有，尝试从远程缓存获取
 	value, ttl, found = c.remote.GetWithTTL(ctx, key)
@@ -117,35 +218,78 @@ func (c *MultiLevelCache) GetWithTTL(ctx context.Context, key string) (interface
 	return value, ttl, true
 }
 
-// Set 设置缓存
-func (c *MultiLevelCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	var err error
+// GetInto 获取缓存值并解码到target，实现ValueDecoder接口
+// 本地缓存解码失败时视为本地未命中并移除该项，再从远程缓存读取；
+// 从远程缓存读取成功后将解码后的值写入本地缓存，之后的读取直接得到目标类型
+func (c *MultiLevelCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	found, err := GetInto(ctx, c.local, key, target)
+	if err == nil && found {
+		return true, nil
+	}
+	if err != nil {
+		_ = c.local.Delete(ctx, key)
+	}
+	if value, _, pending := c.pendingValue(key); pending {
+		return true, assignValue(key, value, target)
+	}
 
-	// 根据写入模式决定如何写入缓存
-	if c.writeMode == WriteModeWriteThrough {
-		// 直写模式：同时写入本地和远程缓存
-		localTTL := ttl
-		if ttl == 0 || ttl > c.localTTL {
-			localTTL = c.localTTL
-		}
+	found, err = GetInto(ctx, c.remote, key, target)
+	if isCircuitOpen(err) {
+		return false, nil
+	}
+	if err != nil || !found {
+		return false, err
+	}
 
-		_ = c.local.Set(ctx, key, value, localTTL)
-		err2 := c.remote.Set(ctx, key, value, ttl)
+	_ = c.local.Set(ctx, key, copyValue(reflect.ValueOf(target).Elem()).Interface(), c.localTTL)
+	return true, nil
+}
 
-		// 只要远程缓存写入成功就认为成功
-		if err2 != nil {
-			return err2
-		}
-		return nil
-	} else {
-		// 回写模式：只写入远程缓存
-		err = c.remote.Set(ctx, key, value, ttl)
-		if err != nil {
-			return err
+// GetIntoWithTTL 获取缓存值和剩余TTL并解码到target，实现ValueDecoder接口
+func (c *MultiLevelCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	ttl, found, err := GetIntoWithTTL(ctx, c.local, key, target)
+	if err == nil && found {
+		return ttl, true, nil
+	}
+	if err != nil {
+		_ = c.local.Delete(ctx, key)
+	}
+	if value, ttl, pending := c.pendingValue(key); pending {
+		if err := assignValue(key, value, target); err != nil {
+			return 0, false, err
 		}
+		return ttl, true, nil
 	}
 
-	return nil
+	ttl, found, err = GetIntoWithTTL(ctx, c.remote, key, target)
+	if isCircuitOpen(err) {
+		return 0, false, nil
+	}
+	if err != nil || !found {
+		return 0, false, err
+	}
+
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	_ = c.local.Set(ctx, key, copyValue(reflect.ValueOf(target).Elem()).Interface(), localTTL)
+	return ttl, true, nil
+}
+
+// GetOrLoad 获取缓存值，未命中时合并并发调用只加载一次，实现Loader接口
+// 远程缓存为启用了加载锁的RedisCache时，多个实例之间同样只有一个实例调用loader；熔断期间只在实例内合并
+func (c *MultiLevelCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	var locker loadLocker
+	if remote, ok := c.origin.(*RedisCache); ok && c.CircuitState() == CircuitClosed {
+		locker = remote.loadLocker()
+	}
+	return loadThrough(ctx, c, &c.loads, locker, key, ttl, loader)
+}
+
+// Set 设置缓存
+func (c *MultiLevelCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.SetItem(ctx, key, &Item{Value: value, Expiration: ttl})
 }
 
 // SetItem 设置带完整选项的缓存项
@@ -153,61 +297,218 @@ func (c *MultiLevelCache) SetItem(ctx context.Context, key string, item *Item) e
 	if item == nil {
 		return errors.New("cache item cannot be nil")
 	}
+	return c.SetMany(ctx, map[string]*Item{key: item})
+}
 
-	var err error
+// GetMany 批量获取缓存值，实现BatchCache接口
+// 先从本地缓存批量读取，再从远程缓存批量读取本地未命中的键，并将远程命中的值回填到本地缓存
+func (c *MultiLevelCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	values, err := GetMany(ctx, c.local, keys)
+	if err != nil {
+		values = make(map[string]interface{}, len(keys))
+	}
 
-	// 根据写入模式决定如何写入缓存
-	if c.writeMode == WriteModeWriteThrough {
-		// 直写模式：同时写入本地和远程缓存
-		localItem := &Item{
-			Value:      item.Value,
-			Expiration: item.Expiration,
-			Tags:       item.Tags,
+	misses := make([]string, 0, len(keys)-len(values))
+	for _, key := range keys {
+		if _, found := values[key]; found {
+			continue
 		}
-
-		// 为本地缓存设置较短的TTL
-		if localItem.Expiration == 0 || localItem.Expiration > c.localTTL {
-			localItem.Expiration = c.localTTL
+		if value, _, found := c.pendingValue(key); found {
+			values[key] = value
+			continue
 		}
+		misses = append(misses, key)
+	}
+	if len(misses) == 0 {
+		return values, nil
+	}
+
+	remoteValues, err := GetMany(ctx, c.remote, misses)
+	if isCircuitOpen(err) {
+		return values, nil
+	}
+	if err != nil {
+		return values, err
+	}
+
+	backfill := make(map[string]*Item, len(remoteValues))
+	for key, value := range remoteValues {
+		values[key] = value
+		backfill[key] = &Item{Value: value, Expiration: c.localTTL}
+	}
+	if len(backfill) > 0 {
+		_ = SetMany(ctx, c.local, backfill)
+	}
+
+	return values, nil
+}
 
-		_ = c.local.SetItem(ctx, key, localItem)
-		err2 := c.remote.SetItem(ctx, key, item)
+// SetMany 批量设置缓存项，实现BatchCache接口
+// 根据写入模式决定如何写入各缓存层级：
+//   - 直写模式：同时写入本地和远程缓存，只要远程缓存写入成功就认为成功
+//   - 回写模式：立即写入本地缓存，由后台协程合并后批量写入远程缓存
+//   - 绕写模式：只写入远程缓存，并删除本地缓存中的旧值
+//
+// 熔断期间只以DegradedTTL写入本地缓存，远程缓存恢复后再同步
+func (c *MultiLevelCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
 
-		// 只要远程缓存写入成功就认为成功
-		if err2 != nil {
-			return err2
+	switch c.writeMode {
+	case WriteModeWriteBack:
+		_ = SetMany(ctx, c.local, c.localItems(items))
+
+		overflow := make(map[string]*Item)
+		for key, item := range items {
+			if !c.writeBehind.enqueue(key, item) {
+				overflow[key] = item
+			}
 		}
-		return nil
-	} else {
-		// 回写模式：只写入远程缓存
-		err = c.remote.SetItem(ctx, key, item)
-		if err != nil {
-			return err
+		if len(overflow) == 0 {
+			return nil
 		}
+		// 队列已满的项同步写入远程缓存
+		items = overflow
+	case WriteModeWriteAround:
+		_ = DeleteMany(ctx, c.local, itemKeys(items))
+	default:
+		_ = SetMany(ctx, c.local, c.localItems(items))
 	}
 
+	degraded, err := c.writeRemote(func() error {
+		return SetMany(ctx, c.remote, items)
+	}, func() {
+		c.reconciler.recordSet(items)
+	})
+	if err != nil {
+		return err
+	}
+	if degraded {
+		_ = SetMany(ctx, c.local, localItemsWithTTL(items, c.degradedTTL))
+		return nil
+	}
+	c.invalidateKeys(ctx, itemKeys(items)...)
 	return nil
 }
 
+// localItems 返回写入本地缓存的项，本地缓存使用较短的TTL
+func (c *MultiLevelCache) localItems(items map[string]*Item) map[string]*Item {
+	return localItemsWithTTL(items, c.localTTL)
+}
+
+// localItemsWithTTL 返回写入本地缓存的项，生存时间不超过maxTTL
+func localItemsWithTTL(items map[string]*Item, maxTTL time.Duration) map[string]*Item {
+	localItems := make(map[string]*Item, len(items))
+	for key, item := range items {
+		localItem := &Item{
+			Value:      item.Value,
+			Expiration: item.Expiration,
+			Tags:       item.Tags,
+		}
+		if localItem.Expiration == 0 || localItem.Expiration > maxTTL {
+			localItem.Expiration = maxTTL
+		}
+		localItems[key] = localItem
+	}
+	return localItems
+}
+
+// itemKeys 返回缓存项的键
+func itemKeys(items map[string]*Item) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// DeleteMany 从所有缓存层级批量删除，实现BatchCache接口
+func (c *MultiLevelCache) DeleteMany(ctx context.Context, keys []string) error {
+	if c.writeBehind != nil {
+		c.writeBehind.cancel(keys...)
+	}
+	_ = DeleteMany(ctx, c.local, keys)
+	degraded, err := c.writeRemote(func() error {
+		return DeleteMany(ctx, c.remote, keys)
+	}, func() {
+		c.reconciler.recordDelete(keys...)
+	})
+	if !degraded {
+		c.invalidateKeys(ctx, keys...)
+	}
+	return err
+}
+
 // Delete 从所有缓存层级删除值
 func (c *MultiLevelCache) Delete(ctx context.Context, key string) error {
-	// 始终从所有层级删除
+	// 始终从所有层级删除，并取消尚未写入远程缓存的值
+	if c.writeBehind != nil {
+		c.writeBehind.cancel(key)
+	}
 	_ = c.local.Delete(ctx, key)
-	return c.remote.Delete(ctx, key)
+	degraded, err := c.writeRemote(func() error {
+		return c.remote.Delete(ctx, key)
+	}, func() {
+		c.reconciler.recordDelete(key)
+	})
+	if !degraded {
+		c.invalidateKeys(ctx, key)
+	}
+	return err
 }
 
 // DeleteByPattern 根据模式从所有缓存层级删除值
 func (c *MultiLevelCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	// 待写入项先写入远程缓存，再由远程缓存按模式删除
+	if c.writeBehind != nil {
+		_ = c.writeBehind.flushAll(ctx)
+	}
+
 	// 始终从所有层级删除
 	_ = c.local.DeleteByPattern(ctx, pattern)
-	return c.remote.DeleteByPattern(ctx, pattern)
+	degraded, err := c.writeRemote(func() error {
+		return c.remote.DeleteByPattern(ctx, pattern)
+	}, func() {
+		c.reconciler.recordPattern(pattern)
+	})
+	if !degraded && c.invalidator != nil {
+		c.invalidator.publishPattern(ctx, pattern)
+	}
+	return err
+}
+
+// Scan 遍历远程缓存中匹配模式的键，实现KeyScanner接口
+// 远程缓存保存了所有数据，本地缓存只是它的子集，因此只遍历远程缓存；待写入项会先写入远程缓存
+func (c *MultiLevelCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if c.writeBehind != nil {
+			_ = c.writeBehind.flushAll(ctx)
+		}
+		for key, err := range scanCache(ctx, c.remote, pattern) {
+			if !yield(key, err) || err != nil {
+				return
+			}
+		}
+	}
 }
 
 // DeleteByTag 删除带特定标签的所有缓存
 func (c *MultiLevelCache) DeleteByTag(ctx context.Context, tag string) error {
-	// 始终从所有层级删除
+	// 始终从所有层级删除，并取消带有该标签的待写入项
+	if c.writeBehind != nil {
+		c.writeBehind.cancelTag(tag)
+	}
 	_ = c.local.DeleteByTag(ctx, tag)
-	return c.remote.DeleteByTag(ctx, tag)
+	degraded, err := c.writeRemote(func() error {
+		return c.remote.DeleteByTag(ctx, tag)
+	}, func() {
+		c.reconciler.recordTag(tag)
+	})
+	if !degraded && c.invalidator != nil {
+		c.invalidator.publishTag(ctx, tag)
+	}
+	return err
 }
 
 // Exists 检查键是否存在于任意缓存层级
@@ -217,13 +518,36 @@ func (c *MultiLevelCache) Exists(ctx context.Context, key string) (bool, error)
 	if err == nil && exists {
 		return true, nil
 	}
+	if _, _, pending := c.pendingValue(key); pending {
+		return true, nil
+	}
 
-	// 再检查远程缓存
-	return c.remote.Exists(ctx, key)
+	// 再检查远程缓存，熔断期间只检查本地缓存
+	exists, err = c.remote.Exists(ctx, key)
+	if isCircuitOpen(err) {
+		return false, nil
+	}
+	return exists, err
 }
 
 // Increment 增加数值，操作会传递到所有缓存层级
+// 计数只在远程缓存中保证原子性，熔断期间和降级期间的写入同步完成之前返回ErrCircuitOpen
 func (c *MultiLevelCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	if c.reconciler != nil {
+		c.reconciler.gate.RLock()
+		defer c.reconciler.gate.RUnlock()
+		if c.reconciler.pending() {
+			return 0, ErrCircuitOpen
+		}
+	}
+
+	// 回写模式下先将该键的待写入值写入远程缓存
+	if c.writeBehind != nil {
+		if err := c.writeBehind.flushKeys(ctx, key); err != nil {
+			return 0, err
+		}
+	}
+
 	// 在远程缓存中执行操作
 	result, err := c.remote.Increment(ctx, key, value)
 	if err != nil {
@@ -238,6 +562,7 @@ func (c *MultiLevelCache) Increment(ctx context.Context, key string, value int64
 		_ = c.local.Delete(ctx, key)
 	}
 
+	c.invalidateKeys(ctx, key)
 	return result, nil
 }
 
@@ -246,28 +571,247 @@ func (c *MultiLevelCache) Decrement(ctx context.Context, key string, value int64
 	return c.Increment(ctx, key, -value)
 }
 
+// conditional 在远程缓存中执行条件操作，远程缓存是键的权威数据源
+// 与Increment相同，有待同步的熔断期间写入时返回ErrCircuitOpen，回写模式下先写入该键的待写入值；
+// fn返回changed为true时删除本地缓存中的旧值并通知其他实例
+func (c *MultiLevelCache) conditional(ctx context.Context, key string, fn func(ctx context.Context, remote ConditionalCache) (changed bool, err error)) error {
+	remote, ok := Conditional(c.origin)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	if c.reconciler != nil {
+		c.reconciler.gate.RLock()
+		defer c.reconciler.gate.RUnlock()
+		if c.reconciler.pending() {
+			return ErrCircuitOpen
+		}
+	}
+
+	if c.writeBehind != nil {
+		if err := c.writeBehind.flushKeys(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	var changed bool
+	run := func(ctx context.Context) error {
+		var err error
+		changed, err = fn(ctx, remote)
+		return err
+	}
+	var err error
+	if guarded, ok := c.remote.(*guardedCache); ok {
+		err = guarded.do(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil && !changed {
+		return err
+	}
+
+	if changed {
+		_ = c.local.Delete(ctx, key)
+		c.invalidateKeys(ctx, key)
+	}
+	return err
+}
+
+// SetNX 仅在键不存在时写入远程缓存，实现ConditionalCache接口
+// 远程缓存未实现ConditionalCache时返回ErrNotSupported
+func (c *MultiLevelCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	var set bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		set, err = remote.SetNX(ctx, key, value, ttl)
+		return set, err
+	})
+	return set, err
+}
+
+// SetXX 仅在键已存在时写入远程缓存
+func (c *MultiLevelCache) SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	var set bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		set, err = remote.SetXX(ctx, key, value, ttl)
+		return set, err
+	})
+	return set, err
+}
+
+// GetSet 在远程缓存中写入新值并返回旧值
+func (c *MultiLevelCache) GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration, old interface{}) (bool, error) {
+	var found bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		found, err = remote.GetSet(ctx, key, value, ttl, old)
+		// 旧值解码失败时新值已经写入
+		return found || err == nil, err
+	})
+	return found, err
+}
+
+// GetAndDelete 从远程缓存删除键并返回删除前的值
+func (c *MultiLevelCache) GetAndDelete(ctx context.Context, key string, target interface{}) (bool, error) {
+	var found bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		found, err = remote.GetAndDelete(ctx, key, target)
+		return found, err
+	})
+	return found, err
+}
+
+// GetVersioned 从远程缓存读取值和版本，不使用本地缓存，保证版本与远程缓存一致
+func (c *MultiLevelCache) GetVersioned(ctx context.Context, key string, target interface{}) (Version, bool, error) {
+	var version Version
+	var found bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		version, found, err = remote.GetVersioned(ctx, key, target)
+		return false, err
+	})
+	return version, found, err
+}
+
+// CompareAndSwap 仅在远程缓存中的当前版本等于version时写入
+func (c *MultiLevelCache) CompareAndSwap(ctx context.Context, key string, version Version, value interface{}, ttl time.Duration) (bool, error) {
+	var swapped bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		swapped, err = remote.CompareAndSwap(ctx, key, version, value, ttl)
+		return swapped, err
+	})
+	return swapped, err
+}
+
+// CompareAndDelete 仅在远程缓存中的当前版本等于version时删除
+func (c *MultiLevelCache) CompareAndDelete(ctx context.Context, key string, version Version) (bool, error) {
+	var deleted bool
+	err := c.conditional(ctx, key, func(ctx context.Context, remote ConditionalCache) (bool, error) {
+		var err error
+		deleted, err = remote.CompareAndDelete(ctx, key, version)
+		return deleted, err
+	})
+	return deleted, err
+}
+
 // Flush 清空所有缓存层级
 func (c *MultiLevelCache) Flush(ctx context.Context) error {
+	// 丢弃尚未写入远程缓存的值
+	if c.writeBehind != nil {
+		c.writeBehind.discard()
+	}
+
 	// 清空本地缓存
 	_ = c.local.Flush(ctx)
 
 	// 清空远程缓存
-	return c.remote.Flush(ctx)
+	degraded, err := c.writeRemote(func() error {
+		return c.remote.Flush(ctx)
+	}, func() {
+		c.reconciler.recordFlush()
+	})
+	if !degraded && c.invalidator != nil {
+		c.invalidator.publishFlush(ctx)
+	}
+	return err
 }
 
 // Close 关闭所有缓存连接
 func (c *MultiLevelCache) Close() error {
+	// 写入剩余的待写入项
+	var flushErr error
+	if c.writeBehind != nil {
+		flushErr = c.writeBehind.close()
+	}
+
+	// 同步熔断期间的写入
+	if c.reconciler != nil {
+		if err := c.reconciler.close(); err != nil && flushErr == nil {
+			flushErr = err
+		}
+	}
+
+	// 停止接收失效消息
+	if c.invalidator != nil {
+		c.invalidator.close()
+	}
+
 	// 关闭本地缓存
 	_ = c.local.Close()
 
 	// 关闭远程缓存
-	return c.remote.Close()
+	if err := c.remote.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// FlushPending 立即将回写模式下所有待写入的项写入远程缓存
+// 非回写模式下直接返回nil
+func (c *MultiLevelCache) FlushPending(ctx context.Context) error {
+	if c.writeBehind == nil {
+		return nil
+	}
+	return c.writeBehind.flushAll(ctx)
+}
+
+// WriteBackStats 返回回写模式的统计信息，非回写模式下返回零值
+func (c *MultiLevelCache) WriteBackStats() WriteBackStats {
+	if c.writeBehind == nil {
+		return WriteBackStats{}
+	}
+	return c.writeBehind.stats()
+}
+
+// CircuitState 返回远程缓存熔断器的当前状态，未启用熔断器时返回CircuitClosed
+func (c *MultiLevelCache) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState()
+}
+
+// CircuitBreakerStats 返回远程缓存熔断器的统计信息，未启用熔断器时返回零值
+func (c *MultiLevelCache) CircuitBreakerStats() CircuitBreakerStats {
+	if c.breaker == nil {
+		return CircuitBreakerStats{}
+	}
+	stats := c.breaker.stats()
+	stats.PendingKeys = int(c.reconciler.pendingCount.Load())
+	stats.Reconciled = c.reconciler.reconciled.Load()
+	stats.ReconcileDropped = c.reconciler.dropped.Load()
+	return stats
+}
+
+// Reconcile 立即将熔断期间的写入同步到远程缓存，熔断器打开时返回ErrCircuitOpen
+// 未启用熔断器时直接返回nil
+func (c *MultiLevelCache) Reconcile(ctx context.Context) error {
+	if c.reconciler == nil {
+		return nil
+	}
+	return c.reconciler.reconcile(ctx)
+}
+
+// pendingValue 返回回写模式下尚未写入远程缓存的值
+func (c *MultiLevelCache) pendingValue(key string) (interface{}, time.Duration, bool) {
+	if c.writeBehind == nil {
+		return nil, 0, false
+	}
+	return c.writeBehind.lookup(key)
 }
 
 // FlushLevel 清空指定缓存层级
 func (c *MultiLevelCache) FlushLevel(ctx context.Context, level CacheLevel) error {
 	var err error
 
+	// 远程缓存被清空时丢弃尚未写入的值
+	if (level == CacheLevelRedis || level == CacheLevelAll) && c.writeBehind != nil {
+		c.writeBehind.discard()
+	}
+
 	switch level {
 	case CacheLevelLocal:
 		err = c.local.Flush(ctx)
@@ -280,5 +824,13 @@ func (c *MultiLevelCache) FlushLevel(ctx context.Context, level CacheLevel) erro
 		err = fmt.Errorf("unknown cache level: %d", level)
 	}
 
+	// 远程缓存被清空后，熔断期间记录的写入不再需要同步，其他实例本地缓存中的数据也已失效
+	if err == nil && level != CacheLevelLocal && c.reconciler != nil {
+		c.reconciler.discard()
+	}
+	if err == nil && level != CacheLevelLocal && c.invalidator != nil {
+		c.invalidator.publishFlush(ctx)
+	}
+
 	return err
 }
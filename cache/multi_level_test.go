@@ -117,6 +117,7 @@ func TestMultiLevelCache_CacheHierarchy(t *testing.T) {
 	writeBackCache, err := NewMultiLevelCache(localCache, remoteCache, MultiLevelOptions{
 		WriteMode: WriteModeWriteBack,
 		LocalTTL:  time.Minute,
+		WriteBack: WriteBackOptions{FlushInterval: time.Hour},
 	})
 	if err != nil {
 		t.Fatalf("NewMultiLevelCache (write-back) returned error: %v", err)
@@ -134,37 +135,88 @@ func TestMultiLevelCache_CacheHierarchy(t *testing.T) {
 			t.Fatalf("WriteBack Set returned error: %v", err)
 		}
 
+		// 验证本地缓存立即设置
+		value, found := localCache.Get(ctx, "wb_key")
+		if !found {
+			t.Fatal("WriteBack: Local cache miss")
+		}
+		if value != "wb_value" {
+			t.Fatalf("WriteBack: Expected local value 'wb_value', got %v", value)
+		}
+
+		// 验证远程缓存尚未写入
+		if _, found := remoteCache.Get(ctx, "wb_key"); found {
+			t.Fatal("WriteBack: Unexpected remote cache hit before flush")
+		}
+
+		// 写入待写入项后远程缓存已设置
+		if err := writeBackCache.FlushPending(ctx); err != nil {
+			t.Fatalf("WriteBack FlushPending returned error: %v", err)
+		}
+		value, ttl, found := remoteCache.GetWithTTL(ctx, "wb_key")
+		if !found {
+			t.Fatal("WriteBack: Remote cache miss after flush")
+		}
+		if value != "wb_value" {
+			t.Fatalf("WriteBack: Expected remote value 'wb_value', got %v", value)
+		}
+		if ttl <= time.Minute || ttl > time.Minute*5 {
+			t.Fatalf("WriteBack: Expected remote TTL between 1-5 minutes, got %v", ttl)
+		}
+	})
+
+	// 创建绕写模式的多级缓存
+	writeAroundCache, err := NewMultiLevelCache(localCache, remoteCache, MultiLevelOptions{
+		WriteMode: WriteModeWriteAround,
+		LocalTTL:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewMultiLevelCache (write-around) returned error: %v", err)
+	}
+
+	// 测试绕写模式
+	t.Run("WriteAround", func(t *testing.T) {
+		// 清空缓存
+		localCache.Flush(ctx)
+		remoteCache.Flush(ctx)
+
+		// 设置值
+		err := writeAroundCache.Set(ctx, "wa_key", "wa_value", time.Minute*5)
+		if err != nil {
+			t.Fatalf("WriteAround Set returned error: %v", err)
+		}
+
 		// 验证本地缓存未设置
-		_, found := localCache.Get(ctx, "wb_key")
+		_, found := localCache.Get(ctx, "wa_key")
 		if found {
-			t.Fatal("WriteBack: Unexpected local cache hit")
+			t.Fatal("WriteAround: Unexpected local cache hit")
 		}
 
 		// 验证远程缓存已设置
-		value, found := remoteCache.Get(ctx, "wb_key")
+		value, found := remoteCache.Get(ctx, "wa_key")
 		if !found {
-			t.Fatal("WriteBack: Remote cache miss")
+			t.Fatal("WriteAround: Remote cache miss")
 		}
-		if value != "wb_value" {
-			t.Fatalf("WriteBack: Expected remote value 'wb_value', got %v", value)
+		if value != "wa_value" {
+			t.Fatalf("WriteAround: Expected remote value 'wa_value', got %v", value)
 		}
 
 		// 从多级缓存获取，应该从远程加载并填充本地
-		value, found = writeBackCache.Get(ctx, "wb_key")
+		value, found = writeAroundCache.Get(ctx, "wa_key")
 		if !found {
-			t.Fatal("WriteBack: Multi-level cache miss")
+			t.Fatal("WriteAround: Multi-level cache miss")
 		}
-		if value != "wb_value" {
-			t.Fatalf("WriteBack: Expected multi-level value 'wb_value', got %v", value)
+		if value != "wa_value" {
+			t.Fatalf("WriteAround: Expected multi-level value 'wa_value', got %v", value)
 		}
 
 		// 验证本地缓存现在已填充
-		value, found = localCache.Get(ctx, "wb_key")
+		value, found = localCache.Get(ctx, "wa_key")
 		if !found {
-			t.Fatal("WriteBack: Local cache miss after Get")
+			t.Fatal("WriteAround: Local cache miss after Get")
 		}
-		if value != "wb_value" {
-			t.Fatalf("WriteBack: Expected local value 'wb_value', got %v", value)
+		if value != "wa_value" {
+			t.Fatalf("WriteAround: Expected local value 'wa_value', got %v", value)
 		}
 	})
 }
@@ -212,7 +264,7 @@ func TestMultiLevelCache_DeleteOperations(t *testing.T) {
 
 	// 测试DeleteByPattern
 	t.Run("DeleteByPattern", func(t *testing.T) {
-		err := multiCache.DeleteByPattern(ctx, "pattern_.*")
+		err := multiCache.DeleteByPattern(ctx, "pattern_*")
 		if err != nil {
 			t.Fatalf("DeleteByPattern returned error: %v", err)
 		}
@@ -0,0 +1,428 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// namespaceKeyPrefix 命名空间键的统一前缀
+const namespaceKeyPrefix = "ns:"
+
+// NamespaceOptions 命名空间选项
+type NamespaceOptions struct {
+	// VersionCacheTTL 在本地缓存版本号的时间，默认0表示每次操作都从缓存读取（GET）版本号
+	// 大于0时每个操作可以省去一次往返，但其他实例执行的Invalidate最多延迟VersionCacheTTL才可见；
+	// 本实例执行的Invalidate立即生效。底层为MultiLevelCache时版本号同样会被保存在本地缓存中，
+	// 其他实例的Invalidate在本地副本过期或收到失效通知后才可见
+	VersionCacheTTL time.Duration
+}
+
+// DefaultNamespaceOptions 返回默认的命名空间选项
+func DefaultNamespaceOptions() NamespaceOptions {
+	return NamespaceOptions{}
+}
+
+// Namespace 带版本号的缓存命名空间
+// 命名空间中的键实际保存为"ns:<name>:<version>:<key>"，版本号保存在同一缓存的"ns:<name>:version"中。
+// Invalidate（以及Flush）只需将版本号加一，旧版本的键立即不可见，因此整体失效的代价与键的数量无关，
+// 不需要像DeleteByPattern那样SCAN整个键空间。
+//
+// 旧版本的键不会被立即删除，依靠TTL过期或由Sweeper在后台清理；标签同样带有版本号，
+// DeleteByTag只影响当前版本的键。
+//
+// 版本号丢失（被淘汰或底层缓存被Flush）时以当前时间的纳秒数重新开始，保证不会回到已经使用过的版本，
+// 避免读到失效前的旧数据。
+//
+// 示例：
+//
+//	products, err := cache.NewNamespace(redisCache, "products")
+//	products.Set(ctx, "product:1", product, time.Hour)
+//	// 商品数据批量更新后，一次INCR使整个命名空间失效
+//	products.Invalidate(ctx)
+type Namespace struct {
+	cache      Cache
+	name       string
+	versionKey string
+	options    NamespaceOptions
+
+	mu        sync.Mutex
+	version   int64
+	fetchedAt time.Time
+}
+
+// NewNamespace 创建缓存命名空间
+// 参数：
+//
+//	c: 保存数据和版本号的缓存，可以是MemoryCache、RedisCache、MultiLevelCache等任意实现
+//	name: 命名空间名称，不能为空，也不能包含":"，以免与其他命名空间的键混淆
+//	opts: 命名空间选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewNamespace(c Cache, name string, opts ...NamespaceOptions) (*Namespace, error) {
+	if c == nil {
+		return nil, errors.New("cache cannot be nil")
+	}
+	if name == "" || strings.Contains(name, ":") {
+		return nil, fmt.Errorf("invalid namespace name %q", name)
+	}
+
+	options := DefaultNamespaceOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	return &Namespace{
+		cache:      c,
+		name:       name,
+		versionKey: namespaceKeyPrefix + name + ":version",
+		options:    options,
+	}, nil
+}
+
+// Name 返回命名空间名称
+func (n *Namespace) Name() string {
+	return n.name
+}
+
+// Cache 返回底层缓存
+func (n *Namespace) Cache() Cache {
+	return n.cache
+}
+
+// Version 返回命名空间的当前版本号
+func (n *Namespace) Version(ctx context.Context) (int64, error) {
+	if n.options.VersionCacheTTL > 0 {
+		n.mu.Lock()
+		if n.version != 0 && time.Since(n.fetchedAt) < n.options.VersionCacheTTL {
+			version := n.version
+			n.mu.Unlock()
+			return version, nil
+		}
+		n.mu.Unlock()
+	}
+
+	version, err := n.fetchVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n.remember(version)
+	return version, nil
+}
+
+// Invalidate 使命名空间中的所有键失效，返回新的版本号
+func (n *Namespace) Invalidate(ctx context.Context) (int64, error) {
+	version, err := n.cache.Increment(ctx, n.versionKey, 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate namespace %s: %w", n.name, err)
+	}
+	if version == 1 {
+		// 版本号刚被创建，之前的版本号可能已经丢失
+		if version, err = n.seedVersion(ctx); err != nil {
+			return 0, err
+		}
+	}
+	n.remember(version)
+	return version, nil
+}
+
+// counterReader 可以只读地读取Increment计数器的缓存
+// 计数器由Redis的INCRBY等命令写入，不经过缓存的序列化器，因此也不能通过序列化器读取
+type counterReader interface {
+	// readCounter 读取计数器，不存在时返回false
+	readCounter(ctx context.Context, key string) (int64, bool, error)
+}
+
+// fetchVersion 从缓存读取版本号，不存在时初始化
+// 缓存实现了counterReader时只读地读取，只在初始化时写入，避免每次读取都产生需要复制和持久化的写命令，
+// 在只读副本上也可以读取；其他缓存（例如MultiLevelCache）加0读取，在所有实现中得到统一的整数类型
+func (n *Namespace) fetchVersion(ctx context.Context) (int64, error) {
+	var (
+		version int64
+		err     error
+	)
+	if reader, ok := findCache[counterReader](n.cache); ok {
+		// 不存在时version为0，与Increment一致
+		version, _, err = reader.readCounter(ctx, n.versionKey)
+	} else {
+		version, err = n.cache.Increment(ctx, n.versionKey, 0)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read namespace %s version: %w", n.name, err)
+	}
+	if version == 0 {
+		return n.seedVersion(ctx)
+	}
+	return version, nil
+}
+
+// seedVersion 以当前时间为起点初始化版本号
+// 并发初始化时各实例得到的值可能不同，但都大于丢失之前的版本号，下次读取时即趋于一致
+func (n *Namespace) seedVersion(ctx context.Context) (int64, error) {
+	version, err := n.cache.Increment(ctx, n.versionKey, time.Now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to initialize namespace %s version: %w", n.name, err)
+	}
+	return version, nil
+}
+
+func (n *Namespace) remember(version int64) {
+	if n.options.VersionCacheTTL <= 0 {
+		return
+	}
+	n.mu.Lock()
+	n.version = version
+	n.fetchedAt = time.Now()
+	n.mu.Unlock()
+}
+
+// prefix 返回指定版本的键前缀
+func (n *Namespace) prefix(version int64) string {
+	return namespaceKeyPrefix + n.name + ":" + strconv.FormatInt(version, 10) + ":"
+}
+
+// Key 返回键在底层缓存中的实际键名
+func (n *Namespace) Key(ctx context.Context, key string) (string, error) {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	return n.prefix(version) + key, nil
+}
+
+// parseVersion 解析底层缓存中的键所属的版本，不属于该命名空间的数据键时返回false
+func (n *Namespace) parseVersion(key string) (int64, bool) {
+	rest, ok := strings.CutPrefix(key, namespaceKeyPrefix+n.name+":")
+	if !ok {
+		return 0, false
+	}
+	versionPart, _, ok := strings.Cut(rest, ":")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// Get 获取缓存值，读取版本号失败时视为未命中
+func (n *Namespace) Get(ctx context.Context, key string) (interface{}, bool) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	return n.cache.Get(ctx, fullKey)
+}
+
+// GetWithTTL 获取缓存值和剩余生存时间，读取版本号失败时视为未命中
+func (n *Namespace) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return nil, 0, false
+	}
+	return n.cache.GetWithTTL(ctx, fullKey)
+}
+
+// GetInto 读取缓存值并解码到target，实现ValueDecoder接口
+func (n *Namespace) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return GetInto(ctx, n.cache, fullKey, target)
+}
+
+// GetIntoWithTTL 读取缓存值和剩余生存时间并解码到target，实现ValueDecoder接口
+func (n *Namespace) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return 0, false, err
+	}
+	return GetIntoWithTTL(ctx, n.cache, fullKey, target)
+}
+
+// GetOrLoad 获取缓存值，未命中时调用loader加载，实现Loader接口
+func (n *Namespace) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return GetOrLoad(ctx, n.cache, fullKey, ttl, loader)
+}
+
+// Set 设置缓存值
+func (n *Namespace) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return err
+	}
+	return n.cache.Set(ctx, fullKey, value, ttl)
+}
+
+// SetItem 设置缓存项，标签同样限定在当前版本中
+func (n *Namespace) SetItem(ctx context.Context, key string, item *Item) error {
+	if item == nil {
+		return errors.New("cache item cannot be nil")
+	}
+	version, err := n.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return n.cache.SetItem(ctx, n.prefix(version)+key, n.scopeItem(version, item))
+}
+
+// scopeItem 返回标签加上版本前缀的缓存项副本
+func (n *Namespace) scopeItem(version int64, item *Item) *Item {
+	if len(item.Tags) == 0 {
+		return item
+	}
+	scoped := *item
+	scoped.Tags = make([]string, len(item.Tags))
+	for i, tag := range item.Tags {
+		scoped.Tags[i] = n.prefix(version) + tag
+	}
+	return &scoped
+}
+
+// GetMany 批量获取缓存值，实现BatchCache接口
+func (n *Namespace) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix := n.prefix(version)
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = prefix + key
+	}
+
+	values, err := GetMany(ctx, n.cache, fullKeys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, len(values))
+	for fullKey, value := range values {
+		result[strings.TrimPrefix(fullKey, prefix)] = value
+	}
+	return result, nil
+}
+
+// SetMany 批量设置缓存项，实现BatchCache接口
+func (n *Namespace) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
+	version, err := n.Version(ctx)
+	if err != nil {
+		return err
+	}
+	prefix := n.prefix(version)
+	scoped := make(map[string]*Item, len(items))
+	for key, item := range items {
+		scoped[prefix+key] = n.scopeItem(version, item)
+	}
+	return SetMany(ctx, n.cache, scoped)
+}
+
+// DeleteMany 批量删除缓存项，实现BatchCache接口
+func (n *Namespace) DeleteMany(ctx context.Context, keys []string) error {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return err
+	}
+	prefix := n.prefix(version)
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = prefix + key
+	}
+	return DeleteMany(ctx, n.cache, fullKeys)
+}
+
+// Delete 删除缓存项
+func (n *Namespace) Delete(ctx context.Context, key string) error {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return err
+	}
+	return n.cache.Delete(ctx, fullKey)
+}
+
+// DeleteByPattern 删除当前版本中键匹配glob模式的缓存项
+func (n *Namespace) DeleteByPattern(ctx context.Context, pattern string) error {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return n.cache.DeleteByPattern(ctx, EscapePattern(n.prefix(version))+pattern)
+}
+
+// Scan 遍历当前版本中匹配模式的键，产出的键不带版本前缀，实现KeyScanner接口
+func (n *Namespace) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		scanner, ok := findCache[KeyScanner](n.cache)
+		if !ok {
+			yield("", ErrNotSupported)
+			return
+		}
+		version, err := n.Version(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		prefix := n.prefix(version)
+		for key, err := range scanner.Scan(ctx, EscapePattern(prefix)+pattern) {
+			if !yield(strings.TrimPrefix(key, prefix), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// DeleteByTag 删除当前版本中带特定标签的缓存项
+func (n *Namespace) DeleteByTag(ctx context.Context, tag string) error {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return n.cache.DeleteByTag(ctx, n.prefix(version)+tag)
+}
+
+// Exists 检查键是否存在
+func (n *Namespace) Exists(ctx context.Context, key string) (bool, error) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return n.cache.Exists(ctx, fullKey)
+}
+
+// Increment 增加计数器值
+func (n *Namespace) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	fullKey, err := n.Key(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return n.cache.Increment(ctx, fullKey, value)
+}
+
+// Decrement 减少计数器值
+func (n *Namespace) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	return n.Increment(ctx, key, -value)
+}
+
+// Flush 清空命名空间，等同于Invalidate，不影响底层缓存中的其他数据
+func (n *Namespace) Flush(ctx context.Context) error {
+	_, err := n.Invalidate(ctx)
+	return err
+}
+
+// Close 不做任何操作，底层缓存通常由多个命名空间共享，需要由创建者关闭
+func (n *Namespace) Close() error {
+	return nil
+}
@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namespaceTestCaches 返回用于命名空间测试的缓存实现
+func namespaceTestCaches(t *testing.T) map[string]Cache {
+	memory := NewMemoryCache()
+	t.Cleanup(func() { _ = memory.Close() })
+	sharded := NewShardedMemoryCache(4)
+	t.Cleanup(func() { _ = sharded.Close() })
+
+	return map[string]Cache{
+		"memory":         memory,
+		"sharded_memory": sharded,
+		"redis":          newMiniRedisCache(t),
+	}
+}
+
+func TestNewNamespace_InvalidName(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	_, err := NewNamespace(c, "")
+	assert.Error(t, err)
+	_, err = NewNamespace(c, "users:v2")
+	assert.Error(t, err)
+	_, err = NewNamespace(nil, "users")
+	assert.Error(t, err)
+}
+
+func TestNamespace_Invalidate(t *testing.T) {
+	for name, c := range namespaceTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			products, err := NewNamespace(c, "products")
+			require.NoError(t, err)
+			users, err := NewNamespace(c, "users")
+			require.NoError(t, err)
+
+			require.NoError(t, products.Set(ctx, "product:1", "apple", time.Hour))
+			require.NoError(t, users.Set(ctx, "product:1", "not a product", time.Hour))
+			require.NoError(t, c.Set(ctx, "plain", "value", time.Hour))
+
+			value, found := products.Get(ctx, "product:1")
+			require.True(t, found)
+			assert.Equal(t, "apple", value)
+
+			before, err := products.Version(ctx)
+			require.NoError(t, err)
+			after, err := products.Invalidate(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, before+1, after)
+
+			_, found = products.Get(ctx, "product:1")
+			assert.False(t, found, "keys of the previous version are invisible")
+			value, found = users.Get(ctx, "product:1")
+			require.True(t, found, "other namespaces are unaffected")
+			assert.Equal(t, "not a product", value)
+			_, found = c.Get(ctx, "plain")
+			assert.True(t, found, "keys outside namespaces are unaffected")
+
+			require.NoError(t, products.Set(ctx, "product:1", "banana", time.Hour))
+			require.NoError(t, products.Flush(ctx))
+			_, found = products.Get(ctx, "product:1")
+			assert.False(t, found, "Flush invalidates the namespace")
+		})
+	}
+}
+
+func TestNamespace_Operations(t *testing.T) {
+	for name, c := range namespaceTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ns, err := NewNamespace(c, "orders")
+			require.NoError(t, err)
+
+			require.NoError(t, ns.SetItem(ctx, "order:1", &Item{Value: "a", Expiration: time.Hour, Tags: []string{"open"}}))
+			require.NoError(t, ns.SetItem(ctx, "order:2", &Item{Value: "b", Expiration: time.Hour, Tags: []string{"open"}}))
+			require.NoError(t, ns.Set(ctx, "order:3", "c", time.Hour))
+
+			values, err := GetMany(ctx, ns, []string{"order:1", "order:3", "missing"})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"order:1": "a", "order:3": "c"}, values)
+
+			var target string
+			found, err := GetInto(ctx, ns, "order:2", &target)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, "b", target)
+
+			require.NoError(t, ns.DeleteByTag(ctx, "open"))
+			exists, err := ns.Exists(ctx, "order:1")
+			require.NoError(t, err)
+			assert.False(t, exists)
+			exists, err = ns.Exists(ctx, "order:3")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			n, err := ns.Increment(ctx, "count", 2)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+
+			fullKey, err := ns.Key(ctx, "order:3")
+			require.NoError(t, err)
+			value, found := c.Get(ctx, fullKey)
+			require.True(t, found, "Key returns the key in the underlying cache")
+			assert.Equal(t, "c", value)
+
+			loaded, err := GetOrLoad(ctx, ns, "order:4", time.Hour, func(ctx context.Context) (interface{}, error) {
+				return "d", nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "d", loaded)
+			_, found = ns.Get(ctx, "order:4")
+			assert.True(t, found)
+		})
+	}
+}
+
+func TestNamespace_LostVersion(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+	ns, err := NewNamespace(c, "users")
+	require.NoError(t, err)
+
+	require.NoError(t, ns.Set(ctx, "user:1", "alice", time.Hour))
+	version, err := ns.Version(ctx)
+	require.NoError(t, err)
+
+	// 版本号被淘汰后不能回到旧版本，否则会读到失效前的数据
+	require.NoError(t, c.Delete(ctx, ns.versionKey))
+	again, err := ns.Version(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, again, version)
+
+	require.NoError(t, c.Delete(ctx, ns.versionKey))
+	invalidated, err := ns.Invalidate(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, invalidated, again)
+}
+
+func TestNamespace_VersionCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+
+	local, err := NewNamespace(c, "users", NamespaceOptions{VersionCacheTTL: 50 * time.Millisecond})
+	require.NoError(t, err)
+	remote, err := NewNamespace(c, "users")
+	require.NoError(t, err)
+
+	require.NoError(t, local.Set(ctx, "user:1", "alice", time.Hour))
+	_, err = remote.Invalidate(ctx)
+	require.NoError(t, err)
+
+	_, found := local.Get(ctx, "user:1")
+	assert.True(t, found, "the cached version is used until VersionCacheTTL elapses")
+
+	time.Sleep(60 * time.Millisecond)
+	_, found = local.Get(ctx, "user:1")
+	assert.False(t, found)
+
+	require.NoError(t, local.Set(ctx, "user:1", "bob", time.Hour))
+	_, err = local.Invalidate(ctx)
+	require.NoError(t, err)
+	_, found = local.Get(ctx, "user:1")
+	assert.False(t, found, "local invalidation takes effect immediately")
+}
+
+// incrementCountingCache 统计Increment调用次数的缓存
+type incrementCountingCache struct {
+	Cache
+	counterReader
+	increments int
+}
+
+func (c *incrementCountingCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	c.increments++
+	return c.Cache.Increment(ctx, key, value)
+}
+
+func TestNamespace_ReadsDoNotWriteVersion(t *testing.T) {
+	ctx := context.Background()
+	for name, c := range namespaceTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			counting := &incrementCountingCache{Cache: c, counterReader: c.(counterReader)}
+			ns, err := NewNamespace(counting, "users")
+			require.NoError(t, err)
+
+			require.NoError(t, ns.Set(ctx, "user:1", "alice", time.Hour))
+			require.Equal(t, 1, counting.increments, "the version is seeded once")
+
+			for i := 0; i < 3; i++ {
+				value, found := ns.Get(ctx, "user:1")
+				assert.True(t, found)
+				assert.Equal(t, "alice", value)
+			}
+			assert.Equal(t, 1, counting.increments, "reads fetch the version without writing it")
+
+			version, err := ns.Invalidate(ctx)
+			require.NoError(t, err)
+			current, err := ns.Version(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, version, current)
+		})
+	}
+}
+
+func TestNamespace_RedisSerializers(t *testing.T) {
+	ctx := context.Background()
+	encrypted, err := NewEncryptingSerializer(&GobSerializer{}, EncryptionOptions{
+		Keys:         map[string][]byte{"k1": testEncryptionKey(1)},
+		PrimaryKeyID: "k1",
+	})
+	require.NoError(t, err)
+	serializers := map[string]Serializer{
+		"json":       &JSONSerializer{},
+		"gob":        &GobSerializer{},
+		"compressed": NewCompressingSerializer(&GobSerializer{}, CompressionOptions{Threshold: 1}),
+		"encrypted":  encrypted,
+	}
+	for name, serializer := range serializers {
+		t.Run(name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			opts := DefaultRedisOptions()
+			opts.Addr = mr.Addr()
+			c, err := NewRedisCache(opts, serializer)
+			require.NoError(t, err)
+			defer c.Close()
+
+			ns, err := NewNamespace(c, "users")
+			require.NoError(t, err)
+
+			first, err := ns.Version(ctx)
+			require.NoError(t, err)
+			second, err := ns.Version(ctx)
+			require.NoError(t, err, "the counter is read without the serializer")
+			assert.Equal(t, first, second)
+
+			require.NoError(t, ns.Set(ctx, "user:1", "alice", time.Hour))
+			var value string
+			found, err := ns.GetInto(ctx, "user:1", &value)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "alice", value)
+		})
+	}
+}
+
+func TestNamespace_VersionError(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+	ns, err := NewNamespace(c, "users")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, ns.versionKey, "not a number", -1))
+	_, found := ns.Get(ctx, "user:1")
+	assert.False(t, found)
+	err = ns.Set(ctx, "user:1", "alice", time.Hour)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
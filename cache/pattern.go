@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"iter"
+	"strings"
+)
+
+// 模式语法
+//
+// DeleteByPattern、Scan和Keys在所有缓存实现中使用与Redis KEYS/SCAN MATCH相同的glob语法，
+// 模式必须匹配整个键，按字节比较并区分大小写：
+//   - *       匹配任意长度（包括0）的任意字节
+//   - ?       匹配任意一个字节
+//   - [abc]   匹配括号中的任意一个字节，[^abc]匹配不在括号中的字节
+//   - [a-z]   匹配范围内的字节，范围两端顺序颠倒时自动交换
+//   - \x      匹配字节x本身，用于转义*、?、[和\
+//
+// 示例：
+//   - "user:*"           所有以"user:"开头的键
+//   - "user:?"           "user:"后跟一个字节的键
+//   - "session:[0-9]*"   "session:"后跟数字开头的键
+//   - "price\*"          键"price*"本身
+//
+// 未闭合的"["一直匹配到模式末尾，模式本身永远不会无效
+
+// KeyScanner 支持按模式遍历键的缓存
+// MemoryCache、ShardedMemoryCache、RedisCache、MultiLevelCache以及Namespace等包装器都实现了此接口
+type KeyScanner interface {
+	// Scan 遍历匹配pattern的键，键的顺序不确定
+	// 遍历期间写入或删除的键可能出现也可能不出现；RedisCache基于SCAN实现，同一个键可能出现多次，
+	// 需要去重时使用Keys。出错时产出一次非nil错误后结束
+	Scan(ctx context.Context, pattern string) iter.Seq2[string, error]
+}
+
+// Keys 返回匹配pattern的所有键，已去重，顺序不确定
+// c本身未实现KeyScanner时，依次查找Unwrap返回的底层缓存；都不支持时返回ErrNotSupported
+func Keys(ctx context.Context, c Cache, pattern string) ([]string, error) {
+	scanner, ok := findCache[KeyScanner](c)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	seen := make(map[string]struct{})
+	keys := []string{}
+	for key, err := range scanner.Scan(ctx, pattern) {
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// scanCache 遍历任意缓存中匹配模式的键，不支持时产出ErrNotSupported
+func scanCache(ctx context.Context, c Cache, pattern string) iter.Seq2[string, error] {
+	if scanner, ok := findCache[KeyScanner](c); ok {
+		return scanner.Scan(ctx, pattern)
+	}
+	return func(yield func(string, error) bool) {
+		yield("", ErrNotSupported)
+	}
+}
+
+// MatchPattern 判断key是否匹配glob模式，语法与Redis相同
+func MatchPattern(pattern, key string) bool {
+	p, k := 0, 0
+	// 最近一个*的位置以及当时匹配到的键的位置，用于回溯
+	starP, starK := -1, 0
+
+	for k < len(key) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				for p < len(pattern) && pattern[p] == '*' {
+					p++
+				}
+				if p == len(pattern) {
+					return true
+				}
+				starP, starK = p, k
+				continue
+			default:
+				if matched, next := matchOne(pattern, p, key[k]); matched {
+					p, k = next, k+1
+					continue
+				}
+			}
+		}
+		// 当前字节不匹配时，让最近的*多吞一个字节后重试
+		if starP < 0 {
+			return false
+		}
+		starK++
+		p, k = starP, starK
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchOne 判断从pattern[p]开始的单字节单元（字面量、?、转义或字符类）是否匹配c
+// 返回是否匹配以及下一个单元的位置
+func matchOne(pattern string, p int, c byte) (bool, int) {
+	switch pattern[p] {
+	case '?':
+		return true, p + 1
+	case '\\':
+		if p+1 < len(pattern) {
+			return pattern[p+1] == c, p + 2
+		}
+		return c == '\\', p + 1
+	case '[':
+		return matchClass(pattern, p+1, c)
+	default:
+		return pattern[p] == c, p + 1
+	}
+}
+
+// matchClass 匹配字符类，p指向"["之后的位置
+func matchClass(pattern string, p int, c byte) (bool, int) {
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			if pattern[p+1] == c {
+				matched = true
+			}
+			p += 2
+		case p+2 < len(pattern) && pattern[p+1] == '-':
+			start, end := pattern[p], pattern[p+2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			p += 3
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+			p++
+		}
+	}
+	if p < len(pattern) {
+		// 跳过"]"
+		p++
+	}
+
+	if negate {
+		matched = !matched
+	}
+	return matched, p
+}
+
+// EscapePattern 转义s中的模式特殊字符，使其只匹配s本身
+// 常用于拼接前缀，例如EscapePattern(prefix)+"*"
+func EscapePattern(s string) string {
+	if !strings.ContainsAny(s, `*?[]\`) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
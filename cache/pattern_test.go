@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"**", "a", true},
+		{"a*", "a", true},
+		{"a*b", "ab", true},
+		{"a*b", "axxb", true},
+		{"a*b", "axxbx", false},
+		{"a*b*c", "abxbc", true},
+		{"*a*a*a*", "aaa", true},
+		{"*a*a*a*", "aa", false},
+		{"?", "", false},
+		{"?", "é", false},
+		{"??", "é", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{`h[\]]llo`, "h]llo", true},
+		{`h[\-]llo`, "h-llo", true},
+		{"[abc", "b", true},
+		{"[abc", "d", false},
+		{"[", "x", false},
+		{`\*`, "*", true},
+		{`\*`, "a", false},
+		{`a\`, `a\`, true},
+		{"User:*", "user:1", false},
+		{"user:*", "user:", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPattern(tt.pattern, tt.key), "MatchPattern(%q, %q)", tt.pattern, tt.key)
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	for _, s := range []string{"plain", "a*b", "a?b", "[x]", `back\slash`, "ns:name:1:"} {
+		escaped := EscapePattern(s)
+		assert.True(t, MatchPattern(escaped, s), "EscapePattern(%q) = %q", s, escaped)
+		assert.True(t, MatchPattern(escaped+"*", s+"suffix"))
+		assert.False(t, MatchPattern(escaped, s+"x"))
+	}
+	assert.False(t, MatchPattern(EscapePattern("a*"), "abc"))
+}
@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// reconcileBatchSize 每次同步到远程缓存的最大键数
+	reconcileBatchSize = 100
+	// reconcileCloseTimeout 关闭时等待剩余项同步完成的最长时间
+	reconcileCloseTimeout = time.Second * 10
+)
+
+// reconcileOp 降级期间记录的按标签删除、按模式删除或清空操作
+type reconcileOp struct {
+	op  string // invalidateTag、invalidatePattern或invalidateFlush
+	arg string
+}
+
+// reconciler 记录熔断期间只作用于本地缓存的写入，远程缓存恢复后按顺序同步
+//
+// 同步时先依次执行记录的按标签删除、按模式删除和清空操作，再写入或删除记录的键。
+// 记录这些操作时会丢弃它们覆盖的之前记录的键，因此先执行操作再写入键与原有的执行顺序结果相同
+type reconciler struct {
+	remote  Cache // 经过熔断器的远程缓存
+	breaker *circuitBreaker
+	maxKeys int
+	// onReconciled 操作或键同步到远程缓存后的回调，用于通知其他实例
+	onReconciled func(ctx context.Context, op *reconcileOp, keys []string)
+
+	// gate 写入方持有读锁完成"检查是否有待同步项并写入远程缓存"，同步方持有写锁，
+	// 保证有待同步项时新的写入也被记录下来，不会被之后同步的旧值覆盖
+	gate sync.RWMutex
+
+	mu   sync.Mutex
+	ops  []reconcileOp
+	keys map[string]*pendingWrite // 值为nil表示删除
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	pendingCount atomic.Int64
+	reconciled   atomic.Uint64
+	dropped      atomic.Uint64
+}
+
+// newReconciler 创建同步器并启动后台同步协程，每隔interval重试一次
+func newReconciler(remote Cache, breaker *circuitBreaker, interval time.Duration, onReconciled func(ctx context.Context, op *reconcileOp, keys []string)) *reconciler {
+	r := &reconciler{
+		remote:       remote,
+		breaker:      breaker,
+		maxKeys:      breaker.options.MaxPendingKeys,
+		onReconciled: onReconciled,
+		keys:         make(map[string]*pendingWrite),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go r.run(interval)
+	return r
+}
+
+// guard 以写入方身份执行一次远程写入
+// 有待同步项或熔断器打开时调用record记录写入并返回degraded为true，此时write的错误被忽略
+func (r *reconciler) guard(write func() error, record func()) (degraded bool, err error) {
+	r.gate.RLock()
+	defer r.gate.RUnlock()
+
+	if r.pendingCount.Load() == 0 {
+		err = write()
+		if !isCircuitOpen(err) {
+			return false, err
+		}
+	}
+
+	record()
+	r.trigger()
+	return true, nil
+}
+
+// pending 返回是否有等待同步的项
+func (r *reconciler) pending() bool {
+	return r.pendingCount.Load() > 0
+}
+
+// recordSet 记录写入
+func (r *reconciler) recordSet(items map[string]*Item) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, item := range items {
+		write := &pendingWrite{key: key, value: item.Value, tags: item.Tags, ttl: item.Expiration}
+		if write.ttl > 0 {
+			write.expireAt = now.Add(write.ttl)
+		}
+		r.putLocked(key, write)
+	}
+	r.updateCountLocked()
+}
+
+// recordDelete 记录删除
+func (r *reconciler) recordDelete(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		r.putLocked(key, nil)
+	}
+	r.updateCountLocked()
+}
+
+// recordTag 记录按标签删除，丢弃之前记录的带有该标签的写入
+func (r *reconciler) recordTag(tag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, write := range r.keys {
+		if write != nil && slices.Contains(write.tags, tag) {
+			delete(r.keys, key)
+		}
+	}
+	r.ops = append(r.ops, reconcileOp{op: invalidateTag, arg: tag})
+	r.updateCountLocked()
+}
+
+// recordPattern 记录按模式删除，丢弃之前记录的匹配该模式的键
+func (r *reconciler) recordPattern(pattern string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.keys {
+		if MatchPattern(pattern, key) {
+			delete(r.keys, key)
+		}
+	}
+	r.ops = append(r.ops, reconcileOp{op: invalidatePattern, arg: pattern})
+	r.updateCountLocked()
+}
+
+// recordFlush 记录清空，丢弃之前记录的所有项
+func (r *reconciler) recordFlush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = make(map[string]*pendingWrite)
+	r.ops = []reconcileOp{{op: invalidateFlush}}
+	r.updateCountLocked()
+}
+
+// discard 丢弃所有待同步项，用于远程缓存被直接清空的情况
+func (r *reconciler) discard() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = make(map[string]*pendingWrite)
+	r.ops = nil
+	r.updateCountLocked()
+}
+
+// putLocked 记录一个键，调用方需持有mu
+// 超出最大数量时新键的写入改为记录删除，同步时删除远程缓存中的旧值，避免旧值在恢复后重新出现；
+// 删除总是记录，只保存键名，因此待同步的键数可能超过最大数量
+func (r *reconciler) putLocked(key string, write *pendingWrite) {
+	if _, ok := r.keys[key]; !ok && write != nil && len(r.keys) >= r.maxKeys {
+		r.dropped.Add(1)
+		write = nil
+	}
+	r.keys[key] = write
+}
+
+// updateCountLocked 更新待同步项数量，调用方需持有mu
+func (r *reconciler) updateCountLocked() {
+	r.pendingCount.Store(int64(len(r.keys) + len(r.ops)))
+}
+
+// trigger 唤醒后台同步协程
+func (r *reconciler) trigger() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 后台同步协程，熔断器关闭或被唤醒时同步，并按interval定期重试
+func (r *reconciler) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.wake:
+			// 熔断器打开期间只由定时重试发出探测，避免每次记录都被拒绝一次
+			if r.breaker.currentState() == CircuitOpen {
+				continue
+			}
+		case <-ticker.C:
+		}
+		_ = r.reconcile(context.Background())
+	}
+}
+
+// reconcile 将待同步项写入远程缓存，遇到错误时停止并保留剩余的项
+func (r *reconciler) reconcile(ctx context.Context) error {
+	for r.pending() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.reconcileBatch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileBatch 同步一个操作或一批键，期间阻塞新的远程写入
+func (r *reconciler) reconcileBatch(ctx context.Context) error {
+	r.gate.Lock()
+	defer r.gate.Unlock()
+
+	r.mu.Lock()
+	if len(r.ops) > 0 {
+		op := r.ops[0]
+		r.mu.Unlock()
+
+		var err error
+		switch op.op {
+		case invalidateTag:
+			err = r.remote.DeleteByTag(ctx, op.arg)
+		case invalidatePattern:
+			err = r.remote.DeleteByPattern(ctx, op.arg)
+		case invalidateFlush:
+			err = r.remote.Flush(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		r.ops = r.ops[1:]
+		r.updateCountLocked()
+		r.mu.Unlock()
+
+		r.reconciled.Add(1)
+		if r.onReconciled != nil {
+			r.onReconciled(ctx, &op, nil)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	sets := make(map[string]*Item)
+	var deletes, expired []string
+	for key, write := range r.keys {
+		if len(sets)+len(deletes)+len(expired) >= reconcileBatchSize {
+			break
+		}
+		if write == nil {
+			deletes = append(deletes, key)
+			continue
+		}
+		ttl, ok := write.remaining(now)
+		if !ok {
+			expired = append(expired, key)
+			continue
+		}
+		sets[key] = &Item{Value: write.value, Expiration: ttl, Tags: write.tags}
+	}
+	r.mu.Unlock()
+
+	if len(sets) > 0 {
+		if err := SetMany(ctx, r.remote, sets); err != nil {
+			return err
+		}
+	}
+	// 降级期间已过期的键在远程缓存中的旧值同样应当删除
+	deletes = append(deletes, expired...)
+	if len(deletes) > 0 {
+		if err := DeleteMany(ctx, r.remote, deletes); err != nil {
+			return err
+		}
+	}
+
+	keys := append(itemKeys(sets), deletes...)
+	r.mu.Lock()
+	for _, key := range keys {
+		delete(r.keys, key)
+	}
+	r.updateCountLocked()
+	r.mu.Unlock()
+
+	r.reconciled.Add(uint64(len(keys)))
+	if r.onReconciled != nil {
+		r.onReconciled(ctx, nil, keys)
+	}
+	return nil
+}
+
+// close 停止后台同步协程并尝试同步剩余的项，重复调用是安全的
+func (r *reconciler) close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), reconcileCloseTimeout)
+		defer cancel()
+		err = r.reconcile(ctx)
+	})
+	return err
+}
@@ -3,9 +3,12 @@ package cache
 import (
 	"context"
 	"fmt"
+	"iter"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/guanzhenxing/go-snap/errors"
 
 	"github.com/redis/go-redis/v9"
@@ -59,6 +62,10 @@ type RedisOptions struct {
 
 	// -------- 默认过期时间 --------
 	DefaultTTL time.Duration // 默认TTL
+
+	// -------- 加载协调 --------
+	LoadLockTTL           time.Duration // GetOrLoad跨实例加载锁的过期时间，0表示只在进程内合并加载
+	LoadLockRetryInterval time.Duration // 未获得加载锁时轮询缓存的间隔，默认50毫秒
 }
 
 // DefaultRedisOptions 返回默认Redis配置
@@ -84,6 +91,7 @@ type RedisCache struct {
 	client     redis.UniversalClient
 	options    RedisOptions
 	serializer Serializer
+	loads      loadGroup
 }
 
 // NewRedisCache 创建新的Redis缓存
@@ -227,6 +235,138 @@ func (c *RedisCache) GetWithTTL(ctx context.Context, key string) (interface{}, t
 	return value, ttl, true
 }
 
+// GetInto 获取缓存值并直接反序列化到target，实现ValueDecoder接口
+func (c *RedisCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, c.prefixKey(key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get cache: %w", err)
+	}
+
+	if err := c.serializer.Unmarshal(data, target); err != nil {
+		return false, fmt.Errorf("%w: key %q: %w", ErrDecode, key, err)
+	}
+	return true, nil
+}
+
+// GetIntoWithTTL 获取缓存值和剩余TTL并直接反序列化到target，实现ValueDecoder接口
+func (c *RedisCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	prefixedKey := c.prefixKey(key)
+
+	pipe := c.client.Pipeline()
+	getCmd := pipe.Get(ctx, prefixedKey)
+	ttlCmd := pipe.TTL(ctx, prefixedKey)
+	_, _ = pipe.Exec(ctx)
+
+	data, err := getCmd.Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get cache: %w", err)
+	}
+	ttl, err := ttlCmd.Result()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get cache ttl: %w", err)
+	}
+
+	if err := c.serializer.Unmarshal(data, target); err != nil {
+		return 0, false, fmt.Errorf("%w: key %q: %w", ErrDecode, key, err)
+	}
+	return ttl, true, nil
+}
+
+// GetOrLoad 获取缓存值，未命中时合并并发调用只加载一次，实现Loader接口
+// 设置了LoadLockTTL时，通过短期Redis锁保证多个实例之间也只有一个实例调用loader
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	return loadThrough(ctx, c, &c.loads, c.loadLocker(), key, ttl, loader)
+}
+
+// loadLocker 返回跨实例加载锁，未启用时返回nil
+func (c *RedisCache) loadLocker() loadLocker {
+	if c.options.LoadLockTTL <= 0 {
+		return nil
+	}
+	return c
+}
+
+// RedisCache在缓存的键空间中保存的内部键前缀：标签集合、键的标签反向索引、条件操作的版本键和加载锁
+// Scan、Keys和DeleteByPattern会跳过这些前缀下的键，业务键不应使用这些前缀
+const (
+	tagKeyPrefix      = "tag:"
+	keyTagsPrefix     = "tags:"
+	versionKeyPrefix  = "version:"
+	loadLockKeyPrefix = "lock:load:"
+)
+
+// reservedKeyPrefixes 所有内部键前缀
+var reservedKeyPrefixes = []string{tagKeyPrefix, keyTagsPrefix, versionKeyPrefix, loadLockKeyPrefix}
+
+// isReservedKey 判断不带KeyPrefix的键是否为内部键
+func isReservedKey(key string) bool {
+	for _, prefix := range reservedKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyTagsKey 返回键的标签反向索引，保存键所在的所有标签名，过期时间与值相同
+// 删除键或不带标签地覆盖键时通过反向索引找到标签集合，不需要扫描所有标签
+func (c *RedisCache) keyTagsKey(key string) string {
+	return c.prefixKey(keyTagsPrefix + key)
+}
+
+// entryKeys 返回键及其版本键和标签反向索引，删除键时一并删除
+func (c *RedisCache) entryKeys(key string) []string {
+	return []string{c.prefixKey(key), c.versionKey(key), c.keyTagsKey(key)}
+}
+
+// loadLockKey 返回键的加载锁
+// 加载锁与标签索引一样使用保留前缀，不会与业务键冲突，也不会被"user:*"之类的模式匹配到
+func (c *RedisCache) loadLockKey(key string) string {
+	return c.prefixKey(loadLockKeyPrefix + key)
+}
+
+// tryLoadLock 使用SET NX获取键的加载锁
+func (c *RedisCache) tryLoadLock(ctx context.Context, key string) (func(), bool, error) {
+	lockKey := c.loadLockKey(key)
+	token := uuid.NewString()
+
+	ok, err := c.client.SetNX(ctx, lockKey, token, c.options.LoadLockTTL).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire load lock: %w", err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	release := func() {
+		// 只删除自己持有的锁，避免锁过期后误删其他实例的锁
+		_ = releaseLoadLockScript.Run(ctx, c.client, []string{lockKey}, token).Err()
+	}
+	return release, true, nil
+}
+
+// loadLockRetryInterval 未获得加载锁时的轮询间隔
+func (c *RedisCache) loadLockRetryInterval() time.Duration {
+	if c.options.LoadLockRetryInterval > 0 {
+		return c.options.LoadLockRetryInterval
+	}
+	return 50 * time.Millisecond
+}
+
+// releaseLoadLockScript 持有者才能释放加载锁
+var releaseLoadLockScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
 // Set 设置缓存
 func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	prefixedKey := c.prefixKey(key)
@@ -241,8 +381,11 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 		ttl = c.options.DefaultTTL
 	}
 
-	err = c.client.Set(ctx, prefixedKey, data, ttl).Err()
-	if err != nil {
+	// 同时删除条件操作的版本键，使之前读取的版本失效
+	pipe := c.client.Pipeline()
+	pipe.Set(ctx, prefixedKey, data, ttl)
+	pipe.Del(ctx, c.versionKey(key))
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to set cache: %w", err)
 	}
 
@@ -271,18 +414,10 @@ func (c *RedisCache) SetItem(ctx context.Context, key string, item *Item) error
 	// 使用管道以减少网络往返
 	pipe := c.client.Pipeline()
 	pipe.Set(ctx, prefixedKey, data, ttl)
+	pipe.Del(ctx, c.versionKey(key))
 
 	// 处理标签索引
-	if len(item.Tags) > 0 {
-		for _, tag := range item.Tags {
-			tagKey := c.prefixKey("tag:" + tag)
-			pipe.SAdd(ctx, tagKey, key)
-			// 如果设置了过期时间，也为标签索引设置相同的过期时间
-			if ttl > 0 {
-				pipe.Expire(ctx, tagKey, ttl)
-			}
-		}
-	}
+	c.pipeTags(ctx, pipe, key, item.Tags, ttl)
 
 	_, err = pipe.Exec(ctx)
 	if err != nil {
@@ -294,105 +429,215 @@ func (c *RedisCache) SetItem(ctx context.Context, key string, item *Item) error
 
 // Delete 删除缓存
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
-	prefixedKey := c.prefixKey(key)
+	return c.DeleteMany(ctx, []string{key})
+}
 
-	// 获取键关联的标签
-	tagsKeyPattern := c.prefixKey("tag:*")
-	var cursor uint64
-	var tags []string
+// GetMany 批量获取缓存值，实现BatchCache接口
+// 单机和哨兵模式使用一次MGET；集群模式下键可能分布在不同槽位，使用管道逐个GET
+func (c *RedisCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
 
-	for {
-		var keys []string
-		var err error
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = c.prefixKey(key)
+	}
 
-		// 扫描所有标签
-		keys, cursor, err = c.client.Scan(ctx, cursor, tagsKeyPattern, 100).Result()
-		if err != nil {
-			return fmt.Errorf("failed to scan tags: %w", err)
+	raw := make([]interface{}, len(keys))
+	if c.options.Mode == RedisModeCluster {
+		pipe := c.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(keys))
+		for i, key := range prefixedKeys {
+			cmds[i] = pipe.Get(ctx, key)
 		}
-
-		// 检查每个标签集合是否包含要删除的键
-		for _, tagKey := range keys {
-			isMember, err := c.client.SIsMember(ctx, tagKey, key).Result()
-			if err != nil {
-				continue
-			}
-			if isMember {
-				// 提取标签名
-				tag := strings.TrimPrefix(tagKey, c.prefixKey("tag:"))
-				tags = append(tags, tag)
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("failed to get cache: %w", err)
+		}
+		for i, cmd := range cmds {
+			if data, err := cmd.Result(); err == nil {
+				raw[i] = data
 			}
 		}
-
-		if cursor == 0 {
-			break
+	} else {
+		result, err := c.client.MGet(ctx, prefixedKeys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cache: %w", err)
 		}
+		raw = result
 	}
 
-	// 使用管道删除键和标签引用
-	pipe := c.client.Pipeline()
-	pipe.Del(ctx, prefixedKey)
+	for i, data := range raw {
+		str, ok := data.(string)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := c.serializer.Unmarshal([]byte(str), &value); err != nil {
+			continue
+		}
+		values[keys[i]] = value
+	}
+	return values, nil
+}
 
-	// 从标签集合中移除键
-	for _, tag := range tags {
-		tagKey := c.prefixKey("tag:" + tag)
-		pipe.SRem(ctx, tagKey, key)
+// SetMany 使用管道批量设置缓存项，实现BatchCache接口
+// 任意一项为nil或序列化失败时整批不写入
+func (c *RedisCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
 	}
 
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to delete cache: %w", err)
+	encoded := make(map[string][]byte, len(items))
+	for key, item := range items {
+		data, err := c.serializer.Marshal(item.Value)
+		if err != nil {
+			return fmt.Errorf("failed to serialize value for key %q: %w", key, err)
+		}
+		encoded[key] = data
 	}
 
-	// 检查并删除空标签
-	for _, tag := range tags {
-		tagKey := c.prefixKey("tag:" + tag)
-		count, err := c.client.SCard(ctx, tagKey).Result()
-		if err == nil && count == 0 {
-			c.client.Del(ctx, tagKey)
+	pipe := c.client.Pipeline()
+	for key, item := range items {
+		ttl := item.Expiration
+		if ttl == 0 {
+			ttl = c.options.DefaultTTL
 		}
+		pipe.Set(ctx, c.prefixKey(key), encoded[key], ttl)
+		pipe.Del(ctx, c.versionKey(key))
+		c.pipeTags(ctx, pipe, key, item.Tags, ttl)
 	}
 
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set cache items: %w", err)
+	}
 	return nil
 }
 
-// DeleteByPattern 根据模式删除缓存
-func (c *RedisCache) DeleteByPattern(ctx context.Context, pattern string) error {
-	prefixedPattern := c.prefixKey(pattern) + "*"
+// DeleteMany 批量删除缓存，实现BatchCache接口
+// 先通过标签反向索引读取键所在的标签，再用一个管道删除键并从这些标签集合中移除
+func (c *RedisCache) DeleteMany(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
 
-	// 使用SCAN命令查找匹配的键
-	var cursor uint64
-	var allKeys []string
+	pipe := c.client.Pipeline()
+	tagCmds := make([]*redis.StringSliceCmd, len(keys))
+	for i, key := range keys {
+		tagCmds[i] = pipe.SMembers(ctx, c.keyTagsKey(key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to get key tags: %w", err)
+	}
 
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = c.client.Scan(ctx, cursor, prefixedPattern, 100).Result()
-		if err != nil {
-			return fmt.Errorf("failed to scan keys: %w", err)
+	pipe = c.client.Pipeline()
+	for i, key := range keys {
+		for _, entryKey := range c.entryKeys(key) {
+			pipe.Del(ctx, entryKey)
 		}
+		// 标签集合的最后一个成员被移除后，Redis会自动删除该集合
+		for _, tag := range tagCmds[i].Val() {
+			pipe.SRem(ctx, c.prefixKey(tagKeyPrefix+tag), key)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete cache: %w", err)
+	}
+	return nil
+}
 
-		allKeys = append(allKeys, keys...)
-
-		if cursor == 0 {
-			break
+// pipeTags 在管道中将键加入标签集合并记录到反向索引，标签集合和反向索引的过期时间与值相同
+func (c *RedisCache) pipeTags(ctx context.Context, pipe redis.Pipeliner, key string, tags []string, ttl time.Duration) {
+	if len(tags) == 0 {
+		return
+	}
+	for _, tag := range tags {
+		tagKey := c.prefixKey(tagKeyPrefix + tag)
+		pipe.SAdd(ctx, tagKey, key)
+		// 如果设置了过期时间，也为标签索引设置相同的过期时间
+		if ttl > 0 {
+			pipe.Expire(ctx, tagKey, ttl)
 		}
 	}
 
-	// 如果找到匹配的键，删除它们
-	if len(allKeys) > 0 {
-		err := c.client.Del(ctx, allKeys...).Err()
-		if err != nil {
+	keyTagsKey := c.keyTagsKey(key)
+	pipe.SAdd(ctx, keyTagsKey, stringArgs(tags)...)
+	if ttl > 0 {
+		pipe.Expire(ctx, keyTagsKey, ttl)
+	}
+}
+
+// DeleteByPattern 删除键匹配glob模式的所有缓存，模式需要匹配KeyPrefix之后的整个键
+// 需要SCAN整个键空间，在键数量很多的实例上较慢；需要频繁整体失效的数据应使用Namespace。
+// 内部键不会被模式匹配到，即使模式为"*"；被删除的键的版本键和标签反向索引一并删除，
+// 标签集合中留下的成员由Sweeper清理
+func (c *RedisCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	keyPrefix := c.prefixKey("")
+	// 按SCAN的每一页分批删除，避免一次性收集所有键和发送过大的DEL命令
+	err := c.scan(ctx, c.prefixPattern(pattern), func(keys []string) error {
+		var entryKeys []string
+		for _, key := range keys {
+			key = strings.TrimPrefix(key, keyPrefix)
+			if !isReservedKey(key) {
+				entryKeys = append(entryKeys, c.entryKeys(key)...)
+			}
+		}
+		if err := c.unlink(ctx, entryKeys); err != nil {
 			return fmt.Errorf("failed to delete keys: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan keys: %w", err)
 	}
 
 	return nil
 }
 
+// Scan 使用SCAN遍历匹配模式的键，产出的键不带KeyPrefix，实现KeyScanner接口
+// 与SCAN命令一样，同一个键可能出现多次；内部键不会产出
+func (c *RedisCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		keyPrefix := c.prefixKey("")
+		stopped := false
+		err := c.scan(ctx, c.prefixPattern(pattern), func(keys []string) error {
+			for _, key := range keys {
+				key = strings.TrimPrefix(key, keyPrefix)
+				if isReservedKey(key) {
+					continue
+				}
+				if !yield(key, nil) {
+					stopped = true
+					return errStopScan
+				}
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield("", fmt.Errorf("failed to scan keys: %w", err))
+		}
+	}
+}
+
+// errStopScan 调用方提前结束遍历时用于中止SCAN
+var errStopScan = errors.New("cache: scan stopped")
+
+// prefixPattern 为模式加上转义后的键前缀
+func (c *RedisCache) prefixPattern(pattern string) string {
+	if c.options.KeyPrefix == "" {
+		return pattern
+	}
+	return EscapePattern(c.options.KeyPrefix+":") + pattern
+}
+
 // DeleteByTag 删除带特定标签的所有缓存
+// 被删除的键同时从它们所在的其他标签集合中移除
 func (c *RedisCache) DeleteByTag(ctx context.Context, tag string) error {
-	tagKey := c.prefixKey("tag:" + tag)
+	tagKey := c.prefixKey(tagKeyPrefix + tag)
 
 	// 获取标签关联的所有键
 	keys, err := c.client.SMembers(ctx, tagKey).Result()
@@ -407,19 +652,11 @@ func (c *RedisCache) DeleteByTag(ctx context.Context, tag string) error {
 		return nil
 	}
 
-	// 预处理键名称
-	prefixedKeys := make([]string, len(keys))
-	for i, key := range keys {
-		prefixedKeys[i] = c.prefixKey(key)
+	if err := c.DeleteMany(ctx, keys); err != nil {
+		return fmt.Errorf("failed to delete keys by tag: %w", err)
 	}
-
-	// 使用管道删除所有键和标签索引
-	pipe := c.client.Pipeline()
-	pipe.Del(ctx, prefixedKeys...)
-	pipe.Del(ctx, tagKey)
-
-	_, err = pipe.Exec(ctx)
-	if err != nil {
+	// 没有反向索引的成员不会被DeleteMany移除，最后删除整个标签集合
+	if err := c.client.Del(ctx, tagKey).Err(); err != nil {
 		return fmt.Errorf("failed to delete keys by tag: %w", err)
 	}
 
@@ -439,11 +676,30 @@ func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 // Increment 增加数值
 func (c *RedisCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
 	prefixedKey := c.prefixKey(key)
-	result, err := c.client.IncrBy(ctx, prefixedKey, value).Result()
-	if err != nil {
+	pipe := c.client.Pipeline()
+	result := pipe.IncrBy(ctx, prefixedKey, value)
+	pipe.Del(ctx, c.versionKey(key))
+	if _, err := pipe.Exec(ctx); err != nil {
 		return 0, fmt.Errorf("failed to increment value: %w", err)
 	}
-	return result, nil
+	return result.Val(), nil
+}
+
+// readCounter 读取Increment计数器，实现counterReader接口
+// INCRBY写入的是Redis整数，直接解析原始数据，不经过序列化器
+func (c *RedisCache) readCounter(ctx context.Context, key string) (int64, bool, error) {
+	data, err := c.client.Get(ctx, c.prefixKey(key)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get counter: %w", structuredError(err))
+	}
+	value, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("value is not an integer: %q", data)
+	}
+	return value, true, nil
 }
 
 // Decrement 减少数值
@@ -462,34 +718,65 @@ func (c *RedisCache) Flush(ctx context.Context) error {
 		return nil
 	}
 
-	// 有前缀，只清除前缀对应的键
-	prefixPattern := c.prefixKey("*")
-	var cursor uint64
-	var allKeys []string
-
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = c.client.Scan(ctx, cursor, prefixPattern, 100).Result()
-		if err != nil {
-			return fmt.Errorf("failed to scan keys with prefix: %w", err)
+	// 有前缀，只清除前缀对应的键，同样需要SCAN整个键空间
+	err := c.scan(ctx, c.prefixPattern("*"), func(keys []string) error {
+		if err := c.unlink(ctx, keys); err != nil {
+			return fmt.Errorf("failed to delete prefixed keys: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan keys with prefix: %w", err)
+	}
 
-		allKeys = append(allKeys, keys...)
+	return nil
+}
 
-		if cursor == 0 {
-			break
-		}
+// scan 遍历匹配match的键，每批调用一次fn，传入的是带前缀的完整键名
+// 集群模式下逐个遍历所有主节点
+func (c *RedisCache) scan(ctx context.Context, match string, fn func(keys []string) error) error {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanNode(ctx, node, match, fn)
+		})
 	}
+	return scanNode(ctx, c.client, match, fn)
+}
 
-	if len(allKeys) > 0 {
-		err := c.client.Del(ctx, allKeys...).Err()
+func scanNode(ctx context.Context, client redis.Cmdable, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, 100).Result()
 		if err != nil {
-			return fmt.Errorf("failed to delete prefixed keys: %w", err)
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
 		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
 	}
+}
 
-	return nil
+// unlink 删除带前缀的完整键名
+// 集群模式下键可能分布在不同槽位，使用管道逐个删除
+func (c *RedisCache) unlink(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, ok := c.client.(*redis.ClusterClient); !ok {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // Close 关闭缓存连接
@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RefreshOptions 提前刷新选项
+type RefreshOptions struct {
+	// SoftTTL 软过期时间，超过后读取立即返回旧值，同时在后台刷新
+	SoftTTL time.Duration
+
+	// HardTTL 硬过期时间，缓存项在底层缓存中的真实生存时间
+	// 超过后缓存项被删除，读取需要等待加载；小于SoftTTL时按SoftTTL处理
+	HardTTL time.Duration
+
+	// Beta XFetch提前刷新系数，0表示只在软过期后刷新
+	// 值越大越倾向于提前刷新，加载耗时越长的键也会越早刷新，1为推荐值
+	Beta float64
+
+	// RefreshTimeout 后台刷新的超时时间
+	RefreshTimeout time.Duration
+
+	// OnRefreshError 后台刷新失败时的回调，失败时继续返回旧值直到硬过期；
+	// loader成功但写入缓存失败时也通过它报告，此时调用方仍然得到加载的值
+	OnRefreshError func(key string, err error)
+}
+
+// DefaultRefreshOptions 返回默认的提前刷新选项
+func DefaultRefreshOptions() RefreshOptions {
+	return RefreshOptions{
+		SoftTTL:        time.Minute,
+		HardTTL:        time.Minute * 10,
+		Beta:           1,
+		RefreshTimeout: time.Second * 30,
+	}
+}
+
+// refreshEntry 带刷新元数据的缓存项
+// 元数据与值一起存储，RedisCache中随值一起序列化
+type refreshEntry[T any] struct {
+	// Value 缓存值
+	Value T `json:"value"`
+	// SoftExpiry 软过期时间点，Unix纳秒
+	SoftExpiry int64 `json:"soft_expiry"`
+	// Delta 上次加载的耗时，纳秒，用于XFetch估算提前刷新的时机
+	Delta int64 `json:"delta"`
+}
+
+// RefreshingCache 支持提前刷新和过期后返回旧值的缓存
+// 缓存项同时具有软过期时间和硬过期时间：软过期之后的读取立即返回旧值，
+// 并由单个后台任务重新加载；硬过期之后缓存项被删除，读取等待加载完成。
+// 设置Beta时按XFetch算法在软过期前概率性地提前刷新，把热点键的刷新分散开
+//
+// 由RefreshingCache管理的键应只通过它读写，直接通过底层缓存读取得到的是带元数据的缓存项
+//
+// 示例：
+//
+//	users := cache.NewRefreshingCache[*User](redisCache, cache.RefreshOptions{
+//	    SoftTTL: time.Minute,
+//	    HardTTL: time.Hour,
+//	    Beta:    1,
+//	})
+//	user, err := users.Get(ctx, "user:123", func(ctx context.Context) (*User, error) {
+//	    return userRepo.FindByID(ctx, 123)
+//	})
+type RefreshingCache[T any] struct {
+	cache      Cache
+	options    RefreshOptions
+	loads      loadGroup
+	refreshing sync.Map
+}
+
+// NewRefreshingCache 创建支持提前刷新的缓存
+// 参数：
+//
+//	c: 底层缓存，可以是MemoryCache、RedisCache、MultiLevelCache等任意实现
+//	opts: 刷新选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewRefreshingCache[T any](c Cache, opts ...RefreshOptions) *RefreshingCache[T] {
+	var options RefreshOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	} else {
+		options = DefaultRefreshOptions()
+	}
+
+	if options.HardTTL > 0 && options.HardTTL < options.SoftTTL {
+		options.HardTTL = options.SoftTTL
+	}
+	if options.RefreshTimeout <= 0 {
+		options.RefreshTimeout = DefaultRefreshOptions().RefreshTimeout
+	}
+
+	return &RefreshingCache[T]{cache: c, options: options}
+}
+
+// Get 获取缓存值
+// 命中且未到刷新时机时直接返回；到了刷新时机时返回旧值并触发后台刷新；
+// 未命中时调用loader加载，同一键的并发未命中只加载一次
+// 参数：
+//
+//	ctx: 上下文，取消时当前调用立即返回
+//	key: 缓存键名
+//	loader: 加载数据的函数
+//
+// 返回：
+//
+//	T: 缓存值或加载的值
+//	error: 未命中且加载失败时返回错误
+func (c *RefreshingCache[T]) Get(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	var entry refreshEntry[T]
+	found, err := GetInto(ctx, c.cache, key, &entry)
+	if err == nil && found {
+		if c.shouldRefresh(time.Now(), &entry) {
+			c.refresh(ctx, key, loader)
+		}
+		return entry.Value, nil
+	}
+
+	value, err := c.loads.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return c.load(ctx, key, loader)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	typed, _ := value.(T)
+	return typed, nil
+}
+
+// Set 直接设置缓存值，软过期时间从现在开始计算
+func (c *RefreshingCache[T]) Set(ctx context.Context, key string, value T) error {
+	return c.store(ctx, key, value, 0)
+}
+
+// Delete 删除缓存
+func (c *RefreshingCache[T]) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// Cache 返回底层缓存
+func (c *RefreshingCache[T]) Cache() Cache {
+	return c.cache
+}
+
+// shouldRefresh 判断缓存项是否到了刷新时机
+func (c *RefreshingCache[T]) shouldRefresh(now time.Time, entry *refreshEntry[T]) bool {
+	return xfetch(now, time.Unix(0, entry.SoftExpiry), time.Duration(entry.Delta), c.options.Beta, rand.Float64())
+}
+
+// refresh 在后台刷新键，同一键同时只有一个刷新任务
+func (c *RefreshingCache[T]) refresh(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) {
+	if _, running := c.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.options.RefreshTimeout)
+	go func() {
+		defer cancel()
+		defer c.refreshing.Delete(key)
+		defer func() {
+			if r := recover(); r != nil {
+				c.refreshFailed(key, fmt.Errorf("cache: loader panic for key %q: %v", key, r))
+			}
+		}()
+
+		if _, err := c.load(ctx, key, loader); err != nil {
+			c.refreshFailed(key, err)
+		}
+	}()
+}
+
+// refreshFailed 报告后台刷新失败
+func (c *RefreshingCache[T]) refreshFailed(key string, err error) {
+	if c.options.OnRefreshError != nil {
+		c.options.OnRefreshError(key, err)
+	}
+}
+
+// load 调用loader并写入缓存，记录加载耗时
+// 写入失败不影响返回加载的值，错误通过OnRefreshError报告
+func (c *RefreshingCache[T]) load(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	value, err := loader(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	if err := c.store(ctx, key, value, time.Since(start)); err != nil {
+		c.refreshFailed(key, fmt.Errorf("cache: failed to store loaded value for key %q: %w", key, err))
+	}
+	return value, nil
+}
+
+// store 写入带元数据的缓存项
+func (c *RefreshingCache[T]) store(ctx context.Context, key string, value T, delta time.Duration) error {
+	entry := refreshEntry[T]{
+		Value:      value,
+		SoftExpiry: time.Now().Add(c.options.SoftTTL).UnixNano(),
+		Delta:      int64(delta),
+	}
+	return c.cache.Set(ctx, key, entry, c.options.HardTTL)
+}
+
+// xfetch XFetch概率提前刷新判断
+// 当 now - delta*beta*ln(r) >= expiry 时刷新，r为(0,1]上的随机数。
+// 距离过期越近、加载耗时越长，提前刷新的概率越高；beta为0时退化为到期刷新
+func xfetch(now, expiry time.Time, delta time.Duration, beta, r float64) bool {
+	if !now.Before(expiry) {
+		return true
+	}
+	if beta <= 0 || delta <= 0 {
+		return false
+	}
+
+	// rand.Float64返回[0,1)，转换为(0,1]避免ln(0)
+	gap := -float64(delta) * beta * math.Log(1-r)
+	return gap >= float64(expiry.Sub(now))
+}
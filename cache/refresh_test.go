@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshingCache_StaleWhileRevalidate(t *testing.T) {
+	for name, c := range typedTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			users := NewRefreshingCache[typedTestUser](c, RefreshOptions{
+				SoftTTL: 50 * time.Millisecond,
+				HardTTL: time.Minute,
+			})
+
+			var version atomic.Int64
+			release := make(chan struct{}, 1)
+			loader := func(ctx context.Context) (typedTestUser, error) {
+				v := version.Add(1)
+				if v > 1 {
+					<-release
+				}
+				return typedTestUser{ID: v, Name: "alice"}, nil
+			}
+
+			user, err := users.Get(ctx, "user:1", loader)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), user.ID)
+
+			// 软过期前直接返回缓存值
+			user, err = users.Get(ctx, "user:1", loader)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), user.ID)
+			assert.Equal(t, int64(1), version.Load())
+
+			// 软过期后立即返回旧值，只触发一次后台刷新
+			time.Sleep(60 * time.Millisecond)
+			for i := 0; i < 10; i++ {
+				user, err = users.Get(ctx, "user:1", loader)
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), user.ID)
+			}
+			assert.Eventually(t, func() bool { return version.Load() == 2 }, time.Second, time.Millisecond)
+			assert.Equal(t, int64(2), version.Load())
+
+			release <- struct{}{}
+			assert.Eventually(t, func() bool {
+				user, err := users.Get(ctx, "user:1", loader)
+				return err == nil && user.ID == 2
+			}, time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestRefreshingCache_RefreshErrorKeepsStaleValue(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	refreshErr := errors.New("db down")
+	reported := make(chan error, 1)
+	values := NewRefreshingCache[string](c, RefreshOptions{
+		SoftTTL:        10 * time.Millisecond,
+		HardTTL:        time.Minute,
+		OnRefreshError: func(key string, err error) { reported <- err },
+	})
+
+	ctx := context.Background()
+	require.NoError(t, values.Set(ctx, "key", "stale"))
+	time.Sleep(20 * time.Millisecond)
+
+	value, err := values.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		return "", refreshErr
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "stale", value)
+
+	select {
+	case err := <-reported:
+		assert.ErrorIs(t, err, refreshErr)
+	case <-time.After(time.Second):
+		t.Fatal("refresh error was not reported")
+	}
+
+	value, err = values.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		return "fresh", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "stale", value)
+}
+
+func TestRefreshingCache_StoreErrorReturnsLoadedValue(t *testing.T) {
+	storeErr := errors.New("cache down")
+	c := NewFaultCache(NewMemoryCache(), FaultOptions{Rules: []FaultRule{
+		{Ops: []Operation{OpSet}, Kind: FaultError, Err: storeErr},
+	}})
+	defer c.Close()
+
+	var reported []error
+	values := NewRefreshingCache[string](c, RefreshOptions{
+		SoftTTL:        time.Minute,
+		HardTTL:        time.Hour,
+		OnRefreshError: func(key string, err error) { reported = append(reported, err) },
+	})
+
+	value, err := values.Get(context.Background(), "key", func(ctx context.Context) (string, error) {
+		return "loaded", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+	require.Len(t, reported, 1)
+	assert.ErrorIs(t, reported[0], storeErr)
+}
+
+func TestRefreshingCache_HardExpiryLoadsInForeground(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	values := NewRefreshingCache[string](c, RefreshOptions{
+		SoftTTL: 10 * time.Millisecond,
+		HardTTL: 20 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+	require.NoError(t, values.Set(ctx, "key", "old"))
+	time.Sleep(30 * time.Millisecond)
+
+	value, err := values.Get(ctx, "key", func(ctx context.Context) (string, error) {
+		return "new", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", value)
+
+	_, err = values.Get(ctx, "missing", func(ctx context.Context) (string, error) {
+		return "", errors.New("not found")
+	})
+	assert.Error(t, err)
+}
+
+func TestRefreshingCache_ProbabilisticEarlyRefresh(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+
+	var loads atomic.Int32
+	values := NewRefreshingCache[int](c, RefreshOptions{
+		SoftTTL: time.Hour,
+		HardTTL: time.Hour,
+		Beta:    1e6,
+	})
+
+	ctx := context.Background()
+	loader := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return 1, nil
+	}
+
+	_, err := values.Get(ctx, "key", loader)
+	require.NoError(t, err)
+
+	// 加载耗时乘以极大的Beta远超软过期时间，读取几乎必然触发提前刷新
+	assert.Eventually(t, func() bool {
+		_, _ = values.Get(ctx, "key", loader)
+		return loads.Load() > 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestXFetch(t *testing.T) {
+	now := time.Now()
+	expiry := now.Add(time.Second)
+
+	assert.True(t, xfetch(expiry, expiry, 0, 0, 0.5), "expired entries always refresh")
+	assert.False(t, xfetch(now, expiry, time.Second, 0, 0.99), "beta 0 disables early refresh")
+	assert.False(t, xfetch(now, expiry, 0, 1, 0.99), "unknown load time disables early refresh")
+
+	// -ln(1-r) 在r接近1时足够大
+	assert.True(t, xfetch(now, expiry, 100*time.Millisecond, 1, 0.99999))
+	assert.False(t, xfetch(now, expiry, 100*time.Millisecond, 1, 0.5))
+}
@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"hash/maphash"
+	"iter"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ShardedMemoryCache 分片内存缓存
+// 按键的哈希值将数据分布到多个独立的MemoryCache分片中，每个分片拥有自己的锁、
+// 标签索引和淘汰器，不同分片上的操作互不阻塞，适合高并发读写的场景
+//
+// 与MemoryCache的差异：
+//   - MaxEntries和MaxCost平均分配到各分片，淘汰在分片内独立进行
+//   - DeleteByPattern、DeleteByTag和Flush会依次作用于所有分片，不是全局原子操作
+//   - 单个键上的操作（包括Increment）仍然是原子的
+type ShardedMemoryCache struct {
+	shards  []*MemoryCache
+	mask    uint64
+	seed    maphash.Seed
+	janitor *janitor
+	closed  sync.Once
+}
+
+// NewShardedMemoryCache 创建分片内存缓存
+// 参数：
+//
+//	shards: 分片数量，会向上取整为2的幂；小于等于0时使用GOMAXPROCS的4倍。
+//	设置了MaxEntries或MaxCost时，分片数会减少到不超过它们，保证每个分片至少能容纳一项
+//	opts: 缓存选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+//
+// 返回：
+//
+//	*ShardedMemoryCache: 配置好的分片内存缓存实例
+//
+// 示例：
+//
+//	cache := cache.NewShardedMemoryCache(64, cache.Options{
+//	    MaxEntries:     100000,
+//	    EvictionPolicy: cache.EvictionTinyLFU,
+//	})
+func NewShardedMemoryCache(shards int, opts ...Options) *ShardedMemoryCache {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	} else {
+		options = DefaultOptions()
+	}
+
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0) * 4
+	}
+	count := 1
+	for count < shards {
+		count <<= 1
+	}
+	for count > 1 && (options.MaxEntries > 0 && count > options.MaxEntries ||
+		options.MaxCost > 0 && int64(count) > options.MaxCost) {
+		count >>= 1
+	}
+
+	// 容量向下取整平均分配到各分片，各分片容量之和不超过总容量；过期清理由统一的清理器负责
+	// 键到分片的映射在每次启动时随机，因此分片不支持快照
+	shardOptions := options
+	shardOptions.CleanupInterval = 0
+	shardOptions.Snapshot = SnapshotOptions{}
+	if options.MaxEntries > 0 {
+		shardOptions.MaxEntries = options.MaxEntries / count
+	}
+	if options.MaxCost > 0 {
+		shardOptions.MaxCost = options.MaxCost / int64(count)
+	}
+
+	c := &ShardedMemoryCache{
+		shards: make([]*MemoryCache, count),
+		mask:   uint64(count - 1),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range c.shards {
+		c.shards[i] = NewMemoryCache(shardOptions)
+	}
+
+	if options.CleanupInterval > 0 {
+		c.janitor = newJanitor(options.CleanupInterval)
+		c.janitor.run(c.deleteExpired)
+	}
+
+	return c
+}
+
+// shard 返回键所在的分片
+func (c *ShardedMemoryCache) shard(key string) *MemoryCache {
+	return c.shards[maphash.String(c.seed, key)&c.mask]
+}
+
+// ShardCount 返回分片数量
+func (c *ShardedMemoryCache) ShardCount() int {
+	return len(c.shards)
+}
+
+// Get 从缓存中检索值
+func (c *ShardedMemoryCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	return c.shard(key).Get(ctx, key)
+}
+
+// GetWithTTL 获取值和剩余TTL
+func (c *ShardedMemoryCache) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, bool) {
+	return c.shard(key).GetWithTTL(ctx, key)
+}
+
+// GetInto 获取缓存值并赋给target，实现ValueDecoder接口
+func (c *ShardedMemoryCache) GetInto(ctx context.Context, key string, target interface{}) (bool, error) {
+	return c.shard(key).GetInto(ctx, key, target)
+}
+
+// GetIntoWithTTL 获取缓存值和剩余TTL并赋给target，实现ValueDecoder接口
+func (c *ShardedMemoryCache) GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error) {
+	return c.shard(key).GetIntoWithTTL(ctx, key, target)
+}
+
+// GetOrLoad 获取缓存值，未命中时合并并发调用只加载一次，实现Loader接口
+func (c *ShardedMemoryCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoadFunc) (interface{}, error) {
+	return c.shard(key).GetOrLoad(ctx, key, ttl, loader)
+}
+
+// Set 设置缓存值
+func (c *ShardedMemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.shard(key).Set(ctx, key, value, ttl)
+}
+
+// SetItem 设置带完整选项的缓存项
+func (c *ShardedMemoryCache) SetItem(ctx context.Context, key string, item *Item) error {
+	return c.shard(key).SetItem(ctx, key, item)
+}
+
+// Delete 从缓存中删除项
+func (c *ShardedMemoryCache) Delete(ctx context.Context, key string) error {
+	return c.shard(key).Delete(ctx, key)
+}
+
+// GetMany 按分片分组后批量获取缓存值，实现BatchCache接口
+func (c *ShardedMemoryCache) GetMany(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(keys))
+	for shard, shardKeys := range c.groupKeys(keys) {
+		shardValues, err := shard.GetMany(ctx, shardKeys)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range shardValues {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// SetMany 按分片分组后批量设置缓存项，实现BatchCache接口
+func (c *ShardedMemoryCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	if err := validateItems(items); err != nil {
+		return err
+	}
+
+	groups := make(map[*MemoryCache]map[string]*Item)
+	for key, item := range items {
+		shard := c.shard(key)
+		if groups[shard] == nil {
+			groups[shard] = make(map[string]*Item)
+		}
+		groups[shard][key] = item
+	}
+
+	for shard, shardItems := range groups {
+		if err := shard.SetMany(ctx, shardItems); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMany 按分片分组后批量删除缓存，实现BatchCache接口
+func (c *ShardedMemoryCache) DeleteMany(ctx context.Context, keys []string) error {
+	for shard, shardKeys := range c.groupKeys(keys) {
+		if err := shard.DeleteMany(ctx, shardKeys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupKeys 将键按所在分片分组
+func (c *ShardedMemoryCache) groupKeys(keys []string) map[*MemoryCache][]string {
+	groups := make(map[*MemoryCache][]string)
+	for _, key := range keys {
+		shard := c.shard(key)
+		groups[shard] = append(groups[shard], key)
+	}
+	return groups
+}
+
+// DeleteByPattern 删除所有分片中键匹配glob模式的缓存
+func (c *ShardedMemoryCache) DeleteByPattern(_ context.Context, pattern string) error {
+	for _, shard := range c.shards {
+		shard.deleteMatching(pattern)
+	}
+	return nil
+}
+
+// Scan 依次遍历每个分片中匹配模式的键，实现KeyScanner接口
+func (c *ShardedMemoryCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, shard := range c.shards {
+			for key, err := range shard.Scan(ctx, pattern) {
+				if !yield(key, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DeleteByTag 删除所有分片中带特定标签的缓存
+func (c *ShardedMemoryCache) DeleteByTag(ctx context.Context, tag string) error {
+	for _, shard := range c.shards {
+		if err := shard.DeleteByTag(ctx, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists 检查键是否存在
+func (c *ShardedMemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	return c.shard(key).Exists(ctx, key)
+}
+
+// Increment 原子地增加数值
+func (c *ShardedMemoryCache) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	return c.shard(key).Increment(ctx, key, value)
+}
+
+// readCounter 读取Increment计数器，实现counterReader接口
+func (c *ShardedMemoryCache) readCounter(ctx context.Context, key string) (int64, bool, error) {
+	return c.shard(key).readCounter(ctx, key)
+}
+
+// Decrement 原子地减少数值
+func (c *ShardedMemoryCache) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	return c.shard(key).Decrement(ctx, key, value)
+}
+
+// Flush 清空所有分片
+func (c *ShardedMemoryCache) Flush(ctx context.Context) error {
+	for _, shard := range c.shards {
+		if err := shard.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 停止清理器并关闭所有分片，重复调用是安全的
+func (c *ShardedMemoryCache) Close() error {
+	c.closed.Do(func() {
+		if c.janitor != nil {
+			c.janitor.stop()
+		}
+		for _, shard := range c.shards {
+			_ = shard.Close()
+		}
+	})
+	return nil
+}
+
+// Stats 返回所有分片汇总的统计信息
+func (c *ShardedMemoryCache) Stats() Stats {
+	var total Stats
+	for _, shard := range c.shards {
+		stats := shard.Stats()
+		total.Entries += stats.Entries
+		total.Cost += stats.Cost
+		total.Evictions += stats.Evictions
+		total.Expirations += stats.Expirations
+	}
+	return total
+}
+
+// deleteExpired 清理所有分片中的过期项
+func (c *ShardedMemoryCache) deleteExpired() {
+	for _, shard := range c.shards {
+		shard.deleteExpired()
+	}
+}
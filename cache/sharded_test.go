@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewShardedMemoryCache(t *testing.T) {
+	cache := NewShardedMemoryCache(10)
+	defer cache.Close()
+	if cache.ShardCount() != 16 {
+		t.Errorf("Expected shard count rounded up to 16, got %d", cache.ShardCount())
+	}
+
+	cache = NewShardedMemoryCache(0)
+	defer cache.Close()
+	if cache.ShardCount() < 4 {
+		t.Errorf("Expected default shard count of at least 4, got %d", cache.ShardCount())
+	}
+}
+
+func TestShardedMemoryCacheImplementation(t *testing.T) {
+	// 使用通用测试函数测试分片内存缓存
+	cache := NewShardedMemoryCache(8)
+	defer cache.Close()
+	testCacheImplementation(t, cache)
+}
+
+func TestShardedMemoryCache_TagsAndPatternAcrossShards(t *testing.T) {
+	cache := NewShardedMemoryCache(16)
+	defer cache.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		tags := []string{"all"}
+		if i%2 == 0 {
+			tags = append(tags, "even")
+		}
+		_ = cache.SetItem(ctx, fmt.Sprintf("user:%d", i), &Item{Value: i, Expiration: time.Minute, Tags: tags})
+		_ = cache.Set(ctx, fmt.Sprintf("order:%d", i), i, time.Minute)
+	}
+
+	if err := cache.DeleteByTag(ctx, "even"); err != nil {
+		t.Fatalf("DeleteByTag failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		_, found := cache.Get(ctx, fmt.Sprintf("user:%d", i))
+		if found == (i%2 == 0) {
+			t.Fatalf("Unexpected presence for user:%d after DeleteByTag: %v", i, found)
+		}
+	}
+
+	if err := cache.DeleteByPattern(ctx, "order:*"); err != nil {
+		t.Fatalf("DeleteByPattern failed: %v", err)
+	}
+	if stats := cache.Stats(); stats.Entries != 50 {
+		t.Errorf("Expected 50 entries after deletes, got %d", stats.Entries)
+	}
+
+	if err := cache.DeleteByPattern(ctx, "["); err != nil {
+		t.Errorf("Patterns are never invalid, got %v", err)
+	}
+
+	_ = cache.Flush(ctx)
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected empty cache after flush, got %d", stats.Entries)
+	}
+}
+
+func TestShardedMemoryCache_SmallCapacity(t *testing.T) {
+	cache := NewShardedMemoryCache(64, Options{MaxEntries: 10})
+	defer cache.Close()
+	ctx := context.Background()
+
+	if cache.ShardCount() != 8 {
+		t.Errorf("Expected shard count capped to 8, got %d", cache.ShardCount())
+	}
+	for i := 0; i < 1000; i++ {
+		_ = cache.Set(ctx, fmt.Sprintf("key:%d", i), i, time.Minute)
+	}
+	if stats := cache.Stats(); stats.Entries > 10 {
+		t.Errorf("Expected at most 10 entries, stats: %+v", stats)
+	}
+
+	costly := NewShardedMemoryCache(64, Options{MaxCost: 4})
+	defer costly.Close()
+	if costly.ShardCount() != 4 {
+		t.Errorf("Expected shard count capped to 4, got %d", costly.ShardCount())
+	}
+}
+
+func TestShardedMemoryCache_IncrementAtomic(t *testing.T) {
+	cache := NewShardedMemoryCache(8)
+	defer cache.Close()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_, _ = cache.Increment(ctx, "counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _ := cache.Get(ctx, "counter")
+	if value != int64(16000) {
+		t.Errorf("Expected counter 16000, got %v", value)
+	}
+}
+
+func TestShardedMemoryCache_CapacityAndExpiration(t *testing.T) {
+	cache := NewShardedMemoryCache(4, Options{
+		MaxEntries:      40,
+		CleanupInterval: time.Millisecond * 20,
+	})
+	defer cache.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 1000; i++ {
+		_ = cache.Set(ctx, fmt.Sprintf("key:%d", i), i, time.Minute)
+	}
+	stats := cache.Stats()
+	if stats.Entries > 40 || stats.Evictions == 0 {
+		t.Errorf("Expected capacity to be enforced, stats: %+v", stats)
+	}
+
+	_ = cache.Set(ctx, "short", "v", time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100)
+	if stats := cache.Stats(); stats.Expirations == 0 {
+		t.Errorf("Expected janitor to clean expired items, stats: %+v", stats)
+	}
+}
+
+func benchmarkCacheParallel(b *testing.B, cache Cache) {
+	defer cache.Close()
+	ctx := context.Background()
+
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key:%d", i)
+		_ = cache.Set(ctx, keys[i], i, time.Minute)
+	}
+
+	// 每个goroutine从不同的位置开始访问，避免同步地争用相同的键
+	var offset atomic.Int64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(offset.Add(997))
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			// 90%读、10%写
+			if i%10 == 0 {
+				_ = cache.Set(ctx, key, i, time.Minute)
+			} else {
+				cache.Get(ctx, key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkParallel_MemoryCache(b *testing.B) {
+	benchmarkCacheParallel(b, NewMemoryCache())
+}
+
+func BenchmarkParallel_ShardedMemoryCache(b *testing.B) {
+	benchmarkCacheParallel(b, NewShardedMemoryCache(0))
+}
+
+func BenchmarkParallel_MemoryCacheLRU(b *testing.B) {
+	benchmarkCacheParallel(b, NewMemoryCache(Options{MaxEntries: 2048}))
+}
+
+func BenchmarkParallel_ShardedMemoryCacheLRU(b *testing.B) {
+	benchmarkCacheParallel(b, NewShardedMemoryCache(0, Options{MaxEntries: 2048}))
+}
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// snapshotVersion 快照文件格式版本
+const snapshotVersion = 1
+
+// SnapshotOptions 内存缓存快照选项
+type SnapshotOptions struct {
+	// Path 快照文件路径，为空时不启用快照
+	Path string
+
+	// Interval 定期写入快照的间隔，0表示只在Close时写入
+	Interval time.Duration
+
+	// Serializer 序列化缓存值的序列化器，默认使用JSON
+	// 快照记录基本类型（整数、浮点数、字符串、布尔值）的原类型，恢复后计数器仍是整数，可以继续Increment；
+	// 其他值被反序列化到interface{}，使用JSON时结构体会变为map[string]interface{}，
+	// 需要通过GetInto或Typed按原类型读取，或者设置Decode恢复原类型
+	Serializer Serializer
+
+	// Decode 可选的解码函数，按键将序列化的数据恢复为原类型
+	// 为nil时使用Serializer反序列化到interface{}
+	Decode func(key string, data []byte) (interface{}, error)
+
+	// OnError 读写快照出错时的回调，例如启动时快照文件损坏或某个值无法序列化
+	// 单个值无法序列化时跳过该值，不影响其他值
+	OnError func(err error)
+}
+
+// snapshotHeader 快照文件头部
+type snapshotHeader struct {
+	Version   int
+	CreatedAt time.Time
+	Entries   int
+}
+
+// snapshotEntry 快照中的缓存项
+type snapshotEntry struct {
+	Key string
+	// Value 序列化后的值
+	Value []byte
+	// Expiration 过期时间（Unix纳秒时间戳），0表示永不过期
+	Expiration int64
+	Tags       []string
+	// Type 值的基本类型名称，不是基本类型时为空
+	Type string
+}
+
+// snapshotScalarTypes 快照中按原类型恢复的基本类型
+var snapshotScalarTypes = func() map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	for _, v := range []interface{}{
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), "", false,
+	} {
+		t := reflect.TypeOf(v)
+		types[t.String()] = t
+	}
+	return types
+}()
+
+// snapshotValueType 返回值的基本类型名称，不是基本类型时返回空字符串
+func snapshotValueType(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	name := reflect.TypeOf(value).String()
+	if _, ok := snapshotScalarTypes[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// WarmupFunc 缓存预热函数，在应用开始提供服务前装入热点数据
+type WarmupFunc func(ctx context.Context, c Cache) error
+
+// WriteSnapshot 将所有未过期的缓存项写入w
+// 快照包含值、过期时间和标签，值使用SnapshotOptions.Serializer序列化，无法序列化的值被跳过
+func (c *MemoryCache) WriteSnapshot(w io.Writer) error {
+	serializer := c.snapshotSerializer()
+	now := time.Now().UnixNano()
+
+	c.mu.RLock()
+	entries := make([]snapshotEntry, 0, len(c.items))
+	values := make([]interface{}, 0, len(c.items))
+	for key, item := range c.items {
+		// 结构化数据不写入快照
+		if (item.Expiration > 0 && now > item.Expiration) || isMemoryStructure(item.Value) {
+			continue
+		}
+		entries = append(entries, snapshotEntry{Key: key, Expiration: item.Expiration, Tags: item.Tags, Type: snapshotValueType(item.Value)})
+		values = append(values, item.Value)
+	}
+	c.mu.RUnlock()
+
+	// 在锁外序列化，避免大量数据阻塞写操作
+	n := 0
+	for i := range entries {
+		data, err := serializer.Marshal(values[i])
+		if err != nil {
+			c.reportSnapshotError(fmt.Errorf("snapshot: skip key %q: %w", entries[i].Key, err))
+			continue
+		}
+		entries[i].Value = data
+		entries[n] = entries[i]
+		n++
+	}
+	entries = entries[:n]
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, CreatedAt: time.Now(), Entries: len(entries)}); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot 从r恢复缓存项，跳过已过期的项，返回恢复的项数
+// 已存在的同名键会被覆盖，设置了容量限制时按淘汰策略淘汰
+func (c *MemoryCache) ReadSnapshot(r io.Reader) (int, error) {
+	dec := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return 0, fmt.Errorf("snapshot: invalid header: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return 0, fmt.Errorf("snapshot: unsupported version %d", header.Version)
+	}
+
+	restored := 0
+	for i := 0; i < header.Entries; i++ {
+		var entry snapshotEntry
+		if err := dec.Decode(&entry); err != nil {
+			return restored, fmt.Errorf("snapshot: truncated after %d entries: %w", i, err)
+		}
+		if entry.Expiration > 0 && time.Now().UnixNano() > entry.Expiration {
+			continue
+		}
+
+		value, err := c.decodeSnapshotValue(&entry)
+		if err != nil {
+			c.reportSnapshotError(fmt.Errorf("snapshot: skip key %q: %w", entry.Key, err))
+			continue
+		}
+
+		item := &memoryItem{
+			Value:      value,
+			Expiration: entry.Expiration,
+			Tags:       entry.Tags,
+			Cost:       c.itemCost(entry.Key, value),
+		}
+		c.mu.Lock()
+		evicted := c.storeUnsafe(entry.Key, item)
+		c.mu.Unlock()
+		c.notifyEvicted(evicted, EvictionReasonCapacity)
+		restored++
+	}
+	return restored, nil
+}
+
+// SaveSnapshot 将快照写入path
+// 先写入同一目录下的临时文件再重命名，写入中途失败不会破坏已有的快照
+func (c *MemoryCache) SaveSnapshot(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := c.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot 从path恢复缓存项，返回恢复的项数
+// 文件不存在时返回0和nil，便于首次启动
+func (c *MemoryCache) LoadSnapshot(path string) (int, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("snapshot: %w", err)
+	}
+	defer f.Close()
+	return c.ReadSnapshot(f)
+}
+
+// startSnapshots 恢复快照并启动定期写入，由NewMemoryCache调用
+func (c *MemoryCache) startSnapshots() {
+	opts := c.options.Snapshot
+	if opts.Path == "" {
+		return
+	}
+
+	if _, err := c.LoadSnapshot(opts.Path); err != nil {
+		c.reportSnapshotError(err)
+	}
+
+	if opts.Interval > 0 {
+		c.snapshotStop = make(chan struct{})
+		c.snapshotDone = make(chan struct{})
+		go c.runSnapshots(opts.Path, opts.Interval)
+	}
+}
+
+func (c *MemoryCache) runSnapshots(path string, interval time.Duration) {
+	defer close(c.snapshotDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.SaveSnapshot(path); err != nil {
+				c.reportSnapshotError(err)
+			}
+		case <-c.snapshotStop:
+			return
+		}
+	}
+}
+
+// stopSnapshots 停止定期写入并写入最后一次快照，由Close调用
+func (c *MemoryCache) stopSnapshots() error {
+	if c.options.Snapshot.Path == "" {
+		return nil
+	}
+	if c.snapshotStop != nil {
+		close(c.snapshotStop)
+		<-c.snapshotDone
+	}
+	return c.SaveSnapshot(c.options.Snapshot.Path)
+}
+
+func (c *MemoryCache) snapshotSerializer() Serializer {
+	if c.options.Snapshot.Serializer != nil {
+		return c.options.Snapshot.Serializer
+	}
+	return DefaultSerializer()
+}
+
+func (c *MemoryCache) decodeSnapshotValue(entry *snapshotEntry) (interface{}, error) {
+	if c.options.Snapshot.Decode != nil {
+		return c.options.Snapshot.Decode(entry.Key, entry.Value)
+	}
+	// 基本类型按原类型恢复，避免JSON将整数变为float64
+	if t, ok := snapshotScalarTypes[entry.Type]; ok {
+		target := reflect.New(t)
+		if err := c.snapshotSerializer().Unmarshal(entry.Value, target.Interface()); err != nil {
+			return nil, err
+		}
+		return target.Elem().Interface(), nil
+	}
+	var value interface{}
+	if err := c.snapshotSerializer().Unmarshal(entry.Value, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c *MemoryCache) reportSnapshotError(err error) {
+	if c.options.Snapshot.OnError != nil {
+		c.options.Snapshot.OnError(err)
+	}
+}
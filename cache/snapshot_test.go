@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshotUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMemoryCache_SnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := NewMemoryCache()
+	defer src.Close()
+
+	require.NoError(t, src.Set(ctx, "count", 41, -1))
+	require.NoError(t, src.SetItem(ctx, "user:1", &Item{
+		Value:      snapshotUser{ID: 1, Name: "alice"},
+		Expiration: time.Hour,
+		Tags:       []string{"users"},
+	}))
+	require.NoError(t, src.Set(ctx, "expiring", "soon", 20*time.Millisecond))
+	require.NoError(t, src.Set(ctx, "func", func() {}, time.Hour))
+
+	var buf bytes.Buffer
+	var snapshotErrs []error
+	src.options.Snapshot.OnError = func(err error) { snapshotErrs = append(snapshotErrs, err) }
+	require.NoError(t, src.WriteSnapshot(&buf))
+	require.Len(t, snapshotErrs, 1, "values that cannot be serialized are skipped")
+
+	time.Sleep(30 * time.Millisecond)
+
+	dst := NewMemoryCache()
+	defer dst.Close()
+	restored, err := dst.ReadSnapshot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored, "expired entries are skipped")
+
+	var user snapshotUser
+	found, err := dst.GetInto(ctx, "user:1", &user)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, snapshotUser{ID: 1, Name: "alice"}, user)
+
+	_, ttl, found := dst.GetWithTTL(ctx, "user:1")
+	require.True(t, found)
+	assert.Greater(t, ttl, 59*time.Minute)
+	assert.LessOrEqual(t, ttl, time.Hour, "remaining TTL is preserved")
+
+	n, err := dst.Increment(ctx, "count", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+
+	require.NoError(t, dst.DeleteByTag(ctx, "users"))
+	exists, _ := dst.Exists(ctx, "user:1")
+	assert.False(t, exists, "tags are restored")
+}
+
+func TestMemoryCache_SnapshotKeepsScalarTypes(t *testing.T) {
+	ctx := context.Background()
+	src := NewMemoryCache()
+	defer src.Close()
+
+	values := map[string]interface{}{
+		"int":     7,
+		"int64":   int64(1<<62 + 1),
+		"uint32":  uint32(3),
+		"float64": 1.5,
+		"string":  "text",
+		"bool":    true,
+	}
+	for key, value := range values {
+		require.NoError(t, src.Set(ctx, key, value, time.Hour))
+	}
+	require.NoError(t, src.Set(ctx, "user:1", snapshotUser{ID: 1, Name: "alice"}, time.Hour))
+
+	var buf bytes.Buffer
+	require.NoError(t, src.WriteSnapshot(&buf))
+
+	dst := NewMemoryCache()
+	defer dst.Close()
+	_, err := dst.ReadSnapshot(&buf)
+	require.NoError(t, err)
+
+	for key, want := range values {
+		got, found := dst.Get(ctx, key)
+		require.True(t, found, key)
+		assert.Equal(t, want, got, "scalar values keep their type")
+	}
+	user, found := dst.Get(ctx, "user:1")
+	require.True(t, found)
+	assert.IsType(t, map[string]interface{}{}, user, "structs need GetInto or Decode")
+
+	n, err := dst.Increment(ctx, "int64", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<62+2), n, "large counters are restored without losing precision")
+}
+
+func TestMemoryCache_SnapshotOnCloseAndStartup(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "cache.snapshot")
+	opts := DefaultOptions()
+	opts.Snapshot = SnapshotOptions{
+		Path: path,
+		Decode: func(key string, data []byte) (interface{}, error) {
+			var user snapshotUser
+			err := json.Unmarshal(data, &user)
+			return &user, err
+		},
+	}
+
+	first := NewMemoryCache(opts)
+	require.NoError(t, first.Set(ctx, "user:1", &snapshotUser{ID: 1, Name: "alice"}, time.Hour))
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close(), "Close is idempotent")
+
+	second := NewMemoryCache(opts)
+	defer second.Close()
+	value, found := second.Get(ctx, "user:1")
+	require.True(t, found)
+	assert.Equal(t, &snapshotUser{ID: 1, Name: "alice"}, value, "Decode restores the original type")
+}
+
+func TestMemoryCache_SnapshotInterval(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	opts := DefaultOptions()
+	opts.Snapshot = SnapshotOptions{Path: path, Interval: 10 * time.Millisecond}
+
+	c := NewMemoryCache(opts)
+	defer c.Close()
+	require.NoError(t, c.Set(ctx, "key", "value", time.Hour))
+
+	require.Eventually(t, func() bool {
+		restored := NewMemoryCache()
+		defer restored.Close()
+		n, err := restored.LoadSnapshot(path)
+		return err == nil && n == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemoryCache_SnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	c := NewMemoryCache()
+	defer c.Close()
+	n, err := c.LoadSnapshot(filepath.Join(dir, "missing"))
+	require.NoError(t, err, "a missing snapshot is not an error")
+	assert.Equal(t, 0, n)
+
+	corrupt := filepath.Join(dir, "corrupt")
+	require.NoError(t, os.WriteFile(corrupt, []byte("not a snapshot"), 0o644))
+	_, err = c.LoadSnapshot(corrupt)
+	assert.Error(t, err)
+
+	var reported []error
+	opts := DefaultOptions()
+	opts.Snapshot = SnapshotOptions{Path: corrupt, OnError: func(err error) { reported = append(reported, err) }}
+	withCorrupt := NewMemoryCache(opts)
+	assert.Len(t, reported, 1, "startup load errors are reported")
+	require.NoError(t, withCorrupt.Close())
+	assert.FileExists(t, corrupt)
+
+	restored := NewMemoryCache()
+	defer restored.Close()
+	_, err = restored.LoadSnapshot(corrupt)
+	assert.NoError(t, err, "Close replaces the corrupt snapshot")
+}
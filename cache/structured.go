@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// StructuredCache 支持哈希、列表、集合和有序集合的缓存
+// RedisCache、MemoryCache和ShardedMemoryCache实现了此接口，语义与Redis对应的命令一致：
+//   - 结构化数据与普通缓存值共用键空间，Delete、Exists、DeleteByPattern和Flush对它们同样有效
+//   - 对保存其他类型数据的键执行操作时返回ErrWrongType，对结构化数据的键调用Get视为未命中
+//   - 删除最后一个元素后键被删除；新建的键永不过期，可通过Expire设置过期时间
+//   - 索引从0开始，负数表示从末尾计数，-1为最后一个元素，范围两端都包含在内
+//
+// 因此业务代码可以在单元测试中使用MemoryCache，在生产环境中使用RedisCache。
+// MemoryCache中结构化数据不计入MaxCost，也不会写入快照
+//
+// 示例：
+//
+//	sc, ok := cache.Structured(c)
+//	if !ok {
+//	    return errors.New("cache does not support structured data")
+//	}
+//	sc.ZIncrBy(ctx, "leaderboard", "alice", 10)
+//	top, err := sc.ZRevRange(ctx, "leaderboard", 0, 9)
+type StructuredCache interface {
+	// Expire 设置键的生存时间，ttl小于等于0时移除过期时间
+	// 返回键是否存在
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// HSet 设置哈希字段，返回新增的字段数
+	HSet(ctx context.Context, key string, fields map[string]string) (int64, error)
+	// HGet 获取哈希字段值
+	HGet(ctx context.Context, key, field string) (string, bool, error)
+	// HGetAll 获取所有哈希字段，键不存在时返回空映射
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// HDel 删除哈希字段，返回删除的字段数
+	HDel(ctx context.Context, key string, fields ...string) (int64, error)
+	// HIncrBy 将哈希字段的整数值增加incr，字段不存在时视为0，返回增加后的值
+	HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error)
+	// HLen 返回哈希字段数
+	HLen(ctx context.Context, key string) (int64, error)
+
+	// LPush 将值依次插入列表头部，返回插入后的列表长度
+	LPush(ctx context.Context, key string, values ...string) (int64, error)
+	// RPush 将值依次追加到列表尾部，返回追加后的列表长度
+	RPush(ctx context.Context, key string, values ...string) (int64, error)
+	// LPop 移除并返回列表头部的值
+	LPop(ctx context.Context, key string) (string, bool, error)
+	// RPop 移除并返回列表尾部的值
+	RPop(ctx context.Context, key string) (string, bool, error)
+	// LRange 返回列表中索引在[start, stop]之间的值
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	// LLen 返回列表长度
+	LLen(ctx context.Context, key string) (int64, error)
+	// LTrim 只保留列表中索引在[start, stop]之间的值
+	LTrim(ctx context.Context, key string, start, stop int64) error
+
+	// SAdd 向集合添加成员，返回新增的成员数
+	SAdd(ctx context.Context, key string, members ...string) (int64, error)
+	// SRem 从集合删除成员，返回删除的成员数
+	SRem(ctx context.Context, key string, members ...string) (int64, error)
+	// SIsMember 判断是否为集合成员
+	SIsMember(ctx context.Context, key, member string) (bool, error)
+	// SMembers 返回集合的所有成员，顺序不确定
+	SMembers(ctx context.Context, key string) ([]string, error)
+	// SCard 返回集合的成员数
+	SCard(ctx context.Context, key string) (int64, error)
+
+	// ZAdd 添加有序集合成员或更新分数，返回新增的成员数
+	ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error)
+	// ZIncrBy 将成员的分数增加incr，成员不存在时视为0，返回增加后的分数
+	ZIncrBy(ctx context.Context, key, member string, incr float64) (float64, error)
+	// ZScore 返回成员的分数
+	ZScore(ctx context.Context, key, member string) (float64, bool, error)
+	// ZRem 删除有序集合成员，返回删除的成员数
+	ZRem(ctx context.Context, key string, members ...string) (int64, error)
+	// ZCard 返回有序集合的成员数
+	ZCard(ctx context.Context, key string) (int64, error)
+	// ZCount 返回分数在[min, max]之间的成员数
+	ZCount(ctx context.Context, key string, min, max float64) (int64, error)
+	// ZRank 返回成员按分数升序的排名，从0开始
+	ZRank(ctx context.Context, key, member string) (int64, bool, error)
+	// ZRevRank 返回成员按分数降序的排名，从0开始
+	ZRevRank(ctx context.Context, key, member string) (int64, bool, error)
+	// ZRange 按分数升序返回排名在[start, stop]之间的成员，分数相同时按成员字典序排列
+	ZRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error)
+	// ZRevRange 按分数降序返回排名在[start, stop]之间的成员，分数相同时按成员字典序逆序排列
+	ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error)
+	// ZRangeByScore 按分数升序返回分数在[min, max]之间的成员
+	// 跳过前offset个，count小于等于0时不限制数量；min、max可以使用math.Inf表示无界
+	ZRangeByScore(ctx context.Context, key string, min, max float64, offset, count int64) ([]ZMember, error)
+	// ZRevRangeByScore 按分数降序返回分数在[min, max]之间的成员
+	ZRevRangeByScore(ctx context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error)
+}
+
+// ZMember 有序集合成员
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// Structured 返回缓存的结构化数据接口
+// c本身未实现StructuredCache时，依次查找Unwrap返回的底层缓存，
+// 因此InstrumentedCache包装的MemoryCache或RedisCache同样可用
+func Structured(c Cache) (StructuredCache, bool) {
+	return findCache[StructuredCache](c)
+}
+
+// normalizeRange 将Redis风格的[start, stop]索引转换为切片的[from, to)区间
+// 返回false表示区间为空
+func normalizeRange(start, stop int64, length int) (int, int, bool) {
+	n := int64(length)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return int(start), int(stop) + 1, true
+}
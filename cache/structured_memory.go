@@ -0,0 +1,824 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// memoryStructure MemoryCache中的结构化数据，保存在items中，与普通值共用键空间
+type memoryStructure interface {
+	length() int
+}
+
+// memoryHash 哈希
+type memoryHash struct {
+	fields map[string]string
+}
+
+func newMemoryHash() *memoryHash { return &memoryHash{fields: make(map[string]string)} }
+
+func (h *memoryHash) length() int { return len(h.fields) }
+
+// memoryList 列表
+type memoryList struct {
+	values []string
+}
+
+func newMemoryList() *memoryList { return &memoryList{} }
+
+func (l *memoryList) length() int { return len(l.values) }
+
+// memorySet 集合
+type memorySet struct {
+	members map[string]struct{}
+}
+
+func newMemorySet() *memorySet { return &memorySet{members: make(map[string]struct{})} }
+
+func (s *memorySet) length() int { return len(s.members) }
+
+// memoryZSet 有序集合，sorted按分数升序、分数相同时按成员字典序排列
+type memoryZSet struct {
+	scores map[string]float64
+	sorted []ZMember
+}
+
+func newMemoryZSet() *memoryZSet { return &memoryZSet{scores: make(map[string]float64)} }
+
+func (z *memoryZSet) length() int { return len(z.sorted) }
+
+// search 返回成员在sorted中应处的位置
+func (z *memoryZSet) search(member string, score float64) int {
+	return sort.Search(len(z.sorted), func(i int) bool {
+		m := z.sorted[i]
+		return m.Score > score || (m.Score == score && m.Member >= member)
+	})
+}
+
+// set 设置成员分数，返回是否为新成员
+func (z *memoryZSet) set(member string, score float64) bool {
+	old, exists := z.scores[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		z.remove(member)
+	}
+
+	i := z.search(member, score)
+	z.sorted = append(z.sorted, ZMember{})
+	copy(z.sorted[i+1:], z.sorted[i:])
+	z.sorted[i] = ZMember{Member: member, Score: score}
+	z.scores[member] = score
+	return !exists
+}
+
+// remove 删除成员，返回成员是否存在
+func (z *memoryZSet) remove(member string) bool {
+	score, exists := z.scores[member]
+	if !exists {
+		return false
+	}
+	i := z.search(member, score)
+	z.sorted = append(z.sorted[:i], z.sorted[i+1:]...)
+	delete(z.scores, member)
+	return true
+}
+
+// scoreRange 返回分数在[min, max]之间的成员在sorted中的区间[from, to)
+func (z *memoryZSet) scoreRange(min, max float64) (int, int) {
+	from := sort.Search(len(z.sorted), func(i int) bool { return z.sorted[i].Score >= min })
+	to := sort.Search(len(z.sorted), func(i int) bool { return z.sorted[i].Score > max })
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+// isMemoryStructure 判断缓存值是否为结构化数据
+func isMemoryStructure(value interface{}) bool {
+	_, ok := value.(memoryStructure)
+	return ok
+}
+
+// lookupStructureUnsafe 查找指定类型的结构化数据（调用方需持有读锁或写锁）
+// 键不存在或已过期时返回false，键保存其他类型的数据时返回ErrWrongType
+func lookupStructureUnsafe[T memoryStructure](c *MemoryCache, key string) (T, bool, error) {
+	var zero T
+	item, found := c.items[key]
+	if !found || (item.Expiration > 0 && time.Now().UnixNano() > item.Expiration) {
+		return zero, false, nil
+	}
+	value, ok := item.Value.(T)
+	if !ok {
+		return zero, false, ErrWrongType
+	}
+	c.recordAccess(key)
+	return value, true, nil
+}
+
+// createStructureUnsafe 查找指定类型的结构化数据，不存在时创建（调用方需持有写锁）
+func createStructureUnsafe[T memoryStructure](c *MemoryCache, key string, create func() T) (T, []evictedEntry, error) {
+	value, found, err := lookupStructureUnsafe[T](c, key)
+	if err != nil || found {
+		return value, nil, err
+	}
+	value = create()
+	evicted := c.storeUnsafe(key, &memoryItem{Value: value, Tags: []string{}})
+	return value, evicted, nil
+}
+
+// removeIfEmptyUnsafe 结构化数据为空时删除键，与Redis一致（调用方需持有写锁）
+func (c *MemoryCache) removeIfEmptyUnsafe(key string, value memoryStructure) {
+	if value.length() > 0 {
+		return
+	}
+	if item, found := c.items[key]; found && item.Value == value {
+		c.removeUnsafe(key, item)
+	}
+}
+
+// writeStructure 在写锁中执行fn，释放锁后调用淘汰回调
+func (c *MemoryCache) writeStructure(fn func() []evictedEntry) {
+	c.mu.Lock()
+	evicted := fn()
+	c.mu.Unlock()
+	c.notifyEvicted(evicted, EvictionReasonCapacity)
+}
+
+// Expire 设置键的生存时间，ttl小于等于0时移除过期时间，实现StructuredCache接口
+func (c *MemoryCache) Expire(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.items[key]
+	if !found || (item.Expiration > 0 && time.Now().UnixNano() > item.Expiration) {
+		return false, nil
+	}
+	if ttl > 0 {
+		item.Expiration = time.Now().Add(ttl).UnixNano()
+	} else {
+		item.Expiration = 0
+	}
+	return true, nil
+}
+
+// HSet 设置哈希字段，返回新增的字段数
+func (c *MemoryCache) HSet(_ context.Context, key string, fields map[string]string) (added int64, err error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var h *memoryHash
+		if h, evicted, err = createStructureUnsafe(c, key, newMemoryHash); err != nil {
+			return evicted
+		}
+		for field, value := range fields {
+			if _, exists := h.fields[field]; !exists {
+				added++
+			}
+			h.fields[field] = value
+		}
+		return evicted
+	})
+	return added, err
+}
+
+// HGet 获取哈希字段值
+func (c *MemoryCache) HGet(_ context.Context, key, field string) (string, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h, found, err := lookupStructureUnsafe[*memoryHash](c, key)
+	if err != nil || !found {
+		return "", false, err
+	}
+	value, exists := h.fields[field]
+	return value, exists, nil
+}
+
+// HGetAll 获取所有哈希字段
+func (c *MemoryCache) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h, found, err := lookupStructureUnsafe[*memoryHash](c, key)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	if found {
+		for field, value := range h.fields {
+			result[field] = value
+		}
+	}
+	return result, nil
+}
+
+// HDel 删除哈希字段，返回删除的字段数
+func (c *MemoryCache) HDel(_ context.Context, key string, fields ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, found, err := lookupStructureUnsafe[*memoryHash](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	var removed int64
+	for _, field := range fields {
+		if _, exists := h.fields[field]; exists {
+			delete(h.fields, field)
+			removed++
+		}
+	}
+	c.removeIfEmptyUnsafe(key, h)
+	return removed, nil
+}
+
+// HIncrBy 将哈希字段的整数值增加incr
+func (c *MemoryCache) HIncrBy(_ context.Context, key, field string, incr int64) (result int64, err error) {
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var h *memoryHash
+		if h, evicted, err = createStructureUnsafe(c, key, newMemoryHash); err != nil {
+			return evicted
+		}
+		var current int64
+		if value, exists := h.fields[field]; exists {
+			if current, err = strconv.ParseInt(value, 10, 64); err != nil {
+				err = fmt.Errorf("cache: hash value is not an integer: %q", value)
+				return evicted
+			}
+		}
+		result = current + incr
+		h.fields[field] = strconv.FormatInt(result, 10)
+		return evicted
+	})
+	return result, err
+}
+
+// HLen 返回哈希字段数
+func (c *MemoryCache) HLen(_ context.Context, key string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h, found, err := lookupStructureUnsafe[*memoryHash](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return int64(len(h.fields)), nil
+}
+
+// LPush 将值依次插入列表头部，返回插入后的列表长度
+func (c *MemoryCache) LPush(_ context.Context, key string, values ...string) (int64, error) {
+	return c.push(key, values, true)
+}
+
+// RPush 将值依次追加到列表尾部，返回追加后的列表长度
+func (c *MemoryCache) RPush(_ context.Context, key string, values ...string) (int64, error) {
+	return c.push(key, values, false)
+}
+
+func (c *MemoryCache) push(key string, values []string, head bool) (length int64, err error) {
+	if len(values) == 0 {
+		return c.LLen(context.Background(), key)
+	}
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var l *memoryList
+		if l, evicted, err = createStructureUnsafe(c, key, newMemoryList); err != nil {
+			return evicted
+		}
+		if head {
+			// LPUSH a b c 的结果为 c b a
+			prefix := make([]string, len(values), len(values)+len(l.values))
+			for i, value := range values {
+				prefix[len(values)-1-i] = value
+			}
+			l.values = append(prefix, l.values...)
+		} else {
+			l.values = append(l.values, values...)
+		}
+		length = int64(len(l.values))
+		return evicted
+	})
+	return length, err
+}
+
+// LPop 移除并返回列表头部的值
+func (c *MemoryCache) LPop(_ context.Context, key string) (string, bool, error) {
+	return c.pop(key, true)
+}
+
+// RPop 移除并返回列表尾部的值
+func (c *MemoryCache) RPop(_ context.Context, key string) (string, bool, error) {
+	return c.pop(key, false)
+}
+
+func (c *MemoryCache) pop(key string, head bool) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, found, err := lookupStructureUnsafe[*memoryList](c, key)
+	if err != nil || !found {
+		return "", false, err
+	}
+	var value string
+	if head {
+		value = l.values[0]
+		l.values = l.values[1:]
+	} else {
+		value = l.values[len(l.values)-1]
+		l.values = l.values[:len(l.values)-1]
+	}
+	c.removeIfEmptyUnsafe(key, l)
+	return value, true, nil
+}
+
+// LRange 返回列表中索引在[start, stop]之间的值
+func (c *MemoryCache) LRange(_ context.Context, key string, start, stop int64) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	l, found, err := lookupStructureUnsafe[*memoryList](c, key)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	if !found {
+		return result, nil
+	}
+	if from, to, ok := normalizeRange(start, stop, len(l.values)); ok {
+		result = append(result, l.values[from:to]...)
+	}
+	return result, nil
+}
+
+// LLen 返回列表长度
+func (c *MemoryCache) LLen(_ context.Context, key string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	l, found, err := lookupStructureUnsafe[*memoryList](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return int64(len(l.values)), nil
+}
+
+// LTrim 只保留列表中索引在[start, stop]之间的值
+func (c *MemoryCache) LTrim(_ context.Context, key string, start, stop int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, found, err := lookupStructureUnsafe[*memoryList](c, key)
+	if err != nil || !found {
+		return err
+	}
+	if from, to, ok := normalizeRange(start, stop, len(l.values)); ok {
+		l.values = append([]string(nil), l.values[from:to]...)
+	} else {
+		l.values = nil
+	}
+	c.removeIfEmptyUnsafe(key, l)
+	return nil
+}
+
+// SAdd 向集合添加成员，返回新增的成员数
+func (c *MemoryCache) SAdd(_ context.Context, key string, members ...string) (added int64, err error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var s *memorySet
+		if s, evicted, err = createStructureUnsafe(c, key, newMemorySet); err != nil {
+			return evicted
+		}
+		for _, member := range members {
+			if _, exists := s.members[member]; !exists {
+				s.members[member] = struct{}{}
+				added++
+			}
+		}
+		return evicted
+	})
+	return added, err
+}
+
+// SRem 从集合删除成员，返回删除的成员数
+func (c *MemoryCache) SRem(_ context.Context, key string, members ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, found, err := lookupStructureUnsafe[*memorySet](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	var removed int64
+	for _, member := range members {
+		if _, exists := s.members[member]; exists {
+			delete(s.members, member)
+			removed++
+		}
+	}
+	c.removeIfEmptyUnsafe(key, s)
+	return removed, nil
+}
+
+// SIsMember 判断是否为集合成员
+func (c *MemoryCache) SIsMember(_ context.Context, key, member string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, found, err := lookupStructureUnsafe[*memorySet](c, key)
+	if err != nil || !found {
+		return false, err
+	}
+	_, exists := s.members[member]
+	return exists, nil
+}
+
+// SMembers 返回集合的所有成员，按字典序排列
+func (c *MemoryCache) SMembers(_ context.Context, key string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, found, err := lookupStructureUnsafe[*memorySet](c, key)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	if found {
+		for member := range s.members {
+			result = append(result, member)
+		}
+		sort.Strings(result)
+	}
+	return result, nil
+}
+
+// SCard 返回集合的成员数
+func (c *MemoryCache) SCard(_ context.Context, key string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s, found, err := lookupStructureUnsafe[*memorySet](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return int64(len(s.members)), nil
+}
+
+// ZAdd 添加有序集合成员或更新分数，返回新增的成员数
+func (c *MemoryCache) ZAdd(_ context.Context, key string, members ...ZMember) (added int64, err error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var z *memoryZSet
+		if z, evicted, err = createStructureUnsafe(c, key, newMemoryZSet); err != nil {
+			return evicted
+		}
+		for _, m := range members {
+			if z.set(m.Member, m.Score) {
+				added++
+			}
+		}
+		return evicted
+	})
+	return added, err
+}
+
+// ZIncrBy 将成员的分数增加incr，返回增加后的分数
+func (c *MemoryCache) ZIncrBy(_ context.Context, key, member string, incr float64) (score float64, err error) {
+	c.writeStructure(func() (evicted []evictedEntry) {
+		var z *memoryZSet
+		if z, evicted, err = createStructureUnsafe(c, key, newMemoryZSet); err != nil {
+			return evicted
+		}
+		score = z.scores[member] + incr
+		z.set(member, score)
+		return evicted
+	})
+	return score, err
+}
+
+// ZScore 返回成员的分数
+func (c *MemoryCache) ZScore(_ context.Context, key, member string) (float64, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil || !found {
+		return 0, false, err
+	}
+	score, exists := z.scores[member]
+	return score, exists, nil
+}
+
+// ZRem 删除有序集合成员，返回删除的成员数
+func (c *MemoryCache) ZRem(_ context.Context, key string, members ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	var removed int64
+	for _, member := range members {
+		if z.remove(member) {
+			removed++
+		}
+	}
+	c.removeIfEmptyUnsafe(key, z)
+	return removed, nil
+}
+
+// ZCard 返回有序集合的成员数
+func (c *MemoryCache) ZCard(_ context.Context, key string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return int64(len(z.sorted)), nil
+}
+
+// ZCount 返回分数在[min, max]之间的成员数
+func (c *MemoryCache) ZCount(_ context.Context, key string, min, max float64) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	from, to := z.scoreRange(min, max)
+	return int64(to - from), nil
+}
+
+// ZRank 返回成员按分数升序的排名
+func (c *MemoryCache) ZRank(_ context.Context, key, member string) (int64, bool, error) {
+	return c.zrank(key, member, false)
+}
+
+// ZRevRank 返回成员按分数降序的排名
+func (c *MemoryCache) ZRevRank(_ context.Context, key, member string) (int64, bool, error) {
+	return c.zrank(key, member, true)
+}
+
+func (c *MemoryCache) zrank(key, member string, reverse bool) (int64, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil || !found {
+		return 0, false, err
+	}
+	score, exists := z.scores[member]
+	if !exists {
+		return 0, false, nil
+	}
+	rank := z.search(member, score)
+	if reverse {
+		rank = len(z.sorted) - 1 - rank
+	}
+	return int64(rank), true, nil
+}
+
+// ZRange 按分数升序返回排名在[start, stop]之间的成员
+func (c *MemoryCache) ZRange(_ context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return c.zrange(key, start, stop, false)
+}
+
+// ZRevRange 按分数降序返回排名在[start, stop]之间的成员
+func (c *MemoryCache) ZRevRange(_ context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return c.zrange(key, start, stop, true)
+}
+
+func (c *MemoryCache) zrange(key string, start, stop int64, reverse bool) ([]ZMember, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil {
+		return nil, err
+	}
+	result := []ZMember{}
+	if !found {
+		return result, nil
+	}
+	from, to, ok := normalizeRange(start, stop, len(z.sorted))
+	if !ok {
+		return result, nil
+	}
+	if !reverse {
+		return append(result, z.sorted[from:to]...), nil
+	}
+	n := len(z.sorted)
+	for i := from; i < to; i++ {
+		result = append(result, z.sorted[n-1-i])
+	}
+	return result, nil
+}
+
+// ZRangeByScore 按分数升序返回分数在[min, max]之间的成员
+func (c *MemoryCache) ZRangeByScore(_ context.Context, key string, min, max float64, offset, count int64) ([]ZMember, error) {
+	return c.zrangeByScore(key, min, max, offset, count, false)
+}
+
+// ZRevRangeByScore 按分数降序返回分数在[min, max]之间的成员
+func (c *MemoryCache) ZRevRangeByScore(_ context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error) {
+	return c.zrangeByScore(key, min, max, offset, count, true)
+}
+
+func (c *MemoryCache) zrangeByScore(key string, min, max float64, offset, count int64, reverse bool) ([]ZMember, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	z, found, err := lookupStructureUnsafe[*memoryZSet](c, key)
+	if err != nil {
+		return nil, err
+	}
+	result := []ZMember{}
+	if !found || offset < 0 {
+		return result, nil
+	}
+
+	from, to := z.scoreRange(min, max)
+	for i := int64(0); i < int64(to-from); i++ {
+		if i < offset {
+			continue
+		}
+		if count > 0 && int64(len(result)) >= count {
+			break
+		}
+		if reverse {
+			result = append(result, z.sorted[to-1-int(i)])
+		} else {
+			result = append(result, z.sorted[from+int(i)])
+		}
+	}
+	return result, nil
+}
+
+// ShardedMemoryCache的结构化数据操作委托给键所在的分片
+
+// Expire 设置键的生存时间
+func (c *ShardedMemoryCache) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.shard(key).Expire(ctx, key, ttl)
+}
+
+// HSet 设置哈希字段
+func (c *ShardedMemoryCache) HSet(ctx context.Context, key string, fields map[string]string) (int64, error) {
+	return c.shard(key).HSet(ctx, key, fields)
+}
+
+// HGet 获取哈希字段值
+func (c *ShardedMemoryCache) HGet(ctx context.Context, key, field string) (string, bool, error) {
+	return c.shard(key).HGet(ctx, key, field)
+}
+
+// HGetAll 获取所有哈希字段
+func (c *ShardedMemoryCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.shard(key).HGetAll(ctx, key)
+}
+
+// HDel 删除哈希字段
+func (c *ShardedMemoryCache) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	return c.shard(key).HDel(ctx, key, fields...)
+}
+
+// HIncrBy 增加哈希字段的整数值
+func (c *ShardedMemoryCache) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return c.shard(key).HIncrBy(ctx, key, field, incr)
+}
+
+// HLen 返回哈希字段数
+func (c *ShardedMemoryCache) HLen(ctx context.Context, key string) (int64, error) {
+	return c.shard(key).HLen(ctx, key)
+}
+
+// LPush 将值插入列表头部
+func (c *ShardedMemoryCache) LPush(ctx context.Context, key string, values ...string) (int64, error) {
+	return c.shard(key).LPush(ctx, key, values...)
+}
+
+// RPush 将值追加到列表尾部
+func (c *ShardedMemoryCache) RPush(ctx context.Context, key string, values ...string) (int64, error) {
+	return c.shard(key).RPush(ctx, key, values...)
+}
+
+// LPop 移除并返回列表头部的值
+func (c *ShardedMemoryCache) LPop(ctx context.Context, key string) (string, bool, error) {
+	return c.shard(key).LPop(ctx, key)
+}
+
+// RPop 移除并返回列表尾部的值
+func (c *ShardedMemoryCache) RPop(ctx context.Context, key string) (string, bool, error) {
+	return c.shard(key).RPop(ctx, key)
+}
+
+// LRange 返回列表中指定范围的值
+func (c *ShardedMemoryCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.shard(key).LRange(ctx, key, start, stop)
+}
+
+// LLen 返回列表长度
+func (c *ShardedMemoryCache) LLen(ctx context.Context, key string) (int64, error) {
+	return c.shard(key).LLen(ctx, key)
+}
+
+// LTrim 只保留列表中指定范围的值
+func (c *ShardedMemoryCache) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return c.shard(key).LTrim(ctx, key, start, stop)
+}
+
+// SAdd 向集合添加成员
+func (c *ShardedMemoryCache) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.shard(key).SAdd(ctx, key, members...)
+}
+
+// SRem 从集合删除成员
+func (c *ShardedMemoryCache) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.shard(key).SRem(ctx, key, members...)
+}
+
+// SIsMember 判断是否为集合成员
+func (c *ShardedMemoryCache) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	return c.shard(key).SIsMember(ctx, key, member)
+}
+
+// SMembers 返回集合的所有成员
+func (c *ShardedMemoryCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.shard(key).SMembers(ctx, key)
+}
+
+// SCard 返回集合的成员数
+func (c *ShardedMemoryCache) SCard(ctx context.Context, key string) (int64, error) {
+	return c.shard(key).SCard(ctx, key)
+}
+
+// ZAdd 添加有序集合成员或更新分数
+func (c *ShardedMemoryCache) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	return c.shard(key).ZAdd(ctx, key, members...)
+}
+
+// ZIncrBy 增加成员的分数
+func (c *ShardedMemoryCache) ZIncrBy(ctx context.Context, key, member string, incr float64) (float64, error) {
+	return c.shard(key).ZIncrBy(ctx, key, member, incr)
+}
+
+// ZScore 返回成员的分数
+func (c *ShardedMemoryCache) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	return c.shard(key).ZScore(ctx, key, member)
+}
+
+// ZRem 删除有序集合成员
+func (c *ShardedMemoryCache) ZRem(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.shard(key).ZRem(ctx, key, members...)
+}
+
+// ZCard 返回有序集合的成员数
+func (c *ShardedMemoryCache) ZCard(ctx context.Context, key string) (int64, error) {
+	return c.shard(key).ZCard(ctx, key)
+}
+
+// ZCount 返回分数在指定区间的成员数
+func (c *ShardedMemoryCache) ZCount(ctx context.Context, key string, min, max float64) (int64, error) {
+	return c.shard(key).ZCount(ctx, key, min, max)
+}
+
+// ZRank 返回成员按分数升序的排名
+func (c *ShardedMemoryCache) ZRank(ctx context.Context, key, member string) (int64, bool, error) {
+	return c.shard(key).ZRank(ctx, key, member)
+}
+
+// ZRevRank 返回成员按分数降序的排名
+func (c *ShardedMemoryCache) ZRevRank(ctx context.Context, key, member string) (int64, bool, error) {
+	return c.shard(key).ZRevRank(ctx, key, member)
+}
+
+// ZRange 按分数升序返回指定排名范围的成员
+func (c *ShardedMemoryCache) ZRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return c.shard(key).ZRange(ctx, key, start, stop)
+}
+
+// ZRevRange 按分数降序返回指定排名范围的成员
+func (c *ShardedMemoryCache) ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return c.shard(key).ZRevRange(ctx, key, start, stop)
+}
+
+// ZRangeByScore 按分数升序返回指定分数区间的成员
+func (c *ShardedMemoryCache) ZRangeByScore(ctx context.Context, key string, min, max float64, offset, count int64) ([]ZMember, error) {
+	return c.shard(key).ZRangeByScore(ctx, key, min, max, offset, count)
+}
+
+// ZRevRangeByScore 按分数降序返回指定分数区间的成员
+func (c *ShardedMemoryCache) ZRevRangeByScore(ctx context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error) {
+	return c.shard(key).ZRevRangeByScore(ctx, key, max, min, offset, count)
+}
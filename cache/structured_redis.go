@@ -0,0 +1,337 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// structuredError 将Redis的WRONGTYPE错误转换为ErrWrongType
+// Lua脚本中redis.call返回的WRONGTYPE错误在部分Redis版本中带有脚本错误前缀，因此按包含判断
+func structuredError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "WRONGTYPE") {
+		return fmt.Errorf("%w: %v", ErrWrongType, err)
+	}
+	return err
+}
+
+// formatScore 将分数转换为Redis的分数参数，支持正负无穷
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(score, 'g', -1, 64)
+	}
+}
+
+// toZMembers 转换go-redis的有序集合成员
+func toZMembers(zs []redis.Z) []ZMember {
+	members := make([]ZMember, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		members[i] = ZMember{Member: member, Score: z.Score}
+	}
+	return members
+}
+
+// Expire 设置键的生存时间，ttl小于等于0时移除过期时间，实现StructuredCache接口
+func (c *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	prefixedKey := c.prefixKey(key)
+	if ttl > 0 {
+		return c.client.Expire(ctx, prefixedKey, ttl).Result()
+	}
+
+	// PERSIST对没有过期时间的键也返回false，需要单独判断键是否存在
+	pipe := c.client.Pipeline()
+	pipe.Persist(ctx, prefixedKey)
+	existsCmd := pipe.Exists(ctx, prefixedKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return existsCmd.Val() > 0, nil
+}
+
+// HSet 设置哈希字段，返回新增的字段数
+func (c *RedisCache) HSet(ctx context.Context, key string, fields map[string]string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	values := make([]interface{}, 0, len(fields)*2)
+	for field, value := range fields {
+		values = append(values, field, value)
+	}
+	n, err := c.client.HSet(ctx, c.prefixKey(key), values...).Result()
+	return n, structuredError(err)
+}
+
+// HGet 获取哈希字段值
+func (c *RedisCache) HGet(ctx context.Context, key, field string) (string, bool, error) {
+	value, err := c.client.HGet(ctx, c.prefixKey(key), field).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, structuredError(err)
+	}
+	return value, true, nil
+}
+
+// HGetAll 获取所有哈希字段
+func (c *RedisCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	values, err := c.client.HGetAll(ctx, c.prefixKey(key)).Result()
+	return values, structuredError(err)
+}
+
+// HDel 删除哈希字段，返回删除的字段数
+func (c *RedisCache) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	n, err := c.client.HDel(ctx, c.prefixKey(key), fields...).Result()
+	return n, structuredError(err)
+}
+
+// HIncrBy 将哈希字段的整数值增加incr
+func (c *RedisCache) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	n, err := c.client.HIncrBy(ctx, c.prefixKey(key), field, incr).Result()
+	return n, structuredError(err)
+}
+
+// HLen 返回哈希字段数
+func (c *RedisCache) HLen(ctx context.Context, key string) (int64, error) {
+	n, err := c.client.HLen(ctx, c.prefixKey(key)).Result()
+	return n, structuredError(err)
+}
+
+// LPush 将值依次插入列表头部，返回插入后的列表长度
+func (c *RedisCache) LPush(ctx context.Context, key string, values ...string) (int64, error) {
+	if len(values) == 0 {
+		return c.LLen(ctx, key)
+	}
+	n, err := c.client.LPush(ctx, c.prefixKey(key), stringArgs(values)...).Result()
+	return n, structuredError(err)
+}
+
+// RPush 将值依次追加到列表尾部，返回追加后的列表长度
+func (c *RedisCache) RPush(ctx context.Context, key string, values ...string) (int64, error) {
+	if len(values) == 0 {
+		return c.LLen(ctx, key)
+	}
+	n, err := c.client.RPush(ctx, c.prefixKey(key), stringArgs(values)...).Result()
+	return n, structuredError(err)
+}
+
+// LPop 移除并返回列表头部的值
+func (c *RedisCache) LPop(ctx context.Context, key string) (string, bool, error) {
+	return popResult(c.client.LPop(ctx, c.prefixKey(key)).Result())
+}
+
+// RPop 移除并返回列表尾部的值
+func (c *RedisCache) RPop(ctx context.Context, key string) (string, bool, error) {
+	return popResult(c.client.RPop(ctx, c.prefixKey(key)).Result())
+}
+
+func popResult(value string, err error) (string, bool, error) {
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, structuredError(err)
+	}
+	return value, true, nil
+}
+
+// LRange 返回列表中索引在[start, stop]之间的值
+func (c *RedisCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	values, err := c.client.LRange(ctx, c.prefixKey(key), start, stop).Result()
+	return values, structuredError(err)
+}
+
+// LLen 返回列表长度
+func (c *RedisCache) LLen(ctx context.Context, key string) (int64, error) {
+	n, err := c.client.LLen(ctx, c.prefixKey(key)).Result()
+	return n, structuredError(err)
+}
+
+// LTrim 只保留列表中索引在[start, stop]之间的值
+func (c *RedisCache) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return structuredError(c.client.LTrim(ctx, c.prefixKey(key), start, stop).Err())
+}
+
+// SAdd 向集合添加成员，返回新增的成员数
+func (c *RedisCache) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	n, err := c.client.SAdd(ctx, c.prefixKey(key), stringArgs(members)...).Result()
+	return n, structuredError(err)
+}
+
+// SRem 从集合删除成员，返回删除的成员数
+func (c *RedisCache) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	n, err := c.client.SRem(ctx, c.prefixKey(key), stringArgs(members)...).Result()
+	return n, structuredError(err)
+}
+
+// SIsMember 判断是否为集合成员
+func (c *RedisCache) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	ok, err := c.client.SIsMember(ctx, c.prefixKey(key), member).Result()
+	return ok, structuredError(err)
+}
+
+// SMembers 返回集合的所有成员，顺序不确定
+func (c *RedisCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	members, err := c.client.SMembers(ctx, c.prefixKey(key)).Result()
+	return members, structuredError(err)
+}
+
+// SCard 返回集合的成员数
+func (c *RedisCache) SCard(ctx context.Context, key string) (int64, error) {
+	n, err := c.client.SCard(ctx, c.prefixKey(key)).Result()
+	return n, structuredError(err)
+}
+
+// ZAdd 添加有序集合成员或更新分数，返回新增的成员数
+func (c *RedisCache) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	zs := make([]redis.Z, len(members))
+	for i, m := range members {
+		zs[i] = redis.Z{Score: m.Score, Member: m.Member}
+	}
+	n, err := c.client.ZAdd(ctx, c.prefixKey(key), zs...).Result()
+	return n, structuredError(err)
+}
+
+// ZIncrBy 将成员的分数增加incr，返回增加后的分数
+func (c *RedisCache) ZIncrBy(ctx context.Context, key, member string, incr float64) (float64, error) {
+	score, err := c.client.ZIncrBy(ctx, c.prefixKey(key), incr, member).Result()
+	return score, structuredError(err)
+}
+
+// ZScore 返回成员的分数
+func (c *RedisCache) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	score, err := c.client.ZScore(ctx, c.prefixKey(key), member).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, structuredError(err)
+	}
+	return score, true, nil
+}
+
+// ZRem 删除有序集合成员，返回删除的成员数
+func (c *RedisCache) ZRem(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	n, err := c.client.ZRem(ctx, c.prefixKey(key), stringArgs(members)...).Result()
+	return n, structuredError(err)
+}
+
+// ZCard 返回有序集合的成员数
+func (c *RedisCache) ZCard(ctx context.Context, key string) (int64, error) {
+	n, err := c.client.ZCard(ctx, c.prefixKey(key)).Result()
+	return n, structuredError(err)
+}
+
+// ZCount 返回分数在[min, max]之间的成员数
+func (c *RedisCache) ZCount(ctx context.Context, key string, min, max float64) (int64, error) {
+	n, err := c.client.ZCount(ctx, c.prefixKey(key), formatScore(min), formatScore(max)).Result()
+	return n, structuredError(err)
+}
+
+// ZRank 返回成员按分数升序的排名
+func (c *RedisCache) ZRank(ctx context.Context, key, member string) (int64, bool, error) {
+	return rankResult(c.client.ZRank(ctx, c.prefixKey(key), member).Result())
+}
+
+// ZRevRank 返回成员按分数降序的排名
+func (c *RedisCache) ZRevRank(ctx context.Context, key, member string) (int64, bool, error) {
+	return rankResult(c.client.ZRevRank(ctx, c.prefixKey(key), member).Result())
+}
+
+func rankResult(rank int64, err error) (int64, bool, error) {
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, structuredError(err)
+	}
+	return rank, true, nil
+}
+
+// ZRange 按分数升序返回排名在[start, stop]之间的成员
+func (c *RedisCache) ZRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	zs, err := c.client.ZRangeWithScores(ctx, c.prefixKey(key), start, stop).Result()
+	if err != nil {
+		return nil, structuredError(err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRevRange 按分数降序返回排名在[start, stop]之间的成员
+func (c *RedisCache) ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	zs, err := c.client.ZRevRangeWithScores(ctx, c.prefixKey(key), start, stop).Result()
+	if err != nil {
+		return nil, structuredError(err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRangeByScore 按分数升序返回分数在[min, max]之间的成员
+func (c *RedisCache) ZRangeByScore(ctx context.Context, key string, min, max float64, offset, count int64) ([]ZMember, error) {
+	if offset < 0 {
+		return []ZMember{}, nil
+	}
+	zs, err := c.client.ZRangeByScoreWithScores(ctx, c.prefixKey(key), scoreRangeBy(min, max, offset, count)).Result()
+	if err != nil {
+		return nil, structuredError(err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRevRangeByScore 按分数降序返回分数在[min, max]之间的成员
+func (c *RedisCache) ZRevRangeByScore(ctx context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error) {
+	if offset < 0 {
+		return []ZMember{}, nil
+	}
+	zs, err := c.client.ZRevRangeByScoreWithScores(ctx, c.prefixKey(key), scoreRangeBy(min, max, offset, count)).Result()
+	if err != nil {
+		return nil, structuredError(err)
+	}
+	return toZMembers(zs), nil
+}
+
+// scoreRangeBy 构造按分数查询的参数，count小于等于0时不限制数量
+func scoreRangeBy(min, max float64, offset, count int64) *redis.ZRangeBy {
+	by := &redis.ZRangeBy{Min: formatScore(min), Max: formatScore(max), Offset: offset, Count: count}
+	if count <= 0 {
+		by.Count = 0
+		if offset > 0 {
+			by.Count = -1
+		}
+	}
+	return by
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
@@ -0,0 +1,352 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// structuredTestCaches 返回用于一致性测试的结构化缓存实现
+func structuredTestCaches(t *testing.T) map[string]interface {
+	Cache
+	StructuredCache
+} {
+	memory := NewMemoryCache()
+	t.Cleanup(func() { _ = memory.Close() })
+	sharded := NewShardedMemoryCache(4)
+	t.Cleanup(func() { _ = sharded.Close() })
+
+	return map[string]interface {
+		Cache
+		StructuredCache
+	}{
+		"memory":         memory,
+		"sharded_memory": sharded,
+		"redis":          newMiniRedisCache(t),
+	}
+}
+
+func TestStructuredCache_Hash(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			n, err := c.HSet(ctx, "user:1", map[string]string{"name": "alice", "age": "30"})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			n, err = c.HSet(ctx, "user:1", map[string]string{"name": "bob", "city": "paris"})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), n, "only new fields are counted")
+
+			value, found, err := c.HGet(ctx, "user:1", "name")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "bob", value)
+			_, found, err = c.HGet(ctx, "user:1", "missing")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			count, err := c.HIncrBy(ctx, "user:1", "visits", 5)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), count)
+			_, err = c.HIncrBy(ctx, "user:1", "name", 1)
+			assert.Error(t, err, "non-integer fields cannot be incremented")
+
+			all, err := c.HGetAll(ctx, "user:1")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"name": "bob", "age": "30", "city": "paris", "visits": "5"}, all)
+
+			length, err := c.HLen(ctx, "user:1")
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), length)
+
+			n, err = c.HDel(ctx, "user:1", "name", "age", "city", "visits", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), n)
+			exists, err := c.Exists(ctx, "user:1")
+			require.NoError(t, err)
+			assert.False(t, exists, "the key is removed with its last field")
+
+			all, err = c.HGetAll(ctx, "user:1")
+			require.NoError(t, err)
+			assert.NotNil(t, all)
+			assert.Empty(t, all)
+		})
+	}
+}
+
+func TestStructuredCache_List(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			n, err := c.RPush(ctx, "queue", "b", "c")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			n, err = c.LPush(ctx, "queue", "a", "z")
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), n)
+
+			values, err := c.LRange(ctx, "queue", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"z", "a", "b", "c"}, values)
+			values, err = c.LRange(ctx, "queue", -2, 10)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b", "c"}, values)
+			values, err = c.LRange(ctx, "queue", 3, 1)
+			require.NoError(t, err)
+			assert.Empty(t, values)
+
+			value, found, err := c.LPop(ctx, "queue")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "z", value)
+			value, found, err = c.RPop(ctx, "queue")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "c", value)
+
+			require.NoError(t, c.LTrim(ctx, "queue", 1, -1))
+			values, err = c.LRange(ctx, "queue", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b"}, values)
+
+			require.NoError(t, c.LTrim(ctx, "queue", 5, 10))
+			length, err := c.LLen(ctx, "queue")
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), length)
+			exists, err := c.Exists(ctx, "queue")
+			require.NoError(t, err)
+			assert.False(t, exists, "trimming every element removes the key")
+
+			_, found, err = c.LPop(ctx, "queue")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestStructuredCache_Set(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			n, err := c.SAdd(ctx, "tags", "go", "redis", "go")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+
+			ok, err := c.SIsMember(ctx, "tags", "go")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = c.SIsMember(ctx, "tags", "java")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			members, err := c.SMembers(ctx, "tags")
+			require.NoError(t, err)
+			sort.Strings(members)
+			assert.Equal(t, []string{"go", "redis"}, members)
+
+			card, err := c.SCard(ctx, "tags")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), card)
+
+			n, err = c.SRem(ctx, "tags", "go", "redis", "java")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			members, err = c.SMembers(ctx, "tags")
+			require.NoError(t, err)
+			assert.Empty(t, members)
+		})
+	}
+}
+
+func TestStructuredCache_SortedSet(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			n, err := c.ZAdd(ctx, "board",
+				ZMember{Member: "carol", Score: 20},
+				ZMember{Member: "alice", Score: 10},
+				ZMember{Member: "bob", Score: 10},
+				ZMember{Member: "dave", Score: 5},
+			)
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), n)
+			n, err = c.ZAdd(ctx, "board", ZMember{Member: "dave", Score: 30})
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), n, "updating a score does not add a member")
+
+			score, err := c.ZIncrBy(ctx, "board", "alice", 2.5)
+			require.NoError(t, err)
+			assert.Equal(t, 12.5, score)
+			score, err = c.ZIncrBy(ctx, "board", "erin", 10)
+			require.NoError(t, err)
+			assert.Equal(t, float64(10), score)
+
+			score, found, err := c.ZScore(ctx, "board", "carol")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, float64(20), score)
+			_, found, err = c.ZScore(ctx, "board", "missing")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			members, err := c.ZRange(ctx, "board", 0, -1)
+			require.NoError(t, err)
+			assert.Equal(t, []ZMember{
+				{Member: "bob", Score: 10},
+				{Member: "erin", Score: 10},
+				{Member: "alice", Score: 12.5},
+				{Member: "carol", Score: 20},
+				{Member: "dave", Score: 30},
+			}, members, "equal scores are ordered by member")
+
+			members, err = c.ZRevRange(ctx, "board", 0, 2)
+			require.NoError(t, err)
+			assert.Equal(t, []ZMember{
+				{Member: "dave", Score: 30},
+				{Member: "carol", Score: 20},
+				{Member: "alice", Score: 12.5},
+			}, members)
+
+			rank, found, err := c.ZRank(ctx, "board", "erin")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, int64(1), rank)
+			rank, found, err = c.ZRevRank(ctx, "board", "erin")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, int64(3), rank)
+			_, found, err = c.ZRank(ctx, "board", "missing")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			count, err := c.ZCount(ctx, "board", 10, 20)
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), count)
+			count, err = c.ZCount(ctx, "board", math.Inf(-1), math.Inf(1))
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), count)
+
+			members, err = c.ZRangeByScore(ctx, "board", 10, 20, 1, 2)
+			require.NoError(t, err)
+			assert.Equal(t, []ZMember{{Member: "erin", Score: 10}, {Member: "alice", Score: 12.5}}, members)
+			members, err = c.ZRangeByScore(ctx, "board", 15, math.Inf(1), 0, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []ZMember{{Member: "carol", Score: 20}, {Member: "dave", Score: 30}}, members)
+			members, err = c.ZRevRangeByScore(ctx, "board", math.Inf(1), 12.5, 1, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []ZMember{{Member: "carol", Score: 20}, {Member: "alice", Score: 12.5}}, members)
+
+			n, err = c.ZRem(ctx, "board", "alice", "bob", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			card, err := c.ZCard(ctx, "board")
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), card)
+		})
+	}
+}
+
+func TestStructuredCache_KeySpace(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			require.NoError(t, c.Set(ctx, "plain", "value", time.Hour))
+			_, err := c.HSet(ctx, "plain", map[string]string{"a": "1"})
+			assert.True(t, errors.Is(err, ErrWrongType), "got %v", err)
+			_, err = c.RPush(ctx, "plain", "x")
+			assert.True(t, errors.Is(err, ErrWrongType), "got %v", err)
+
+			_, err = c.SAdd(ctx, "set", "a")
+			require.NoError(t, err)
+			_, err = c.ZAdd(ctx, "set", ZMember{Member: "a", Score: 1})
+			assert.True(t, errors.Is(err, ErrWrongType), "got %v", err)
+			_, _, err = c.LPop(ctx, "set")
+			assert.True(t, errors.Is(err, ErrWrongType), "got %v", err)
+
+			_, found := c.Get(ctx, "set")
+			assert.False(t, found, "Get does not return structured data")
+			exists, err := c.Exists(ctx, "set")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			require.NoError(t, c.Delete(ctx, "set"))
+			ok, err := c.SIsMember(ctx, "set", "a")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestStructuredCache_Expire(t *testing.T) {
+	for name, c := range structuredTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			ok, err := c.Expire(ctx, "missing", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			_, err = c.RPush(ctx, "list", "a")
+			require.NoError(t, err)
+			ok, err = c.Expire(ctx, "list", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = c.Expire(ctx, "list", 0)
+			require.NoError(t, err)
+			assert.True(t, ok, "removing the expiry reports an existing key")
+			ok, err = c.Expire(ctx, "list", 0)
+			require.NoError(t, err)
+			assert.True(t, ok, "a key without expiry still exists")
+		})
+	}
+}
+
+func TestMemoryCache_StructuredExpiration(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+
+	_, err := c.HSet(ctx, "session", map[string]string{"user": "alice"})
+	require.NoError(t, err)
+	ok, err := c.Expire(ctx, "session", 20*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, found, err := c.HGet(ctx, "session", "user")
+	require.NoError(t, err)
+	assert.False(t, found, "expired structures are not visible")
+
+	_, err = c.RPush(ctx, "session", "a")
+	assert.NoError(t, err, "an expired key can be reused with another type")
+}
+
+func TestStructured(t *testing.T) {
+	memory := NewMemoryCache()
+	defer memory.Close()
+
+	sc, ok := Structured(memory)
+	require.True(t, ok)
+	assert.Same(t, memory, sc)
+
+	sc, ok = Structured(NewInstrumentedCache(memory))
+	require.True(t, ok)
+	assert.Same(t, memory, sc, "wrappers are unwrapped")
+
+	multi, err := NewMultiLevelCache(NewMemoryCache(), NewMemoryCache())
+	require.NoError(t, err)
+	defer multi.Close()
+	_, ok = Structured(multi)
+	assert.False(t, ok)
+}
@@ -0,0 +1,365 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sweepBatchSize 清理时每批处理的键数
+const sweepBatchSize = 100
+
+// keySweeper 支持遍历和直接删除键的缓存，Sweeper用它清理命名空间旧版本的键
+type keySweeper interface {
+	KeyScanner
+	// removeKeys 删除键，不需要维护标签索引
+	removeKeys(ctx context.Context, keys []string) error
+}
+
+// tagSweeper 标签索引与数据分开保存的缓存，Sweeper用它清理已不存在的键留下的标签成员
+type tagSweeper interface {
+	sweepTags(ctx context.Context) (int, error)
+}
+
+// findCache 在c及其Unwrap返回的底层缓存中查找实现了T的缓存
+func findCache[T any](c Cache) (T, bool) {
+	for c != nil {
+		if t, ok := c.(T); ok {
+			return t, true
+		}
+		wrapper, ok := c.(interface{ Unwrap() Cache })
+		if !ok {
+			break
+		}
+		c = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// SweeperOptions 后台清理选项
+type SweeperOptions struct {
+	// Interval 后台清理的间隔，默认5分钟；小于0时不启动后台清理，只能手动调用Sweep
+	Interval time.Duration
+
+	// Timeout 每次后台清理的超时时间，默认1分钟
+	Timeout time.Duration
+
+	// OnError 后台清理出错时的回调
+	OnError func(err error)
+}
+
+// DefaultSweeperOptions 返回默认的后台清理选项
+func DefaultSweeperOptions() SweeperOptions {
+	return SweeperOptions{
+		Interval: time.Minute * 5,
+		Timeout:  time.Minute,
+	}
+}
+
+// SweepResult 一次清理的结果
+type SweepResult struct {
+	// StaleKeys 删除的命名空间旧版本键数
+	StaleKeys int
+	// OrphanedTagMembers 从标签索引中移除的、对应的键已不存在的成员数
+	OrphanedTagMembers int
+}
+
+// Sweeper 后台清理器
+// 清理两类不会自动消失的数据：
+//   - 命名空间失效后旧版本的键，在TTL很长或永不过期时会一直占用空间
+//   - RedisCache的标签索引中对应的键已过期或被删除的成员，以及因此变空的标签集合
+//
+// 清理使用SCAN分批进行，不会阻塞Redis，但仍会遍历整个键空间，因此应以较长的间隔在后台运行。
+// 目前支持MemoryCache、ShardedMemoryCache和RedisCache（包括被InstrumentedCache等通过Unwrap包装的实例），
+// 其他缓存会被跳过
+//
+// 示例：
+//
+//	sweeper := cache.NewSweeper(redisCache)
+//	defer sweeper.Close()
+//	sweeper.Add(products, users)
+type Sweeper struct {
+	cache   Cache
+	options SweeperOptions
+
+	mu         sync.Mutex
+	namespaces []*Namespace
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewSweeper 创建后台清理器并启动后台清理协程
+// 参数：
+//
+//	c: 需要清理标签索引的缓存
+//	opts: 清理选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewSweeper(c Cache, opts ...SweeperOptions) *Sweeper {
+	options := DefaultSweeperOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	defaults := DefaultSweeperOptions()
+	if options.Interval == 0 {
+		options.Interval = defaults.Interval
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaults.Timeout
+	}
+
+	s := &Sweeper{
+		cache:   c,
+		options: options,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if options.Interval > 0 {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+// Add 添加需要清理旧版本键的命名空间
+func (s *Sweeper) Add(namespaces ...*Namespace) {
+	s.mu.Lock()
+	s.namespaces = append(s.namespaces, namespaces...)
+	s.mu.Unlock()
+}
+
+// Sweep 立即执行一次清理
+// 先删除命名空间旧版本的键，再清理标签索引，使旧版本键留下的标签成员在同一次清理中被移除
+func (s *Sweeper) Sweep(ctx context.Context) (SweepResult, error) {
+	var result SweepResult
+
+	s.mu.Lock()
+	namespaces := append([]*Namespace(nil), s.namespaces...)
+	s.mu.Unlock()
+
+	for _, ns := range namespaces {
+		n, err := sweepNamespace(ctx, ns)
+		result.StaleKeys += n
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if sweeper, ok := findCache[tagSweeper](s.cache); ok {
+		n, err := sweeper.sweepTags(ctx)
+		result.OrphanedTagMembers += n
+		if err != nil {
+			return result, fmt.Errorf("failed to sweep tags: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// sweepNamespace 删除命名空间中版本号小于当前版本的键
+func sweepNamespace(ctx context.Context, ns *Namespace) (int, error) {
+	sweeper, ok := findCache[keySweeper](ns.cache)
+	if !ok {
+		return 0, nil
+	}
+
+	// 直接读取版本号，不使用本地缓存的值；即使读到的版本已被其他实例更新，也只会少删而不会误删
+	version, err := ns.fetchVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	stale := make([]string, 0, sweepBatchSize)
+	flush := func() error {
+		if len(stale) == 0 {
+			return nil
+		}
+		if err := sweeper.removeKeys(ctx, stale); err != nil {
+			return err
+		}
+		removed += len(stale)
+		stale = stale[:0]
+		return nil
+	}
+
+	for key, err := range sweeper.Scan(ctx, EscapePattern(namespaceKeyPrefix+ns.name+":")+"*") {
+		if err == nil {
+			if v, ok := ns.parseVersion(key); ok && v < version {
+				stale = append(stale, key)
+			}
+			if len(stale) < sweepBatchSize {
+				continue
+			}
+			err = flush()
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to sweep namespace %s: %w", ns.name, err)
+		}
+	}
+	if err := flush(); err != nil {
+		return removed, fmt.Errorf("failed to sweep namespace %s: %w", ns.name, err)
+	}
+	return removed, nil
+}
+
+// run 后台清理协程
+func (s *Sweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+		if _, err := s.Sweep(ctx); err != nil && s.options.OnError != nil {
+			s.options.OnError(err)
+		}
+		cancel()
+	}
+}
+
+// Close 停止后台清理协程，不关闭缓存，重复调用是安全的
+func (s *Sweeper) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+// removeKeys 删除键，实现keySweeper接口
+func (c *MemoryCache) removeKeys(ctx context.Context, keys []string) error {
+	return c.DeleteMany(ctx, keys)
+}
+
+// removeKeys 删除键，实现keySweeper接口
+func (c *ShardedMemoryCache) removeKeys(ctx context.Context, keys []string) error {
+	return c.DeleteMany(ctx, keys)
+}
+
+// removeKeys 直接删除键及其版本键和标签反向索引，留下的标签成员由sweepTags清理，实现keySweeper接口
+func (c *RedisCache) removeKeys(ctx context.Context, keys []string) error {
+	prefixed := make([]string, 0, len(keys)*3)
+	for _, key := range keys {
+		prefixed = append(prefixed, c.entryKeys(key)...)
+	}
+	return c.unlink(ctx, prefixed)
+}
+
+// sweepTags 从标签集合中移除对应的键已不存在的成员，实现tagSweeper接口
+// 标签集合的最后一个成员被移除后，Redis会自动删除该集合
+func (c *RedisCache) sweepTags(ctx context.Context) (int, error) {
+	removed := 0
+	err := c.scan(ctx, c.prefixPattern(tagKeyPrefix+"*"), func(tagKeys []string) error {
+		for _, tagKey := range tagKeys {
+			n, err := c.sweepTag(ctx, tagKey)
+			removed += n
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// sweepTag 清理一个标签集合
+func (c *RedisCache) sweepTag(ctx context.Context, tagKey string) (int, error) {
+	removed := 0
+	var cursor uint64
+	for {
+		members, next, err := c.client.SScan(ctx, tagKey, cursor, "", sweepBatchSize).Result()
+		if err != nil {
+			return removed, err
+		}
+
+		orphans, err := c.missingKeys(ctx, members)
+		if err != nil {
+			return removed, err
+		}
+		if len(orphans) > 0 {
+			n, err := c.removeTagMembers(ctx, tagKey, orphans)
+			removed += n
+			if err != nil {
+				return removed, err
+			}
+		}
+
+		if next == 0 {
+			return removed, nil
+		}
+		cursor = next
+	}
+}
+
+// missingKeys 返回keys中在Redis中不存在的键
+func (c *RedisCache) missingKeys(ctx context.Context, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.Exists(ctx, c.prefixKey(key))
+	}
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for i, cmd := range cmds {
+		if exists, ok := cmd.(*redis.IntCmd); ok && exists.Val() == 0 {
+			missing = append(missing, keys[i])
+		}
+	}
+	return missing, nil
+}
+
+// removeTagMembers 从标签集合中移除成员
+// 判断键不存在与移除成员之间键可能被重新写入，移除后再次检查：键存在且标签反向索引中仍有该标签，
+// 说明当前写入带有这个标签，重新加入标签集合。写入时先SET再更新标签，条件写入会删除反向索引，
+// 因此不会把已不带该标签的键加回去
+func (c *RedisCache) removeTagMembers(ctx context.Context, tagKey string, members []string) (int, error) {
+	if err := c.client.SRem(ctx, tagKey, stringArgs(members)...).Err(); err != nil {
+		return 0, err
+	}
+
+	tag := strings.TrimPrefix(tagKey, c.prefixKey(tagKeyPrefix))
+	pipe := c.client.Pipeline()
+	exists := make([]*redis.IntCmd, len(members))
+	tagged := make([]*redis.BoolCmd, len(members))
+	for i, key := range members {
+		exists[i] = pipe.Exists(ctx, c.prefixKey(key))
+		tagged[i] = pipe.SIsMember(ctx, c.keyTagsKey(key), tag)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var restored []string
+	for i, key := range members {
+		if exists[i].Val() > 0 && tagged[i].Val() {
+			restored = append(restored, key)
+		}
+	}
+	if len(restored) == 0 {
+		return len(members), nil
+	}
+	if err := c.client.SAdd(ctx, tagKey, stringArgs(restored)...).Err(); err != nil {
+		return len(members) - len(restored), err
+	}
+	return len(members) - len(restored), nil
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweeper_StaleNamespaceKeys(t *testing.T) {
+	for name, c := range namespaceTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			products, err := NewNamespace(c, "products")
+			require.NoError(t, err)
+			other, err := NewNamespace(c, "productsx")
+			require.NoError(t, err)
+
+			require.NoError(t, products.Set(ctx, "product:1", "old", -1))
+			require.NoError(t, products.Set(ctx, "product:2", "old", -1))
+			require.NoError(t, other.Set(ctx, "product:1", "other", -1))
+			_, err = products.Invalidate(ctx)
+			require.NoError(t, err)
+			require.NoError(t, products.Set(ctx, "product:1", "new", -1))
+
+			sweeper := NewSweeper(c, SweeperOptions{Interval: -1})
+			defer sweeper.Close()
+			sweeper.Add(products, other)
+
+			result, err := sweeper.Sweep(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.StaleKeys)
+
+			value, found := products.Get(ctx, "product:1")
+			require.True(t, found, "keys of the current version are kept")
+			assert.Equal(t, "new", value)
+			_, found = other.Get(ctx, "product:1")
+			assert.True(t, found, "namespaces sharing a name prefix are kept")
+			_, err = products.Version(ctx)
+			require.NoError(t, err)
+
+			result, err = sweeper.Sweep(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 0, result.StaleKeys)
+		})
+	}
+}
+
+func TestSweeper_RedisOrphanedTags(t *testing.T) {
+	ctx := context.Background()
+	c := newMiniRedisCache(t)
+	client := c.GetClient()
+
+	require.NoError(t, c.SetItem(ctx, "user:1", &Item{Value: "alice", Expiration: -1, Tags: []string{"users"}}))
+	require.NoError(t, c.SetItem(ctx, "user:2", &Item{Value: "bob", Expiration: -1, Tags: []string{"users", "admins"}}))
+	// 模拟键过期：直接删除数据键，标签集合中仍保留成员
+	require.NoError(t, client.Del(ctx, c.prefixKey("user:2")).Err())
+
+	ns, err := NewNamespace(c, "orders")
+	require.NoError(t, err)
+	require.NoError(t, ns.SetItem(ctx, "order:1", &Item{Value: "a", Expiration: -1, Tags: []string{"open"}}))
+	_, err = ns.Invalidate(ctx)
+	require.NoError(t, err)
+
+	sweeper := NewSweeper(NewInstrumentedCache(c), SweeperOptions{Interval: -1})
+	defer sweeper.Close()
+	sweeper.Add(ns)
+
+	result, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.StaleKeys)
+	assert.Equal(t, 3, result.OrphanedTagMembers, "user:2 in two tags and the stale order")
+
+	members, err := client.SMembers(ctx, c.prefixKey("tag:users")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, members)
+	n, err := client.Exists(ctx, c.prefixKey("tag:admins")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n, "empty tag sets are removed")
+}
+
+func TestSweeper_RemoveTagMembersKeepsCurrentTags(t *testing.T) {
+	ctx := context.Background()
+	c := newMiniRedisCache(t)
+	client := c.GetClient()
+	tagKey := c.prefixKey(tagKeyPrefix + "users")
+
+	// 三个成员在检查时都不存在，移除前retagged被重新写入并带有标签，untagged被不带标签地写入
+	require.NoError(t, c.SetItem(ctx, "retagged", &Item{Value: 1, Expiration: time.Hour, Tags: []string{"users"}}))
+	require.NoError(t, c.Set(ctx, "untagged", 2, time.Hour))
+	require.NoError(t, client.SAdd(ctx, tagKey, "stale", "untagged").Err())
+
+	removed, err := c.removeTagMembers(ctx, tagKey, []string{"stale", "retagged", "untagged"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	members, err := client.SMembers(ctx, tagKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"retagged"}, members, "only the write that still carries the tag is restored")
+}
+
+func TestSweeper_Background(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+	ns, err := NewNamespace(c, "users")
+	require.NoError(t, err)
+	require.NoError(t, ns.Set(ctx, "user:1", "alice", -1))
+	_, err = ns.Invalidate(ctx)
+	require.NoError(t, err)
+
+	var errs atomic.Int32
+	sweeper := NewSweeper(c, SweeperOptions{Interval: 10 * time.Millisecond, OnError: func(error) { errs.Add(1) }})
+	sweeper.Add(ns)
+
+	require.Eventually(t, func() bool {
+		return c.Stats().Entries == 1
+	}, time.Second, 10*time.Millisecond, "only the version key remains")
+	require.NoError(t, sweeper.Close())
+	require.NoError(t, sweeper.Close(), "Close is idempotent")
+	assert.Zero(t, errs.Load())
+}
+
+func TestRedisCache_DeleteByPatternAndFlushWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	c := newMiniRedisCache(t)
+	c.options.KeyPrefix = "app"
+
+	require.NoError(t, c.Set(ctx, "user:1", 1, time.Hour))
+	require.NoError(t, c.Set(ctx, "user:2", 2, time.Hour))
+	require.NoError(t, c.Set(ctx, "order:1", 1, time.Hour))
+	require.NoError(t, c.GetClient().Set(ctx, "outside", 1, time.Hour).Err())
+
+	require.NoError(t, c.DeleteByPattern(ctx, "user:*"))
+	keys, err := c.GetClient().Keys(ctx, "*").Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app:order:1", "outside"}, keys)
+
+	require.NoError(t, c.Flush(ctx))
+	keys, err = c.GetClient().Keys(ctx, "*").Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outside"}, keys, "Flush only removes prefixed keys")
+}
+
+func TestRedisCache_ScanSkipsReservedKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newMiniRedisCache(t)
+	c.options.KeyPrefix = "app"
+
+	require.NoError(t, c.SetItem(ctx, "user:1", &Item{Value: 1, Expiration: time.Hour, Tags: []string{"users"}}))
+	_, _, err := c.GetVersioned(ctx, "user:1", nil)
+	require.NoError(t, err)
+	require.NoError(t, c.GetClient().Set(ctx, c.loadLockKey("user:1"), "token", time.Hour).Err())
+
+	keys, err := Keys(ctx, c, "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, keys)
+
+	// 删除所有业务键时保留内部键，标签索引仍然可用
+	require.NoError(t, c.DeleteByPattern(ctx, "*"))
+	for _, key := range []string{c.prefixKey(tagKeyPrefix + "users"), c.loadLockKey("user:1")} {
+		n, err := c.GetClient().Exists(ctx, key).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n, key)
+	}
+	keys, err = Keys(ctx, c, "*")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ValueDecoder 支持将缓存值直接解码到目标类型的缓存实现
+// 实现此接口的缓存在TypedCache中无需经过interface{}中转，
+// 例如RedisCache直接将序列化数据反序列化到目标结构体，避免得到map[string]interface{}
+type ValueDecoder interface {
+	// GetInto 获取缓存值并解码到target
+	// 参数：
+	//   ctx: 上下文
+	//   key: 缓存键名
+	//   target: 目标指针，必须为非nil指针
+	// 返回：
+	//   bool: 是否找到缓存
+	//   error: 读取或解码失败时返回错误，解码失败的错误可用errors.Is(err, ErrDecode)判断
+	GetInto(ctx context.Context, key string, target interface{}) (bool, error)
+
+	// GetIntoWithTTL 获取缓存值和剩余生存时间并解码到target
+	// 剩余生存时间的含义与GetWithTTL相同
+	GetIntoWithTTL(ctx context.Context, key string, target interface{}) (time.Duration, bool, error)
+}
+
+// TypedCache 类型安全的缓存包装器
+// 在任意Cache实现之上提供以T为值类型的读写接口，保证内存缓存、Redis缓存和多级缓存
+// 对同一类型返回相同的结果，解码失败时返回错误而不是当作未命中
+//
+// 示例：
+//
+//	users := cache.NewTypedCache[*User](redisCache)
+//	_ = users.Set(ctx, "user:123", &User{ID: 123}, time.Hour)
+//	user, found, err := users.Get(ctx, "user:123")
+type TypedCache[T any] struct {
+	cache Cache
+}
+
+// NewTypedCache 创建类型安全的缓存包装器
+func NewTypedCache[T any](c Cache) *TypedCache[T] {
+	return &TypedCache[T]{cache: c}
+}
+
+// Get 获取缓存值
+// 返回：
+//
+//	T: 缓存值，未找到或出错时为T的零值
+//	bool: 是否找到缓存
+//	error: 读取或解码失败时返回错误
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var value T
+	found, err := GetInto(ctx, c.cache, key, &value)
+	if err != nil || !found {
+		var zero T
+		return zero, false, err
+	}
+	return value, true, nil
+}
+
+// GetWithTTL 获取缓存值和剩余生存时间
+func (c *TypedCache[T]) GetWithTTL(ctx context.Context, key string) (T, time.Duration, bool, error) {
+	var value T
+	ttl, found, err := GetIntoWithTTL(ctx, c.cache, key, &value)
+	if err != nil || !found {
+		var zero T
+		return zero, 0, false, err
+	}
+	return value, ttl, true, nil
+}
+
+// Set 设置缓存值
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return c.cache.Set(ctx, key, value, ttl)
+}
+
+// SetItem 设置带标签的缓存值
+func (c *TypedCache[T]) SetItem(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	return c.cache.SetItem(ctx, key, &Item{Value: value, Expiration: ttl, Tags: tags})
+}
+
+// Delete 删除缓存
+func (c *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// GetOrLoad 获取缓存值，未命中时调用loader加载并写入缓存
+// 底层缓存实现了Loader接口时，同一键的并发未命中只调用一次loader
+// 参数：
+//
+//	ctx: 上下文
+//	key: 缓存键名
+//	ttl: 写入缓存时使用的生存时间
+//	loader: 未命中时加载数据的函数
+//
+// 返回：
+//
+//	T: 缓存值或加载的值
+//	error: 解码失败、加载失败或写入缓存失败时返回错误
+func (c *TypedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	value, found, err := c.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if found {
+		return value, nil
+	}
+
+	loaded, err := GetOrLoad(ctx, c.cache, key, ttl, func(ctx context.Context) (interface{}, error) {
+		return loader(ctx)
+	})
+	if loaded == nil {
+		var zero T
+		return zero, err
+	}
+
+	// 等待其他调用的加载结果时得到的可能是缓存中的原始值，需要转换为T
+	if typed, ok := loaded.(T); ok {
+		return typed, err
+	}
+	if decodeErr := assignValue(key, loaded, &value); decodeErr != nil {
+		var zero T
+		return zero, decodeErr
+	}
+	return value, err
+}
+
+// Cache 返回底层缓存
+func (c *TypedCache[T]) Cache() Cache {
+	return c.cache
+}
+
+// GetInto 从任意缓存读取值并解码到target
+// 缓存实现了ValueDecoder接口时使用其实现，否则读取值后按类型赋值或经JSON转换
+func GetInto(ctx context.Context, c Cache, key string, target interface{}) (bool, error) {
+	if decoder, ok := c.(ValueDecoder); ok {
+		return decoder.GetInto(ctx, key, target)
+	}
+
+	value, found := c.Get(ctx, key)
+	if !found {
+		return false, nil
+	}
+	if err := assignValue(key, value, target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetIntoWithTTL 从任意缓存读取值和剩余生存时间并解码到target
+func GetIntoWithTTL(ctx context.Context, c Cache, key string, target interface{}) (time.Duration, bool, error) {
+	if decoder, ok := c.(ValueDecoder); ok {
+		return decoder.GetIntoWithTTL(ctx, key, target)
+	}
+
+	value, ttl, found := c.GetWithTTL(ctx, key)
+	if !found {
+		return 0, false, nil
+	}
+	if err := assignValue(key, value, target); err != nil {
+		return 0, false, err
+	}
+	return ttl, true, nil
+}
+
+// assignValue 将内存中的值赋给target
+// 类型可以直接赋值时赋值深拷贝，值与指针之间自动转换，修改target不会影响缓存中的值；
+// 否则通过JSON转换，与RedisCache使用默认序列化器时的行为保持一致
+func assignValue(key string, value interface{}, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: key %q: target must be a non-nil pointer, got %T", ErrDecode, key, target)
+	}
+	dst := rv.Elem()
+
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	src := reflect.ValueOf(value)
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(copyValue(src))
+		return nil
+	case dst.Kind() == reflect.Ptr && src.Type().AssignableTo(dst.Type().Elem()):
+		// 存储的是值，目标是指针
+		ptr := reflect.New(dst.Type().Elem())
+		ptr.Elem().Set(copyValue(src))
+		dst.Set(ptr)
+		return nil
+	case src.Kind() == reflect.Ptr && !src.IsNil() && src.Elem().Type().AssignableTo(dst.Type()):
+		// 存储的是指针，目标是值
+		dst.Set(copyValue(src.Elem()))
+		return nil
+	}
+
+	serializer := &JSONSerializer{}
+	data, err := serializer.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: key %q: %w", ErrDecode, key, err)
+	}
+	if err := serializer.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%w: key %q: %w", ErrDecode, key, err)
+	}
+	return nil
+}
+
+// copyValue 返回v的深拷贝，指针、map、切片和接口指向的数据都会复制
+// 结构体的未导出字段无法通过反射写入，按值复制，其中的指针等引用仍与原值共享
+func copyValue(v reflect.Value) reflect.Value {
+	return deepCopy(v, make(map[uintptr]reflect.Value))
+}
+
+// deepCopy 递归复制v，seen记录已复制的指针和map，保证循环引用和共享引用在副本中保持相同结构
+func deepCopy(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := seen[v.Pointer()]; ok {
+			return copied
+		}
+		ptr := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = ptr
+		ptr.Elem().Set(deepCopy(v.Elem(), seen))
+		return ptr
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := seen[v.Pointer()]; ok {
+			return copied
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		seen[v.Pointer()] = m
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(deepCopy(iter.Key(), seen), deepCopy(iter.Value(), seen))
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return s
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return a
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := s.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i), seen))
+			}
+		}
+		return s
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(deepCopy(v.Elem(), seen))
+		return i
+	default:
+		return v
+	}
+}
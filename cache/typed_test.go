@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestUser struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+// newMiniRedisCache 创建基于miniredis的Redis缓存
+func newMiniRedisCache(t *testing.T) *RedisCache {
+	mr := miniredis.RunT(t)
+
+	opts := DefaultRedisOptions()
+	opts.Addr = mr.Addr()
+	redisCache, err := NewRedisCache(opts, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = redisCache.Close() })
+	return redisCache
+}
+
+// typedTestCaches 返回用于一致性测试的各种缓存实现
+func typedTestCaches(t *testing.T) map[string]Cache {
+	memory := NewMemoryCache()
+	t.Cleanup(func() { _ = memory.Close() })
+
+	local := NewMemoryCache()
+	t.Cleanup(func() { _ = local.Close() })
+	multi, err := NewMultiLevelCache(local, newMiniRedisCache(t))
+	require.NoError(t, err)
+
+	return map[string]Cache{
+		"memory":      memory,
+		"redis":       newMiniRedisCache(t),
+		"multi_level": multi,
+	}
+}
+
+func TestTypedCache_ConsistentAcrossBackends(t *testing.T) {
+	ctx := context.Background()
+	user := &typedTestUser{ID: 42, Name: "alice", Roles: []string{"admin"}}
+
+	for name, c := range typedTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			users := NewTypedCache[*typedTestUser](c)
+			require.NoError(t, users.Set(ctx, "user:42", user, time.Minute))
+
+			got, found, err := users.Get(ctx, "user:42")
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, user, got)
+
+			// 按值类型读取同一个键
+			byValue, found, err := NewTypedCache[typedTestUser](c).Get(ctx, "user:42")
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, *user, byValue)
+
+			got, ttl, found, err := users.GetWithTTL(ctx, "user:42")
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, user, got)
+			assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+			// 数值保持目标类型而不是float64
+			counters := NewTypedCache[int64](c)
+			require.NoError(t, counters.Set(ctx, "count", 7, time.Minute))
+			count, found, err := counters.Get(ctx, "count")
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, int64(7), count)
+
+			// 未命中
+			_, found, err = users.Get(ctx, "user:missing")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			// 类型不匹配时返回解码错误
+			require.NoError(t, c.Set(ctx, "user:bad", "not a user", time.Minute))
+			_, found, err = users.Get(ctx, "user:bad")
+			assert.False(t, found)
+			assert.True(t, errors.Is(err, ErrDecode), "expected ErrDecode, got %v", err)
+		})
+	}
+}
+
+func TestTypedCache_GetOrLoad(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range typedTestCaches(t) {
+		t.Run(name, func(t *testing.T) {
+			users := NewTypedCache[typedTestUser](c)
+
+			loads := 0
+			loader := func(ctx context.Context) (typedTestUser, error) {
+				loads++
+				return typedTestUser{ID: 7, Name: "bob"}, nil
+			}
+
+			for i := 0; i < 3; i++ {
+				user, err := users.GetOrLoad(ctx, "user:7", time.Minute, loader)
+				require.NoError(t, err)
+				assert.Equal(t, typedTestUser{ID: 7, Name: "bob"}, user)
+			}
+			assert.Equal(t, 1, loads)
+
+			// 加载失败时不写入缓存
+			loadErr := errors.New("db down")
+			_, err := users.GetOrLoad(ctx, "user:8", time.Minute, func(ctx context.Context) (typedTestUser, error) {
+				return typedTestUser{}, loadErr
+			})
+			assert.ErrorIs(t, err, loadErr)
+			exists, err := c.Exists(ctx, "user:8")
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestMultiLevelCache_GetIntoPopulatesLocal(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryCache()
+	defer local.Close()
+	remote := newMiniRedisCache(t)
+
+	multi, err := NewMultiLevelCache(local, remote)
+	require.NoError(t, err)
+
+	require.NoError(t, remote.Set(ctx, "user:1", &typedTestUser{ID: 1, Name: "carol"}, time.Minute))
+
+	user, found, err := NewTypedCache[*typedTestUser](multi).Get(ctx, "user:1")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "carol", user.Name)
+
+	// 本地缓存中保存的是解码后的类型
+	value, found := local.Get(ctx, "user:1")
+	require.True(t, found)
+	assert.IsType(t, &typedTestUser{}, value)
+}
+
+func TestMemoryCache_GetIntoReturnsCopy(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	defer c.Close()
+
+	require.NoError(t, c.Set(ctx, "user", &typedTestUser{ID: 1, Name: "alice", Roles: []string{"admin"}}, time.Minute))
+	require.NoError(t, c.Set(ctx, "settings", map[string][]string{"langs": {"go"}}, time.Minute))
+
+	var user *typedTestUser
+	_, err := c.GetInto(ctx, "user", &user)
+	require.NoError(t, err)
+	user.Name = "mallory"
+	user.Roles[0] = "root"
+
+	var byValue typedTestUser
+	_, err = c.GetInto(ctx, "user", &byValue)
+	require.NoError(t, err)
+	byValue.Roles = append(byValue.Roles[:0], "guest")
+
+	var settings map[string][]string
+	_, err = c.GetInto(ctx, "settings", &settings)
+	require.NoError(t, err)
+	settings["langs"][0] = "rust"
+	settings["extra"] = nil
+
+	// 修改读取到的值不影响缓存中的值
+	var stored *typedTestUser
+	_, err = c.GetInto(ctx, "user", &stored)
+	require.NoError(t, err)
+	assert.Equal(t, &typedTestUser{ID: 1, Name: "alice", Roles: []string{"admin"}}, stored)
+	_, err = c.GetInto(ctx, "settings", &settings)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"langs": {"go"}}, settings)
+
+	// 多级缓存写入本地缓存的是副本
+	local := NewMemoryCache()
+	defer local.Close()
+	remote := NewMemoryCache()
+	defer remote.Close()
+	multi, err := NewMultiLevelCache(local, remote)
+	require.NoError(t, err)
+	require.NoError(t, remote.Set(ctx, "user", &typedTestUser{ID: 2, Name: "bob"}, time.Minute))
+	var fromRemote *typedTestUser
+	_, err = multi.GetInto(ctx, "user", &fromRemote)
+	require.NoError(t, err)
+	fromRemote.Name = "eve"
+	_, err = multi.GetInto(ctx, "user", &stored)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", stored.Name)
+}
+
+func TestCopyValue_Cycles(t *testing.T) {
+	type node struct {
+		Next *node
+		Tags map[string]interface{}
+	}
+	n := &node{Tags: map[string]interface{}{"list": []interface{}{1, "a"}}}
+	n.Next = n
+
+	copied := copyValue(reflect.ValueOf(n)).Interface().(*node)
+	assert.NotSame(t, n, copied)
+	assert.Same(t, copied, copied.Next)
+	copied.Tags["list"].([]interface{})[0] = 2
+	assert.Equal(t, 1, n.Tags["list"].([]interface{})[0])
+}
@@ -0,0 +1,427 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteBackOptions 回写模式选项
+type WriteBackOptions struct {
+	// FlushInterval 后台批量写入远程缓存的间隔，默认1秒
+	FlushInterval time.Duration
+
+	// BatchSize 每批写入远程缓存的最大键数，待写入的键达到此数量时立即触发写入，默认100
+	BatchSize int
+
+	// QueueSize 待写入键的最大数量，默认10000
+	// 队列已满时新键直接同步写入远程缓存，已在队列中的键仍然合并写入
+	QueueSize int
+
+	// MaxRetries 写入远程缓存失败后的最大重试次数，默认3
+	// 超过后放弃写入，计入失败数并调用OnFlushError
+	MaxRetries int
+
+	// CloseTimeout 关闭时等待剩余写入完成的最长时间，默认10秒
+	CloseTimeout time.Duration
+
+	// OnFlushError 放弃写入时的回调
+	OnFlushError func(keys []string, err error)
+}
+
+// DefaultWriteBackOptions 返回默认的回写模式选项
+func DefaultWriteBackOptions() WriteBackOptions {
+	return WriteBackOptions{
+		FlushInterval: time.Second,
+		BatchSize:     100,
+		QueueSize:     10000,
+		MaxRetries:    3,
+		CloseTimeout:  time.Second * 10,
+	}
+}
+
+// WriteBackStats 回写模式统计信息
+type WriteBackStats struct {
+	// Pending 等待写入远程缓存的键数
+	Pending int
+	// Flushed 成功写入远程缓存的键数
+	Flushed uint64
+	// Coalesced 被同一键的后续写入合并的写入次数
+	Coalesced uint64
+	// Retried 写入失败后重新排队的次数
+	Retried uint64
+	// Failed 超过重试次数被放弃的键数
+	Failed uint64
+	// Overflowed 队列已满时直接同步写入远程缓存的次数
+	Overflowed uint64
+}
+
+// pendingWrite 等待写入远程缓存的项
+type pendingWrite struct {
+	key      string
+	value    interface{}
+	tags     []string
+	ttl      time.Duration // 0或负数时原样传给远程缓存
+	expireAt time.Time     // ttl为正数时的过期时间点
+	attempts int
+}
+
+// remaining 返回写入时应使用的剩余生存时间，已过期时返回false
+func (w *pendingWrite) remaining(now time.Time) (time.Duration, bool) {
+	if w.ttl <= 0 {
+		return w.ttl, true
+	}
+	ttl := w.expireAt.Sub(now)
+	return ttl, ttl > 0
+}
+
+// writeBehind 回写队列
+// 写入先进入按键合并的有序队列，由后台协程按批写入远程缓存
+type writeBehind struct {
+	remote  Cache
+	options WriteBackOptions
+	// onFlushed 一批键成功写入远程缓存后的回调
+	onFlushed func(ctx context.Context, keys ...string)
+
+	mu      sync.Mutex
+	queue   *list.List
+	pending map[string]*list.Element
+
+	// flushMu 串行化写入远程缓存，保证删除等操作不会与正在进行的写入交错
+	flushMu sync.Mutex
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	flushed    atomic.Uint64
+	coalesced  atomic.Uint64
+	retried    atomic.Uint64
+	failed     atomic.Uint64
+	overflowed atomic.Uint64
+}
+
+// newWriteBehind 创建回写队列并启动后台写入协程
+func newWriteBehind(remote Cache, opts WriteBackOptions, onFlushed func(ctx context.Context, keys ...string)) *writeBehind {
+	defaults := DefaultWriteBackOptions()
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaults.FlushInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaults.BatchSize
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaults.QueueSize
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.CloseTimeout <= 0 {
+		opts.CloseTimeout = defaults.CloseTimeout
+	}
+
+	w := &writeBehind{
+		remote:    remote,
+		options:   opts,
+		onFlushed: onFlushed,
+		queue:     list.New(),
+		pending:   make(map[string]*list.Element),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// enqueue 将写入加入队列，同一键的重复写入只保留最新的值
+// 返回false表示队列已满，调用方需要同步写入远程缓存
+func (w *writeBehind) enqueue(key string, item *Item) bool {
+	write := &pendingWrite{key: key, value: item.Value, tags: item.Tags, ttl: item.Expiration}
+	if write.ttl > 0 {
+		write.expireAt = time.Now().Add(write.ttl)
+	}
+
+	w.mu.Lock()
+	if elem, ok := w.pending[key]; ok {
+		elem.Value = write
+		w.mu.Unlock()
+		w.coalesced.Add(1)
+		return true
+	}
+	if len(w.pending) >= w.options.QueueSize {
+		w.mu.Unlock()
+		w.overflowed.Add(1)
+		return false
+	}
+	w.pending[key] = w.queue.PushBack(write)
+	full := len(w.pending) >= w.options.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// lookup 返回队列中尚未写入远程缓存的值
+func (w *writeBehind) lookup(key string) (interface{}, time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	elem, ok := w.pending[key]
+	if !ok {
+		return nil, 0, false
+	}
+	write := elem.Value.(*pendingWrite)
+	ttl, ok := write.remaining(time.Now())
+	if !ok {
+		return nil, 0, false
+	}
+	if ttl == 0 {
+		// 使用远程缓存默认TTL的项剩余时间未知
+		ttl = -1
+	}
+	return write.value, ttl, true
+}
+
+// cancel 取消键的待写入项，会等待正在进行的写入完成
+// 调用方随后删除远程缓存中的键时，不会被稍后完成的写入覆盖
+func (w *writeBehind) cancel(keys ...string) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, key := range keys {
+		if elem, ok := w.pending[key]; ok {
+			w.queue.Remove(elem)
+			delete(w.pending, key)
+		}
+	}
+}
+
+// cancelTag 取消带有指定标签的待写入项
+func (w *writeBehind) cancelTag(tag string) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, elem := range w.pending {
+		for _, t := range elem.Value.(*pendingWrite).tags {
+			if t == tag {
+				w.queue.Remove(elem)
+				delete(w.pending, key)
+				break
+			}
+		}
+	}
+}
+
+// discard 丢弃所有待写入项
+func (w *writeBehind) discard() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queue.Init()
+	w.pending = make(map[string]*list.Element)
+}
+
+// flushKeys 立即将指定键的待写入项写入远程缓存
+func (w *writeBehind) flushKeys(ctx context.Context, keys ...string) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	batch := make([]*pendingWrite, 0, len(keys))
+	for _, key := range keys {
+		if elem, ok := w.pending[key]; ok {
+			batch = append(batch, w.queue.Remove(elem).(*pendingWrite))
+			delete(w.pending, key)
+		}
+	}
+	w.mu.Unlock()
+
+	return w.write(ctx, batch)
+}
+
+// flushAll 将所有待写入项写入远程缓存，写入失败的项按重试规则重新排队
+// 只有在有项超过重试次数被放弃时才返回写入错误，重试后成功的写入不视为失败
+func (w *writeBehind) flushAll(ctx context.Context) error {
+	failed := w.failed.Load()
+
+	var lastErr error
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		flushed, err := w.flushBatch(ctx)
+		if isCircuitOpen(err) {
+			// 熔断期间的项保留在队列中，等待远程缓存恢复
+			return err
+		}
+		if err != nil {
+			lastErr = err
+		}
+		if !flushed {
+			break
+		}
+	}
+
+	if w.failed.Load() > failed {
+		return lastErr
+	}
+	return nil
+}
+
+// flushBatch 从队列头部取出一批写入远程缓存，队列为空时返回false
+func (w *writeBehind) flushBatch(ctx context.Context) (bool, error) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	batch := make([]*pendingWrite, 0, w.options.BatchSize)
+	for len(batch) < w.options.BatchSize {
+		elem := w.queue.Front()
+		if elem == nil {
+			break
+		}
+		write := w.queue.Remove(elem).(*pendingWrite)
+		delete(w.pending, write.key)
+		batch = append(batch, write)
+	}
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return false, nil
+	}
+	return true, w.write(ctx, batch)
+}
+
+// write 将一批写入远程缓存，调用方需持有flushMu
+func (w *writeBehind) write(ctx context.Context, batch []*pendingWrite) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	items := make(map[string]*Item, len(batch))
+	for _, write := range batch {
+		ttl, ok := write.remaining(now)
+		if !ok {
+			continue
+		}
+		items[write.key] = &Item{Value: write.value, Expiration: ttl, Tags: write.tags}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := SetMany(ctx, w.remote, items); err != nil {
+		w.requeue(batch, err)
+		return err
+	}
+
+	w.flushed.Add(uint64(len(items)))
+	if w.onFlushed != nil {
+		w.onFlushed(ctx, itemKeys(items)...)
+	}
+	return nil
+}
+
+// requeue 将写入失败的项重新排队，已有更新的写入或超过重试次数的项不再排队
+func (w *writeBehind) requeue(batch []*pendingWrite, err error) {
+	var dropped []string
+
+	w.mu.Lock()
+	for i := len(batch) - 1; i >= 0; i-- {
+		write := batch[i]
+		if _, superseded := w.pending[write.key]; superseded {
+			continue
+		}
+		// 熔断期间没有实际写入远程缓存，不计入重试次数
+		if !isCircuitOpen(err) {
+			write.attempts++
+		}
+		if write.attempts > w.options.MaxRetries {
+			dropped = append(dropped, write.key)
+			continue
+		}
+		// 放回队列头部，保持原有的写入顺序
+		w.pending[write.key] = w.queue.PushFront(write)
+		w.retried.Add(1)
+	}
+	w.mu.Unlock()
+
+	if len(dropped) > 0 {
+		w.failed.Add(uint64(len(dropped)))
+		if w.options.OnFlushError != nil {
+			w.options.OnFlushError(dropped, err)
+		}
+	}
+}
+
+// run 后台写入协程
+func (w *writeBehind) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+
+		// 写入失败时等待下一个周期再重试，避免远程缓存不可用时持续重试
+		for {
+			flushed, err := w.flushBatch(context.Background())
+			if !flushed || err != nil {
+				break
+			}
+		}
+	}
+}
+
+// stats 返回统计信息
+func (w *writeBehind) stats() WriteBackStats {
+	w.mu.Lock()
+	pending := len(w.pending)
+	w.mu.Unlock()
+
+	return WriteBackStats{
+		Pending:    pending,
+		Flushed:    w.flushed.Load(),
+		Coalesced:  w.coalesced.Load(),
+		Retried:    w.retried.Load(),
+		Failed:     w.failed.Load(),
+		Overflowed: w.overflowed.Load(),
+	}
+}
+
+// close 停止后台协程并写入剩余的项，重复调用是安全的
+func (w *writeBehind) close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), w.options.CloseTimeout)
+		defer cancel()
+		err = w.flushAll(ctx)
+	})
+	return err
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRemoteCache 在设置失败次数内写入失败的远程缓存
+type flakyRemoteCache struct {
+	*MemoryCache
+	failures atomic.Int32
+	writes   atomic.Int32
+}
+
+var errRemoteDown = errors.New("remote down")
+
+func (c *flakyRemoteCache) SetMany(ctx context.Context, items map[string]*Item) error {
+	c.writes.Add(1)
+	if c.failures.Add(-1) >= 0 {
+		return errRemoteDown
+	}
+	return c.MemoryCache.SetMany(ctx, items)
+}
+
+func newWriteBackCache(t *testing.T, local, remote Cache, opts WriteBackOptions) *MultiLevelCache {
+	multi, err := NewMultiLevelCache(local, remote, MultiLevelOptions{
+		WriteMode: WriteModeWriteBack,
+		LocalTTL:  time.Minute,
+		WriteBack: opts,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = multi.Close() })
+	return multi
+}
+
+func TestWriteBack_CoalescesWrites(t *testing.T) {
+	remote := &flakyRemoteCache{MemoryCache: NewMemoryCache()}
+	c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Set(ctx, "counter", i, time.Minute))
+	}
+
+	stats := c.WriteBackStats()
+	assert.Equal(t, 1, stats.Pending)
+	assert.Equal(t, uint64(9), stats.Coalesced)
+
+	require.NoError(t, c.FlushPending(ctx))
+	value, found := remote.Get(ctx, "counter")
+	require.True(t, found)
+	assert.Equal(t, 9, value)
+	assert.Equal(t, int32(1), remote.writes.Load())
+
+	stats = c.WriteBackStats()
+	assert.Equal(t, 0, stats.Pending)
+	assert.Equal(t, uint64(1), stats.Flushed)
+}
+
+func TestWriteBack_BackgroundFlush(t *testing.T) {
+	t.Run("interval", func(t *testing.T) {
+		remote := NewMemoryCache()
+		c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: 10 * time.Millisecond})
+
+		require.NoError(t, c.Set(context.Background(), "key", "value", time.Minute))
+		assert.Eventually(t, func() bool {
+			_, found := remote.Get(context.Background(), "key")
+			return found
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		remote := NewMemoryCache()
+		c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: time.Hour, BatchSize: 5})
+
+		ctx := context.Background()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, c.Set(ctx, fmt.Sprintf("key:%d", i), i, time.Minute))
+		}
+		assert.Eventually(t, func() bool {
+			return remote.Stats().Entries == 5
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestWriteBack_QueueOverflowWritesSynchronously(t *testing.T) {
+	remote := NewMemoryCache()
+	c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: time.Hour, QueueSize: 2})
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "a", 1, time.Minute))
+	require.NoError(t, c.Set(ctx, "b", 2, time.Minute))
+	require.NoError(t, c.Set(ctx, "c", 3, time.Minute))
+
+	_, found := remote.Get(ctx, "a")
+	assert.False(t, found)
+	value, found := remote.Get(ctx, "c")
+	assert.True(t, found)
+	assert.Equal(t, 3, value)
+
+	// 已在队列中的键仍然合并
+	require.NoError(t, c.Set(ctx, "a", 10, time.Minute))
+	stats := c.WriteBackStats()
+	assert.Equal(t, uint64(1), stats.Overflowed)
+	assert.Equal(t, uint64(1), stats.Coalesced)
+	assert.Equal(t, 2, stats.Pending)
+}
+
+func TestWriteBack_RetryOnRemoteFailure(t *testing.T) {
+	remote := &flakyRemoteCache{MemoryCache: NewMemoryCache()}
+	remote.failures.Store(2)
+
+	var dropped []string
+	c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+		OnFlushError:  func(keys []string, err error) { dropped = append(dropped, keys...) },
+	})
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+	require.NoError(t, c.FlushPending(ctx), "write should succeed within the retry budget")
+
+	value, found := remote.Get(ctx, "key")
+	require.True(t, found)
+	assert.Equal(t, "value", value)
+
+	stats := c.WriteBackStats()
+	assert.Equal(t, uint64(2), stats.Retried)
+	assert.Equal(t, uint64(0), stats.Failed)
+	assert.Empty(t, dropped)
+
+	// 超过重试次数后放弃写入
+	remote.failures.Store(100)
+	require.NoError(t, c.Set(ctx, "lost", "value", time.Minute))
+	assert.ErrorIs(t, c.FlushPending(ctx), errRemoteDown)
+
+	stats = c.WriteBackStats()
+	assert.Equal(t, uint64(1), stats.Failed)
+	assert.Equal(t, 0, stats.Pending)
+	assert.Equal(t, []string{"lost"}, dropped)
+}
+
+func TestWriteBack_CloseFlushesPending(t *testing.T) {
+	remote := &flakyRemoteCache{MemoryCache: NewMemoryCache()}
+	c, err := NewMultiLevelCache(NewMemoryCache(), remote, MultiLevelOptions{
+		WriteMode: WriteModeWriteBack,
+		LocalTTL:  time.Minute,
+		WriteBack: WriteBackOptions{FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+
+	require.NoError(t, c.Close())
+
+	// 内存缓存关闭后仍可读取
+	value, found := remote.Get(ctx, "key")
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+}
+
+func TestWriteBack_ConsistencyWithPendingWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads see pending values after local eviction", func(t *testing.T) {
+		local := NewMemoryCache(Options{MaxEntries: 1})
+		c := newWriteBackCache(t, local, NewMemoryCache(), WriteBackOptions{FlushInterval: time.Hour})
+
+		require.NoError(t, c.Set(ctx, "a", "1", time.Minute))
+		require.NoError(t, c.Set(ctx, "b", "2", time.Minute))
+		_, found := local.Get(ctx, "a")
+		require.False(t, found, "a should be evicted from the local layer")
+
+		value, found := c.Get(ctx, "a")
+		assert.True(t, found)
+		assert.Equal(t, "1", value)
+
+		values, err := c.GetMany(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("delete cancels pending write", func(t *testing.T) {
+		remote := NewMemoryCache()
+		c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: time.Hour})
+
+		require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+		require.NoError(t, c.Delete(ctx, "key"))
+		require.NoError(t, c.FlushPending(ctx))
+
+		_, found := remote.Get(ctx, "key")
+		assert.False(t, found)
+		_, found = c.Get(ctx, "key")
+		assert.False(t, found)
+	})
+
+	t.Run("delete by tag cancels pending write", func(t *testing.T) {
+		remote := NewMemoryCache()
+		c := newWriteBackCache(t, NewMemoryCache(), remote, WriteBackOptions{FlushInterval: time.Hour})
+
+		require.NoError(t, c.SetItem(ctx, "key", &Item{Value: "value", Expiration: time.Minute, Tags: []string{"t"}}))
+		require.NoError(t, c.DeleteByTag(ctx, "t"))
+		require.NoError(t, c.FlushPending(ctx))
+
+		_, found := remote.Get(ctx, "key")
+		assert.False(t, found)
+	})
+
+	t.Run("increment sees pending value", func(t *testing.T) {
+		c := newWriteBackCache(t, NewMemoryCache(), NewMemoryCache(), WriteBackOptions{FlushInterval: time.Hour})
+
+		require.NoError(t, c.Set(ctx, "counter", int64(5), time.Minute))
+		result, err := c.Increment(ctx, "counter", 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), result)
+	})
+}
@@ -0,0 +1,458 @@
+package dbstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/guanzhenxing/go-snap/cache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// CacheOptions 缓存仓储选项
+type CacheOptions struct {
+	// TTL 缓存项的默认生存时间，默认10分钟
+	TTL time.Duration
+
+	// KeyPrefix 缓存键前缀，默认"dbstore"
+	// 按主键查询的键为"<KeyPrefix>:<表名>:<主键>"，按条件查询的键为"<KeyPrefix>:<表名>:query:<条件哈希>"
+	KeyPrefix string
+
+	// OnError 读写缓存出错时的回调
+	// 缓存出错不会影响数据库操作的结果，读取出错时直接查询数据库
+	OnError func(err error)
+}
+
+// DefaultCacheOptions 返回默认的缓存仓储选项
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		TTL:       time.Minute * 10,
+		KeyPrefix: "dbstore",
+	}
+}
+
+// ModelCacheOptions 单个模型的缓存选项
+type ModelCacheOptions struct {
+	// TTL 该模型缓存项的生存时间，0表示使用CacheOptions.TTL
+	TTL time.Duration
+
+	// Disabled 是否禁用该模型的缓存
+	Disabled bool
+
+	// DisableQueryCache 是否禁用FindOneBy的缓存，只缓存FindByID
+	DisableQueryCache bool
+}
+
+// CachedRepository 旁路缓存仓储
+// 包装BaseRepository，将FindByID和FindOneBy的结果缓存在任意cache.Cache中：
+//   - 缓存键由模型的表名和主键（或查询条件）生成，每个缓存项带有表级标签
+//   - Save、Update、Delete、DeleteByID删除对应主键的缓存项和该表所有条件查询的缓存项
+//   - UpdateBy、DeleteBy可能影响任意行，删除该表的所有缓存项
+//   - Create、CreateInBatches删除该表所有条件查询的缓存项
+//
+// 通过Transaction执行的写操作在事务提交后才使缓存失效，回滚时不做任何操作；
+// 事务内的读取直接查询数据库，不读写缓存。直接使用Store.Transaction时缓存不会感知事务
+//
+// 示例：
+//
+//	repo := dbstore.NewCachedRepository(store, redisCache)
+//	repo.ConfigureModel(&User{}, dbstore.ModelCacheOptions{TTL: time.Hour})
+//
+//	var user User
+//	err := repo.FindByID(ctx, 1, &user) // 第二次读取命中缓存
+//
+//	err = repo.Transaction(ctx, func(tx *dbstore.CachedRepository) error {
+//	    user.Name = "新名称"
+//	    return tx.Save(ctx, &user) // 提交后删除缓存
+//	})
+type CachedRepository struct {
+	Repository
+
+	store   *Store
+	cache   cache.Cache
+	options CacheOptions
+	models  *modelCacheRegistry
+
+	// tx 事务内的待失效项，不在事务中时为nil
+	tx *pendingInvalidation
+}
+
+// modelCacheRegistry 按表名保存的模型缓存选项，事务仓储与原仓储共享
+type modelCacheRegistry struct {
+	mu      sync.RWMutex
+	options map[string]ModelCacheOptions
+}
+
+// pendingInvalidation 事务提交后需要失效的缓存
+type pendingInvalidation struct {
+	mu   sync.Mutex
+	keys []string
+	tags []string
+}
+
+func (p *pendingInvalidation) add(keys, tags []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, keys...)
+	p.tags = append(p.tags, tags...)
+}
+
+// NewCachedRepository 创建旁路缓存仓储
+// 参数：
+//
+//	store: 数据库存储实例
+//	c: 缓存实例，可以是MemoryCache、RedisCache、MultiLevelCache等任意实现
+//	opts: 缓存选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewCachedRepository(store *Store, c cache.Cache, opts ...CacheOptions) *CachedRepository {
+	defaults := DefaultCacheOptions()
+	options := defaults
+	if len(opts) > 0 {
+		options = opts[0]
+		if options.TTL <= 0 {
+			options.TTL = defaults.TTL
+		}
+		if options.KeyPrefix == "" {
+			options.KeyPrefix = defaults.KeyPrefix
+		}
+	}
+
+	return &CachedRepository{
+		Repository: NewRepository(store),
+		store:      store,
+		cache:      c,
+		options:    options,
+		models:     &modelCacheRegistry{options: make(map[string]ModelCacheOptions)},
+	}
+}
+
+// ConfigureModel 设置模型的缓存选项
+// 参数：
+//
+//	model: 模型实例，用于确定表名，例如&User{}
+//	opts: 该模型的缓存选项
+func (r *CachedRepository) ConfigureModel(model interface{}, opts ModelCacheOptions) error {
+	schema, err := r.schema(model)
+	if err != nil {
+		return err
+	}
+
+	r.models.mu.Lock()
+	defer r.models.mu.Unlock()
+	r.models.options[schema.Table] = opts
+	return nil
+}
+
+// Transaction 在事务中执行fn，fn中的写操作在事务提交后才使缓存失效
+// 在事务仓储上再次调用Transaction会创建保存点，失效操作延迟到最外层事务提交
+func (r *CachedRepository) Transaction(ctx context.Context, fn func(tx *CachedRepository) error) error {
+	pending := &pendingInvalidation{}
+	err := r.store.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txStore := &Store{db: tx, config: r.store.config, log: r.store.log}
+		return fn(&CachedRepository{
+			Repository: NewRepository(txStore),
+			store:      txStore,
+			cache:      r.cache,
+			options:    r.options,
+			models:     r.models,
+			tx:         pending,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.tx != nil {
+		r.tx.add(pending.keys, pending.tags)
+		return nil
+	}
+	r.invalidateNow(ctx, pending.keys, pending.tags)
+	return nil
+}
+
+// FindByID 通过ID查找记录，优先读取缓存
+func (r *CachedRepository) FindByID(ctx context.Context, id interface{}, result interface{}) error {
+	table, opts, ok := r.cacheable(result)
+	if !ok {
+		return r.Repository.FindByID(ctx, id, result)
+	}
+
+	key := r.idKey(table, id)
+	if r.fromCache(ctx, key, result) {
+		return nil
+	}
+
+	if err := r.Repository.FindByID(ctx, id, result); err != nil {
+		return err
+	}
+	r.toCache(ctx, key, result, opts, r.tableTag(table))
+	return nil
+}
+
+// FindOneBy 通过条件查找单个记录，优先读取缓存
+func (r *CachedRepository) FindOneBy(ctx context.Context, query interface{}, args []interface{}, result interface{}) error {
+	table, opts, ok := r.cacheable(result)
+	if !ok || opts.DisableQueryCache {
+		return r.Repository.FindOneBy(ctx, query, args, result)
+	}
+
+	key := r.queryKey(table, query, args)
+	if r.fromCache(ctx, key, result) {
+		return nil
+	}
+
+	if err := r.Repository.FindOneBy(ctx, query, args, result); err != nil {
+		return err
+	}
+	r.toCache(ctx, key, result, opts, r.tableTag(table), r.queryTag(table))
+	return nil
+}
+
+// Create 创建记录，并使该表条件查询的缓存失效
+func (r *CachedRepository) Create(ctx context.Context, model interface{}) error {
+	if err := r.Repository.Create(ctx, model); err != nil {
+		return err
+	}
+	r.invalidateQueries(ctx, model)
+	return nil
+}
+
+// CreateInBatches 批量创建记录，并使该表条件查询的缓存失效
+func (r *CachedRepository) CreateInBatches(ctx context.Context, models interface{}, batchSize int) error {
+	if err := r.Repository.CreateInBatches(ctx, models, batchSize); err != nil {
+		return err
+	}
+	r.invalidateQueries(ctx, models)
+	return nil
+}
+
+// Save 保存记录，并使该记录和该表条件查询的缓存失效
+func (r *CachedRepository) Save(ctx context.Context, model interface{}) error {
+	if err := r.Repository.Save(ctx, model); err != nil {
+		return err
+	}
+	r.invalidateModel(ctx, model)
+	return nil
+}
+
+// Update 更新记录，并使该记录和该表条件查询的缓存失效
+func (r *CachedRepository) Update(ctx context.Context, model interface{}) error {
+	if err := r.Repository.Update(ctx, model); err != nil {
+		return err
+	}
+	r.invalidateModel(ctx, model)
+	return nil
+}
+
+// Delete 删除记录，并使该记录和该表条件查询的缓存失效
+func (r *CachedRepository) Delete(ctx context.Context, model interface{}) error {
+	if err := r.Repository.Delete(ctx, model); err != nil {
+		return err
+	}
+	r.invalidateModel(ctx, model)
+	return nil
+}
+
+// DeleteByID 通过ID删除记录，并使该记录和该表条件查询的缓存失效
+// id可以是单个主键或主键切片
+func (r *CachedRepository) DeleteByID(ctx context.Context, model interface{}, id interface{}) error {
+	if err := r.Repository.DeleteByID(ctx, model, id); err != nil {
+		return err
+	}
+
+	schema, err := r.schema(model)
+	if err != nil {
+		r.reportError(err)
+		return nil
+	}
+
+	var keys []string
+	if v := reflect.ValueOf(id); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			keys = append(keys, r.idKey(schema.Table, v.Index(i).Interface()))
+		}
+	} else {
+		keys = append(keys, r.idKey(schema.Table, id))
+	}
+	r.invalidate(ctx, keys, []string{r.queryTag(schema.Table)})
+	return nil
+}
+
+// DeleteBy 通过条件删除记录，并使该表的所有缓存失效
+func (r *CachedRepository) DeleteBy(ctx context.Context, model interface{}, query interface{}, args []interface{}) error {
+	if err := r.Repository.DeleteBy(ctx, model, query, args); err != nil {
+		return err
+	}
+	r.invalidateTable(ctx, model)
+	return nil
+}
+
+// UpdateBy 通过条件更新记录，并使该表的所有缓存失效
+func (r *CachedRepository) UpdateBy(ctx context.Context, model interface{}, values map[string]interface{}, query interface{}, args []interface{}) error {
+	if err := r.Repository.UpdateBy(ctx, model, values, query, args); err != nil {
+		return err
+	}
+	r.invalidateTable(ctx, model)
+	return nil
+}
+
+// InvalidateTable 使模型对应表的所有缓存失效，用于绕过仓储直接修改数据之后
+func (r *CachedRepository) InvalidateTable(ctx context.Context, model interface{}) {
+	r.invalidateTable(ctx, model)
+}
+
+// cacheable 判断结果类型是否需要缓存，返回表名和模型缓存选项
+// 事务中的读取可能看到未提交的数据，不读写缓存
+func (r *CachedRepository) cacheable(result interface{}) (string, ModelCacheOptions, bool) {
+	if r.tx != nil {
+		return "", ModelCacheOptions{}, false
+	}
+	schema, err := r.schema(result)
+	if err != nil {
+		return "", ModelCacheOptions{}, false
+	}
+
+	r.models.mu.RLock()
+	opts := r.models.options[schema.Table]
+	r.models.mu.RUnlock()
+	if opts.Disabled {
+		return "", opts, false
+	}
+	return schema.Table, opts, true
+}
+
+// fromCache 从缓存读取记录，读取或解码出错时视为未命中
+func (r *CachedRepository) fromCache(ctx context.Context, key string, result interface{}) bool {
+	found, err := cache.GetInto(ctx, r.cache, key, result)
+	if err != nil {
+		r.reportError(err)
+		return false
+	}
+	return found
+}
+
+// toCache 缓存记录的副本，避免调用方修改结果时影响缓存
+func (r *CachedRepository) toCache(ctx context.Context, key string, result interface{}, opts ModelCacheOptions, tags ...string) {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = r.options.TTL
+	}
+
+	value := reflect.ValueOf(result)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	item := &cache.Item{Value: value.Interface(), Expiration: ttl, Tags: tags}
+	if err := r.cache.SetItem(ctx, key, item); err != nil {
+		r.reportError(err)
+	}
+}
+
+// invalidateModel 使单条记录和该表条件查询的缓存失效
+// 模型没有单一主键或主键为零值时只使条件查询的缓存失效
+func (r *CachedRepository) invalidateModel(ctx context.Context, model interface{}) {
+	schema, err := r.schema(model)
+	if err != nil {
+		r.reportError(err)
+		return
+	}
+
+	tags := []string{r.queryTag(schema.Table)}
+	if len(schema.PrimaryFields) != 1 {
+		// 复合主键的记录无法通过FindByID缓存，但可能出现在条件查询中
+		r.invalidate(ctx, nil, tags)
+		return
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(model))
+	if value.Kind() != reflect.Struct {
+		r.invalidate(ctx, nil, []string{r.tableTag(schema.Table)})
+		return
+	}
+	id, zero := schema.PrimaryFields[0].ValueOf(ctx, value)
+	if zero {
+		r.invalidate(ctx, nil, tags)
+		return
+	}
+	r.invalidate(ctx, []string{r.idKey(schema.Table, id)}, tags)
+}
+
+// invalidateQueries 使该表条件查询的缓存失效
+func (r *CachedRepository) invalidateQueries(ctx context.Context, model interface{}) {
+	schema, err := r.schema(model)
+	if err != nil {
+		r.reportError(err)
+		return
+	}
+	r.invalidate(ctx, nil, []string{r.queryTag(schema.Table)})
+}
+
+// invalidateTable 使该表的所有缓存失效
+func (r *CachedRepository) invalidateTable(ctx context.Context, model interface{}) {
+	schema, err := r.schema(model)
+	if err != nil {
+		r.reportError(err)
+		return
+	}
+	r.invalidate(ctx, nil, []string{r.tableTag(schema.Table)})
+}
+
+// invalidate 使缓存失效，事务中延迟到提交之后
+func (r *CachedRepository) invalidate(ctx context.Context, keys, tags []string) {
+	if r.tx != nil {
+		r.tx.add(keys, tags)
+		return
+	}
+	r.invalidateNow(ctx, keys, tags)
+}
+
+func (r *CachedRepository) invalidateNow(ctx context.Context, keys, tags []string) {
+	if len(keys) > 0 {
+		if err := cache.DeleteMany(ctx, r.cache, keys); err != nil {
+			r.reportError(err)
+		}
+	}
+	for _, tag := range tags {
+		if err := r.cache.DeleteByTag(ctx, tag); err != nil {
+			r.reportError(err)
+		}
+	}
+}
+
+// schema 解析模型的表结构，GORM会缓存解析结果
+func (r *CachedRepository) schema(model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.store.DB()}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+	}
+	return stmt.Schema, nil
+}
+
+func (r *CachedRepository) idKey(table string, id interface{}) string {
+	return fmt.Sprintf("%s:%s:%v", r.options.KeyPrefix, table, id)
+}
+
+func (r *CachedRepository) queryKey(table string, query interface{}, args []interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v\x00%v", query, args)))
+	return fmt.Sprintf("%s:%s:query:%s", r.options.KeyPrefix, table, hex.EncodeToString(sum[:16]))
+}
+
+// tableTag 表中所有缓存项共有的标签
+func (r *CachedRepository) tableTag(table string) string {
+	return r.options.KeyPrefix + ":" + table
+}
+
+// queryTag 表中条件查询缓存项的标签
+func (r *CachedRepository) queryTag(table string) string {
+	return r.options.KeyPrefix + ":" + table + ":query"
+}
+
+func (r *CachedRepository) reportError(err error) {
+	if r.options.OnError != nil {
+		r.options.OnError(err)
+	}
+}
@@ -0,0 +1,182 @@
+package dbstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guanzhenxing/go-snap/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// 缓存仓储的测试模型
+type CachedTestUser struct {
+	ID    uint   `gorm:"primarykey"`
+	Name  string `gorm:"size:100;not null"`
+	Email string `gorm:"size:100"`
+}
+
+func setupCachedRepository(t *testing.T) (*Store, *CachedRepository, cache.Cache) {
+	config := DefaultConfig()
+	config.Driver = "sqlite"
+	config.DSN = "file:" + t.Name() + "?mode=memory&cache=shared"
+	config.SingularTable = true
+	config.TablePrefix = "test_"
+
+	store, err := New(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	require.NoError(t, store.Migrate(&CachedTestUser{}))
+
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	repo := NewCachedRepository(store, c, CacheOptions{
+		OnError: func(err error) { t.Errorf("unexpected cache error: %v", err) },
+	})
+	return store, repo, c
+}
+
+// renameDirectly 绕过仓储修改数据库，用于判断读取是否来自缓存
+func renameDirectly(t *testing.T, store *Store, id uint, name string) {
+	require.NoError(t, store.DB().Model(&CachedTestUser{}).Where("id = ?", id).Update("name", name).Error)
+}
+
+func TestCachedRepository_FindByID(t *testing.T) {
+	store, repo, c := setupCachedRepository(t)
+	ctx := context.Background()
+	require.Implements(t, (*Repository)(nil), repo)
+
+	user := &CachedTestUser{Name: "alice"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	var found CachedTestUser
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+	assert.Equal(t, "alice", found.Name)
+
+	exists, err := c.Exists(ctx, "dbstore:test_cached_test_user:1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	renameDirectly(t, store, user.ID, "changed")
+	var cached CachedTestUser
+	require.NoError(t, repo.FindByID(ctx, user.ID, &cached))
+	assert.Equal(t, "alice", cached.Name, "second read should be served from cache")
+
+	var missing CachedTestUser
+	err = repo.FindByID(ctx, 999, &missing)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestCachedRepository_FindOneBy(t *testing.T) {
+	store, repo, _ := setupCachedRepository(t)
+	ctx := context.Background()
+
+	user := &CachedTestUser{Name: "bob", Email: "bob@example.com"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	var found CachedTestUser
+	require.NoError(t, repo.FindOneBy(ctx, "email = ?", []interface{}{"bob@example.com"}, &found))
+	assert.Equal(t, "bob", found.Name)
+
+	renameDirectly(t, store, user.ID, "changed")
+	require.NoError(t, repo.FindOneBy(ctx, "email = ?", []interface{}{"bob@example.com"}, &found))
+	assert.Equal(t, "bob", found.Name)
+
+	// 创建记录可能改变条件查询的结果
+	require.NoError(t, repo.Create(ctx, &CachedTestUser{Name: "carol"}))
+	require.NoError(t, repo.FindOneBy(ctx, "email = ?", []interface{}{"bob@example.com"}, &found))
+	assert.Equal(t, "changed", found.Name)
+}
+
+func TestCachedRepository_InvalidateOnWrite(t *testing.T) {
+	store, repo, _ := setupCachedRepository(t)
+	ctx := context.Background()
+
+	user := &CachedTestUser{Name: "alice"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	read := func() CachedTestUser {
+		var found CachedTestUser
+		require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+		return found
+	}
+
+	read()
+	user.Name = "saved"
+	require.NoError(t, repo.Save(ctx, user))
+	assert.Equal(t, "saved", read().Name)
+
+	renameDirectly(t, store, user.ID, "by-condition")
+	require.NoError(t, repo.UpdateBy(ctx, &CachedTestUser{}, map[string]interface{}{"email": "x"}, "id = ?", []interface{}{user.ID}))
+	assert.Equal(t, "by-condition", read().Name)
+
+	require.NoError(t, repo.DeleteByID(ctx, &CachedTestUser{}, user.ID))
+	var found CachedTestUser
+	assert.True(t, errors.Is(repo.FindByID(ctx, user.ID, &found), gorm.ErrRecordNotFound))
+}
+
+func TestCachedRepository_Transaction(t *testing.T) {
+	_, repo, _ := setupCachedRepository(t)
+	ctx := context.Background()
+
+	user := &CachedTestUser{Name: "alice"}
+	require.NoError(t, repo.Create(ctx, user))
+	var found CachedTestUser
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+
+	// 回滚时保留缓存
+	rollback := errors.New("rollback")
+	err := repo.Transaction(ctx, func(tx *CachedRepository) error {
+		require.NoError(t, tx.UpdateBy(ctx, &CachedTestUser{}, map[string]interface{}{"name": "rolled back"}, "id = ?", []interface{}{user.ID}))
+		return rollback
+	})
+	assert.ErrorIs(t, err, rollback)
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+	assert.Equal(t, "alice", found.Name)
+
+	// 提交之前缓存不失效，提交之后失效
+	err = repo.Transaction(ctx, func(tx *CachedRepository) error {
+		user.Name = "committed"
+		if err := tx.Save(ctx, user); err != nil {
+			return err
+		}
+
+		var inside CachedTestUser
+		require.NoError(t, tx.FindByID(ctx, user.ID, &inside))
+		assert.Equal(t, "committed", inside.Name, "reads inside the transaction bypass the cache")
+
+		return tx.Transaction(ctx, func(nested *CachedRepository) error {
+			return nested.UpdateBy(ctx, &CachedTestUser{}, map[string]interface{}{"email": "nested"}, "id = ?", []interface{}{user.ID})
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+	assert.Equal(t, "committed", found.Name)
+	assert.Equal(t, "nested", found.Email)
+}
+
+func TestCachedRepository_ConfigureModel(t *testing.T) {
+	store, repo, c := setupCachedRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.ConfigureModel(&CachedTestUser{}, ModelCacheOptions{Disabled: true}))
+
+	user := &CachedTestUser{Name: "alice"}
+	require.NoError(t, repo.Create(ctx, user))
+	var found CachedTestUser
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+
+	renameDirectly(t, store, user.ID, "changed")
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+	assert.Equal(t, "changed", found.Name)
+
+	require.NoError(t, repo.ConfigureModel(&CachedTestUser{}, ModelCacheOptions{TTL: time.Hour}))
+	require.NoError(t, repo.FindByID(ctx, user.ID, &found))
+	_, ttl, ok := c.GetWithTTL(ctx, "dbstore:test_cached_test_user:1")
+	require.True(t, ok)
+	assert.Greater(t, ttl, 50*time.Minute)
+}
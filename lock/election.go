@@ -0,0 +1,280 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ElectionOptions 领导者选举选项
+type ElectionOptions struct {
+	// 租约时长，领导者在此时间内未续约则失去领导权
+	LeaseDuration time.Duration
+
+	// 领导者续约的间隔，应明显小于租约时长
+	RenewInterval time.Duration
+
+	// 安全余量，领导者从最近一次成功续约的请求发出起，经过LeaseDuration-SafetyMargin仍未续约成功时卸任，
+	// 保证在Redis中的租约过期、其他副本可能当选之前不再认为自己是领导者。
+	// 默认等于RenewInterval，应大于Redis往返时间与各节点时钟速率误差之和
+	SafetyMargin time.Duration
+
+	// 竞选失败后重试的间隔
+	RetryInterval time.Duration
+
+	// 参选者标识，作为锁的值写入Redis，默认为主机名、进程号和时间戳的组合
+	Identity string
+
+	// 当选领导者时的回调
+	OnElected func()
+
+	// 失去领导权时的回调，包括租约被抢占、续约超时和主动卸任
+	OnLost func()
+}
+
+// DefaultElectionOptions 返回默认的领导者选举选项
+func DefaultElectionOptions() ElectionOptions {
+	return ElectionOptions{
+		LeaseDuration: time.Second * 15,
+		RenewInterval: time.Second * 5,
+		RetryInterval: time.Second * 2,
+	}
+}
+
+// Elector 基于Redis租约的领导者选举器
+// 多个副本使用相同的键竞选，同一时刻最多只有一个副本持有租约成为领导者，
+// 领导者定期续约，续约失败或租约被他人持有时立即失去领导权并重新参选
+type Elector struct {
+	client  redis.UniversalClient
+	key     string
+	options ElectionOptions
+	lock    *RedisLock
+
+	leader  atomic.Bool
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped bool
+}
+
+// NewElector 创建领导者选举器
+func NewElector(client redis.UniversalClient, key string, opts ...ElectionOptions) *Elector {
+	var options ElectionOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	} else {
+		options = DefaultElectionOptions()
+	}
+
+	defaults := DefaultElectionOptions()
+	if options.LeaseDuration <= 0 {
+		options.LeaseDuration = defaults.LeaseDuration
+	}
+	if options.RenewInterval <= 0 || options.RenewInterval >= options.LeaseDuration {
+		options.RenewInterval = options.LeaseDuration / 3
+	}
+	if options.SafetyMargin <= 0 || options.SafetyMargin >= options.LeaseDuration {
+		options.SafetyMargin = options.RenewInterval
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaults.RetryInterval
+	}
+	if options.Identity == "" {
+		hostname, _ := os.Hostname()
+		options.Identity = fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+	}
+
+	return &Elector{
+		client:  client,
+		key:     key,
+		options: options,
+		lock: NewRedisLock(client, key, Options{
+			Expiration:    options.LeaseDuration,
+			RetryInterval: options.RetryInterval,
+			RandomValue:   options.Identity,
+		}),
+	}
+}
+
+// Start 开始竞选，竞选和续约在后台goroutine中进行
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return ErrElectorStopped
+	}
+	if e.cancel != nil {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.run(runCtx)
+	return nil
+}
+
+// Stop 停止竞选，如果当前是领导者则释放租约主动卸任
+func (e *Elector) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	e.stopped = true
+	cancel, done := e.cancel, e.done
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if !e.leader.Load() {
+		return nil
+	}
+
+	_, err := e.lock.Release(ctx)
+	e.stepDown()
+	if err != nil {
+		return fmt.Errorf("failed to step down: %w", err)
+	}
+	return nil
+}
+
+// IsLeader 当前是否为领导者
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Identity 返回参选者标识
+func (e *Elector) Identity() string {
+	return e.options.Identity
+}
+
+// Key 返回选举使用的键
+func (e *Elector) Key() string {
+	return e.key
+}
+
+// Leader 返回当前领导者的标识，没有领导者时返回空字符串
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	identity, err := e.client.Get(ctx, e.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get leader: %w", err)
+	}
+	return identity, nil
+}
+
+// run 竞选主循环
+func (e *Elector) run(ctx context.Context) {
+	defer close(e.done)
+
+	for {
+		// 租约从请求发出时开始计算，响应延迟不会推迟卸任时间
+		sent := time.Now()
+		acquired, err := e.lock.tryAcquire(ctx)
+		if ctx.Err() != nil {
+			if acquired {
+				// 停止时恰好当选，释放租约避免其他副本等待过期
+				_, _ = e.lock.Release(context.WithoutCancel(ctx))
+			}
+			return
+		}
+		if err != nil || !acquired {
+			// 租约被他人持有或Redis不可用时等待后重试
+			if !e.sleep(ctx, e.options.RetryInterval) {
+				return
+			}
+			continue
+		}
+
+		e.leader.Store(true)
+		if e.options.OnElected != nil {
+			e.options.OnElected()
+		}
+
+		if !e.renew(ctx, sent) {
+			// 停止时保留领导者状态，由Stop释放租约后卸任
+			return
+		}
+		e.stepDown()
+	}
+}
+
+// leaseDeadline 返回在sent时发出的获取或续约请求成功后，领导者必须卸任的时间
+func (e *Elector) leaseDeadline(sent time.Time) time.Time {
+	return sent.Add(e.options.LeaseDuration - e.options.SafetyMargin)
+}
+
+// renew 周期性续约，租约丢失或即将到期时返回true，上下文结束时返回false
+// acquired为获取租约的请求发出的时间
+func (e *Elector) renew(ctx context.Context, acquired time.Time) bool {
+	ticker := time.NewTicker(e.options.RenewInterval)
+	defer ticker.Stop()
+
+	deadline := e.leaseDeadline(acquired)
+	expiry := time.NewTimer(time.Until(deadline))
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-expiry.C:
+			// 续约持续失败，在Redis中的租约过期前卸任，避免出现两个领导者
+			return true
+		case <-ticker.C:
+		}
+
+		sent := time.Now()
+		refreshCtx, cancel := context.WithDeadline(ctx, deadline)
+		ok, err := e.lock.Refresh(refreshCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return false
+		}
+		if err == nil && ok {
+			deadline = e.leaseDeadline(sent)
+			expiry.Reset(time.Until(deadline))
+			continue
+		}
+		// 租约已被他人持有时立即卸任；网络错误时在卸任时间之前继续尝试
+		if err == nil || !time.Now().Before(deadline) {
+			return true
+		}
+	}
+}
+
+// stepDown 失去领导权
+func (e *Elector) stepDown() {
+	if e.leader.CompareAndSwap(true, false) && e.options.OnLost != nil {
+		e.options.OnLost()
+	}
+}
+
+// sleep 等待指定时间，上下文结束时返回false
+func (e *Elector) sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
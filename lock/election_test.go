@@ -0,0 +1,136 @@
+package lock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 创建测试用的选举选项
+func testElectionOptions(identity string, elected, lost *atomic.Int32) ElectionOptions {
+	return ElectionOptions{
+		LeaseDuration: time.Second,
+		RenewInterval: time.Millisecond * 20,
+		RetryInterval: time.Millisecond * 20,
+		Identity:      identity,
+		OnElected:     func() { elected.Add(1) },
+		OnLost:        func() { lost.Add(1) },
+	}
+}
+
+// 测试单个参选者当选并主动卸任
+func TestElectorElectAndStepDown(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	var elected, lost atomic.Int32
+	elector := NewElector(client, "test-leader", testElectionOptions("node-1", &elected, &lost))
+	assert.Equal(t, "node-1", elector.Identity())
+	assert.False(t, elector.IsLeader())
+
+	assert.NoError(t, elector.Start(context.Background()))
+	assert.Eventually(t, elector.IsLeader, time.Second, time.Millisecond*10)
+	assert.Equal(t, int32(1), elected.Load())
+
+	leader, err := elector.Leader(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "node-1", leader)
+
+	// 续约应保持租约
+	time.Sleep(time.Millisecond * 100)
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, int32(0), lost.Load())
+
+	// 卸任后释放租约
+	assert.NoError(t, elector.Stop(context.Background()))
+	assert.False(t, elector.IsLeader())
+	assert.Equal(t, int32(1), lost.Load())
+	assert.False(t, mr.Exists("test-leader"))
+
+	// 停止后不能再次启动
+	assert.ErrorIs(t, elector.Start(context.Background()), ErrElectorStopped)
+	assert.NoError(t, elector.Stop(context.Background()))
+}
+
+// 测试多个参选者中只有一个领导者，领导者卸任后其他参选者接任
+func TestElectorFailover(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	var elected1, lost1, elected2, lost2 atomic.Int32
+	e1 := NewElector(client, "test-leader", testElectionOptions("node-1", &elected1, &lost1))
+	e2 := NewElector(client, "test-leader", testElectionOptions("node-2", &elected2, &lost2))
+
+	assert.NoError(t, e1.Start(context.Background()))
+	assert.Eventually(t, e1.IsLeader, time.Second, time.Millisecond*10)
+	assert.NoError(t, e2.Start(context.Background()))
+	defer e2.Stop(context.Background())
+
+	time.Sleep(time.Millisecond * 100)
+	assert.False(t, e2.IsLeader())
+
+	assert.NoError(t, e1.Stop(context.Background()))
+	assert.Eventually(t, e2.IsLeader, time.Second, time.Millisecond*10)
+
+	leader, err := e2.Leader(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "node-2", leader)
+	assert.Equal(t, int32(1), lost1.Load())
+	assert.Equal(t, int32(0), lost2.Load())
+}
+
+// 测试租约过期后领导者失去领导权并重新参选
+func TestElectorLeaseExpiry(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	var elected, lost atomic.Int32
+	elector := NewElector(client, "test-leader", testElectionOptions("node-1", &elected, &lost))
+	assert.NoError(t, elector.Start(context.Background()))
+	defer elector.Stop(context.Background())
+	assert.Eventually(t, elector.IsLeader, time.Second, time.Millisecond*10)
+
+	// 模拟租约过期
+	mr.FastForward(time.Second * 2)
+	assert.Eventually(t, func() bool { return lost.Load() == 1 }, time.Second, time.Millisecond*10)
+
+	// 没有其他参选者时重新当选
+	assert.Eventually(t, func() bool { return elected.Load() == 2 && elector.IsLeader() }, time.Second, time.Millisecond*10)
+
+	// 模拟租约被其他副本持有
+	mr.Set("test-leader", "intruder")
+	assert.Eventually(t, func() bool { return lost.Load() == 2 }, time.Second, time.Millisecond*10)
+	assert.False(t, elector.IsLeader())
+}
+
+// 测试续约持续失败时领导者在Redis中的租约过期前卸任
+func TestElectorStepsDownBeforeLeaseExpires(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	var elected, lost atomic.Int32
+	opts := testElectionOptions("node-1", &elected, &lost)
+	opts.LeaseDuration = time.Millisecond * 500
+	opts.RenewInterval = time.Millisecond * 100
+	opts.SafetyMargin = time.Millisecond * 200
+	elector := NewElector(client, "test-leader", opts)
+	assert.NoError(t, elector.Start(context.Background()))
+	defer elector.Stop(context.Background())
+	assert.Eventually(t, elector.IsLeader, time.Second, time.Millisecond*10)
+
+	// 之后的续约全部失败，最近一次成功续约的请求在此之前发出，租约最晚在此后LeaseDuration过期
+	failedAt := time.Now()
+	mr.SetError("connection lost")
+	defer mr.SetError("")
+
+	for elector.IsLeader() {
+		if !assert.Less(t, time.Since(failedAt), opts.LeaseDuration, "leader must step down before the lease expires") {
+			return
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	assert.Equal(t, int32(1), lost.Load())
+}
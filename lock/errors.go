@@ -15,4 +15,7 @@ var (
 
 	// ErrLockNotHeld 表示未持有锁
 	ErrLockNotHeld = errors.New("lock is not held")
+
+	// ErrElectorStopped 表示选举器已停止
+	ErrElectorStopped = errors.New("elector is stopped")
 )
@@ -43,14 +43,12 @@ func (l *RedisLock) Acquire(ctx context.Context) (bool, error) {
 	var retries int
 
 	for {
-		// 尝试使用SET NX命令获取锁
-		ok, err := l.client.SetNX(ctx, l.key, l.options.RandomValue, l.options.Expiration).Result()
+		ok, err := l.tryAcquire(ctx)
 		if err != nil {
-			return false, fmt.Errorf("failed to acquire lock: %w", err)
+			return false, err
 		}
 
 		if ok {
-			l.isAcquired = true
 			return true, nil
 		}
 
@@ -69,6 +67,18 @@ func (l *RedisLock) Acquire(ctx context.Context) (bool, error) {
 	}
 }
 
+// tryAcquire 使用SET NX尝试获取一次锁
+func (l *RedisLock) tryAcquire(ctx context.Context) (bool, error) {
+	ok, err := l.client.SetNX(ctx, l.key, l.options.RandomValue, l.options.Expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if ok {
+		l.isAcquired = true
+	}
+	return ok, nil
+}
+
 // Release 释放锁，只有持有锁的客户端才能释放
 func (l *RedisLock) Release(ctx context.Context) (bool, error) {
 	if !l.isAcquired {
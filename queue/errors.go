@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"github.com/guanzhenxing/go-snap/errors"
+)
+
+// 任务队列相关错误定义
+var (
+	// ErrNoHandler 表示任务类型没有注册处理器
+	ErrNoHandler = errors.New("no handler registered for job type")
+
+	// ErrManagerStopped 表示任务管理器已停止，不再接受新任务
+	ErrManagerStopped = errors.New("job manager is stopped")
+
+	// ErrManagerRunning 表示任务管理器已经在运行
+	ErrManagerRunning = errors.New("job manager is already running")
+
+	// ErrInvalidBackend 表示无效的队列后端
+	ErrInvalidBackend = errors.New("invalid queue backend")
+)
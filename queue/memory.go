@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend 基于内存的任务存储后端
+// 适用于测试和单实例应用，进程重启后任务会丢失
+type MemoryBackend struct {
+	mu      sync.Mutex
+	ready   map[string][]*Job
+	delayed map[string][]*Job
+	dead    map[string][]*Job
+}
+
+// NewMemoryBackend 创建新的内存任务存储后端
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		ready:   make(map[string][]*Job),
+		delayed: make(map[string][]*Job),
+		dead:    make(map[string][]*Job),
+	}
+}
+
+// Enqueue 保存任务
+func (b *MemoryBackend) Enqueue(_ context.Context, job *Job) error {
+	cp := *job
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cp.RunAt.After(time.Now()) {
+		b.delayed[cp.Queue] = append(b.delayed[cp.Queue], &cp)
+	} else {
+		b.ready[cp.Queue] = append(b.ready[cp.Queue], &cp)
+	}
+	return nil
+}
+
+// Dequeue 取出一个已到期的任务
+func (b *MemoryBackend) Dequeue(_ context.Context, queue string) (*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.promoteDue(queue)
+
+	jobs := b.ready[queue]
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	job := jobs[0]
+	jobs[0] = nil
+	b.ready[queue] = jobs[1:]
+	return job, nil
+}
+
+// DeadLetter 将任务保存到死信队列
+func (b *MemoryBackend) DeadLetter(_ context.Context, job *Job) error {
+	cp := *job
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.dead[cp.Queue] = append(b.dead[cp.Queue], &cp)
+	return nil
+}
+
+// DeadLetters 返回指定队列中的所有死信任务
+func (b *MemoryBackend) DeadLetters(_ context.Context, queue string) ([]*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]*Job, 0, len(b.dead[queue]))
+	for _, job := range b.dead[queue] {
+		cp := *job
+		result = append(result, &cp)
+	}
+	return result, nil
+}
+
+// Size 返回指定队列中等待执行的任务数量
+func (b *MemoryBackend) Size(_ context.Context, queue string) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return int64(len(b.ready[queue]) + len(b.delayed[queue])), nil
+}
+
+// Close 关闭后端
+func (b *MemoryBackend) Close() error {
+	return nil
+}
+
+// promoteDue 将到期的延迟任务移动到就绪队列（调用方需持有锁）
+func (b *MemoryBackend) promoteDue(queue string) {
+	delayed := b.delayed[queue]
+	if len(delayed) == 0 {
+		return
+	}
+
+	now := time.Now()
+	pending := delayed[:0]
+	for _, job := range delayed {
+		if job.RunAt.After(now) {
+			pending = append(pending, job)
+		} else {
+			b.ready[queue] = append(b.ready[queue], job)
+		}
+	}
+
+	// 清理尾部引用，避免内存泄漏
+	for i := len(pending); i < len(delayed); i++ {
+		delayed[i] = nil
+	}
+	b.delayed[queue] = pending
+}
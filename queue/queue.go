@@ -0,0 +1,494 @@
+// Package queue 提供后台任务队列实现
+// 允许在请求处理过程中投递任务，并由后台工作协程异步执行
+//
+// # 主要功能
+//
+// - 类型化任务处理器：通过泛型函数Handle注册强类型的负载处理器
+// - 延迟任务：支持指定延迟时间或执行时间点
+// - 失败重试：按指数退避策略自动重试失败的任务
+// - 死信存储：超过最大重试次数的任务进入死信队列，便于排查
+// - 队列并发度：每个队列可以独立配置工作协程数量
+// - 优雅停止：Stop会等待正在执行的任务完成
+//
+// # 存储后端
+//
+// - MemoryBackend：基于内存的后端，适用于测试和单实例场景
+// - RedisBackend：基于Redis的后端，可复用cache.RedisCache的客户端，任务确认前保存在处理中集合，超时后重新投递
+//
+// # 使用示例
+//
+//	manager := queue.NewManager(queue.NewMemoryBackend())
+//
+//	queue.Handle(manager, "email.send", func(ctx context.Context, p EmailPayload) error {
+//	    return sendEmail(ctx, p)
+//	})
+//
+//	_ = manager.Start(context.Background())
+//	defer manager.Stop(context.Background())
+//
+//	_, err := manager.Enqueue(ctx, "email.send", EmailPayload{To: "a@b.com"},
+//	    queue.WithDelay(time.Minute))
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultQueue 默认队列名称
+const DefaultQueue = "default"
+
+// Job 表示一个待执行的后台任务
+type Job struct {
+	// ID 任务唯一标识
+	ID string `json:"id"`
+	// Queue 任务所属队列
+	Queue string `json:"queue"`
+	// Type 任务类型，用于查找对应的处理器
+	Type string `json:"type"`
+	// Payload 任务负载，JSON编码
+	Payload json.RawMessage `json:"payload"`
+	// Attempts 已执行次数
+	Attempts int `json:"attempts"`
+	// MaxRetries 最大重试次数
+	MaxRetries int `json:"max_retries"`
+	// RunAt 任务最早执行时间
+	RunAt time.Time `json:"run_at"`
+	// CreatedAt 任务创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// LastError 最近一次执行失败的错误信息
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Decode 将任务负载解码到v中
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Backend 任务存储后端接口
+type Backend interface {
+	// Enqueue 保存任务，RunAt晚于当前时间的任务作为延迟任务保存
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Dequeue 取出指定队列中一个已到期的任务，没有可执行任务时返回nil, nil
+	Dequeue(ctx context.Context, queue string) (*Job, error)
+
+	// DeadLetter 将任务保存到死信队列
+	DeadLetter(ctx context.Context, job *Job) error
+
+	// DeadLetters 返回指定队列中的所有死信任务
+	DeadLetters(ctx context.Context, queue string) ([]*Job, error)
+
+	// Size 返回指定队列中等待执行的任务数量（包括延迟任务）
+	Size(ctx context.Context, queue string) (int64, error)
+
+	// Close 关闭后端并释放资源
+	Close() error
+}
+
+// Acknowledger 支持确认任务的后端实现此接口
+// 出队的任务在确认前不会被删除，超时未确认时由后端重新投递，避免工作协程崩溃时丢失任务
+type Acknowledger interface {
+	// Ack 确认任务已处理完成，包括执行成功、重新投递等待重试和转入死信队列
+	Ack(ctx context.Context, job *Job) error
+}
+
+// Handler 任务处理函数
+type Handler func(ctx context.Context, job *Job) error
+
+// Options 任务管理器选项
+type Options struct {
+	// Concurrency 默认队列的工作协程数量
+	Concurrency int
+
+	// Queues 其他队列及其工作协程数量
+	// 只有在这里声明的队列（以及默认队列）才会被消费
+	Queues map[string]int
+
+	// PollInterval 队列为空时的轮询间隔
+	PollInterval time.Duration
+
+	// MaxRetries 任务默认的最大重试次数
+	MaxRetries int
+
+	// RetryBackoff 第一次重试前的等待时间，之后按指数增长
+	RetryBackoff time.Duration
+
+	// MaxRetryBackoff 重试等待时间的上限
+	MaxRetryBackoff time.Duration
+
+	// OnError 后端出错时的回调，例如出队、确认或转入死信队列失败
+	OnError func(err error)
+}
+
+// DefaultOptions 返回默认的任务管理器选项
+func DefaultOptions() Options {
+	return Options{
+		Concurrency:     4,
+		PollInterval:    time.Millisecond * 200,
+		MaxRetries:      3,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute * 5,
+	}
+}
+
+// EnqueueOption 投递任务时的选项
+type EnqueueOption func(job *Job)
+
+// WithQueue 指定任务所属队列
+func WithQueue(queue string) EnqueueOption {
+	return func(job *Job) {
+		job.Queue = queue
+	}
+}
+
+// WithDelay 指定任务延迟执行的时间
+func WithDelay(delay time.Duration) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = time.Now().Add(delay)
+	}
+}
+
+// WithRunAt 指定任务的执行时间点
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = t
+	}
+}
+
+// WithMaxRetries 指定任务的最大重试次数
+func WithMaxRetries(n int) EnqueueOption {
+	return func(job *Job) {
+		job.MaxRetries = n
+	}
+}
+
+// Stats 任务管理器统计数据
+type Stats struct {
+	// Enqueued 投递的任务数
+	Enqueued int64
+	// Processed 执行成功的任务数
+	Processed int64
+	// Failed 执行失败的次数（包括会被重试的失败）
+	Failed int64
+	// Retried 重新投递等待重试的次数
+	Retried int64
+	// DeadLettered 进入死信队列的任务数
+	DeadLettered int64
+}
+
+// Manager 任务管理器，负责投递任务以及调度工作协程执行任务
+type Manager struct {
+	backend  Backend
+	options  Options
+	handlers map[string]Handler
+	mu       sync.RWMutex
+
+	running bool
+	stopped bool
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+
+	// jobCtx 传递给处理器的上下文，在停止超时时被取消
+	jobCtx    context.Context
+	jobCancel context.CancelFunc
+
+	enqueued     atomic.Int64
+	processed    atomic.Int64
+	failed       atomic.Int64
+	retried      atomic.Int64
+	deadLettered atomic.Int64
+}
+
+// NewManager 创建新的任务管理器
+func NewManager(backend Backend, opts ...Options) *Manager {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	} else {
+		options = DefaultOptions()
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultOptions().PollInterval
+	}
+
+	return &Manager{
+		backend:  backend,
+		options:  options,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register 注册任务类型的处理器，重复注册会覆盖之前的处理器
+func (m *Manager) Register(jobType string, handler Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[jobType] = handler
+}
+
+// Handle 注册类型化的任务处理器，任务负载会被解码为T后传给fn
+func Handle[T any](m *Manager, jobType string, fn func(ctx context.Context, payload T) error) {
+	m.Register(jobType, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Decode(&payload); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Enqueue 投递任务，payload会被编码为JSON
+func (m *Manager) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*Job, error) {
+	m.mu.RLock()
+	stopped := m.stopped
+	m.mu.RUnlock()
+	if stopped {
+		return nil, ErrManagerStopped
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize payload: %w", err)
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:         uuid.NewString(),
+		Queue:      DefaultQueue,
+		Type:       jobType,
+		Payload:    data,
+		MaxRetries: m.options.MaxRetries,
+		RunAt:      now,
+		CreatedAt:  now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := m.backend.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	m.enqueued.Add(1)
+	return job, nil
+}
+
+// Start 启动所有队列的工作协程
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return ErrManagerRunning
+	}
+	if m.stopped {
+		return ErrManagerStopped
+	}
+
+	m.running = true
+	m.stopCh = make(chan struct{})
+	m.jobCtx, m.jobCancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	for queue, concurrency := range m.queues() {
+		for i := 0; i < concurrency; i++ {
+			m.wg.Add(1)
+			go m.work(queue)
+		}
+	}
+
+	return nil
+}
+
+// Stop 停止工作协程并等待正在执行的任务完成
+// 如果ctx在任务完成前结束，会取消传递给处理器的上下文并返回ctx的错误
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	wasRunning := m.running
+	m.running = false
+	m.mu.Unlock()
+
+	if !wasRunning {
+		return nil
+	}
+
+	close(m.stopCh)
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.jobCancel()
+		return nil
+	case <-ctx.Done():
+		m.jobCancel()
+		return ctx.Err()
+	}
+}
+
+// Stats 返回任务管理器的统计数据
+func (m *Manager) Stats() Stats {
+	return Stats{
+		Enqueued:     m.enqueued.Load(),
+		Processed:    m.processed.Load(),
+		Failed:       m.failed.Load(),
+		Retried:      m.retried.Load(),
+		DeadLettered: m.deadLettered.Load(),
+	}
+}
+
+// Backend 返回任务存储后端
+func (m *Manager) Backend() Backend {
+	return m.backend
+}
+
+// queues 返回需要消费的队列及其并发度
+func (m *Manager) queues() map[string]int {
+	result := map[string]int{DefaultQueue: m.options.Concurrency}
+	for queue, concurrency := range m.options.Queues {
+		if concurrency <= 0 {
+			concurrency = 1
+		}
+		result[queue] = concurrency
+	}
+	return result
+}
+
+// work 工作协程主循环
+func (m *Manager) work(queue string) {
+	defer m.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		default:
+		}
+
+		job, err := m.backend.Dequeue(m.jobCtx, queue)
+		if err != nil {
+			m.reportError(fmt.Errorf("failed to dequeue job from queue %q: %w", queue, err))
+		} else if job != nil {
+			m.process(job)
+			m.ack(job)
+			continue
+		}
+
+		// 队列为空或后端出错，等待下一次轮询
+		timer.Reset(m.options.PollInterval)
+		select {
+		case <-m.stopCh:
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// process 执行单个任务，并根据执行结果重试或转入死信队列
+func (m *Manager) process(job *Job) {
+	m.mu.RLock()
+	handler, ok := m.handlers[job.Type]
+	m.mu.RUnlock()
+
+	if !ok {
+		job.LastError = fmt.Sprintf("%v: %s", ErrNoHandler, job.Type)
+		m.deadLetter(job)
+		return
+	}
+
+	job.Attempts++
+	err := m.invoke(handler, job)
+	if err == nil {
+		m.processed.Add(1)
+		return
+	}
+
+	m.failed.Add(1)
+	job.LastError = err.Error()
+
+	if job.Attempts > job.MaxRetries {
+		m.deadLetter(job)
+		return
+	}
+
+	job.RunAt = time.Now().Add(m.backoff(job.Attempts))
+	if err := m.backend.Enqueue(m.jobCtx, job); err != nil {
+		job.LastError = fmt.Sprintf("failed to requeue job: %v", err)
+		m.deadLetter(job)
+		return
+	}
+	m.retried.Add(1)
+}
+
+// invoke 调用处理器，并将panic转换为错误
+func (m *Manager) invoke(handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panic: %v", r)
+		}
+	}()
+	return handler(m.jobCtx, job)
+}
+
+// deadLetter 将任务转入死信队列
+func (m *Manager) deadLetter(job *Job) {
+	if err := m.backend.DeadLetter(m.jobCtx, job); err != nil {
+		m.reportError(fmt.Errorf("failed to dead letter job %s: %w", job.ID, err))
+		return
+	}
+	m.deadLettered.Add(1)
+}
+
+// ack 确认任务处理完成，后端不支持确认时忽略
+func (m *Manager) ack(job *Job) {
+	acker, ok := m.backend.(Acknowledger)
+	if !ok {
+		return
+	}
+	if err := acker.Ack(m.jobCtx, job); err != nil {
+		m.reportError(fmt.Errorf("failed to ack job %s: %w", job.ID, err))
+	}
+}
+
+// reportError 通过OnError回调报告后端错误
+func (m *Manager) reportError(err error) {
+	if m.options.OnError != nil {
+		m.options.OnError(err)
+	}
+}
+
+// backoff 计算第attempt次失败后的重试等待时间
+func (m *Manager) backoff(attempt int) time.Duration {
+	delay := m.options.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if m.options.MaxRetryBackoff > 0 && delay >= m.options.MaxRetryBackoff {
+			return m.options.MaxRetryBackoff
+		}
+	}
+	if m.options.MaxRetryBackoff > 0 && delay > m.options.MaxRetryBackoff {
+		return m.options.MaxRetryBackoff
+	}
+	return delay
+}
@@ -0,0 +1,288 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type emailPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+func testOptions() Options {
+	return Options{
+		Concurrency:     2,
+		PollInterval:    time.Millisecond * 5,
+		MaxRetries:      2,
+		RetryBackoff:    time.Millisecond * 10,
+		MaxRetryBackoff: time.Millisecond * 40,
+	}
+}
+
+// 测试类型化处理器
+func TestManagerTypedHandler(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), testOptions())
+
+	received := make(chan emailPayload, 1)
+	Handle(m, "email.send", func(ctx context.Context, p emailPayload) error {
+		received <- p
+		return nil
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	job, err := m.Enqueue(context.Background(), "email.send", emailPayload{To: "a@b.com", Subject: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultQueue, job.Queue)
+	assert.NotEmpty(t, job.ID)
+
+	select {
+	case p := <-received:
+		assert.Equal(t, "a@b.com", p.To)
+		assert.Equal(t, "hi", p.Subject)
+	case <-time.After(time.Second):
+		t.Fatal("任务未被执行")
+	}
+
+	assert.Eventually(t, func() bool { return m.Stats().Processed == 1 }, time.Second, time.Millisecond*5)
+}
+
+// 测试延迟任务
+func TestManagerDelayedJob(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), testOptions())
+
+	var ranAt atomic.Int64
+	m.Register("delayed", func(ctx context.Context, job *Job) error {
+		ranAt.Store(time.Now().UnixNano())
+		return nil
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	start := time.Now()
+	_, err := m.Enqueue(context.Background(), "delayed", nil, WithDelay(time.Millisecond*100))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return ranAt.Load() > 0 }, time.Second, time.Millisecond*5)
+	assert.GreaterOrEqual(t, time.Duration(ranAt.Load()-start.UnixNano()), time.Millisecond*100)
+}
+
+// 测试失败重试和死信
+func TestManagerRetryAndDeadLetter(t *testing.T) {
+	backend := NewMemoryBackend()
+	m := NewManager(backend, testOptions())
+
+	var calls atomic.Int32
+	m.Register("always.fail", func(ctx context.Context, job *Job) error {
+		calls.Add(1)
+		return errors.New("boom")
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	_, err := m.Enqueue(context.Background(), "always.fail", nil)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return m.Stats().DeadLettered == 1 }, time.Second*2, time.Millisecond*5)
+
+	// 首次执行 + 2次重试
+	assert.Equal(t, int32(3), calls.Load())
+
+	stats := m.Stats()
+	assert.Equal(t, int64(3), stats.Failed)
+	assert.Equal(t, int64(2), stats.Retried)
+
+	dead, err := backend.DeadLetters(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Equal(t, "boom", dead[0].LastError)
+}
+
+// 测试重试后成功
+func TestManagerRetrySucceeds(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), testOptions())
+
+	var calls atomic.Int32
+	m.Register("flaky", func(ctx context.Context, job *Job) error {
+		if calls.Add(1) < 2 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	_, err := m.Enqueue(context.Background(), "flaky", nil)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return m.Stats().Processed == 1 }, time.Second, time.Millisecond*5)
+	assert.Equal(t, int64(0), m.Stats().DeadLettered)
+}
+
+// 测试panic隔离和未注册处理器
+func TestManagerPanicAndMissingHandler(t *testing.T) {
+	backend := NewMemoryBackend()
+	m := NewManager(backend, testOptions())
+
+	m.Register("panic", func(ctx context.Context, job *Job) error {
+		panic("unexpected")
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	_, err := m.Enqueue(context.Background(), "panic", nil, WithMaxRetries(0))
+	require.NoError(t, err)
+	_, err = m.Enqueue(context.Background(), "unknown", nil)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return m.Stats().DeadLettered == 2 }, time.Second, time.Millisecond*5)
+
+	dead, err := backend.DeadLetters(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	require.Len(t, dead, 2)
+	for _, job := range dead {
+		switch job.Type {
+		case "panic":
+			assert.Contains(t, job.LastError, "panic")
+		case "unknown":
+			assert.Contains(t, job.LastError, ErrNoHandler.Error())
+		}
+	}
+}
+
+// 测试队列并发度
+func TestManagerQueueConcurrency(t *testing.T) {
+	opts := testOptions()
+	opts.Queues = map[string]int{"reports": 3}
+	m := NewManager(NewMemoryBackend(), opts)
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	m.Register("report", func(ctx context.Context, job *Job) error {
+		n := running.Add(1)
+		for {
+			cur := maxRunning.Load()
+			if n <= cur || maxRunning.CompareAndSwap(cur, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+
+	for i := 0; i < 6; i++ {
+		_, err := m.Enqueue(context.Background(), "report", i, WithQueue("reports"))
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool { return running.Load() == 3 }, time.Second, time.Millisecond*5)
+	close(release)
+
+	assert.Eventually(t, func() bool { return m.Stats().Processed == 6 }, time.Second, time.Millisecond*5)
+	assert.Equal(t, int32(3), maxRunning.Load())
+	require.NoError(t, m.Stop(context.Background()))
+}
+
+// 测试优雅停止
+func TestManagerGracefulStop(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), testOptions())
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	m.Register("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		time.Sleep(time.Millisecond * 100)
+		finished.Store(true)
+		return nil
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	_, err := m.Enqueue(context.Background(), "slow", nil)
+	require.NoError(t, err)
+
+	<-started
+	require.NoError(t, m.Stop(context.Background()))
+	assert.True(t, finished.Load())
+
+	// 停止后不再接受新任务
+	_, err = m.Enqueue(context.Background(), "slow", nil)
+	assert.ErrorIs(t, err, ErrManagerStopped)
+}
+
+// 测试停止超时
+func TestManagerStopTimeout(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), testOptions())
+
+	started := make(chan struct{})
+	m.Register("blocking", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	_, err := m.Enqueue(context.Background(), "blocking", nil)
+	require.NoError(t, err)
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	assert.ErrorIs(t, m.Stop(ctx), context.DeadlineExceeded)
+}
+
+// 测试退避计算
+func TestManagerBackoff(t *testing.T) {
+	m := NewManager(NewMemoryBackend(), Options{
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Second * 5,
+	})
+
+	assert.Equal(t, time.Second, m.backoff(1))
+	assert.Equal(t, time.Second*2, m.backoff(2))
+	assert.Equal(t, time.Second*4, m.backoff(3))
+	assert.Equal(t, time.Second*5, m.backoff(4))
+	assert.Equal(t, time.Second*5, m.backoff(10))
+}
+
+// 测试内存后端
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "1", Queue: "q", RunAt: time.Now()}))
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "2", Queue: "q", RunAt: time.Now().Add(time.Hour)}))
+
+	size, err := b.Size(ctx, "q")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), size)
+
+	job, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "1", job.ID)
+
+	// 延迟任务未到期
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = b.Dequeue(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+}
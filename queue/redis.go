@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guanzhenxing/go-snap/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// dequeueScript 将处理超时的任务放回就绪列表头部，将到期的延迟任务移动到就绪列表，
+// 然后弹出一个就绪任务并以ARGV[3]为截止时间放入处理中集合
+// KEYS[1]: 就绪列表  KEYS[2]: 延迟有序集合  KEYS[3]: 处理中有序集合
+// ARGV[1]: 当前时间（毫秒）  ARGV[2]: 单次最多移动的任务数  ARGV[3]: 处理截止时间（毫秒）
+var dequeueScript = redis.NewScript(`
+	local expired = redis.call("ZRANGEBYSCORE", KEYS[3], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[2]))
+	for _, member in ipairs(expired) do
+		redis.call("ZREM", KEYS[3], member)
+		redis.call("RPUSH", KEYS[1], member)
+	end
+	local due = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[2]))
+	for _, member in ipairs(due) do
+		redis.call("ZREM", KEYS[2], member)
+		redis.call("LPUSH", KEYS[1], member)
+	end
+	local job = redis.call("RPOP", KEYS[1])
+	if job then
+		redis.call("ZADD", KEYS[3], ARGV[3], job)
+	end
+	return job
+`)
+
+// RedisBackendOptions Redis任务存储后端选项
+type RedisBackendOptions struct {
+	// KeyPrefix 所有队列键的统一前缀
+	KeyPrefix string
+
+	// PromoteBatch 每次出队时最多移动的到期延迟任务数和超时任务数
+	PromoteBatch int
+
+	// VisibilityTimeout 任务出队后等待确认的时间
+	// 超过该时间仍未确认的任务（例如工作协程所在进程崩溃）会被重新投递，
+	// 执行时间可能超过该值的任务会被重复执行，因此应大于任务的最长执行时间
+	VisibilityTimeout time.Duration
+}
+
+// DefaultRedisBackendOptions 返回默认的Redis后端选项
+func DefaultRedisBackendOptions() RedisBackendOptions {
+	return RedisBackendOptions{
+		KeyPrefix:         "queue",
+		PromoteBatch:      100,
+		VisibilityTimeout: time.Minute * 5,
+	}
+}
+
+// RedisBackend 基于Redis的任务存储后端
+// 就绪任务保存在列表中，延迟任务保存在以执行时间为分值的有序集合中，死信任务保存在单独的列表中
+// 出队的任务保存在以处理截止时间为分值的有序集合中，确认后移除，超时未确认时重新投递
+// 同一队列的键使用相同的hash tag，保证集群模式下Lua脚本可以正常执行
+type RedisBackend struct {
+	client  redis.UniversalClient
+	options RedisBackendOptions
+
+	// inflight 本实例出队但尚未确认的任务，键为任务ID，值为处理中集合的成员
+	mu       sync.Mutex
+	inflight map[string]string
+}
+
+// NewRedisBackend 创建新的Redis任务存储后端
+func NewRedisBackend(client redis.UniversalClient, opts ...RedisBackendOptions) *RedisBackend {
+	var options RedisBackendOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	} else {
+		options = DefaultRedisBackendOptions()
+	}
+
+	if options.PromoteBatch <= 0 {
+		options.PromoteBatch = DefaultRedisBackendOptions().PromoteBatch
+	}
+	if options.VisibilityTimeout <= 0 {
+		options.VisibilityTimeout = DefaultRedisBackendOptions().VisibilityTimeout
+	}
+
+	return &RedisBackend{
+		client:   client,
+		options:  options,
+		inflight: make(map[string]string),
+	}
+}
+
+// FromRedisCache 复用Redis缓存的客户端创建任务存储后端
+func FromRedisCache(redisCache *cache.RedisCache, opts ...RedisBackendOptions) (*RedisBackend, error) {
+	if redisCache == nil {
+		return nil, ErrInvalidBackend
+	}
+	return NewRedisBackend(redisCache.GetClient(), opts...), nil
+}
+
+// key 生成队列相关的键
+func (b *RedisBackend) key(queue, kind string) string {
+	key := "{" + queue + "}:" + kind
+	if b.options.KeyPrefix == "" {
+		return key
+	}
+	return b.options.KeyPrefix + ":" + key
+}
+
+// Enqueue 保存任务
+func (b *RedisBackend) Enqueue(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to serialize job: %w", err)
+	}
+
+	if job.RunAt.After(time.Now()) {
+		err = b.client.ZAdd(ctx, b.key(job.Queue, "delayed"), redis.Z{
+			Score:  float64(job.RunAt.UnixMilli()),
+			Member: data,
+		}).Err()
+	} else {
+		err = b.client.LPush(ctx, b.key(job.Queue, "ready"), data).Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+// Dequeue 取出一个已到期的任务，任务在Ack之前保存在处理中集合
+func (b *RedisBackend) Dequeue(ctx context.Context, queue string) (*Job, error) {
+	keys := []string{b.key(queue, "ready"), b.key(queue, "delayed"), b.key(queue, "processing")}
+	now := time.Now()
+	data, err := dequeueScript.Run(ctx, b.client, keys, now.UnixMilli(), b.options.PromoteBatch,
+		now.Add(b.options.VisibilityTimeout).UnixMilli()).Text()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to dequeue job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		// 无法解析的任务直接移除，避免超时后被反复投递
+		_ = b.client.ZRem(ctx, b.key(queue, "processing"), data).Err()
+		return nil, fmt.Errorf("failed to deserialize job: %w", err)
+	}
+
+	b.mu.Lock()
+	b.inflight[job.ID] = data
+	b.mu.Unlock()
+	return &job, nil
+}
+
+// Ack 确认任务处理完成，将其从处理中集合移除
+func (b *RedisBackend) Ack(ctx context.Context, job *Job) error {
+	b.mu.Lock()
+	data, ok := b.inflight[job.ID]
+	delete(b.inflight, job.ID)
+	b.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if err := b.client.ZRem(ctx, b.key(job.Queue, "processing"), data).Err(); err != nil {
+		return fmt.Errorf("failed to ack job: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter 将任务保存到死信队列
+func (b *RedisBackend) DeadLetter(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to serialize job: %w", err)
+	}
+
+	if err := b.client.RPush(ctx, b.key(job.Queue, "dead"), data).Err(); err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters 返回指定队列中的所有死信任务
+func (b *RedisBackend) DeadLetters(ctx context.Context, queue string) ([]*Job, error) {
+	items, err := b.client.LRange(ctx, b.key(queue, "dead"), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(items))
+	for _, item := range items {
+		var job Job
+		if err := json.Unmarshal([]byte(item), &job); err != nil {
+			return nil, fmt.Errorf("failed to deserialize job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Size 返回指定队列中等待执行的任务数量
+func (b *RedisBackend) Size(ctx context.Context, queue string) (int64, error) {
+	pipe := b.client.Pipeline()
+	readyCmd := pipe.LLen(ctx, b.key(queue, "ready"))
+	delayedCmd := pipe.ZCard(ctx, b.key(queue, "delayed"))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to get queue size: %w", err)
+	}
+	return readyCmd.Val() + delayedCmd.Val(), nil
+}
+
+// Close 关闭后端
+// 客户端通常与缓存共享，因此这里不会关闭客户端
+func (b *RedisBackend) Close() error {
+	return nil
+}
+
+// GetClient 返回Redis客户端实例
+func (b *RedisBackend) GetClient() redis.UniversalClient {
+	return b.client
+}
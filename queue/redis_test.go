@@ -0,0 +1,205 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 创建测试用的Redis客户端
+func setupRedisTest(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("创建miniredis失败: %s", err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	return mr, client
+}
+
+// 测试Redis后端的基本操作
+func TestRedisBackend(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	b := NewRedisBackend(client)
+
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "1", Queue: "q", Type: "a", RunAt: time.Now()}))
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "2", Queue: "q", Type: "b", RunAt: time.Now()}))
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "3", Queue: "q", Type: "c", RunAt: time.Now().Add(time.Hour)}))
+
+	assert.True(t, mr.Exists("queue:{q}:ready"))
+	assert.True(t, mr.Exists("queue:{q}:delayed"))
+
+	size, err := b.Size(ctx, "q")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+
+	// 先进先出
+	job, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "1", job.ID)
+
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "2", job.ID)
+
+	// 延迟任务未到期
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	// 死信
+	require.NoError(t, b.DeadLetter(ctx, &Job{ID: "4", Queue: "q", LastError: "failed"}))
+	dead, err := b.DeadLetters(ctx, "q")
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "4", dead[0].ID)
+	assert.Equal(t, "failed", dead[0].LastError)
+}
+
+// 测试Redis后端延迟任务到期
+func TestRedisBackendDelayed(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	b := NewRedisBackend(client, RedisBackendOptions{KeyPrefix: "jobs"})
+
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "1", Queue: "q", RunAt: time.Now().Add(time.Millisecond * 50)}))
+
+	job, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	time.Sleep(time.Millisecond * 60)
+
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "1", job.ID)
+	assert.False(t, mr.Exists("jobs:{q}:ready"))
+}
+
+// 测试基于Redis后端的任务管理器
+func TestManagerWithRedisBackend(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	backend := NewRedisBackend(client)
+	m := NewManager(backend, testOptions())
+
+	done := make(chan string, 1)
+	Handle(m, "email.send", func(ctx context.Context, p emailPayload) error {
+		done <- p.To
+		return nil
+	})
+	m.Register("fail", func(ctx context.Context, job *Job) error {
+		return errors.New("boom")
+	})
+
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	_, err := m.Enqueue(context.Background(), "email.send", emailPayload{To: "x@y.com"})
+	require.NoError(t, err)
+	_, err = m.Enqueue(context.Background(), "fail", nil, WithMaxRetries(1))
+	require.NoError(t, err)
+
+	select {
+	case to := <-done:
+		assert.Equal(t, "x@y.com", to)
+	case <-time.After(time.Second):
+		t.Fatal("任务未被执行")
+	}
+
+	assert.Eventually(t, func() bool { return m.Stats().DeadLettered == 1 }, time.Second*2, time.Millisecond*5)
+
+	dead, err := backend.DeadLetters(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.Eventually(t, func() bool { return !mr.Exists("queue:{default}:processing") }, time.Second, time.Millisecond*5,
+		"processed jobs are acked")
+}
+
+// 测试未确认的任务在可见性超时后重新投递
+func TestRedisBackendVisibilityTimeout(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	b := NewRedisBackend(client, RedisBackendOptions{KeyPrefix: "queue", VisibilityTimeout: time.Millisecond * 30})
+
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "1", Queue: "q", RunAt: time.Now()}))
+	require.NoError(t, b.Enqueue(ctx, &Job{ID: "2", Queue: "q", RunAt: time.Now()}))
+
+	// 模拟工作协程取出任务后崩溃，任务保存在处理中集合
+	crashed, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, crashed)
+	assert.Equal(t, "1", crashed.ID)
+	assert.True(t, mr.Exists("queue:{q}:processing"))
+
+	acked, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, acked)
+	require.NoError(t, b.Ack(ctx, acked))
+
+	job, err := b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	assert.Nil(t, job, "任务在超时前不会重新投递")
+
+	time.Sleep(time.Millisecond * 40)
+
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	require.NotNil(t, job, "超时未确认的任务重新投递")
+	assert.Equal(t, "1", job.ID)
+	require.NoError(t, b.Ack(ctx, job))
+
+	time.Sleep(time.Millisecond * 40)
+	job, err = b.Dequeue(ctx, "q")
+	require.NoError(t, err)
+	assert.Nil(t, job, "已确认的任务不会重新投递")
+	assert.False(t, mr.Exists("queue:{q}:processing"))
+}
+
+// 测试任务管理器报告后端错误
+func TestManagerReportsBackendErrors(t *testing.T) {
+	mr, client := setupRedisTest(t)
+	defer mr.Close()
+
+	errs := make(chan error, 16)
+	opts := testOptions()
+	opts.OnError = func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+	m := NewManager(NewRedisBackend(client), opts)
+
+	mr.SetError("connection refused")
+	require.NoError(t, m.Start(context.Background()))
+	defer m.Stop(context.Background())
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "failed to dequeue job")
+	case <-time.After(time.Second):
+		t.Fatal("出队错误未被报告")
+	}
+}
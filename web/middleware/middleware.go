@@ -9,7 +9,8 @@
 // 4. RateLimit - 限制请求频率，防止滥用
 // 5. RequestSizeLimiter - 限制请求体大小，防止大请求DOS攻击
 // 6. JWT/认证 - 验证用户身份
-// 7. 业务中间件 - 应用特定的业务逻辑
+// 7. ResponseCache - 缓存GET响应，放在认证之后以免缓存未授权的请求
+// 8. 业务中间件 - 应用特定的业务逻辑
 //
 // 使用示例：
 //
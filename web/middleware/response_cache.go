@@ -0,0 +1,611 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guanzhenxing/go-snap/cache"
+)
+
+// ResponseCacheOptions 响应缓存选项
+type ResponseCacheOptions struct {
+	// TTL 响应未指定max-age或s-maxage时的缓存时间，默认1分钟
+	TTL time.Duration
+
+	// KeyPrefix 缓存键和标签的前缀，默认"httpcache"
+	KeyPrefix string
+
+	// Methods 可缓存的请求方法，默认只缓存GET
+	Methods []string
+
+	// Statuses 可缓存的响应状态码，默认只缓存200
+	Statuses []int
+
+	// VaryHeaders 参与生成缓存键的请求头，例如Accept、Accept-Language
+	// 这些请求头会加入响应的Vary头；响应的Vary头中包含未配置的请求头时不缓存该响应
+	VaryHeaders []string
+
+	// MaxBodySize 可缓存的最大响应体字节数，超过时直接输出且不缓存，默认1MB
+	MaxBodySize int
+
+	// CacheAuthorized 是否缓存带有Authorization请求头的请求，默认不缓存
+	// 仅当响应内容与用户身份无关时才应启用
+	CacheAuthorized bool
+
+	// CacheCookies 是否缓存带有Cookie请求头的请求，默认不缓存
+	// Cookie通常携带会话，仅当响应内容与Cookie无关时才应启用；
+	// 将Cookie加入VaryHeaders时按Cookie区分缓存项，同样可以缓存
+	CacheCookies bool
+
+	// CacheSetCookie 是否缓存带有Set-Cookie响应头的响应，默认不缓存
+	CacheSetCookie bool
+
+	// Skip 返回true时不读写缓存，用于排除依赖其他用户状态的请求
+	Skip func(c *gin.Context) bool
+
+	// Tags 返回响应的自定义标签，可通过PurgeTags清除
+	// 每个响应还会按路径层级自动打上路径标签，可通过PurgePath清除
+	Tags func(c *gin.Context) []string
+
+	// OnError 读写缓存出错时的回调，缓存出错时请求按未命中处理
+	OnError func(err error)
+}
+
+// DefaultResponseCacheOptions 返回默认的响应缓存选项
+func DefaultResponseCacheOptions() ResponseCacheOptions {
+	return ResponseCacheOptions{
+		TTL:         time.Minute,
+		KeyPrefix:   "httpcache",
+		Methods:     []string{http.MethodGet},
+		Statuses:    []int{http.StatusOK},
+		MaxBodySize: 1 << 20,
+	}
+}
+
+// cachedResponse 缓存的响应，字段导出以便在Redis等缓存中序列化
+type cachedResponse struct {
+	Status       int                 `json:"status"`
+	Header       map[string][]string `json:"header"`
+	Body         []byte              `json:"body"`
+	ETag         string              `json:"etag"`
+	LastModified string              `json:"last_modified"`
+	StoredAt     time.Time           `json:"stored_at"`
+}
+
+// ResponseCache HTTP响应缓存
+// 将GET请求的状态码、响应头和响应体缓存在任意cache.Cache中：
+//   - 缓存键由请求方法、路径、规范化后的查询参数和配置的Vary请求头生成
+//   - 遵循请求的Cache-Control：no-store不读写缓存，no-cache和max-age=0跳过缓存重新生成，max-age限制可接受的缓存时长
+//   - 遵循响应的Cache-Control：no-store、no-cache、private不缓存，s-maxage和max-age决定缓存时间
+//   - 为响应补充ETag和Last-Modified，并对If-None-Match、If-Modified-Since返回304
+//   - 默认不缓存带Authorization或Cookie的请求和带Set-Cookie的响应
+//
+// 命中的响应带有X-Cache: HIT和Age头，未命中的响应带有X-Cache: MISS
+//
+// 示例：
+//
+//	responses := middleware.NewResponseCache(redisCache, middleware.ResponseCacheOptions{
+//	    TTL:         5 * time.Minute,
+//	    VaryHeaders: []string{"Accept-Language"},
+//	})
+//	router.GET("/products/:id", responses.Handler(), handlers.GetProduct)
+//
+//	// 商品更新后清除所有/products/*的缓存
+//	responses.PurgePath(ctx, "/products/*")
+type ResponseCache struct {
+	cache    cache.Cache
+	options  ResponseCacheOptions
+	methods  map[string]bool
+	statuses map[int]bool
+	vary     []string
+}
+
+// NewResponseCache 创建HTTP响应缓存
+// 参数：
+//
+//	c: 缓存实例，可以是MemoryCache、RedisCache、MultiLevelCache等任意实现
+//	opts: 响应缓存选项，如果提供多个，只使用第一个；如果不提供，使用默认选项
+func NewResponseCache(c cache.Cache, opts ...ResponseCacheOptions) *ResponseCache {
+	defaults := DefaultResponseCacheOptions()
+	options := defaults
+	if len(opts) > 0 {
+		options = opts[0]
+		if options.TTL <= 0 {
+			options.TTL = defaults.TTL
+		}
+		if options.KeyPrefix == "" {
+			options.KeyPrefix = defaults.KeyPrefix
+		}
+		if len(options.Methods) == 0 {
+			options.Methods = defaults.Methods
+		}
+		if len(options.Statuses) == 0 {
+			options.Statuses = defaults.Statuses
+		}
+		if options.MaxBodySize <= 0 {
+			options.MaxBodySize = defaults.MaxBodySize
+		}
+	}
+
+	rc := &ResponseCache{
+		cache:    c,
+		options:  options,
+		methods:  make(map[string]bool, len(options.Methods)),
+		statuses: make(map[int]bool, len(options.Statuses)),
+	}
+	for _, method := range options.Methods {
+		rc.methods[strings.ToUpper(method)] = true
+	}
+	for _, status := range options.Statuses {
+		rc.statuses[status] = true
+	}
+	for _, header := range options.VaryHeaders {
+		rc.vary = append(rc.vary, textproto.CanonicalMIMEHeaderKey(header))
+	}
+	sort.Strings(rc.vary)
+	return rc
+}
+
+// CacheResponse 响应缓存中间件，等同于NewResponseCache(c, opts...).Handler()
+//
+// 示例：
+//
+//	router.GET("/articles", middleware.CacheResponse(memoryCache), handlers.ListArticles)
+func CacheResponse(c cache.Cache, opts ...ResponseCacheOptions) gin.HandlerFunc {
+	return NewResponseCache(c, opts...).Handler()
+}
+
+// Handler 返回响应缓存中间件
+func (rc *ResponseCache) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rc.cacheableRequest(c) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		directives := parseCacheControl(c.Request.Header.Values("Cache-Control"))
+		if _, ok := directives["no-store"]; ok {
+			c.Next()
+			return
+		}
+
+		key := rc.key(c)
+		if !revalidate(c.Request.Header, directives) {
+			var entry cachedResponse
+			found, err := cache.GetInto(ctx, rc.cache, key, &entry)
+			if err != nil {
+				rc.reportError(err)
+			}
+			if found && acceptableAge(directives, time.Since(entry.StoredAt)) {
+				rc.serve(c, &entry, true)
+				c.Abort()
+				return
+			}
+		}
+
+		// 之前的中间件设置的响应头（如X-Request-ID）属于当前请求，不写入缓存
+		before := c.Writer.Header().Clone()
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK, limit: rc.options.MaxBodySize}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.passthrough {
+			return
+		}
+
+		entry := &cachedResponse{
+			Status:   writer.status,
+			Header:   addedHeaders(before, c.Writer.Header()),
+			Body:     writer.body.Bytes(),
+			StoredAt: time.Now(),
+		}
+		entry.ETag, entry.LastModified = rc.validators(entry)
+		if ttl, ok := rc.responseTTL(entry.Status, c.Writer.Header()); ok {
+			item := &cache.Item{Value: *entry, Expiration: ttl, Tags: rc.tags(c)}
+			if err := rc.cache.SetItem(ctx, key, item); err != nil {
+				rc.reportError(err)
+			}
+		}
+		rc.serve(c, entry, false)
+	}
+}
+
+// PurgePath 清除路径及其所有子路径的缓存响应
+// path可以以"/*"结尾，例如"/products/*"与"/products"等价，都会清除/products和/products/42的响应
+func (rc *ResponseCache) PurgePath(ctx context.Context, path string) error {
+	path = strings.TrimSuffix(path, "*")
+	return rc.cache.DeleteByTag(ctx, rc.pathTag(cleanPath(path)))
+}
+
+// PurgeTags 清除带有指定自定义标签的缓存响应
+func (rc *ResponseCache) PurgeTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		if err := rc.cache.DeleteByTag(ctx, rc.customTag(tag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheableRequest 判断请求是否可以使用缓存
+func (rc *ResponseCache) cacheableRequest(c *gin.Context) bool {
+	if !rc.methods[c.Request.Method] {
+		return false
+	}
+	if !rc.options.CacheAuthorized && c.GetHeader("Authorization") != "" {
+		return false
+	}
+	if !rc.options.CacheCookies && !rc.varies("Cookie") && c.GetHeader("Cookie") != "" {
+		return false
+	}
+	if rc.options.Skip != nil && rc.options.Skip(c) {
+		return false
+	}
+	return true
+}
+
+// responseTTL 根据响应决定是否缓存及缓存时间
+func (rc *ResponseCache) responseTTL(status int, header http.Header) (time.Duration, bool) {
+	if !rc.statuses[status] {
+		return 0, false
+	}
+	if !rc.options.CacheSetCookie && header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			// 缓存键不包含的请求头会导致不同的响应共用一个缓存项
+			if name == "*" || !rc.varies(name) {
+				return 0, false
+			}
+		}
+	}
+
+	directives := parseCacheControl(header.Values("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[directive]; ok {
+			return 0, false
+		}
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := directives[directive]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return rc.options.TTL, true
+}
+
+// validators 返回响应的ETag和Last-Modified，响应未设置时生成
+func (rc *ResponseCache) validators(entry *cachedResponse) (string, string) {
+	header := http.Header(entry.Header)
+	etag := header.Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(entry.Body)
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	lastModified := header.Get("Last-Modified")
+	if lastModified == "" {
+		lastModified = entry.StoredAt.UTC().Format(http.TimeFormat)
+	}
+	return etag, lastModified
+}
+
+// serve 输出响应，条件请求匹配时返回304
+func (rc *ResponseCache) serve(c *gin.Context, entry *cachedResponse, hit bool) {
+	header := c.Writer.Header()
+	if hit {
+		for name, values := range entry.Header {
+			header[name] = append([]string(nil), values...)
+		}
+		header.Set("X-Cache", "HIT")
+		header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	} else {
+		header.Set("X-Cache", "MISS")
+	}
+	header.Set("ETag", entry.ETag)
+	header.Set("Last-Modified", entry.LastModified)
+	if len(rc.vary) > 0 {
+		header.Set("Vary", mergeVary(header.Values("Vary"), rc.vary))
+	}
+
+	if entry.Status == http.StatusOK && notModified(c.Request.Header, entry.ETag, entry.LastModified) {
+		for _, name := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+			header.Del(name)
+		}
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Writer.WriteHeader(entry.Status)
+	c.Writer.WriteHeaderNow()
+	if len(entry.Body) > 0 {
+		if _, err := c.Writer.Write(entry.Body); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// key 生成缓存键，查询参数和Vary请求头经过规范化后取哈希
+func (rc *ResponseCache) key(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+
+	var b strings.Builder
+	b.WriteString(query.Encode())
+	for _, name := range rc.vary {
+		b.WriteString("\x00")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strings.Join(c.Request.Header.Values(name), ","))
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return fmt.Sprintf("%s:%s:%s:%s", rc.options.KeyPrefix, c.Request.Method, cleanPath(c.Request.URL.Path), hex.EncodeToString(sum[:12]))
+}
+
+// tags 返回响应的路径标签和自定义标签
+// 路径/products/42的路径标签为"/"、"/products"和"/products/42"
+func (rc *ResponseCache) tags(c *gin.Context) []string {
+	path := cleanPath(c.Request.URL.Path)
+	tags := []string{rc.pathTag("/")}
+	for i := 1; i < len(path); i++ {
+		if path[i] == '/' {
+			tags = append(tags, rc.pathTag(path[:i]))
+		}
+	}
+	if path != "/" {
+		tags = append(tags, rc.pathTag(path))
+	}
+
+	if rc.options.Tags != nil {
+		for _, tag := range rc.options.Tags(c) {
+			tags = append(tags, rc.customTag(tag))
+		}
+	}
+	return tags
+}
+
+// varies 判断请求头是否参与生成缓存键
+func (rc *ResponseCache) varies(name string) bool {
+	i := sort.SearchStrings(rc.vary, name)
+	return i < len(rc.vary) && rc.vary[i] == name
+}
+
+func (rc *ResponseCache) pathTag(path string) string {
+	return rc.options.KeyPrefix + ":path:" + path
+}
+
+func (rc *ResponseCache) customTag(tag string) string {
+	return rc.options.KeyPrefix + ":tag:" + tag
+}
+
+func (rc *ResponseCache) reportError(err error) {
+	if rc.options.OnError != nil {
+		rc.options.OnError(err)
+	}
+}
+
+// cleanPath 去掉路径末尾的斜杠，空路径视为根路径
+func cleanPath(path string) string {
+	path = strings.TrimRight(path, "/")
+	if path == "" {
+		return "/"
+	}
+	if path[0] != '/' {
+		return "/" + path
+	}
+	return path
+}
+
+// parseCacheControl 解析Cache-Control指令，指令名转为小写，无值的指令值为空字符串
+func parseCacheControl(values []string) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, arg, _ := strings.Cut(part, "=")
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return directives
+}
+
+// revalidate 判断请求是否要求跳过缓存重新生成响应
+func revalidate(header http.Header, directives map[string]string) bool {
+	if _, ok := directives["no-cache"]; ok {
+		return true
+	}
+	if value, ok := directives["max-age"]; ok && value == "0" {
+		return true
+	}
+	_, hasCacheControl := header["Cache-Control"]
+	return !hasCacheControl && strings.EqualFold(header.Get("Pragma"), "no-cache")
+}
+
+// acceptableAge 判断缓存的响应是否满足请求的max-age
+func acceptableAge(directives map[string]string, age time.Duration) bool {
+	value, ok := directives["max-age"]
+	if !ok {
+		return true
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return true
+	}
+	return age <= time.Duration(seconds)*time.Second
+}
+
+// notModified 判断条件请求是否可以返回304
+// 存在If-None-Match时忽略If-Modified-Since
+func notModified(header http.Header, etag, lastModified string) bool {
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		modified, err := http.ParseTime(lastModified)
+		if err != nil {
+			return false
+		}
+		return !modified.After(since)
+	}
+	return false
+}
+
+// addedHeaders 返回处理过程中新增或修改的响应头
+func addedHeaders(before, after http.Header) map[string][]string {
+	added := make(map[string][]string)
+	for name, values := range after {
+		if old, ok := before[name]; ok && strings.Join(old, "\x00") == strings.Join(values, "\x00") {
+			continue
+		}
+		added[name] = append([]string(nil), values...)
+	}
+	return added
+}
+
+// mergeVary 合并响应已有的Vary头和配置的Vary请求头
+func mergeVary(existing []string, vary []string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, value := range append(existing, vary...) {
+		for _, name := range strings.Split(value, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// bufferedWriter 缓冲响应状态码和响应体，以便在输出前生成ETag并写入缓存
+// 响应体超过限制或调用Flush时切换为直接输出，此时响应不会被缓存
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status      int
+	written     bool
+	body        bytes.Buffer
+	limit       int
+	passthrough bool
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	if w.body.Len()+len(data) > w.limit {
+		if err := w.startPassthrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+func (w *bufferedWriter) Flush() {
+	if !w.passthrough {
+		if err := w.startPassthrough(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// startPassthrough 输出已缓冲的内容并切换为直接输出
+func (w *bufferedWriter) startPassthrough() error {
+	w.passthrough = true
+	w.ResponseWriter.Header().Set("X-Cache", "MISS")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	w.body.Reset()
+	return err
+}
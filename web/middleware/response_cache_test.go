@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guanzhenxing/go-snap/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResponseCacheRouter 创建带响应缓存的路由，返回每个路径的处理次数
+func newResponseCacheRouter(t *testing.T, opts ...ResponseCacheOptions) (*gin.Engine, *ResponseCache, map[string]int) {
+	gin.SetMode(gin.TestMode)
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	responses := NewResponseCache(c, opts...)
+	calls := make(map[string]int)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Header("X-Request-ID", fmt.Sprint(len(calls), c.Request.URL.String()))
+		c.Next()
+	})
+	router.Use(responses.Handler())
+
+	handler := func(c *gin.Context) {
+		calls[c.Request.URL.Path]++
+		c.String(http.StatusOK, "%s #%d %s", c.Request.URL.Path, calls[c.Request.URL.Path], c.GetHeader("Accept-Language"))
+	}
+	router.GET("/products", handler)
+	router.GET("/products/:id", handler)
+	router.GET("/orders/:id", handler)
+	router.GET("/private", func(c *gin.Context) {
+		calls["/private"]++
+		c.Header("Cache-Control", "private")
+		c.String(http.StatusOK, "private")
+	})
+	router.GET("/short", func(c *gin.Context) {
+		calls["/short"]++
+		c.Header("Cache-Control", "max-age=0")
+		c.String(http.StatusOK, "short")
+	})
+	router.GET("/cookie", func(c *gin.Context) {
+		calls["/cookie"]++
+		c.SetCookie("session", "abc", 60, "/", "", false, true)
+		c.String(http.StatusOK, "cookie")
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		calls["/missing"]++
+		c.String(http.StatusNotFound, "missing")
+	})
+	return router, responses, calls
+}
+
+func doRequest(router http.Handler, target string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCache_HitAndMiss(t *testing.T) {
+	router, _, calls := newResponseCacheRouter(t)
+
+	first := doRequest(router, "/products?b=2&a=1", nil)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.NotEmpty(t, first.Header().Get("ETag"))
+	assert.NotEmpty(t, first.Header().Get("Last-Modified"))
+
+	second := doRequest(router, "/products?a=1&b=2", nil)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.Equal(t, "text/plain; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.NotEqual(t, first.Header().Get("X-Request-ID"), second.Header().Get("X-Request-ID"), "headers set before the middleware are not cached")
+	assert.Equal(t, 1, calls["/products"])
+
+	doRequest(router, "/products?a=1", nil)
+	assert.Equal(t, 2, calls["/products"], "different query is a different entry")
+}
+
+func TestResponseCache_VaryHeaders(t *testing.T) {
+	router, _, calls := newResponseCacheRouter(t, ResponseCacheOptions{VaryHeaders: []string{"accept-language"}})
+
+	zh := doRequest(router, "/products/1", map[string]string{"Accept-Language": "zh"})
+	en := doRequest(router, "/products/1", map[string]string{"Accept-Language": "en"})
+	assert.Contains(t, zh.Body.String(), "zh")
+	assert.Contains(t, en.Body.String(), "en")
+	assert.Equal(t, "Accept-Language", en.Header().Get("Vary"))
+
+	again := doRequest(router, "/products/1", map[string]string{"Accept-Language": "zh"})
+	assert.Equal(t, "HIT", again.Header().Get("X-Cache"))
+	assert.Contains(t, again.Body.String(), "zh")
+	assert.Equal(t, 2, calls["/products/1"])
+}
+
+func TestResponseCache_RequestCacheControl(t *testing.T) {
+	router, _, calls := newResponseCacheRouter(t)
+
+	doRequest(router, "/products", nil)
+	doRequest(router, "/products", map[string]string{"Cache-Control": "no-cache"})
+	assert.Equal(t, 2, calls["/products"], "no-cache forces regeneration")
+
+	refreshed := doRequest(router, "/products", nil)
+	assert.Contains(t, refreshed.Body.String(), "#2", "regenerated response replaces the cached one")
+
+	doRequest(router, "/products", map[string]string{"Cache-Control": "no-store"})
+	assert.Equal(t, 3, calls["/products"])
+	assert.Contains(t, doRequest(router, "/products", nil).Body.String(), "#2", "no-store does not write the cache")
+
+	doRequest(router, "/products", map[string]string{"Cache-Control": "max-age=3600"})
+	assert.Equal(t, 3, calls["/products"])
+}
+
+func TestResponseCache_NotCacheable(t *testing.T) {
+	router, _, calls := newResponseCacheRouter(t)
+
+	for _, path := range []string{"/private", "/short", "/cookie", "/missing"} {
+		doRequest(router, path, nil)
+		w := doRequest(router, path, nil)
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"), path)
+		assert.Equal(t, 2, calls[path], path)
+	}
+
+	doRequest(router, "/orders/1", map[string]string{"Authorization": "Bearer token"})
+	doRequest(router, "/orders/1", map[string]string{"Authorization": "Bearer token"})
+	assert.Equal(t, 2, calls["/orders/1"], "authorized requests bypass the cache")
+}
+
+func TestResponseCache_Cookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(opts ResponseCacheOptions) *gin.Engine {
+		c := cache.NewMemoryCache()
+		t.Cleanup(func() { c.Close() })
+		router := gin.New()
+		router.Use(NewResponseCache(c, opts).Handler())
+		router.GET("/me", func(c *gin.Context) {
+			session, _ := c.Cookie("session")
+			c.String(http.StatusOK, "hello %s", session)
+		})
+		return router
+	}
+
+	// 默认不缓存带Cookie的请求，避免会话相关的响应返回给其他用户
+	router := newRouter(ResponseCacheOptions{})
+	alice := doRequest(router, "/me", map[string]string{"Cookie": "session=alice"})
+	bob := doRequest(router, "/me", map[string]string{"Cookie": "session=bob"})
+	assert.Equal(t, "hello alice", alice.Body.String())
+	assert.Equal(t, "hello bob", bob.Body.String())
+	assert.Empty(t, bob.Header().Get("X-Cache"))
+
+	// Cookie参与缓存键时按Cookie区分缓存项
+	router = newRouter(ResponseCacheOptions{VaryHeaders: []string{"Cookie"}})
+	doRequest(router, "/me", map[string]string{"Cookie": "session=alice"})
+	bob = doRequest(router, "/me", map[string]string{"Cookie": "session=bob"})
+	assert.Equal(t, "hello bob", bob.Body.String())
+	again := doRequest(router, "/me", map[string]string{"Cookie": "session=alice"})
+	assert.Equal(t, "HIT", again.Header().Get("X-Cache"))
+	assert.Equal(t, "hello alice", again.Body.String())
+
+	// 显式启用时忽略Cookie
+	router = newRouter(ResponseCacheOptions{CacheCookies: true})
+	doRequest(router, "/me", map[string]string{"Cookie": "theme=dark"})
+	shared := doRequest(router, "/me", map[string]string{"Cookie": "theme=light"})
+	assert.Equal(t, "HIT", shared.Header().Get("X-Cache"))
+}
+
+func TestResponseCache_ConditionalRequests(t *testing.T) {
+	router, _, _ := newResponseCacheRouter(t)
+
+	first := doRequest(router, "/products/1", nil)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w := doRequest(router, "/products/1", map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = doRequest(router, "/products/1", map[string]string{"If-None-Match": `"other"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(router, "/products/1", map[string]string{"If-Modified-Since": first.Header().Get("Last-Modified")})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// 未命中时生成的ETag同样可以匹配
+	w = doRequest(router, "/products/2", map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestResponseCache_Purge(t *testing.T) {
+	router, responses, calls := newResponseCacheRouter(t, ResponseCacheOptions{
+		Tags: func(c *gin.Context) []string {
+			if strings.HasPrefix(c.Request.URL.Path, "/orders") {
+				return []string{"orders"}
+			}
+			return nil
+		},
+	})
+	ctx := context.Background()
+
+	for _, path := range []string{"/products", "/products/1", "/products/2", "/orders/1"} {
+		doRequest(router, path, nil)
+	}
+
+	require.NoError(t, responses.PurgePath(ctx, "/products/*"))
+	for _, path := range []string{"/products", "/products/1", "/products/2", "/orders/1"} {
+		doRequest(router, path, nil)
+	}
+	assert.Equal(t, 2, calls["/products"])
+	assert.Equal(t, 2, calls["/products/1"])
+	assert.Equal(t, 2, calls["/products/2"])
+	assert.Equal(t, 1, calls["/orders/1"])
+
+	require.NoError(t, responses.PurgeTags(ctx, "orders"))
+	doRequest(router, "/orders/1", nil)
+	assert.Equal(t, 2, calls["/orders/1"])
+}
+
+func TestResponseCache_MaxBodySize(t *testing.T) {
+	router, _, calls := newResponseCacheRouter(t, ResponseCacheOptions{MaxBodySize: 8})
+
+	first := doRequest(router, "/products/1", nil)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Contains(t, first.Body.String(), "/products/1 #1")
+
+	doRequest(router, "/products/1", nil)
+	assert.Equal(t, 2, calls["/products/1"], "responses larger than MaxBodySize are not cached")
+}